@@ -4,10 +4,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/joeyhipolito/obsidian-cli/internal/cmd"
 	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/template"
 )
 
 const version = "0.1.0"
@@ -55,7 +57,7 @@ func run() error {
 	forceFlag := false
 	applyFlag := false
 	fixFlag := false
-	staleDays := 30
+	staleDays := 0 // 0 = the stale check's configured threshold
 	sourceFlag := ""
 	ingestTopic := ""
 	ingestDomain := ""
@@ -113,7 +115,7 @@ func run() error {
 		return cmd.ConfigureCmd()
 	case "doctor":
 		return cmd.DoctorCmd(jsonOutput)
-	case "read", "append", "capture", "create", "list", "search", "index", "sync", "enrich", "maintain", "ingest", "triage", "resurface", "auto-capture", "promote":
+	case "read", "append", "capture", "create", "daily", "list", "search", "index", "sync", "enrich", "maintain", "health", "validate", "ingest", "triage", "resurface", "review", "auto-capture", "digest", "promote", "moc", "mentions", "graph", "history", "diff", "attachments", "dedupe":
 		// handled below after vault resolution
 	default:
 		return fmt.Errorf("unknown command: %s\n\nRun 'obsidian --help' for usage", subcommand)
@@ -142,6 +144,9 @@ func run() error {
 	case "create":
 		return handleCreateCommand(vaultPath, filteredArgs, jsonOutput)
 
+	case "daily":
+		return handleDailyCommand(vaultPath, filteredArgs, jsonOutput)
+
 	case "list":
 		dir := ""
 		if len(filteredArgs) > 0 {
@@ -163,10 +168,16 @@ func run() error {
 		return cmd.SyncCmd(vaultPath, websitePath, dryRun, forceFlag, jsonOutput)
 
 	case "enrich":
-		return cmd.EnrichCmd(vaultPath, applyFlag, jsonOutput)
+		return handleEnrichCommand(vaultPath, filteredArgs, applyFlag, jsonOutput)
 
 	case "maintain":
-		return cmd.MaintainCmd(vaultPath, staleDays, fixFlag, jsonOutput)
+		return handleMaintainCommand(vaultPath, filteredArgs, staleDays, fixFlag, dryRun, jsonOutput)
+
+	case "health":
+		return handleHealthCommand(vaultPath, filteredArgs, jsonOutput)
+
+	case "validate":
+		return handleValidateCommand(vaultPath, filteredArgs, fixFlag, jsonOutput)
 
 	case "ingest":
 		return cmd.IngestCmd(vaultPath, cmd.IngestOptions{
@@ -184,6 +195,9 @@ func run() error {
 	case "resurface":
 		return handleResurfaceCommand(vaultPath, filteredArgs, jsonOutput)
 
+	case "review":
+		return handleReviewCommand(vaultPath, filteredArgs, jsonOutput)
+
 	case "auto-capture":
 		return cmd.AutoCaptureCmd(vaultPath, cmd.AutoCaptureOptions{
 			Since:      ingestSince,
@@ -191,16 +205,55 @@ func run() error {
 			JSONOutput: jsonOutput,
 		})
 
+	case "digest":
+		return handleDigestCommand(vaultPath, filteredArgs, ingestSince, staleDays, dryRun, jsonOutput)
+
 	case "promote":
-		return cmd.PromoteCmd(vaultPath, cmd.PromoteOptions{
-			DryRun:     dryRun,
-			JSONOutput: jsonOutput,
-		})
+		return handlePromoteCommand(vaultPath, filteredArgs, dryRun, jsonOutput)
+
+	case "moc":
+		return handleMocCommand(vaultPath, filteredArgs, dryRun, jsonOutput)
+
+	case "mentions":
+		return handleMentionsCommand(vaultPath, filteredArgs, applyFlag, jsonOutput)
+
+	case "graph":
+		return handleGraphCommand(vaultPath, filteredArgs, jsonOutput)
+
+	case "history":
+		if len(filteredArgs) < 1 {
+			return fmt.Errorf("history requires a note path\n\nUsage: obsidian history <path>")
+		}
+		return cmd.HistoryCmd(vaultPath, filteredArgs[0], jsonOutput)
+
+	case "diff":
+		return handleDiffCommand(vaultPath, filteredArgs, jsonOutput)
+
+	case "attachments":
+		return handleAttachmentsCommand(vaultPath, filteredArgs, dryRun, jsonOutput)
+
+	case "dedupe":
+		return handleDedupeCommand(vaultPath, filteredArgs, jsonOutput)
 	}
 
 	return nil
 }
 
+// handleDigestCommand parses and executes the digest command. --since,
+// --stale-days and --dry-run are already extracted by the global flag loop.
+func handleDigestCommand(vaultPath string, args []string, since string, staleDays int, dryRun, jsonOutput bool) error {
+	opts := cmd.DigestOptions{Since: since, StaleDays: staleDays, DryRun: dryRun, JSONOutput: jsonOutput}
+	for _, arg := range args {
+		switch arg {
+		case "--narrative":
+			opts.Narrative = true
+		default:
+			return fmt.Errorf("unknown digest flag: %s", arg)
+		}
+	}
+	return cmd.DigestCmd(vaultPath, opts)
+}
+
 // parseInt parses a string to int, returning an error if invalid.
 func parseInt(s string) (int, error) {
 	n := 0
@@ -217,25 +270,81 @@ func parseInt(s string) (int, error) {
 // The body comes from the first positional argument; --source is already
 // extracted into sourceFlag by the global flag loop.
 func handleCaptureCommand(vaultPath string, args []string, source string, jsonOutput bool) error {
-	for _, arg := range args {
-		if arg == "--help" || arg == "-h" {
-			fmt.Print(`Usage: obsidian capture <body> [--source <url>]
+	opts := cmd.CaptureOptions{Source: source, JSONOutput: jsonOutput}
+	fetchURL := ""
+	var bodyParts, vars []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--help", "-h":
+			fmt.Print(`Usage: obsidian capture <body> [--source <url>] [--title <t>] [--tags a,b] [--to <folder>] [--id <key>] [--template <t>]
        echo 'text' | obsidian capture
+       obsidian capture --fetch <url>
 
 Options:
   --source <url>   URL or origin of the capture
+  --title <title>  Note title (also names the file)
+  --tags <a,b>     Comma-separated tags to add
+  --to <folder>    Write to this folder instead of Inbox/
+  --id <key>       Idempotency key: re-running with the same key does nothing
+  --format <f>     Input format: auto (default), text, or json (JSON object,
+                   array, or NDJSON with id, title, body, source, tags, to)
+  --template <t>   Render each capture through a note template; the text
+                   goes where the template has {{content}}
+  --var key=value  Template variable (repeatable)
+  --fetch <url>    Fetch the page, extract the article and save it as a
+                   reference note in References/ (limits: fetch_max_bytes,
+                   fetch_max_chars, fetch_timeout in config)
   --json           Output in JSON format
   --help, -h       Show this help
+
+Piped text that starts with frontmatter keeps its properties.
 `)
 			return nil
+		case "--fetch", "--title", "--tags", "--to", "--id", "--format", "--template", "--var":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires an argument", args[i])
+			}
+			value := args[i+1]
+			i++
+			switch args[i-1] {
+			case "--fetch":
+				fetchURL = value
+			case "--title":
+				opts.Title = value
+			case "--tags":
+				for _, t := range strings.Split(value, ",") {
+					if t = strings.TrimSpace(t); t != "" {
+						opts.Tags = append(opts.Tags, t)
+					}
+				}
+			case "--to":
+				opts.To = value
+			case "--id":
+				opts.ID = value
+			case "--format":
+				opts.Format = value
+			case "--template":
+				opts.Template = value
+			case "--var":
+				vars = append(vars, value)
+			}
+		default:
+			bodyParts = append(bodyParts, args[i])
 		}
 	}
 
-	body := ""
-	if len(args) > 0 {
-		body = strings.Join(args, " ")
+	var err error
+	if opts.Vars, err = template.ParseVars(vars); err != nil {
+		return err
 	}
-	return cmd.CaptureCmd(vaultPath, body, source, jsonOutput)
+	if fetchURL != "" {
+		if len(bodyParts) > 0 || source != "" || opts.Format != "" {
+			return fmt.Errorf("--fetch cannot be combined with a body, --source or --format")
+		}
+		return cmd.CaptureFetchCmd(vaultPath, fetchURL, opts)
+	}
+	opts.Body = strings.Join(bodyParts, " ")
+	return cmd.CaptureCmd(vaultPath, opts)
 }
 
 // handleAppendCommand parses and executes the append command.
@@ -272,6 +381,7 @@ func handleCreateCommand(vaultPath string, args []string, jsonOutput bool) error
 	}
 	notePath := args[0]
 	var opts cmd.CreateOptions
+	var vars []string
 
 	remaining := args[1:]
 	for i := 0; i < len(remaining); i++ {
@@ -323,14 +433,57 @@ func handleCreateCommand(vaultPath string, args []string, jsonOutput bool) error
 			}
 			opts.Template = remaining[i+1]
 			i++
+		case "--var":
+			if i+1 >= len(remaining) {
+				return fmt.Errorf("--var requires an argument")
+			}
+			vars = append(vars, remaining[i+1])
+			i++
 		default:
 			return fmt.Errorf("unknown flag: %s", remaining[i])
 		}
 	}
 
+	var err error
+	if opts.Vars, err = template.ParseVars(vars); err != nil {
+		return err
+	}
 	return cmd.CreateCmd(vaultPath, notePath, opts, jsonOutput)
 }
 
+// handleDailyCommand parses and executes the daily command.
+func handleDailyCommand(vaultPath string, args []string, jsonOutput bool) error {
+	opts := cmd.DailyOptions{JSONOutput: jsonOutput}
+	var vars []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--date", "--template", "--var":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires an argument", args[i])
+			}
+			switch args[i] {
+			case "--date":
+				opts.Date = args[i+1]
+			case "--template":
+				opts.Template = args[i+1]
+			case "--var":
+				vars = append(vars, args[i+1])
+			}
+			i++
+		default:
+			if opts.Date != "" || strings.HasPrefix(args[i], "--") {
+				return fmt.Errorf("unknown daily argument: %s\n\nUsage: obsidian daily [--date <day>] [--template <t>] [--var key=value]", args[i])
+			}
+			opts.Date = args[i]
+		}
+	}
+	var err error
+	if opts.Vars, err = template.ParseVars(vars); err != nil {
+		return err
+	}
+	return cmd.DailyCmd(vaultPath, opts)
+}
+
 // handleTriageCommand parses and executes the triage command.
 func handleTriageCommand(vaultPath string, args []string, dryRun, jsonOutput bool) error {
 	opts := cmd.TriageOptions{
@@ -344,6 +497,18 @@ func handleTriageCommand(vaultPath string, args []string, dryRun, jsonOutput boo
 			opts.List = true
 		case "--auto":
 			opts.Auto = true
+		case "--interactive", "-i":
+			opts.Interactive = true
+		case "--titles":
+			opts.Titles = true
+		case "--summaries":
+			opts.Summaries = true
+		case "--merge-duplicates":
+			opts.MergeDuplicates = true
+		case "--train":
+			opts.Train = true
+		case "--eval":
+			opts.Eval = true
 		case "--older":
 			if i+1 >= len(args) {
 				return fmt.Errorf("--older requires an argument (e.g. 7d, 24h)")
@@ -360,6 +525,86 @@ func handleTriageCommand(vaultPath string, args []string, dryRun, jsonOutput boo
 	return cmd.TriageCmd(vaultPath, opts)
 }
 
+// handleMaintainCommand parses and executes the maintain command.
+// --fix, --stale-days and --dry-run are already extracted by the global flag loop.
+func handleMaintainCommand(vaultPath string, args []string, staleDays int, fix, dryRun, jsonOutput bool) error {
+	opts := cmd.MaintainOptions{
+		StaleDays:  staleDays,
+		Fix:        fix,
+		DryRun:     dryRun,
+		JSONOutput: jsonOutput,
+	}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--fix-links":
+			opts.FixLinks = true
+		case "--check":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--check requires a check name (see --list-checks)")
+			}
+			i++
+			opts.Check = args[i]
+		case "--list-checks":
+			opts.ListChecks = true
+		default:
+			return fmt.Errorf("unknown maintain flag: %s", args[i])
+		}
+	}
+
+	return cmd.MaintainCmd(vaultPath, opts)
+}
+
+// handleHealthCommand parses and executes the health command.
+func handleHealthCommand(vaultPath string, args []string, jsonOutput bool) error {
+	opts := cmd.HealthOptions{JSONOutput: jsonOutput}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--trend":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--trend requires a window, e.g. 90d")
+			}
+			opts.Trend = args[i+1]
+			i++
+		case "--threshold":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--threshold requires a percentage")
+			}
+			t, err := strconv.ParseFloat(args[i+1], 64)
+			if err != nil || t <= 0 {
+				return fmt.Errorf("--threshold must be a positive percentage, got %q", args[i+1])
+			}
+			opts.Threshold = t
+			i++
+		default:
+			return fmt.Errorf("unknown health flag: %s", args[i])
+		}
+	}
+	return cmd.HealthCmd(vaultPath, opts)
+}
+
+// handleValidateCommand parses and executes the validate command. --fix is
+// already extracted by the global flag loop.
+func handleValidateCommand(vaultPath string, args []string, fix, jsonOutput bool) error {
+	opts := cmd.ValidateOptions{Fix: fix, JSONOutput: jsonOutput}
+	for _, arg := range args {
+		switch {
+		case arg == "--all":
+			opts.All = true
+		case strings.HasPrefix(arg, "--"):
+			return fmt.Errorf("unknown validate flag: %s", arg)
+		case opts.Path != "":
+			return fmt.Errorf("validate takes one path\n\nUsage: obsidian validate <path>|--all [--fix]")
+		default:
+			opts.Path = arg
+		}
+	}
+	if opts.Path == "" && !opts.All {
+		return fmt.Errorf("validate requires a note or folder, or --all\n\nUsage: obsidian validate <path>|--all [--fix]")
+	}
+	return cmd.ValidateCmd(vaultPath, opts)
+}
+
 // handleResurfaceCommand parses and executes the resurface command.
 func handleResurfaceCommand(vaultPath string, args []string, jsonOutput bool) error {
 	opts := cmd.ResurfaceOptions{
@@ -396,6 +641,343 @@ func handleResurfaceCommand(vaultPath string, args []string, jsonOutput bool) er
 	return cmd.ResurfaceCmd(vaultPath, query, opts)
 }
 
+// handleReviewCommand parses and executes the review command: list due
+// notes, review them interactively, or grade one note.
+func handleReviewCommand(vaultPath string, args []string, jsonOutput bool) error {
+	opts := cmd.ReviewOptions{JSONOutput: jsonOutput}
+	var positional []string
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--interactive", "-i":
+			opts.Interactive = true
+		case "--limit", "--new":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a number", args[i])
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("%s requires a number", args[i])
+			}
+			if args[i] == "--limit" {
+				opts.Limit = n
+			} else if n == 0 {
+				opts.New = -1
+			} else {
+				opts.New = n
+			}
+			i++
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return fmt.Errorf("unknown review flag: %s", args[i])
+			}
+			positional = append(positional, args[i])
+		}
+	}
+
+	switch len(positional) {
+	case 0:
+		return cmd.ReviewCmd(vaultPath, opts)
+	case 2:
+		return cmd.ReviewGradeCmd(vaultPath, positional[0], positional[1], jsonOutput)
+	default:
+		return fmt.Errorf("usage: obsidian review [--interactive] | obsidian review <path> again|hard|good|easy")
+	}
+}
+
+// handleDiffCommand parses and executes the diff command.
+func handleDiffCommand(vaultPath string, args []string, jsonOutput bool) error {
+	notePath := ""
+	rev := 0
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--rev":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--rev requires a revision number")
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil || n == 0 {
+				return fmt.Errorf("--rev requires a revision number")
+			}
+			rev = n
+			i++
+		default:
+			if notePath != "" {
+				return fmt.Errorf("unexpected argument: %s", args[i])
+			}
+			notePath = args[i]
+		}
+	}
+
+	if notePath == "" {
+		return fmt.Errorf("diff requires a note path\n\nUsage: obsidian diff <path> [--rev N]")
+	}
+	return cmd.DiffCmd(vaultPath, notePath, rev, jsonOutput)
+}
+
+// handleAttachmentsCommand parses and executes the attachments command.
+func handleAttachmentsCommand(vaultPath string, args []string, dryRun, jsonOutput bool) error {
+	opts := cmd.AttachmentsOptions{
+		Action:     "list",
+		DryRun:     dryRun,
+		JSONOutput: jsonOutput,
+	}
+	if len(args) > 0 {
+		opts.Action = args[0]
+		args = args[1:]
+	}
+
+	if opts.Action == "move" {
+		if len(args) != 2 {
+			return fmt.Errorf("move requires a source and destination\n\nUsage: obsidian attachments move <from> <to>")
+		}
+		opts.From, opts.To = args[0], args[1]
+	} else if len(args) > 0 {
+		return fmt.Errorf("unexpected argument: %s", args[0])
+	}
+
+	return cmd.AttachmentsCmd(vaultPath, opts)
+}
+
+// handlePromoteCommand parses and executes the promote command.
+func handlePromoteCommand(vaultPath string, args []string, dryRun, jsonOutput bool) error {
+	opts := cmd.PromoteOptions{DryRun: dryRun, JSONOutput: jsonOutput}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--all":
+			opts.All = true
+		case "--synthesize":
+			opts.Synthesize = true
+		case "--summarize":
+			opts.Summarize = true
+		case "--select":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--select requires cluster numbers, e.g. --select 1,3")
+			}
+			for _, part := range strings.Split(args[i+1], ",") {
+				n, err := parseInt(strings.TrimSpace(part))
+				if err != nil || n < 1 {
+					return fmt.Errorf("--select requires cluster numbers, e.g. --select 1,3")
+				}
+				opts.Select = append(opts.Select, n)
+			}
+			i++
+		case "--min-score":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--min-score requires a value between 0 and 1")
+			}
+			score, err := strconv.ParseFloat(args[i+1], 64)
+			if err != nil || score <= 0 || score > 1 {
+				return fmt.Errorf("--min-score must be between 0 and 1, got %q", args[i+1])
+			}
+			opts.MinScore = score
+			i++
+		case "--algorithm":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--algorithm requires agglomerative, hdbscan, louvain or components")
+			}
+			opts.Algorithm = args[i+1]
+			i++
+		case "--tag-threshold", "--semantic-threshold", "--linkage":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value between 0 and 1", args[i])
+			}
+			v, err := strconv.ParseFloat(args[i+1], 64)
+			if err != nil || v <= 0 || v > 1 {
+				return fmt.Errorf("%s must be between 0 and 1, got %q", args[i], args[i+1])
+			}
+			switch args[i] {
+			case "--tag-threshold":
+				opts.TagThreshold = v
+			case "--semantic-threshold":
+				opts.SemanticThreshold = v
+			default:
+				opts.Linkage = v
+			}
+			i++
+		case "--min-size", "--max-size":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a number of notes", args[i])
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil || n < 2 {
+				return fmt.Errorf("%s must be at least 2, got %q", args[i], args[i+1])
+			}
+			if args[i] == "--min-size" {
+				opts.MinSize = n
+			} else {
+				opts.MaxSize = n
+			}
+			i++
+		default:
+			return fmt.Errorf("unknown promote flag: %s", args[i])
+		}
+	}
+	return cmd.PromoteCmd(vaultPath, opts)
+}
+
+// handleEnrichCommand parses and executes the enrich command.
+func handleEnrichCommand(vaultPath string, args []string, apply, jsonOutput bool) error {
+	opts := cmd.EnrichOptions{Apply: apply, JSONOutput: jsonOutput}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--interactive", "-i":
+			opts.Interactive = true
+		case "--tags":
+			opts.Tags = true
+		case "--accept-file", "--heading", "--link-format":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", args[i])
+			}
+			switch args[i] {
+			case "--accept-file":
+				opts.AcceptFile = args[i+1]
+			case "--heading":
+				opts.Heading = args[i+1]
+			default:
+				opts.LinkFormat = args[i+1]
+			}
+			i++
+		default:
+			return fmt.Errorf("unknown enrich flag: %s", args[i])
+		}
+	}
+	return cmd.EnrichCmd(vaultPath, opts)
+}
+
+// handleMocCommand parses and executes the moc command.
+func handleMocCommand(vaultPath string, args []string, dryRun, jsonOutput bool) error {
+	opts := cmd.MocOptions{DryRun: dryRun, JSONOutput: jsonOutput}
+	var targets []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--title", "--path", "--algorithm":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", args[i])
+			}
+			switch args[i] {
+			case "--title":
+				opts.Title = args[i+1]
+			case "--path":
+				opts.Path = args[i+1]
+			default:
+				opts.Algorithm = args[i+1]
+			}
+			i++
+		case "--limit", "--min-size":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a number", args[i])
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("%s must be a positive number, got %q", args[i], args[i+1])
+			}
+			if args[i] == "--limit" {
+				opts.Limit = n
+			} else {
+				opts.MinSize = n
+			}
+			i++
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return fmt.Errorf("unknown moc flag: %s", args[i])
+			}
+			targets = append(targets, args[i])
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("moc requires a tag, folder or query\n\nUsage: obsidian moc <#tag|folder/|query>")
+	}
+	opts.Target = strings.Join(targets, " ")
+	return cmd.MocCmd(vaultPath, opts)
+}
+
+// handleMentionsCommand parses and executes the mentions command.
+func handleMentionsCommand(vaultPath string, args []string, apply, jsonOutput bool) error {
+	opts := cmd.MentionsOptions{Apply: apply, JSONOutput: jsonOutput}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			return fmt.Errorf("unknown mentions flag: %s", arg)
+		}
+		if opts.Path != "" {
+			return fmt.Errorf("mentions takes at most one path\n\nUsage: obsidian mentions [path] [--apply]")
+		}
+		opts.Path = arg
+	}
+	return cmd.MentionsCmd(vaultPath, opts)
+}
+
+// handleGraphCommand parses and executes the graph command.
+func handleGraphCommand(vaultPath string, args []string, jsonOutput bool) error {
+	opts := cmd.GraphOptions{Action: "stats", JSONOutput: jsonOutput}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		opts.Action = args[0]
+		args = args[1:]
+	}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--semantic":
+			opts.Semantic = true
+		case "--threshold":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--threshold requires a value between 0 and 1")
+			}
+			t, err := strconv.ParseFloat(args[i+1], 64)
+			if err != nil || t <= 0 || t > 1 {
+				return fmt.Errorf("--threshold must be between 0 and 1, got %q", args[i+1])
+			}
+			opts.Semantic, opts.SemanticThreshold = true, t
+			i++
+		case "--top":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--top requires a number")
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("--top must be a positive number, got %q", args[i+1])
+			}
+			opts.Top = n
+			i++
+		case "--format", "--output", "-o":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", args[i])
+			}
+			if args[i] == "--format" {
+				opts.Format = args[i+1]
+			} else {
+				opts.Output = args[i+1]
+			}
+			i++
+		default:
+			return fmt.Errorf("unknown graph flag: %s", args[i])
+		}
+	}
+	return cmd.GraphCmd(vaultPath, opts)
+}
+
+// handleDedupeCommand parses and executes the dedupe command.
+func handleDedupeCommand(vaultPath string, args []string, jsonOutput bool) error {
+	opts := cmd.DedupeOptions{JSONOutput: jsonOutput}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--threshold":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--threshold requires a value between 0 and 1")
+			}
+			t, err := strconv.ParseFloat(args[i+1], 64)
+			if err != nil || t <= 0 || t > 1 {
+				return fmt.Errorf("--threshold must be between 0 and 1, got %q", args[i+1])
+			}
+			opts.Threshold = t
+			i++
+		default:
+			return fmt.Errorf("unknown dedupe flag: %s", args[i])
+		}
+	}
+	return cmd.DedupeCmd(vaultPath, opts)
+}
+
 // handleSearchCommand parses and executes the search command.
 func handleSearchCommand(vaultPath string, args []string, jsonOutput bool) error {
 	mode := ""
@@ -434,6 +1016,12 @@ COMMANDS:
                             --section <heading>  Append inside a named section
     capture <body>          Create a fleeting note in Inbox/
                             --source <url>       URL or origin of the capture
+                            --title, --tags a,b  Title and tags for the note
+                            --to <folder>        Write outside Inbox/
+                            --id <key>           Skip if this key was captured before
+                            --format json        JSON/NDJSON batch from stdin
+                            --fetch <url>        Save a web article as a reference note
+                            --template <t>       Render captures through a template ({{content}})
     create <path>           Create a new note
                             --title <title>      Note title (also adds H1 heading)
                             --type <type>        Frontmatter type field
@@ -441,7 +1029,14 @@ COMMANDS:
                             --status <status>    Frontmatter status field
                             --summary <text>     Frontmatter summary field
                             --tags <t1,t2,...>   Comma-separated tags
-                            --template <path>    Vault note to use as body template
+                            --template <t>       Template path or name in the templates folder;
+                                                 renders {{title}}, {{date:FORMAT}}, {{#if}} and
+                                                 Templater tp.date/tp.file/tp.system.prompt
+                            --var key=value      Template variable (repeatable)
+    daily [day]             Create the daily note (Daily notes folder, format, template)
+                            --date <day>         YYYY-MM-DD, yesterday, tomorrow or +N/-N (default: today)
+                            --template <t>       Use this template instead of the configured one
+                            --var key=value      Template variable (repeatable)
     list [dir]              List notes in vault or directory
     search <query>          Search notes (keyword + semantic)
                             --mode keyword|semantic|hybrid (default: hybrid)
@@ -450,10 +1045,31 @@ COMMANDS:
                             --dry-run  Preview without writing
                             --force    Overwrite unchanged + include unpublished
     enrich                  Suggest links, tags, detect orphan notes
-                            --apply    Write suggested links to notes
+                            --apply              Write suggested links to notes
+                            --tags               With --apply/--interactive: also add suggested tags
+                            --interactive        Accept or reject each suggestion
+                            --accept-file <path> Apply "accept|reject <note> -> <note>" and
+                                                 "accept|reject <note> #tag" lines
+                            --heading <text>     Section for added links (default: ## Related Notes)
+                            --link-format <fmt>  Line per link: {link} {note} {path} {reason}
+                                                 {similarity} (default: "- {link}")
     maintain                Vault health checks and reporting
-                            --stale-days N  Days before note is stale (default: 30)
+                            --stale-days N  Days before note is stale (default: check_stale_threshold or 30)
+                            --check <name>  Run a single check
+                            --list-checks   List checks with their weights, caps and thresholds
                             --fix           Add frontmatter to notes missing it
+                            --fix-links     Repair broken links (fuzzy name, alias, embeddings);
+                                            asks before low-confidence fixes
+                            --dry-run       With --fix-links: report repairs without writing
+    health                  Orphans, inbox depth and link density; each run (and each
+                            full maintain run) is recorded in the index
+                            --trend <window>  Sparklines of recorded metrics, e.g. 90d;
+                                              exits 1 when a metric regressed
+                            --threshold N     Percent worsening that counts as a
+                                              regression (default: 5)
+    validate <path>|--all   Check notes against the schema for their type
+                            (.obsidian/schemas.toml); exits 1 on errors
+                            --fix           Fill missing required properties with defaults
     ingest                  Import data from external sources into vault
                             --source scout|learnings  (required)
                             --topic <name>            Filter scout by topic
@@ -463,6 +1079,18 @@ COMMANDS:
     triage                  Review and process notes in Inbox/
                             --list           Show pending notes with age (default)
                             --auto           Classify, enrich, and move each note
+                                             (routing rules: .obsidian/triage-rules.toml)
+                            --interactive    Review each note: accept, retype, change destination,
+                                             edit tags, skip, delete or merge (decisions are
+                                             recorded in .obsidian/triage-labels.jsonl)
+                            --titles         Generate a title, summary: and slug for untitled
+                                             notes (LLM, or the first sentence without one)
+                            --summaries      Also add a summary: to notes that have a title
+                            --merge-duplicates
+                                             Append notes that duplicate an existing note to it
+                            --train          Learn note types from triaged notes and decisions;
+                                             reports cross-validated accuracy and saves the model
+                            --eval           Report cross-validated accuracy without saving
                             --older <dur>    Filter to notes older than duration (e.g. 7d, 24h)
                             --dry-run        Preview --auto without writing
                             --quiet          Suppress output when inbox is clear (cron-friendly)
@@ -470,12 +1098,71 @@ COMMANDS:
                             --limit N        Max results (default 5)
                             --older <dur>    Only notes older than duration (default 7d)
                             --random         Surface random old notes for serendipitous rediscovery
+                            Notes reviewed within the --older window are left out
+    review                  List notes due for spaced-repetition review today
+                            --interactive    Show each due note and grade it
+                            --limit N        Max notes (default 20)
+                            --new N          Never-reviewed notes to introduce (default 5)
+    review <path> <grade>   Grade a review: again, hard, good or easy
     auto-capture            Capture learnings, workspace artifacts, and scout intel into vault
                             --since <duration>   Limit lookback window (e.g. 7d, 24h, 2w)
                             --dry-run            Preview what would be captured
+    digest                  Write a weekly digest note to Digests/YYYY-Www.md: new and
+                            modified notes, triage, ingested items, link suggestions,
+                            resurfaced notes and health changes since the last digest
+                            --since <duration>   Window (default 7d)
+                            --narrative          Add an LLM-written summary
+                            --dry-run            Print the note instead of writing it
     promote                 Detect clusters of related notes and merge into canonical notes
                             --dry-run            Preview clusters without modifying anything
                             --json               Machine-readable cluster output
+                            --select 1,3         Promote these clusters without prompting
+                            --all                Promote every cluster without prompting
+                            --min-score N        Promote clusters scoring at least N (0-1)
+                            --synthesize         Merge headings and drop repeated paragraphs
+                                                 instead of stacking the sources
+                            --summarize          Add an LLM summary at the top (implies --synthesize)
+                            --algorithm NAME     agglomerative (default), hdbscan, louvain or components
+                            --tag-threshold N    Tag overlap (Jaccard) that relates two notes (default 0.25)
+                            --semantic-threshold N
+                                                 Embedding similarity that relates two notes (default 0.80)
+                            --linkage N          Agglomerative: minimum average similarity to merge (default 0.4)
+                            --min-size N         Smallest cluster (default 3)
+                            --max-size N         Largest cluster; bigger groups are split (default 15)
+    moc <#tag|folder/|query>
+                            Create or refresh a Map-of-Content note under MOCs/: matching
+                            notes grouped into sub-topics, each linked with a one-line
+                            summary. Only the part between the moc markers is rewritten
+                            --title T            Note title (default from the target)
+                            --path P             Note path (default MOCs/<title>.md)
+                            --limit N            Search results to include for a query (default 50)
+                            --algorithm NAME     Clustering algorithm (as for promote)
+                            --min-size N         Smallest sub-topic (default 2)
+                            --dry-run            Print the note instead of writing it
+    mentions [path]         Unlinked mentions: other notes' titles, filenames and aliases
+                            written as plain text (outside code, links and tags)
+                            --apply              Turn them into [[wikilinks]], keeping the text
+    graph [stats]           Link graph: in/out degree, PageRank hubs, betweenness bridges,
+                            connected components and Louvain communities
+                            --semantic           Also join notes with similar embeddings
+                            --threshold N        Similarity for a semantic edge (default 0.80)
+                            --top N              Notes and communities listed (default 10)
+    graph export            Write the graph with per-note metrics for Gephi or Graphviz
+                            --format graphml|dot|json (default graphml)
+                            --output <file>      Write to a file instead of stdout
+                            --semantic, --threshold N as for stats
+    history <path>          List stored snapshots of a note (taken before the CLI modifies it)
+    diff <path>             Show a unified diff between a snapshot and the current note
+                            --rev N              Compare against revision N (default: latest)
+    attachments [list]      List images, audio, video and PDFs with sizes and duplicates
+    attachments orphans     Attachments no note or canvas references
+    attachments missing     Embeds and links pointing at missing files
+    attachments move <from> <to>
+                            Move an attachment and rewrite links to it
+                            --dry-run            Show affected notes without writing
+    dedupe                  Report likely duplicate notes (same source URL, near-identical
+                            text, or near-identical embeddings)
+                            --threshold N        Text similarity threshold (default 0.6)
     configure               Set up API key and vault path
     configure show          Show current configuration
     doctor                  Validate installation and configuration
@@ -499,9 +1186,15 @@ EXAMPLES:
     obsidian capture "rough idea about search"      # Quick fleeting note
     obsidian capture "link worth reading" --source https://example.com
     echo "piped text" | obsidian capture            # Capture from stdin
+    obsidian capture "standup notes" --to Meetings --tags work --id standup-0312
+    jq -c '.[]' items.json | obsidian capture       # NDJSON batch: {"id","title","body","tags"}
+    obsidian capture --fetch https://go.dev/blog/intro-generics  # Article → References/
     obsidian create projects/new-idea.md --title "New Idea" --type idea
     obsidian create projects/new-idea.md --tags "go,cli" --status draft
     obsidian create projects/new-idea.md --template "99 Templates/idea.md"
+    obsidian create Meetings/kickoff.md --template meeting --var project=apollo
+    obsidian daily                                  # Today's daily note from its template
+    obsidian daily tomorrow --json                  # Path and cursor line for an editor
     obsidian list daily/                            # List notes in folder
     obsidian search "project ideas"                 # Hybrid search (default)
     obsidian search "golang" --mode keyword         # Keyword-only search
@@ -510,7 +1203,15 @@ EXAMPLES:
     obsidian sync --dry-run                         # Preview sync changes
     obsidian enrich                                 # Find note connections
     obsidian enrich --apply                         # Apply suggested links
+    obsidian enrich --interactive --tags            # Review links and tags one by one
+    obsidian enrich --accept-file decisions.txt     # Apply reviewed decisions
+    obsidian enrich --apply --link-format "- {link} — {reason}"
     obsidian maintain                               # Vault health report
+    obsidian maintain --fix-links --dry-run         # Preview broken link repairs
+    obsidian maintain --check duplicate-titles      # Run one health check
+    obsidian validate --all                         # Check notes against type schemas
+    obsidian validate Tasks/ --fix                  # ...and fill in default values
+    obsidian health --trend 90d                     # Health score, orphans, broken links over time
     obsidian ingest --source scout                  # Import scout intel
     obsidian ingest --source scout --topic "ai-models" --since 7d
     obsidian ingest --source learnings              # Import orchestrator learnings
@@ -520,24 +1221,53 @@ EXAMPLES:
     obsidian triage --older 7d                      # Only notes older than 7 days
     obsidian triage --auto                          # Classify and move inbox notes
     obsidian triage --auto --dry-run                # Preview triage without writing
+    obsidian triage --auto --titles --dry-run       # Preview generated titles and file names
+    obsidian triage --interactive                   # Review inbox notes one at a time
+    obsidian triage --auto --merge-duplicates       # Fold repeated captures into existing notes
+    obsidian triage --train                         # Train the offline classifier
     obsidian triage --auto --json                   # Structured output
     obsidian triage --auto --quiet                  # Cron-friendly: no output when inbox is clear
     obsidian resurface "golang patterns"            # Find old notes about golang patterns
     obsidian resurface "golang patterns" --older 14d --limit 3
     obsidian resurface --random                     # Random old note for serendipitous rediscovery
     obsidian resurface --random --older 30d --json  # Random old notes as JSON
+    obsidian review                                 # Notes due for review today
+    obsidian review --interactive                   # Grade each due note
+    obsidian review Notes/sharding.md good          # Schedule the next review
     obsidian auto-capture                           # Capture all via workflow outputs
     obsidian auto-capture --since 7d               # Only items from the last 7 days
     obsidian auto-capture --dry-run                 # Preview what would be captured
     obsidian auto-capture --json                    # Machine-readable output
+    obsidian digest                                 # This week's digest → Digests/2026-W42.md
+    obsidian digest --since 14d --narrative         # Two-week window with an LLM summary
     obsidian promote                                # Detect clusters, interactively promote
     obsidian promote --dry-run                      # Preview clusters without writing
     obsidian promote --json                         # Machine-readable cluster output
+    obsidian promote --select 1,3 --synthesize      # Promote clusters 1 and 3 as merged notes
+    obsidian promote --min-score 0.6 --dry-run      # Which clusters would be promoted
+    obsidian promote --algorithm louvain --max-size 8 --dry-run
+    obsidian moc "#databases"                       # MOC of notes tagged databases
+    obsidian moc Projects/ --title "Projects MOC"    # MOC of a folder
+    obsidian moc "vector search" --dry-run          # MOC of search results, printed
+    obsidian mentions                               # Unlinked mentions across the vault
+    obsidian mentions Projects/ --apply             # Link mentions in Projects/ notes
+    obsidian graph stats                            # Hubs, bridges and communities
+    obsidian graph export --format dot -o vault.dot # Graphviz: sfdp -Tsvg vault.dot
+    obsidian graph export --semantic -o vault.graphml
+    obsidian history Notes/go-patterns.md           # List prior versions of a note
+    obsidian diff Notes/go-patterns.md              # What changed since the last snapshot
+    obsidian diff Notes/go-patterns.md --rev 3      # Diff against revision 3
+    obsidian attachments orphans                    # Unused images and PDFs
+    obsidian attachments move img.png Attachments/  # Move and update embeds
+    obsidian dedupe                                 # Find duplicate notes across the vault
     obsidian doctor                                 # Check setup
 
-CRON SETUP (run triage hourly, only emails on activity):
+CRON SETUP (hourly triage that only emails on activity, weekly digest):
     # crontab -e
     0 * * * * /usr/local/bin/obsidian triage --auto --quiet 2>&1
+    0 18 * * 0 /usr/local/bin/obsidian digest 2>&1   # weekly digest, Sunday evening
+    0 7 * * * /usr/local/bin/obsidian maintain >/dev/null && /usr/local/bin/obsidian health --trend 30d >/dev/null
+                                                      # daily snapshot; mails only on regression
 
 For more information, visit: https://obsidian.md
 `, version)
@@ -0,0 +1,96 @@
+package classify
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func syntheticExamples() []Example {
+	tasks := []string{"- [ ] call the dentist", "- [ ] renew passport\n- [ ] book flights", "todo: send invoice", "- [ ] fix flaky test", "- [x] buy milk\n- [ ] buy eggs", "- [ ] review pull request", "todo: reply to email"}
+	refs := []string{"https://golang.org/doc/effective_go", "Read https://go.dev/blog/errors later", "Paper: https://arxiv.org/abs/1234", "https://example.com/article about caching", "Bookmark https://news.ycombinator.com/item", "https://github.com/foo/bar readme", "Docs at https://sqlite.org/fts5.html"}
+	ideas := []string{"What if search ranked recent notes higher", "Maybe the garden could use raised beds", "Idea: weekly review ritual on sundays", "Could we cache embeddings per paragraph", "A tool that turns voice memos into notes", "What if the inbox expired old captures", "Thought: fewer meetings, more writing"}
+	var ex []Example
+	for i, t := range tasks {
+		ex = append(ex, Example{ID: fmt.Sprintf("t%d", i), Text: t, Label: "task"})
+	}
+	for i, t := range refs {
+		ex = append(ex, Example{ID: fmt.Sprintf("r%d", i), Text: t, Label: "reference"})
+	}
+	for i, t := range ideas {
+		ex = append(ex, Example{ID: fmt.Sprintf("i%d", i), Text: t, Label: "idea"})
+	}
+	return ex
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("# Plan\n- [ ] Read https://www.Go.dev/blog and the docs\nTODO: ship it")
+	want := "_heading plan _checkbox _url _domain:go.dev read docs _todo todo ship"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Tokenize() = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestFit(t *testing.T) {
+	model, report, err := Fit(syntheticExamples(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+	if report.Folds != 5 || report.Examples != 21 {
+		t.Errorf("report = %+v", report)
+	}
+	if report.Accuracy < 0.8 {
+		t.Errorf("cross-validated accuracy = %.2f, want >= 0.8 (confusion %v)", report.Accuracy, report.Confusion)
+	}
+	if report.Baseline < 0.33 || report.Baseline > 0.34 {
+		t.Errorf("baseline = %.2f, want 1/3", report.Baseline)
+	}
+
+	tests := map[string]string{
+		"- [ ] water the plants":                "task",
+		"https://pkg.go.dev/net/http reference": "reference",
+	}
+	for text, want := range tests {
+		if p := model.Predict(text); p.Label != want {
+			t.Errorf("Predict(%q) = %+v, want %s", text, p, want)
+		}
+	}
+}
+
+func TestFit_TooFewExamples(t *testing.T) {
+	if _, _, err := Fit(syntheticExamples()[:5], DefaultOptions()); err == nil {
+		t.Error("Fit() with 5 examples should fail")
+	}
+	var one []Example
+	for i := 0; i < MinExamples; i++ {
+		one = append(one, Example{ID: fmt.Sprint(i), Text: "x", Label: "idea"})
+	}
+	if _, _, err := Fit(one, DefaultOptions()); err == nil || !strings.Contains(err.Error(), "2 types") {
+		t.Errorf("Fit() with one class error = %v", err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	model, _, err := Fit(syntheticExamples(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "sub", "model.json")
+	if err := Save(path, model); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	text := "- [ ] pay rent"
+	if a, b := model.Predict(text), loaded.Predict(text); a.Label != b.Label || a.Confidence != b.Confidence {
+		t.Errorf("loaded model predicts %+v, original %+v", b, a)
+	}
+
+	missing, err := Load(filepath.Join(t.TempDir(), "none.json"))
+	if missing != nil || err != nil {
+		t.Errorf("Load(missing) = %v, %v; want nil, nil", missing, err)
+	}
+}
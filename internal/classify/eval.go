@@ -0,0 +1,200 @@
+package classify
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// calibrationBins is the number of confidence bins used for the expected
+// calibration error.
+const calibrationBins = 10
+
+// ClassMetrics holds per-class cross-validation results.
+type ClassMetrics struct {
+	Class     string  `json:"class"`
+	Support   int     `json:"support"` // examples with this label
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// Report summarises cross-validation of a training set.
+type Report struct {
+	Examples    int                       `json:"examples"`
+	Folds       int                       `json:"folds"`
+	Accuracy    float64                   `json:"accuracy"`
+	Baseline    float64                   `json:"baseline"` // accuracy of always predicting the majority class
+	Temperature float64                   `json:"temperature"`
+	ECE         float64                   `json:"ece"` // expected calibration error after temperature scaling
+	PerClass    []ClassMetrics            `json:"per_class"`
+	Confusion   map[string]map[string]int `json:"confusion"` // actual -> predicted -> count
+}
+
+// Fit cross-validates the examples, fits a calibration temperature on the
+// out-of-fold predictions, and trains the final model on all examples.
+func Fit(examples []Example, opts Options) (*Model, Report, error) {
+	if len(examples) < MinExamples {
+		return nil, Report{}, fmt.Errorf("need at least %d labelled notes, have %d", MinExamples, len(examples))
+	}
+	classes := labelSet(examples)
+	if len(classes) < 2 {
+		return nil, Report{}, fmt.Errorf("need labelled notes of at least 2 types, have only %q", classes[0])
+	}
+	k := opts.Folds
+	if k < 2 {
+		k = 2
+	}
+	if k > len(examples) {
+		k = len(examples)
+	}
+
+	// Out-of-fold logits for every example.
+	folds := assignFolds(examples, k)
+	logits := make([][]float64, len(examples))
+	for f := 0; f < k; f++ {
+		var trainSet []Example
+		for i, ex := range examples {
+			if folds[i] != f {
+				trainSet = append(trainSet, ex)
+			}
+		}
+		m := train(trainSet, classes, opts)
+		for i, ex := range examples {
+			if folds[i] == f {
+				logits[i] = m.logits(m.Vocab.vectorize(Tokenize(ex.Text)))
+			}
+		}
+	}
+
+	classIndex := make(map[string]int, len(classes))
+	for i, c := range classes {
+		classIndex[c] = i
+	}
+	labels := make([]int, len(examples))
+	for i, ex := range examples {
+		labels[i] = classIndex[ex.Label]
+	}
+
+	report := Report{Examples: len(examples), Folds: k}
+	report.Temperature = fitTemperature(logits, labels)
+	report.evaluate(logits, labels, classes)
+
+	model := train(examples, classes, opts)
+	model.Temperature = report.Temperature
+	return model, report, nil
+}
+
+// assignFolds assigns examples to k folds, stratified by label so each fold
+// sees every class in proportion. Assignment is deterministic: examples are
+// ordered by ID within each label.
+func assignFolds(examples []Example, k int) []int {
+	byLabel := make(map[string][]int)
+	for i, ex := range examples {
+		byLabel[ex.Label] = append(byLabel[ex.Label], i)
+	}
+	labels := make([]string, 0, len(byLabel))
+	for l := range byLabel {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	folds := make([]int, len(examples))
+	next := 0
+	for _, l := range labels {
+		idx := byLabel[l]
+		sort.Slice(idx, func(a, b int) bool { return examples[idx[a]].ID < examples[idx[b]].ID })
+		for _, i := range idx {
+			folds[i] = next % k
+			next++
+		}
+	}
+	return folds
+}
+
+// fitTemperature returns the temperature minimising the negative
+// log-likelihood of the labels, searched over a grid.
+func fitTemperature(logits [][]float64, labels []int) float64 {
+	best, bestNLL := 1.0, math.Inf(1)
+	for t := 0.25; t <= 5.0001; t += 0.05 {
+		var nll float64
+		for i, l := range logits {
+			p := softmax(l, t)[labels[i]]
+			nll -= math.Log(math.Max(p, 1e-12))
+		}
+		if nll < bestNLL {
+			best, bestNLL = t, nll
+		}
+	}
+	return math.Round(best*100) / 100
+}
+
+// evaluate fills accuracy, per-class metrics, confusion and calibration error
+// from out-of-fold logits.
+func (r *Report) evaluate(logits [][]float64, labels []int, classes []string) {
+	r.Confusion = make(map[string]map[string]int)
+	predicted := make([]int, len(classes))
+	correct := make([]int, len(classes))
+	support := make([]int, len(classes))
+
+	var hits int
+	var binConf, binAcc [calibrationBins]float64
+	var binCount [calibrationBins]int
+	for i, l := range logits {
+		probs := softmax(l, r.Temperature)
+		pred := 0
+		for c := range probs {
+			if probs[c] > probs[pred] {
+				pred = c
+			}
+		}
+		actual := labels[i]
+		support[actual]++
+		predicted[pred]++
+		if r.Confusion[classes[actual]] == nil {
+			r.Confusion[classes[actual]] = make(map[string]int)
+		}
+		r.Confusion[classes[actual]][classes[pred]]++
+
+		ok := 0.0
+		if pred == actual {
+			hits++
+			correct[pred]++
+			ok = 1
+		}
+		b := int(probs[pred] * calibrationBins)
+		if b == calibrationBins {
+			b--
+		}
+		binConf[b] += probs[pred]
+		binAcc[b] += ok
+		binCount[b]++
+	}
+
+	n := float64(len(logits))
+	r.Accuracy = float64(hits) / n
+	for b := range binCount {
+		if binCount[b] > 0 {
+			r.ECE += math.Abs(binAcc[b]-binConf[b]) / n
+		}
+	}
+
+	majority := 0
+	for c, s := range support {
+		if s > majority {
+			majority = s
+		}
+		m := ClassMetrics{Class: classes[c], Support: s}
+		if predicted[c] > 0 {
+			m.Precision = float64(correct[c]) / float64(predicted[c])
+		}
+		if s > 0 {
+			m.Recall = float64(correct[c]) / float64(s)
+		}
+		if m.Precision+m.Recall > 0 {
+			m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+		}
+		r.PerClass = append(r.PerClass, m)
+	}
+	r.Baseline = float64(majority) / n
+}
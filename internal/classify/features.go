@@ -0,0 +1,137 @@
+package classify
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Structural tokens added alongside words. They carry the signals the regex
+// classifier looks for, so the model can learn how much each one matters.
+const (
+	tokenCheckbox = "_checkbox"
+	tokenURL      = "_url"
+	tokenHeading  = "_heading"
+	tokenTodo     = "_todo"
+)
+
+var (
+	checkboxRe = regexp.MustCompile(`^\s*[-*]\s+\[[ xX]\]`)
+	urlRe      = regexp.MustCompile(`https?://([^/\s)>\]]+)\S*`)
+	headingRe  = regexp.MustCompile(`^#{1,6}\s`)
+	todoRe     = regexp.MustCompile(`(?i)^\s*(todo|action):`)
+)
+
+// stopwords are frequent English words that carry no type signal.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "has": true,
+	"have": true, "in": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "were": true, "will": true, "with": true,
+}
+
+// Tokenize splits note text into lowercase word tokens plus structural tokens
+// for checkboxes, URLs (with their domain), headings and TODO markers.
+func Tokenize(text string) []string {
+	var tokens []string
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case checkboxRe.MatchString(line):
+			tokens = append(tokens, tokenCheckbox)
+		case headingRe.MatchString(line):
+			tokens = append(tokens, tokenHeading)
+		case todoRe.MatchString(line):
+			tokens = append(tokens, tokenTodo)
+		}
+		for _, m := range urlRe.FindAllStringSubmatch(line, -1) {
+			tokens = append(tokens, tokenURL, "_domain:"+strings.TrimPrefix(strings.ToLower(m[1]), "www."))
+		}
+		line = urlRe.ReplaceAllString(line, " ")
+
+		for _, w := range strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len([]rune(w)) < 2 || stopwords[w] {
+				continue
+			}
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// sparse is a sparse feature vector sorted by index.
+type sparse struct {
+	idx []int
+	val []float64
+}
+
+// vocabulary maps terms to feature indices with their inverse document frequency.
+type vocabulary struct {
+	Terms map[string]int `json:"terms"`
+	IDF   []float64      `json:"idf"`
+}
+
+// buildVocabulary collects terms appearing in at least minDF documents and
+// computes smoothed IDF weights.
+func buildVocabulary(docs [][]string, minDF int) vocabulary {
+	df := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for _, t := range doc {
+			if !seen[t] {
+				seen[t] = true
+				df[t]++
+			}
+		}
+	}
+	var terms []string
+	for t, n := range df {
+		if n >= minDF {
+			terms = append(terms, t)
+		}
+	}
+	sort.Strings(terms)
+
+	v := vocabulary{Terms: make(map[string]int, len(terms)), IDF: make([]float64, len(terms))}
+	n := float64(len(docs))
+	for i, t := range terms {
+		v.Terms[t] = i
+		v.IDF[i] = math.Log((1+n)/(1+float64(df[t]))) + 1
+	}
+	return v
+}
+
+// vectorize converts tokens to an L2-normalised TF-IDF vector using
+// sublinear term frequency. Unknown terms are ignored.
+func (v vocabulary) vectorize(tokens []string) sparse {
+	counts := make(map[int]int)
+	for _, t := range tokens {
+		if i, ok := v.Terms[t]; ok {
+			counts[i]++
+		}
+	}
+	var vec sparse
+	for i := range counts {
+		vec.idx = append(vec.idx, i)
+	}
+	sort.Ints(vec.idx)
+
+	var norm float64
+	vec.val = make([]float64, len(vec.idx))
+	for j, i := range vec.idx {
+		w := (1 + math.Log(float64(counts[i]))) * v.IDF[i]
+		vec.val[j] = w
+		norm += w * w
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for j := range vec.val {
+			vec.val[j] /= norm
+		}
+	}
+	return vec
+}
@@ -0,0 +1,225 @@
+// Package classify is an offline note-type classifier trained on the vault's
+// own triage history. Notes are tokenized into TF-IDF bag-of-words vectors and
+// classified with multinomial logistic regression; confidences are calibrated
+// by temperature scaling fitted on cross-validated predictions.
+package classify
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// modelVersion is bumped when the saved model format changes.
+const modelVersion = 1
+
+// MinExamples is the smallest training set Fit accepts.
+const MinExamples = 10
+
+// Example is one labelled note.
+type Example struct {
+	ID    string // stable identifier (usually the note path), used for fold assignment
+	Text  string
+	Label string
+}
+
+// Options controls training.
+type Options struct {
+	Epochs       int     // full-batch gradient descent iterations
+	LearningRate float64 // step size
+	L2           float64 // weight decay
+	MinDF        int     // minimum document frequency for a term to enter the vocabulary
+	Folds        int     // cross-validation folds
+}
+
+// DefaultOptions returns the training settings used by triage --train.
+func DefaultOptions() Options {
+	return Options{Epochs: 300, LearningRate: 1.0, L2: 1e-4, MinDF: 1, Folds: 5}
+}
+
+// Model is a trained classifier.
+type Model struct {
+	Version     int         `json:"version"`
+	Classes     []string    `json:"classes"`
+	Vocab       vocabulary  `json:"vocab"`
+	Weights     [][]float64 `json:"weights"` // per class: one weight per term, then the bias
+	Temperature float64     `json:"temperature"`
+	Examples    int         `json:"examples"`
+}
+
+// Prediction is a classification with its calibrated probability.
+type Prediction struct {
+	Label         string             `json:"label"`
+	Confidence    float64            `json:"confidence"`
+	Probabilities map[string]float64 `json:"probabilities"`
+}
+
+// Predict classifies text.
+func (m *Model) Predict(text string) Prediction {
+	probs := softmax(m.logits(m.Vocab.vectorize(Tokenize(text))), m.Temperature)
+	p := Prediction{Probabilities: make(map[string]float64, len(m.Classes))}
+	for c, prob := range probs {
+		p.Probabilities[m.Classes[c]] = prob
+		if prob > p.Confidence {
+			p.Label, p.Confidence = m.Classes[c], prob
+		}
+	}
+	return p
+}
+
+// logits returns the raw class scores for a feature vector.
+func (m *Model) logits(x sparse) []float64 {
+	bias := len(m.Vocab.IDF)
+	out := make([]float64, len(m.Classes))
+	for c, w := range m.Weights {
+		s := w[bias]
+		for j, i := range x.idx {
+			s += w[i] * x.val[j]
+		}
+		out[c] = s
+	}
+	return out
+}
+
+// softmax converts logits to probabilities at temperature t.
+func softmax(logits []float64, t float64) []float64 {
+	if t <= 0 {
+		t = 1
+	}
+	max := math.Inf(-1)
+	for _, l := range logits {
+		max = math.Max(max, l/t)
+	}
+	out := make([]float64, len(logits))
+	var sum float64
+	for i, l := range logits {
+		out[i] = math.Exp(l/t - max)
+		sum += out[i]
+	}
+	for i := range out {
+		out[i] /= sum
+	}
+	return out
+}
+
+// train fits a model on examples for a fixed class list, at temperature 1.
+func train(examples []Example, classes []string, opts Options) *Model {
+	docs := make([][]string, len(examples))
+	for i, ex := range examples {
+		docs[i] = Tokenize(ex.Text)
+	}
+	vocab := buildVocabulary(docs, opts.MinDF)
+
+	classIndex := make(map[string]int, len(classes))
+	for i, c := range classes {
+		classIndex[c] = i
+	}
+	xs := make([]sparse, len(docs))
+	ys := make([]int, len(docs))
+	for i, doc := range docs {
+		xs[i] = vocab.vectorize(doc)
+		ys[i] = classIndex[examples[i].Label]
+	}
+
+	dim := len(vocab.IDF) + 1
+	m := &Model{
+		Version:     modelVersion,
+		Classes:     classes,
+		Vocab:       vocab,
+		Weights:     make([][]float64, len(classes)),
+		Temperature: 1,
+		Examples:    len(examples),
+	}
+	grads := make([][]float64, len(classes))
+	for c := range classes {
+		m.Weights[c] = make([]float64, dim)
+		grads[c] = make([]float64, dim)
+	}
+
+	n := float64(len(xs))
+	for epoch := 0; epoch < opts.Epochs; epoch++ {
+		for c := range grads {
+			clear(grads[c])
+		}
+		for i, x := range xs {
+			probs := softmax(m.logits(x), 1)
+			for c, p := range probs {
+				g := p
+				if c == ys[i] {
+					g -= 1
+				}
+				for j, k := range x.idx {
+					grads[c][k] += g * x.val[j]
+				}
+				grads[c][dim-1] += g
+			}
+		}
+		for c := range m.Weights {
+			for k := range m.Weights[c] {
+				decay := opts.L2 * m.Weights[c][k]
+				if k == dim-1 {
+					decay = 0 // bias is not regularised
+				}
+				m.Weights[c][k] -= opts.LearningRate * (grads[c][k]/n + decay)
+			}
+		}
+	}
+	return m
+}
+
+// labelSet returns the sorted distinct labels of examples.
+func labelSet(examples []Example) []string {
+	seen := make(map[string]bool)
+	var classes []string
+	for _, ex := range examples {
+		if !seen[ex.Label] {
+			seen[ex.Label] = true
+			classes = append(classes, ex.Label)
+		}
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// Save writes a model as JSON, creating parent directories.
+func Save(path string, m *Model) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("encoding model: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load reads a model saved by Save. It returns (nil, nil) when the file does
+// not exist.
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decoding model: %w", err)
+	}
+	if m.Version != modelVersion {
+		return nil, fmt.Errorf("model version %d is not supported (retrain with triage --train)", m.Version)
+	}
+	if len(m.Weights) != len(m.Classes) {
+		return nil, fmt.Errorf("model is corrupt: %d weight rows for %d classes", len(m.Weights), len(m.Classes))
+	}
+	for _, w := range m.Weights {
+		if len(w) != len(m.Vocab.IDF)+1 {
+			return nil, fmt.Errorf("model is corrupt: weight row does not match vocabulary")
+		}
+	}
+	return &m, nil
+}
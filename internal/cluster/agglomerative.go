@@ -0,0 +1,109 @@
+package cluster
+
+// agglomerative runs average-linkage clustering on each connected component.
+// Groups in different components have average similarity 0, so they would
+// never merge anyway; splitting first keeps the work quadratic in the
+// component size rather than the item count.
+func agglomerative(sim [][]float64, opts Options) [][]int {
+	var groups [][]int
+	for _, comp := range components(sim, allIndexes(len(sim))) {
+		if len(comp) < opts.MinSize {
+			continue
+		}
+		groups = append(groups, averageLinkage(sim, comp, opts)...)
+	}
+	return groups
+}
+
+// averageLinkage clusters items bottom-up. Starting from singletons, it
+// repeatedly merges the two groups with the highest average pairwise
+// similarity, as long as that average is at least opts.Linkage and the merged
+// group fits in opts.MaxSize. Unlike connected components, one item related
+// to two groups cannot join them: the average over all pairs stays low.
+func averageLinkage(sim [][]float64, items []int, opts Options) [][]int {
+	k := len(items)
+	members := make([][]int, k)
+	link := make([][]float64, k) // average similarity between groups
+	for a := range items {
+		members[a] = []int{items[a]}
+		link[a] = make([]float64, k)
+		for b := range items {
+			link[a][b] = sim[items[a]][items[b]]
+		}
+	}
+	active := make([]bool, k)
+	for a := range active {
+		active[a] = true
+	}
+
+	fits := func(a, b int) bool {
+		return opts.MaxSize <= 0 || len(members[a])+len(members[b]) <= opts.MaxSize
+	}
+	// best[a] is the group a would most like to merge with, or -1.
+	best := make([]int, k)
+	findBest := func(a int) {
+		best[a] = -1
+		for b := 0; b < k; b++ {
+			if b == a || !active[b] || !fits(a, b) || link[a][b] < opts.Linkage {
+				continue
+			}
+			if best[a] < 0 || link[a][b] > link[a][best[a]] {
+				best[a] = b
+			}
+		}
+	}
+	for a := 0; a < k; a++ {
+		findBest(a)
+	}
+
+	for {
+		// The closest pair overall; ties go to the lowest indexes.
+		x := -1
+		for a := 0; a < k; a++ {
+			if active[a] && best[a] >= 0 && (x < 0 || link[a][best[a]] > link[x][best[x]]) {
+				x = a
+			}
+		}
+		if x < 0 {
+			break
+		}
+		y := best[x]
+		if y < x {
+			x, y = y, x
+		}
+
+		// Merge y into x; the average linkage to any other group is the
+		// size-weighted mean of the two (Lance-Williams).
+		nx, ny := float64(len(members[x])), float64(len(members[y]))
+		for c := 0; c < k; c++ {
+			if active[c] && c != x && c != y {
+				link[x][c] = (nx*link[x][c] + ny*link[y][c]) / (nx + ny)
+				link[c][x] = link[x][c]
+			}
+		}
+		members[x] = append(members[x], members[y]...)
+		members[y] = nil
+		active[y] = false
+
+		for c := 0; c < k; c++ {
+			if !active[c] {
+				continue
+			}
+			switch {
+			case c == x || best[c] == x || best[c] == y:
+				findBest(c)
+			case fits(c, x) && link[c][x] >= opts.Linkage &&
+				(best[c] < 0 || link[c][x] > link[c][best[c]] || (link[c][x] == link[c][best[c]] && x < best[c])):
+				best[c] = x
+			}
+		}
+	}
+
+	var groups [][]int
+	for a := 0; a < k; a++ {
+		if active[a] {
+			groups = append(groups, members[a])
+		}
+	}
+	return groups
+}
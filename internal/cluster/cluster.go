@@ -0,0 +1,180 @@
+// Package cluster groups items by pairwise similarity. Every algorithm takes
+// a symmetric similarity matrix with values in [0, 1], where 0 means the two
+// items are unrelated, and returns groups of item indexes.
+//
+// Results are deterministic: items are visited in index order and ties go to
+// the lowest index, so the same input always yields the same groups in the
+// same order.
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Algorithm names a clustering method.
+type Algorithm string
+
+// Available algorithms.
+const (
+	// Components takes connected components of the graph of related pairs.
+	// A single item related to two topics joins them into one group.
+	Components Algorithm = "components"
+	// Agglomerative merges groups bottom-up while their average pairwise
+	// similarity (average linkage) stays at or above Options.Linkage.
+	Agglomerative Algorithm = "agglomerative"
+	// Density keeps groups that persist across similarity levels, in the
+	// style of HDBSCAN. Items with too few related neighbours are noise.
+	Density Algorithm = "hdbscan"
+	// Louvain finds communities that maximize modularity on the weighted
+	// graph of related pairs.
+	Louvain Algorithm = "louvain"
+)
+
+// Algorithms lists the available algorithms.
+var Algorithms = []Algorithm{Agglomerative, Density, Louvain, Components}
+
+// ParseAlgorithm parses an algorithm name. "hdbscan" and "density" both name
+// Density.
+func ParseAlgorithm(s string) (Algorithm, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "density" {
+		return Density, nil
+	}
+	for _, a := range Algorithms {
+		if s == string(a) {
+			return a, nil
+		}
+	}
+	names := make([]string, len(Algorithms))
+	for i, a := range Algorithms {
+		names[i] = string(a)
+	}
+	return "", fmt.Errorf("unknown clustering algorithm %q (want %s)", s, strings.Join(names, ", "))
+}
+
+// Defaults used when Options leaves a value unset.
+const (
+	DefaultMinSize = 3
+	DefaultLinkage = 0.4
+)
+
+// Options controls clustering.
+type Options struct {
+	MinSize int     // smallest group returned (default 3, at least 2)
+	MaxSize int     // largest group returned; 0 for no limit
+	Linkage float64 // Agglomerative: minimum average similarity to merge (default 0.4)
+}
+
+func (o Options) withDefaults() Options {
+	if o.MinSize <= 0 {
+		o.MinSize = DefaultMinSize
+	}
+	o.MinSize = max(o.MinSize, 2)
+	if o.MaxSize > 0 && o.MaxSize < o.MinSize {
+		o.MaxSize = o.MinSize
+	}
+	if o.Linkage <= 0 {
+		o.Linkage = DefaultLinkage
+	}
+	return o
+}
+
+// Run clusters with the named algorithm. Groups are sorted by their first
+// index, and the indexes within a group ascend. Groups larger than MaxSize
+// are split with average linkage, except with Density, which never selects
+// them.
+func Run(alg Algorithm, sim [][]float64, opts Options) ([][]int, error) {
+	opts = opts.withDefaults()
+	var groups [][]int
+	switch alg {
+	case Components, "":
+		groups = splitOversized(sim, components(sim, allIndexes(len(sim))), opts)
+	case Agglomerative:
+		groups = agglomerative(sim, opts)
+	case Density:
+		groups = density(sim, opts)
+	case Louvain:
+		groups = splitOversized(sim, louvain(sim), opts)
+	default:
+		return nil, fmt.Errorf("unknown clustering algorithm %q", alg)
+	}
+
+	var kept [][]int
+	for _, g := range groups {
+		if len(g) >= opts.MinSize {
+			sort.Ints(g)
+			kept = append(kept, g)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i][0] < kept[j][0] })
+	return kept, nil
+}
+
+// AverageSimilarity returns the mean pairwise similarity within a group.
+func AverageSimilarity(sim [][]float64, group []int) float64 {
+	if len(group) < 2 {
+		return 0
+	}
+	total := 0.0
+	for a := 0; a < len(group); a++ {
+		for b := a + 1; b < len(group); b++ {
+			total += sim[group[a]][group[b]]
+		}
+	}
+	return total / float64(len(group)*(len(group)-1)/2)
+}
+
+func allIndexes(n int) []int {
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	return idx
+}
+
+// components returns the connected components, among items, of the graph
+// whose edges are the related pairs.
+func components(sim [][]float64, items []int) [][]int {
+	in := make(map[int]bool, len(items))
+	for _, i := range items {
+		in[i] = true
+	}
+	seen := make(map[int]bool, len(items))
+	var groups [][]int
+	for _, start := range items {
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+		group := []int{start}
+		for q := 0; q < len(group); q++ {
+			cur := group[q]
+			for _, next := range items {
+				if !seen[next] && in[next] && sim[cur][next] > 0 {
+					seen[next] = true
+					group = append(group, next)
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// splitOversized splits groups larger than opts.MaxSize with average linkage.
+func splitOversized(sim [][]float64, groups [][]int, opts Options) [][]int {
+	if opts.MaxSize <= 0 {
+		return groups
+	}
+	var out [][]int
+	for _, g := range groups {
+		if len(g) <= opts.MaxSize {
+			out = append(out, g)
+			continue
+		}
+		out = append(out, averageLinkage(sim, g, opts)...)
+	}
+	return out
+}
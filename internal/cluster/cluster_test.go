@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// twoTopics builds two tight groups of four (0-3 and 5-8) joined through
+// item 4, which is related to one member of each, plus an unrelated item 9.
+func twoTopics() [][]float64 {
+	n := 10
+	sim := make([][]float64, n)
+	for i := range sim {
+		sim[i] = make([]float64, n)
+		sim[i][i] = 1
+	}
+	set := func(a, b int, v float64) { sim[a][b], sim[b][a] = v, v }
+	for _, g := range [][]int{{0, 1, 2, 3}, {5, 6, 7, 8}} {
+		for a := 0; a < len(g); a++ {
+			for b := a + 1; b < len(g); b++ {
+				set(g[a], g[b], 0.9)
+			}
+		}
+	}
+	set(3, 4, 0.6)
+	set(4, 5, 0.6)
+	return sim
+}
+
+func TestRun(t *testing.T) {
+	sim := twoTopics()
+	tests := []struct {
+		alg  Algorithm
+		opts Options
+		want [][]int
+	}{
+		// The bridge chains both topics into one component.
+		{Components, Options{}, [][]int{{0, 1, 2, 3, 4, 5, 6, 7, 8}}},
+		{Agglomerative, Options{}, [][]int{{0, 1, 2, 3}, {5, 6, 7, 8}}},
+		{Density, Options{}, [][]int{{0, 1, 2, 3}, {5, 6, 7, 8}}},
+		// Louvain places the bridge in the first community it ties with.
+		{Louvain, Options{}, [][]int{{0, 1, 2, 3, 4}, {5, 6, 7, 8}}},
+		// Components respects MaxSize by splitting with average linkage.
+		{Components, Options{MaxSize: 5}, [][]int{{0, 1, 2, 3}, {5, 6, 7, 8}}},
+		{Agglomerative, Options{MaxSize: 3}, [][]int{{0, 1, 2}, {5, 6, 7}}},
+		{Agglomerative, Options{MinSize: 5}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%+v", tt.alg, tt.opts), func(t *testing.T) {
+			got, err := Run(tt.alg, sim, tt.opts)
+			if err != nil {
+				t.Fatalf("Run() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Deterministic(t *testing.T) {
+	sim := twoTopics()
+	for _, alg := range Algorithms {
+		first, _ := Run(alg, sim, Options{MaxSize: 4})
+		for i := 0; i < 5; i++ {
+			if got, _ := Run(alg, sim, Options{MaxSize: 4}); !reflect.DeepEqual(got, first) {
+				t.Fatalf("%s: run %d = %v, first = %v", alg, i, got, first)
+			}
+		}
+		for _, g := range first {
+			if len(g) > 4 {
+				t.Errorf("%s: group %v exceeds MaxSize", alg, g)
+			}
+		}
+	}
+}
+
+func TestDensity_Noise(t *testing.T) {
+	// A tight group of three with a loosely attached fourth item: the
+	// straggler has only one related neighbour and is left out.
+	sim := [][]float64{
+		{1, 0.9, 0.85, 0.3},
+		{0.9, 1, 0.9, 0},
+		{0.85, 0.9, 1, 0},
+		{0.3, 0, 0, 1},
+	}
+	got, err := Run(Density, sim, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]int{{0, 1, 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Run(Density) = %v, want %v", got, want)
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	for in, want := range map[string]Algorithm{
+		"agglomerative": Agglomerative,
+		" HDBSCAN ":     Density,
+		"density":       Density,
+		"louvain":       Louvain,
+		"components":    Components,
+	} {
+		if got, err := ParseAlgorithm(in); err != nil || got != want {
+			t.Errorf("ParseAlgorithm(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseAlgorithm("kmeans"); err == nil {
+		t.Error("ParseAlgorithm(kmeans) succeeded")
+	}
+}
+
+func TestAverageSimilarity(t *testing.T) {
+	sim := twoTopics()
+	if got := AverageSimilarity(sim, []int{0, 1, 2}); got != 0.9 {
+		t.Errorf("AverageSimilarity = %v, want 0.9", got)
+	}
+	if got := AverageSimilarity(sim, []int{0}); got != 0 {
+		t.Errorf("AverageSimilarity(single) = %v, want 0", got)
+	}
+}
+
+func TestCommunities(t *testing.T) {
+	// Two triangles joined by one light edge, a repeated edge, a self-loop,
+	// and an isolated item.
+	edges := []Edge{
+		{0, 1, 1}, {1, 2, 1}, {2, 0, 1},
+		{3, 4, 1}, {4, 5, 0.5}, {4, 5, 0.5}, {5, 3, 1},
+		{2, 3, 0.2}, {1, 1, 5},
+	}
+	got := Communities(7, edges)
+	if want := [][]int{{0, 1, 2}, {3, 4, 5}, {6}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Communities() = %v, want %v", got, want)
+	}
+}
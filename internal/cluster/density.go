@@ -0,0 +1,266 @@
+package cluster
+
+import (
+	"math"
+	"sort"
+)
+
+// minDistance keeps lambda = 1/distance finite for identical items.
+const minDistance = 1e-6
+
+// density clusters in the style of HDBSCAN, with distance 1 - similarity:
+//
+//  1. An item's core distance is the distance to its (MinSize-1)th nearest
+//     neighbour. Items without that many related neighbours are noise.
+//  2. The mutual reachability distance of two items is the largest of their
+//     distance and both core distances, which pushes sparse items apart.
+//  3. A minimum spanning tree over mutual reachability gives a single-linkage
+//     hierarchy, condensed so that splits shedding fewer than MinSize items
+//     count as items falling out rather than new clusters.
+//  4. Each condensed cluster's stability is the sum over its items of how
+//     long (in lambda = 1/distance) they stayed in it. Bottom-up, a cluster
+//     is kept when it is more stable than its kept descendants together,
+//     and it fits in MaxSize.
+//
+// Each connected component of the related pairs is clustered on its own, and
+// its root may be kept, so a component that is one tight group is returned
+// whole.
+func density(sim [][]float64, opts Options) [][]int {
+	n := len(sim)
+	dist := func(a, b int) float64 {
+		return math.Max(1-sim[a][b], minDistance)
+	}
+
+	core := make([]float64, n)
+	var dense []int
+	for a := 0; a < n; a++ {
+		ds := make([]float64, 0, n-1)
+		for b := 0; b < n; b++ {
+			if b != a {
+				ds = append(ds, dist(a, b))
+			}
+		}
+		if len(ds) < opts.MinSize-1 {
+			continue
+		}
+		sort.Float64s(ds)
+		core[a] = ds[opts.MinSize-2]
+		if core[a] < 1 {
+			dense = append(dense, a)
+		}
+	}
+
+	reach := func(a, b int) float64 {
+		return math.Max(dist(a, b), math.Max(core[a], core[b]))
+	}
+	var groups [][]int
+	for _, comp := range components(sim, dense) {
+		if len(comp) >= opts.MinSize {
+			groups = append(groups, condensedClusters(comp, reach, opts)...)
+		}
+	}
+	return groups
+}
+
+// mergeNode is an internal node of the single-linkage hierarchy. Nodes
+// 0..k-1 are the items; node k+t is the t-th merge.
+type mergeNode struct {
+	left, right int
+	dist        float64
+	size        int
+}
+
+// condensedClusters builds the hierarchy for one component and returns the
+// most stable clusters.
+func condensedClusters(items []int, reach func(a, b int) float64, opts Options) [][]int {
+	k := len(items)
+	nodes := singleLinkage(items, reach)
+	size := func(node int) int {
+		if node < k {
+			return 1
+		}
+		return nodes[node-k].size
+	}
+	lambda := func(node int) float64 {
+		return 1 / math.Max(nodes[node-k].dist, minDistance)
+	}
+
+	type condensed struct {
+		parent    int
+		node      int // hierarchy node where the cluster is born
+		birth     float64
+		stability float64
+		children  []int
+	}
+	// The root is born at distance 1, where components separate, so it is
+	// only kept when the component holds together well past that.
+	clusters := []condensed{{parent: -1, node: k + len(nodes) - 1, birth: 1}}
+	owner := make([]int, k) // cluster each item last belonged to, or -1
+
+	var leaves func(node, c int)
+	leaves = func(node, c int) {
+		if node < k {
+			owner[node] = c
+			return
+		}
+		leaves(nodes[node-k].left, c)
+		leaves(nodes[node-k].right, c)
+	}
+	// fallOut records the items under node leaving cluster c at level l.
+	// Items leaving at the level the cluster was born never belonged to it:
+	// they are the bridge whose removal split the parent, and become noise.
+	fallOut := func(node, c int, l float64) {
+		if l <= clusters[c].birth {
+			c = -1
+		}
+		leaves(node, c)
+	}
+
+	type frame struct{ node, cluster int }
+	stack := []frame{{clusters[0].node, 0}}
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		c := f.cluster
+		if f.node < k {
+			owner[f.node] = c
+			continue
+		}
+		m := nodes[f.node-k]
+		l := lambda(f.node)
+		sl, sr := size(m.left), size(m.right)
+		clusters[c].stability += float64(sl+sr) * (l - clusters[c].birth)
+
+		switch {
+		case sl >= opts.MinSize && sr >= opts.MinSize:
+			for _, child := range []int{m.right, m.left} {
+				clusters = append(clusters, condensed{parent: c, node: child, birth: l})
+				id := len(clusters) - 1
+				clusters[c].children = append(clusters[c].children, id)
+				stack = append(stack, frame{child, id})
+			}
+		case sl < opts.MinSize && sr < opts.MinSize:
+			fallOut(f.node, c, l)
+		default:
+			// The smaller side falls out; the cluster continues as the larger.
+			small, big := m.left, m.right
+			if sl >= opts.MinSize {
+				small, big = m.right, m.left
+			}
+			fallOut(small, c, l)
+			// Points continuing in the cluster are credited again from the
+			// next split, so take back their share of this one.
+			clusters[c].stability -= float64(size(big)) * (l - clusters[c].birth)
+			stack = append(stack, frame{big, c})
+		}
+	}
+
+	// Excess of mass, bottom-up. Children are created after their parents.
+	best := make([]float64, len(clusters))
+	selected := make([]bool, len(clusters))
+	for c := len(clusters) - 1; c >= 0; c-- {
+		childSum := 0.0
+		for _, child := range clusters[c].children {
+			childSum += best[child]
+		}
+		fits := opts.MaxSize <= 0 || size(clusters[c].node) <= opts.MaxSize
+		if fits && clusters[c].stability >= childSum {
+			best[c], selected[c] = clusters[c].stability, true
+		} else {
+			best[c] = childSum
+		}
+	}
+	// Keep only the topmost selected cluster on each path.
+	label := make([]int, len(clusters))
+	for c := range clusters {
+		label[c] = -1
+		if p := clusters[c].parent; p >= 0 && label[p] >= 0 {
+			label[c] = label[p]
+		} else if selected[c] {
+			label[c] = c
+		}
+	}
+
+	byLabel := make(map[int][]int)
+	var order []int
+	for i, c := range owner {
+		if c < 0 {
+			continue
+		}
+		if l := label[c]; l >= 0 {
+			if _, ok := byLabel[l]; !ok {
+				order = append(order, l)
+			}
+			byLabel[l] = append(byLabel[l], items[i])
+		}
+	}
+	groups := make([][]int, 0, len(order))
+	for _, l := range order {
+		groups = append(groups, byLabel[l])
+	}
+	return groups
+}
+
+// singleLinkage builds the single-linkage hierarchy of items from a minimum
+// spanning tree (Prim's algorithm) over the reach distance.
+func singleLinkage(items []int, reach func(a, b int) float64) []mergeNode {
+	k := len(items)
+	type edge struct {
+		a, b int
+		w    float64
+	}
+	inTree := make([]bool, k)
+	nearest := make([]float64, k)
+	from := make([]int, k)
+	for i := range nearest {
+		nearest[i] = math.Inf(1)
+	}
+	edges := make([]edge, 0, k-1)
+	cur := 0
+	inTree[0] = true
+	for len(edges) < k-1 {
+		next := -1
+		for j := 0; j < k; j++ {
+			if inTree[j] {
+				continue
+			}
+			if d := reach(items[cur], items[j]); d < nearest[j] {
+				nearest[j], from[j] = d, cur
+			}
+			if next < 0 || nearest[j] < nearest[next] {
+				next = j
+			}
+		}
+		edges = append(edges, edge{from[next], next, nearest[next]})
+		inTree[next] = true
+		cur = next
+	}
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].w < edges[j].w })
+
+	// Union-find over items; root[x] is the hierarchy node of x's set.
+	parent := make([]int, k)
+	root := make([]int, k)
+	for i := range parent {
+		parent[i], root[i] = i, i
+	}
+	var find func(x int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	nodes := make([]mergeNode, 0, k-1)
+	sizes := make([]int, k)
+	for i := range sizes {
+		sizes[i] = 1
+	}
+	for _, e := range edges {
+		ra, rb := find(e.a), find(e.b)
+		nodes = append(nodes, mergeNode{left: root[ra], right: root[rb], dist: e.w, size: sizes[ra] + sizes[rb]})
+		parent[rb] = ra
+		sizes[ra] += sizes[rb]
+		root[ra] = k + len(nodes) - 1
+	}
+	return nodes
+}
@@ -0,0 +1,180 @@
+package cluster
+
+import "sort"
+
+// louvainMinGain is the smallest modularity gain that moves a node, so that
+// floating-point noise cannot make nodes flip back and forth.
+const louvainMinGain = 1e-12
+
+// Edge is a weighted undirected edge between items A and B, for graphs too
+// sparse or too large for a similarity matrix.
+type Edge struct {
+	A, B   int
+	Weight float64
+}
+
+// arc is one direction of an edge in an adjacency list. An arc to the node
+// itself holds the weight inside a collapsed community.
+type arc struct {
+	to int
+	w  float64
+}
+
+// Communities partitions items 0..n-1 with the Louvain method on the graph
+// of edges. Parallel edges add up, and self-loops and non-positive weights
+// are ignored. Every item is in exactly one community; items without edges
+// are singletons. Communities are sorted by their first index, and the
+// indexes within each ascend.
+func Communities(n int, edges []Edge) [][]int {
+	weights := make([]map[int]float64, n)
+	for _, e := range edges {
+		if e.A == e.B || e.Weight <= 0 {
+			continue
+		}
+		for _, p := range [][2]int{{e.A, e.B}, {e.B, e.A}} {
+			if weights[p[0]] == nil {
+				weights[p[0]] = make(map[int]float64)
+			}
+			weights[p[0]][p[1]] += e.Weight
+		}
+	}
+	adj := make([][]arc, n)
+	for a, m := range weights {
+		for b, w := range m {
+			adj[a] = append(adj[a], arc{b, w})
+		}
+		sort.Slice(adj[a], func(i, j int) bool { return adj[a][i].to < adj[a][j].to })
+	}
+
+	groups := louvainGraph(adj)
+	for _, g := range groups {
+		sort.Ints(g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+// louvain finds communities on the weighted graph of related pairs with the
+// Louvain method.
+func louvain(sim [][]float64) [][]int {
+	adj := make([][]arc, len(sim))
+	for a := range sim {
+		for b, w := range sim[a] {
+			if a != b && w > 0 {
+				adj[a] = append(adj[a], arc{b, w})
+			}
+		}
+	}
+	return louvainGraph(adj)
+}
+
+// louvainGraph runs the Louvain method on adjacency lists sorted by target:
+// nodes move to the neighbouring community with the largest modularity gain
+// until none improves, then each community is collapsed into one node and
+// the process repeats on the smaller graph. Nodes and neighbours are visited
+// in index order, so the result is deterministic. Nodes without arcs are
+// returned as singletons.
+func louvainGraph(adj [][]arc) [][]int {
+	members := make([][]int, len(adj))
+	for a := range members {
+		members[a] = []int{a}
+	}
+
+	for {
+		comm, moved := louvainLocalMoves(adj)
+		if !moved {
+			break
+		}
+		// Renumber communities in order of first appearance.
+		ids := make(map[int]int)
+		for _, c := range comm {
+			if _, ok := ids[c]; !ok {
+				ids[c] = len(ids)
+			}
+		}
+		k := len(ids)
+		weights := make([]map[int]float64, k)
+		nextMembers := make([][]int, k)
+		for a := range adj {
+			ca := ids[comm[a]]
+			nextMembers[ca] = append(nextMembers[ca], members[a]...)
+			if weights[ca] == nil {
+				weights[ca] = make(map[int]float64)
+			}
+			for _, e := range adj[a] {
+				weights[ca][ids[comm[e.to]]] += e.w
+			}
+		}
+		next := make([][]arc, k)
+		for a, m := range weights {
+			for b, w := range m {
+				next[a] = append(next[a], arc{b, w})
+			}
+			sort.Slice(next[a], func(i, j int) bool { return next[a][i].to < next[a][j].to })
+		}
+		adj, members = next, nextMembers
+	}
+	return members
+}
+
+// louvainLocalMoves runs the local moving phase on adj and returns each
+// node's community and whether any node moved.
+func louvainLocalMoves(adj [][]arc) ([]int, bool) {
+	n := len(adj)
+	// A self-loop already holds both directions of the weight inside a
+	// collapsed community, so arc sums are the weighted degrees.
+	degree := make([]float64, n)
+	m2 := 0.0 // twice the total edge weight
+	for a := range adj {
+		for _, e := range adj[a] {
+			degree[a] += e.w
+		}
+		m2 += degree[a]
+	}
+	comm := make([]int, n)
+	total := make([]float64, n) // sum of degrees in each community
+	for a := range comm {
+		comm[a] = a
+		total[a] = degree[a]
+	}
+	if m2 == 0 {
+		return comm, false
+	}
+
+	movedAny := false
+	for improved := true; improved; {
+		improved = false
+		for a := 0; a < n; a++ {
+			// Weight from a to each neighbouring community.
+			links := make(map[int]float64)
+			var order []int
+			for _, e := range adj[a] {
+				if e.to == a || e.w == 0 {
+					continue
+				}
+				if _, ok := links[comm[e.to]]; !ok {
+					order = append(order, comm[e.to])
+				}
+				links[comm[e.to]] += e.w
+			}
+
+			own := comm[a]
+			total[own] -= degree[a]
+			gain := func(c int) float64 {
+				return links[c] - total[c]*degree[a]/m2
+			}
+			best, bestGain := own, gain(own)
+			for _, c := range order {
+				if g := gain(c); g > bestGain+louvainMinGain {
+					best, bestGain = c, g
+				}
+			}
+			total[best] += degree[a]
+			if best != own {
+				comm[a] = best
+				improved, movedAny = true, true
+			}
+		}
+	}
+	return comm, movedAny
+}
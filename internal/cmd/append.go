@@ -32,6 +32,7 @@ func AppendCmd(vaultPath, notePath, text, section string, jsonOutput bool) error
 		}
 	}
 
+	snapshotNote(vaultPath, notePath, "append")
 	if err := vault.AppendToNote(vaultPath, notePath, text, section); err != nil {
 		return err
 	}
@@ -0,0 +1,669 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// AttachmentsOptions configures the attachments command.
+type AttachmentsOptions struct {
+	Action     string // list, orphans, missing or move
+	From       string // move: vault path of the attachment
+	To         string // move: destination path or folder
+	DryRun     bool
+	JSONOutput bool
+}
+
+// AttachmentEntry is an attachment with the number of notes referencing it.
+type AttachmentEntry struct {
+	vault.AttachmentInfo
+	References int `json:"references"`
+}
+
+// AttachmentKindTotal holds the count and size of one kind of attachment.
+type AttachmentKindTotal struct {
+	Count int   `json:"count"`
+	Bytes int64 `json:"bytes"`
+}
+
+// AttachmentTotals summarises attachment counts and sizes.
+type AttachmentTotals struct {
+	Count      int                            `json:"count"`
+	TotalBytes int64                          `json:"total_bytes"`
+	ByKind     map[string]AttachmentKindTotal `json:"by_kind,omitempty"`
+}
+
+// DuplicateGroup is a set of attachments with identical content.
+type DuplicateGroup struct {
+	Hash        string   `json:"hash"`
+	Size        int64    `json:"size"`
+	Paths       []string `json:"paths"`
+	WastedBytes int64    `json:"wasted_bytes"` // size of every copy beyond the first
+}
+
+// AttachmentsOutput is the JSON output for attachments list.
+type AttachmentsOutput struct {
+	Attachments []AttachmentEntry `json:"attachments"`
+	Duplicates  []DuplicateGroup  `json:"duplicates"`
+	Totals      AttachmentTotals  `json:"totals"`
+}
+
+// AttachmentOrphansOutput is the JSON output for attachments orphans.
+type AttachmentOrphansOutput struct {
+	Orphans    []AttachmentEntry `json:"orphans"`
+	TotalBytes int64             `json:"total_bytes"`
+}
+
+// AttachmentMissingOutput is the JSON output for attachments missing.
+type AttachmentMissingOutput struct {
+	Missing []BrokenLink `json:"missing"`
+}
+
+// AttachmentMoveOutput is the JSON output for attachments move.
+type AttachmentMoveOutput struct {
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	NotesUpdated []string `json:"notes_updated"`
+	LinksUpdated int      `json:"links_updated"`
+	DryRun       bool     `json:"dry_run,omitempty"`
+}
+
+// attachmentScan is the result of matching vault attachments against the
+// links, embeds, properties and canvases that reference them.
+type attachmentScan struct {
+	attachments []vault.AttachmentInfo // images, audio, video and PDFs
+	canvases    []vault.AttachmentInfo // boards that place attachments
+	references  map[string]int         // attachment path -> referencing notes and canvases
+	missing     []BrokenLink           // attachment links whose file does not exist
+}
+
+// AttachmentsCmd lists, audits or moves the vault's attachments.
+func AttachmentsCmd(vaultPath string, opts AttachmentsOptions) error {
+	notes, err := vault.ListNotes(vaultPath, "")
+	if err != nil {
+		return fmt.Errorf("failed to list notes: %w", err)
+	}
+	loaded := loadVaultNotes(vaultPath, notes)
+
+	resolver := vault.NewResolver()
+	for _, n := range loaded {
+		resolver.AddNote(n.info.Path, n.parsed)
+	}
+	if err := resolver.AddAttachments(vaultPath); err != nil {
+		return fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	scan, err := scanAttachments(vaultPath, loaded, resolver)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Action {
+	case "", "list":
+		return attachmentsList(vaultPath, scan, opts.JSONOutput)
+	case "orphans":
+		return attachmentsOrphans(scan, opts.JSONOutput)
+	case "missing":
+		return attachmentsMissing(scan, opts.JSONOutput)
+	case "move":
+		return attachmentsMove(vaultPath, loaded, resolver, scan, opts)
+	default:
+		return fmt.Errorf("unknown attachments action: %s (use list, orphans, missing or move)", opts.Action)
+	}
+}
+
+// scanAttachments lists attachments and counts the references to each one.
+// Only the file types Obsidian opens as attachments count; canvases,
+// Excalidraw drawings, scripts and other files are vault content, not
+// attachments. A note references an attachment through a link, an embed or
+// a frontmatter property such as cover: "[[photo.png]]"; a canvas
+// references the files it places on the board.
+func scanAttachments(vaultPath string, loaded []maintainNote, resolver *vault.Resolver) (attachmentScan, error) {
+	files, err := vault.ListAttachments(vaultPath, "")
+	if err != nil {
+		return attachmentScan{}, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	scan := attachmentScan{references: make(map[string]int)}
+	for _, f := range files {
+		switch f.Kind {
+		case vault.AttachmentCanvas:
+			scan.canvases = append(scan.canvases, f)
+		case vault.AttachmentOther:
+		default:
+			scan.attachments = append(scan.attachments, f)
+		}
+	}
+
+	for _, n := range loaded {
+		referenced := make(map[string]bool)
+		links := append(append([]vault.Link{}, n.parsed.Links...), n.parsed.Embeds...)
+		for _, l := range links {
+			res := resolver.Resolve(n.info.Path, l)
+			switch {
+			case res.Attachment:
+				referenced[res.Path] = true
+			case res.Status == vault.Missing && l.IsAttachment():
+				scan.missing = append(scan.missing, BrokenLink{
+					Source: n.info.Path,
+					Target: l.Target,
+					Line:   l.Line,
+					Reason: "attachment not found",
+				})
+			}
+		}
+		for _, target := range frontmatterLinkTargets(n.parsed.Frontmatter) {
+			if res := resolver.ResolveTarget(n.info.Path, target); res.Attachment {
+				referenced[res.Path] = true
+			}
+		}
+		for p := range referenced {
+			scan.references[p]++
+		}
+	}
+
+	for _, a := range scan.canvases {
+		for _, file := range canvasFiles(vaultPath, a.Path) {
+			if res := resolver.ResolveTarget(a.Path, file); res.Attachment && res.Path != a.Path {
+				scan.references[res.Path]++
+			}
+		}
+	}
+
+	sort.Slice(scan.missing, func(i, j int) bool {
+		if scan.missing[i].Source != scan.missing[j].Source {
+			return scan.missing[i].Source < scan.missing[j].Source
+		}
+		return scan.missing[i].Line < scan.missing[j].Line
+	})
+	return scan, nil
+}
+
+// orphans returns attachments no note or canvas references.
+func (s attachmentScan) orphans() []AttachmentEntry {
+	var orphans []AttachmentEntry
+	for _, a := range s.attachments {
+		if s.references[a.Path] > 0 {
+			continue
+		}
+		orphans = append(orphans, AttachmentEntry{AttachmentInfo: a})
+	}
+	return orphans
+}
+
+// totals sums attachment counts and sizes, overall and per kind.
+func (s attachmentScan) totals() AttachmentTotals {
+	t := AttachmentTotals{ByKind: make(map[string]AttachmentKindTotal)}
+	for _, a := range s.attachments {
+		t.Count++
+		t.TotalBytes += a.Size
+		k := t.ByKind[a.Kind]
+		k.Count++
+		k.Bytes += a.Size
+		t.ByKind[a.Kind] = k
+	}
+	return t
+}
+
+// frontmatterLinkTargets returns property values that look like file
+// references: "[[target]]" links and bare values with an attachment extension.
+func frontmatterLinkTargets(fm map[string]any) []string {
+	var values []string
+	for _, v := range fm {
+		switch val := v.(type) {
+		case string:
+			values = append(values, val)
+		case []string:
+			values = append(values, val...)
+		}
+	}
+
+	var targets []string
+	for _, v := range values {
+		v = strings.Trim(strings.TrimSpace(v), `"'`)
+		if strings.HasPrefix(v, "[[") && strings.HasSuffix(v, "]]") {
+			inner := strings.TrimSuffix(strings.TrimPrefix(v, "[["), "]]")
+			if idx := strings.Index(inner, "|"); idx >= 0 {
+				inner = inner[:idx]
+			}
+			targets = append(targets, inner)
+			continue
+		}
+		if (vault.Link{Target: v}).IsAttachment() && !strings.Contains(v, "://") {
+			targets = append(targets, v)
+		}
+	}
+	return targets
+}
+
+// rewriteFrontmatterRefs rewrites the file references frontmatterLinkTargets
+// reads from a note's properties. fn is called with each target; when it
+// returns ok, the target is replaced, keeping any [[ ]], alias and quotes.
+// Returns the new content and the number of references rewritten.
+func rewriteFrontmatterRefs(content string, fn func(target string) (newTarget string, ok bool)) (string, int) {
+	fm, _, ok := vault.SplitFrontmatter(content)
+	if !ok || fm == "" {
+		return content, 0
+	}
+	start := strings.Index(content, "\n") + 1
+
+	lines := strings.Split(fm, "\n")
+	count := 0
+	for i, line := range lines {
+		spans := frontmatterValueSpans(line)
+		// Apply right to left so earlier offsets stay valid.
+		for j := len(spans) - 1; j >= 0; j-- {
+			s, e := spans[j][0], spans[j][1]
+			quote := byte(0)
+			if e-s >= 2 && (line[s] == '"' || line[s] == '\'') && line[e-1] == line[s] {
+				quote = line[s]
+				s, e = s+1, e-1
+			}
+			v := line[s:e]
+			if strings.HasPrefix(v, "[[") && strings.HasSuffix(v, "]]") {
+				inner := v[2 : len(v)-2]
+				if idx := strings.IndexAny(inner, "#|"); idx >= 0 {
+					inner = inner[:idx]
+				}
+				s, e = s+2, s+2+len(inner)
+			} else if !(vault.Link{Target: v}).IsAttachment() || strings.Contains(v, "://") {
+				continue
+			}
+			newTarget, ok := fn(line[s:e])
+			if !ok {
+				continue
+			}
+			switch quote {
+			case '"':
+				newTarget = yamlEscaper.Replace(newTarget)
+			case '\'':
+				newTarget = strings.ReplaceAll(newTarget, "'", "''")
+			}
+			line = line[:s] + newTarget + line[e:]
+			count++
+		}
+		lines[i] = line
+	}
+	if count == 0 {
+		return content, 0
+	}
+	return content[:start] + strings.Join(lines, "\n") + content[start+len(fm):], count
+}
+
+// frontmatterValueSpans returns the byte ranges of the scalar values on a
+// frontmatter line: the value of "key: value", each item of an inline
+// "[a, b]" list, or a "- item" list entry.
+func frontmatterValueSpans(line string) [][2]int {
+	end := len(strings.TrimRight(line, " \t\r"))
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	switch rest := line[start:end]; {
+	case rest == "" || strings.HasPrefix(rest, "#"):
+		return nil
+	case strings.HasPrefix(rest, "- "):
+		start += 2
+	default:
+		idx := strings.Index(rest, ":")
+		if idx < 0 {
+			return nil
+		}
+		start += idx + 1
+	}
+	for start < end && (line[start] == ' ' || line[start] == '\t') {
+		start++
+	}
+	if start >= end {
+		return nil
+	}
+
+	v := line[start:end]
+	if !strings.HasPrefix(v, "[") || strings.HasPrefix(v, "[[") || !strings.HasSuffix(v, "]") {
+		return [][2]int{{start, end}}
+	}
+	var spans [][2]int
+	pos := start + 1
+	for _, item := range strings.Split(v[1:len(v)-1], ",") {
+		s, e := pos, pos+len(item)
+		for s < e && line[s] == ' ' {
+			s++
+		}
+		for e > s && line[e-1] == ' ' {
+			e--
+		}
+		if s < e {
+			spans = append(spans, [2]int{s, e})
+		}
+		pos += len(item) + 1
+	}
+	return spans
+}
+
+// canvasFiles returns the vault paths of file nodes on a JSON Canvas board.
+func canvasFiles(vaultPath, canvasPath string) []string {
+	data, err := os.ReadFile(filepath.Join(vaultPath, canvasPath))
+	if err != nil {
+		return nil
+	}
+	var canvas struct {
+		Nodes []struct {
+			Type string `json:"type"`
+			File string `json:"file"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &canvas); err != nil {
+		return nil
+	}
+	var files []string
+	for _, n := range canvas.Nodes {
+		if n.Type == "file" && n.File != "" {
+			files = append(files, n.File)
+		}
+	}
+	return files
+}
+
+// findDuplicateAttachments groups attachments with identical content. Files
+// are only hashed when another attachment has the same size.
+func findDuplicateAttachments(vaultPath string, attachments []vault.AttachmentInfo) []DuplicateGroup {
+	bySize := make(map[int64][]string)
+	for _, a := range attachments {
+		if a.Size > 0 {
+			bySize[a.Size] = append(bySize[a.Size], a.Path)
+		}
+	}
+
+	var groups []DuplicateGroup
+	for size, paths := range bySize {
+		if len(paths) < 2 {
+			continue
+		}
+		byHash := make(map[string][]string)
+		for _, p := range paths {
+			hash, err := vault.HashFile(vaultPath, p)
+			if err != nil {
+				continue
+			}
+			byHash[hash] = append(byHash[hash], p)
+		}
+		for hash, same := range byHash {
+			if len(same) < 2 {
+				continue
+			}
+			sort.Strings(same)
+			groups = append(groups, DuplicateGroup{
+				Hash:        hash,
+				Size:        size,
+				Paths:       same,
+				WastedBytes: size * int64(len(same)-1),
+			})
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].WastedBytes != groups[j].WastedBytes {
+			return groups[i].WastedBytes > groups[j].WastedBytes
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+	return groups
+}
+
+func attachmentsList(vaultPath string, scan attachmentScan, jsonOutput bool) error {
+	result := AttachmentsOutput{
+		Attachments: []AttachmentEntry{},
+		Duplicates:  findDuplicateAttachments(vaultPath, scan.attachments),
+		Totals:      scan.totals(),
+	}
+	for _, a := range scan.attachments {
+		result.Attachments = append(result.Attachments, AttachmentEntry{
+			AttachmentInfo: a,
+			References:     scan.references[a.Path],
+		})
+	}
+	if result.Duplicates == nil {
+		result.Duplicates = []DuplicateGroup{}
+	}
+
+	if jsonOutput {
+		return output.JSON(result)
+	}
+
+	if len(result.Attachments) == 0 {
+		fmt.Println("No attachments found.")
+		return nil
+	}
+
+	for _, a := range result.Attachments {
+		fmt.Printf("  %-50s %-7s %9s  %d refs\n", a.Path, a.Kind, formatBytes(a.Size), a.References)
+	}
+
+	fmt.Printf("\nTotal: %d attachments, %s\n", result.Totals.Count, formatBytes(result.Totals.TotalBytes))
+	kinds := make([]string, 0, len(result.Totals.ByKind))
+	for k := range result.Totals.ByKind {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		kt := result.Totals.ByKind[k]
+		fmt.Printf("  %-7s %4d  %s\n", k, kt.Count, formatBytes(kt.Bytes))
+	}
+
+	if len(result.Duplicates) > 0 {
+		var wasted int64
+		for _, g := range result.Duplicates {
+			wasted += g.WastedBytes
+		}
+		fmt.Printf("\nDuplicates: %d groups (%s reclaimable)\n", len(result.Duplicates), formatBytes(wasted))
+		for _, g := range result.Duplicates {
+			fmt.Printf("  - %s (%s each)\n", strings.Join(g.Paths, ", "), formatBytes(g.Size))
+		}
+	}
+	return nil
+}
+
+func attachmentsOrphans(scan attachmentScan, jsonOutput bool) error {
+	result := AttachmentOrphansOutput{Orphans: scan.orphans()}
+	for _, o := range result.Orphans {
+		result.TotalBytes += o.Size
+	}
+
+	if jsonOutput {
+		if result.Orphans == nil {
+			result.Orphans = []AttachmentEntry{}
+		}
+		return output.JSON(result)
+	}
+
+	if len(result.Orphans) == 0 {
+		fmt.Println("No orphan attachments.")
+		return nil
+	}
+	fmt.Printf("Orphan attachments: %d (%s)\n\n", len(result.Orphans), formatBytes(result.TotalBytes))
+	for _, o := range result.Orphans {
+		fmt.Printf("  - %s (%s)\n", o.Path, formatBytes(o.Size))
+	}
+	return nil
+}
+
+func attachmentsMissing(scan attachmentScan, jsonOutput bool) error {
+	if jsonOutput {
+		missing := scan.missing
+		if missing == nil {
+			missing = []BrokenLink{}
+		}
+		return output.JSON(AttachmentMissingOutput{Missing: missing})
+	}
+
+	if len(scan.missing) == 0 {
+		fmt.Println("No missing attachments.")
+		return nil
+	}
+	fmt.Printf("Missing attachments: %d\n\n", len(scan.missing))
+	for _, m := range scan.missing {
+		fmt.Printf("  - %s:%d embeds %s\n", m.Source, m.Line, m.Target)
+	}
+	return nil
+}
+
+// attachmentsMove moves an attachment and rewrites every link, embed,
+// property and canvas node that points at it.
+func attachmentsMove(vaultPath string, loaded []maintainNote, resolver *vault.Resolver, scan attachmentScan, opts AttachmentsOptions) error {
+	if opts.From == "" || opts.To == "" {
+		return fmt.Errorf("move requires a source and destination\n\nUsage: obsidian attachments move <from> <to>")
+	}
+
+	from := filepath.ToSlash(filepath.Clean(opts.From))
+	var found bool
+	for _, a := range scan.attachments {
+		if strings.EqualFold(a.Path, from) {
+			from, found = a.Path, true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("attachment not found: %s", opts.From)
+	}
+
+	to := filepath.ToSlash(filepath.Clean(opts.To))
+	if strings.HasSuffix(opts.To, "/") || isDir(filepath.Join(vaultPath, to)) {
+		to = path.Join(to, path.Base(from))
+	}
+	if filepath.IsAbs(opts.To) || to == "." || strings.HasPrefix(to, "../") || strings.HasSuffix(to, ".md") {
+		return fmt.Errorf("invalid destination: %s", opts.To)
+	}
+	if _, err := os.Stat(filepath.Join(vaultPath, to)); err == nil {
+		return fmt.Errorf("destination already exists: %s", to)
+	}
+
+	// Bare-name links stay bare when the new filename is still unique.
+	newName := path.Base(to)
+	nameUnique := true
+	for _, a := range scan.attachments {
+		if a.Path != from && strings.EqualFold(path.Base(a.Path), newName) {
+			nameUnique = false
+			break
+		}
+	}
+
+	result := AttachmentMoveOutput{From: from, To: to, NotesUpdated: []string{}, DryRun: opts.DryRun}
+	updates := make(map[string]string) // note or canvas path -> new content
+
+	for _, n := range loaded {
+		content, count := vault.RewriteLinks(n.content, func(l vault.Link) (string, bool) {
+			res := resolver.Resolve(n.info.Path, l)
+			if !res.Attachment || res.Path != from {
+				return "", false
+			}
+			if l.Kind == vault.LinkMarkdown {
+				rel, err := filepath.Rel(path.Dir(n.info.Path), to)
+				if err != nil {
+					return to, true
+				}
+				return filepath.ToSlash(rel), true
+			}
+			if nameUnique && !strings.Contains(l.Target, "/") {
+				return newName, true
+			}
+			return to, true
+		})
+		content, fmCount := rewriteFrontmatterRefs(content, func(target string) (string, bool) {
+			res := resolver.ResolveTarget(n.info.Path, target)
+			if !res.Attachment || res.Path != from {
+				return "", false
+			}
+			if nameUnique && !strings.Contains(target, "/") {
+				return newName, true
+			}
+			return to, true
+		})
+		count += fmCount
+		if count > 0 {
+			updates[n.info.Path] = content
+			result.LinksUpdated += count
+		}
+	}
+
+	for _, a := range scan.canvases {
+		if a.Path == from {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(vaultPath, a.Path))
+		if err != nil {
+			continue
+		}
+		content := string(data)
+		count := 0
+		for _, sep := range []string{`":"`, `": "`} {
+			old := `"file` + sep + from + `"`
+			count += strings.Count(content, old)
+			content = strings.ReplaceAll(content, old, `"file`+sep+to+`"`)
+		}
+		if count > 0 {
+			updates[a.Path] = content
+			result.LinksUpdated += count
+		}
+	}
+
+	for p := range updates {
+		result.NotesUpdated = append(result.NotesUpdated, p)
+	}
+	sort.Strings(result.NotesUpdated)
+
+	if !opts.DryRun {
+		fullTo := filepath.Join(vaultPath, to)
+		if err := os.MkdirAll(filepath.Dir(fullTo), 0755); err != nil {
+			return fmt.Errorf("creating destination directory: %w", err)
+		}
+		if err := os.Rename(filepath.Join(vaultPath, from), fullTo); err != nil {
+			return fmt.Errorf("moving attachment: %w", err)
+		}
+		snapshots := newNoteHistory(vaultPath)
+		defer snapshots.save()
+		for _, p := range result.NotesUpdated {
+			if strings.HasSuffix(p, ".md") {
+				snapshots.snapshot(p, "attachments move")
+			}
+			if err := os.WriteFile(filepath.Join(vaultPath, p), []byte(updates[p]), 0644); err != nil {
+				return fmt.Errorf("updating %s: %w", p, err)
+			}
+		}
+	}
+
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+
+	verb := "Moved"
+	if opts.DryRun {
+		verb = "Would move"
+	}
+	fmt.Printf("%s %s -> %s\n", verb, from, to)
+	fmt.Printf("Links updated: %d in %d files\n", result.LinksUpdated, len(result.NotesUpdated))
+	for _, p := range result.NotesUpdated {
+		fmt.Printf("  - %s\n", p)
+	}
+	return nil
+}
+
+// isDir reports whether p is an existing directory.
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
+
+// formatBytes renders a byte count as B, KB or MB.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
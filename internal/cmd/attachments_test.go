@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func attachmentTestVault(t *testing.T) string {
+	return writeVaultFiles(t, map[string]string{
+		"Notes/a.md":               "---\ncover: \"[[cover.jpg]]\"\n---\n![[diagram.png]] and ![photo](../img/photo.png)\n![[gone.png]]\n",
+		"Notes/b.md":               "See [[Notes/a]] and [the pdf](paper.pdf).\n",
+		"Notes/paper.pdf":          "%PDF",
+		"Attachments/diagram.png":  "PNGDATA",
+		"img/photo.png":            "PHOTO",
+		"img/cover.jpg":            "JPEG",
+		"img/unused.gif":           "GIF",
+		"img/copy-of-photo.png":    "PHOTO",
+		"Boards/board.canvas":      `{"nodes":[{"id":"1","type":"file","file":"img/unused.gif"}]}`,
+		"old/stray.png":            "STRAY",
+		"Boards/sketch.excalidraw": "{}",
+		"scripts/export.js":        "// not an attachment",
+	})
+}
+
+func TestScanAttachments(t *testing.T) {
+	dir := attachmentTestVault(t)
+
+	out := captureStdout(t, func() {
+		if err := AttachmentsCmd(dir, AttachmentsOptions{Action: "orphans", JSONOutput: true}); err != nil {
+			t.Fatalf("AttachmentsCmd(orphans) error: %v", err)
+		}
+	})
+	var orphans AttachmentOrphansOutput
+	if err := json.Unmarshal([]byte(out), &orphans); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	var paths []string
+	for _, o := range orphans.Orphans {
+		paths = append(paths, o.Path)
+	}
+	want := "img/copy-of-photo.png,old/stray.png"
+	if strings.Join(paths, ",") != want {
+		t.Errorf("orphans = %v, want %s", paths, want)
+	}
+
+	out = captureStdout(t, func() {
+		if err := AttachmentsCmd(dir, AttachmentsOptions{Action: "missing", JSONOutput: true}); err != nil {
+			t.Fatalf("AttachmentsCmd(missing) error: %v", err)
+		}
+	})
+	var missing AttachmentMissingOutput
+	if err := json.Unmarshal([]byte(out), &missing); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(missing.Missing) != 1 || missing.Missing[0].Target != "gone.png" || missing.Missing[0].Line != 5 {
+		t.Errorf("missing = %+v, want gone.png on line 5", missing.Missing)
+	}
+}
+
+func TestAttachmentsList_Duplicates(t *testing.T) {
+	dir := attachmentTestVault(t)
+
+	out := captureStdout(t, func() {
+		if err := AttachmentsCmd(dir, AttachmentsOptions{Action: "list", JSONOutput: true}); err != nil {
+			t.Fatalf("AttachmentsCmd(list) error: %v", err)
+		}
+	})
+	var result AttachmentsOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Totals.Count != 7 || result.Totals.ByKind["image"].Count != 6 {
+		t.Errorf("totals = %+v", result.Totals)
+	}
+	if len(result.Duplicates) != 1 || strings.Join(result.Duplicates[0].Paths, ",") != "img/copy-of-photo.png,img/photo.png" {
+		t.Errorf("duplicates = %+v", result.Duplicates)
+	}
+}
+
+func TestAttachmentsMove(t *testing.T) {
+	dir := attachmentTestVault(t)
+
+	captureStdout(t, func() {
+		err := AttachmentsCmd(dir, AttachmentsOptions{Action: "move", From: "img/unused.gif", To: "Media/", JSONOutput: true})
+		if err != nil {
+			t.Fatalf("move error: %v", err)
+		}
+		err = AttachmentsCmd(dir, AttachmentsOptions{Action: "move", From: "Attachments/diagram.png", To: "Media/arch.png", JSONOutput: true})
+		if err != nil {
+			t.Fatalf("move error: %v", err)
+		}
+		err = AttachmentsCmd(dir, AttachmentsOptions{Action: "move", From: "img/photo.png", To: "Media/photo.png", JSONOutput: true})
+		if err != nil {
+			t.Fatalf("move error: %v", err)
+		}
+	})
+
+	if _, err := os.Stat(filepath.Join(dir, "Media/arch.png")); err != nil {
+		t.Errorf("attachment not moved: %v", err)
+	}
+	a, _ := os.ReadFile(filepath.Join(dir, "Notes/a.md"))
+	if !strings.Contains(string(a), "![[arch.png]]") || !strings.Contains(string(a), "![photo](../Media/photo.png)") {
+		t.Errorf("note links not rewritten:\n%s", a)
+	}
+	board, _ := os.ReadFile(filepath.Join(dir, "Boards/board.canvas"))
+	if !strings.Contains(string(board), `"file":"Media/unused.gif"`) {
+		t.Errorf("canvas not rewritten:\n%s", board)
+	}
+}
+
+func TestAttachmentsMove_Frontmatter(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/a.md":    "---\ncover: \"[[cover.jpg]]\"\n---\nNo body links.\n",
+		"Notes/b.md":    "---\nbanner: img/cover.jpg\ngallery:\n  - '[[cover.jpg|Cover]]'\nimages: [img/cover.jpg, other.png]\n---\nBody.\n",
+		"img/cover.jpg": "JPEG",
+	})
+
+	captureStdout(t, func() {
+		err := AttachmentsCmd(dir, AttachmentsOptions{Action: "move", From: "img/cover.jpg", To: "Media/My Cover (1).jpg"})
+		if err != nil {
+			t.Fatalf("move error: %v", err)
+		}
+	})
+
+	a := mustRead(t, filepath.Join(dir, "Notes/a.md"))
+	if !strings.Contains(a, `cover: "[[My Cover (1).jpg]]"`) {
+		t.Errorf("frontmatter link not rewritten:\n%s", a)
+	}
+	b := mustRead(t, filepath.Join(dir, "Notes/b.md"))
+	for _, want := range []string{
+		"banner: Media/My Cover (1).jpg\n",
+		"  - '[[My Cover (1).jpg|Cover]]'\n",
+		"images: [Media/My Cover (1).jpg, other.png]\n",
+	} {
+		if !strings.Contains(b, want) {
+			t.Errorf("frontmatter missing %q:\n%s", want, b)
+		}
+	}
+}
+
+func TestAttachmentsMove_InvalidDestination(t *testing.T) {
+	dir := attachmentTestVault(t)
+	for _, to := range []string{filepath.Join(dir, "Media/x.png"), "../x.png", "Notes/x.md"} {
+		err := AttachmentsCmd(dir, AttachmentsOptions{Action: "move", From: "img/unused.gif", To: to})
+		if err == nil || !strings.Contains(err.Error(), "invalid destination") {
+			t.Errorf("move to %q error = %v, want invalid destination", to, err)
+		}
+	}
+}
+
+func TestAttachmentsMove_MarkdownEscaping(t *testing.T) {
+	dir := attachmentTestVault(t)
+	captureStdout(t, func() {
+		err := AttachmentsCmd(dir, AttachmentsOptions{Action: "move", From: "img/photo.png", To: "Media/My Photo (2).png"})
+		if err != nil {
+			t.Fatalf("move error: %v", err)
+		}
+	})
+	a := mustRead(t, filepath.Join(dir, "Notes/a.md"))
+	if !strings.Contains(a, "![photo](../Media/My%20Photo%20%282%29.png)") {
+		t.Errorf("markdown link not escaped:\n%s", a)
+	}
+}
@@ -1,63 +1,581 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/dedupe"
 	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/template"
 	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+	"github.com/joeyhipolito/obsidian-cli/internal/webpage"
 )
 
+// Capture input formats.
+const (
+	CaptureFormatAuto = "auto" // JSON/NDJSON when the input parses as capture items, text otherwise
+	CaptureFormatText = "text"
+	CaptureFormatJSON = "json" // a JSON object, a JSON array, or NDJSON
+)
+
+// captureIDKey is the frontmatter property that records a capture's --id.
+const captureIDKey = "capture_id"
+
+// CaptureOptions configures the capture command.
+type CaptureOptions struct {
+	Body       string   // text to capture; read from stdin when empty
+	Source     string   // default source for every item
+	Title      string   // title of a single capture
+	Tags       []string // added to every item
+	To         string   // target folder (default Inbox, or References for --fetch)
+	ID         string   // idempotency key of a single capture
+	Format     string   // auto (default), text or json
+	Template   string   // note template each capture is rendered through
+	Vars       map[string]string
+	JSONOutput bool
+}
+
+// CaptureItem is one item of structured capture input. A JSON object, a JSON
+// array of objects, or newline-delimited objects (NDJSON) are accepted.
+type CaptureItem struct {
+	ID     string   `json:"id,omitempty"` // idempotency key; an item whose ID was already captured is skipped
+	Title  string   `json:"title,omitempty"`
+	Body   string   `json:"body"`
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	To     string   `json:"to,omitempty"` // target folder
+}
+
 // CaptureOutput represents the JSON output format for the capture command.
 type CaptureOutput struct {
-	Path   string `json:"path"`
-	Source string `json:"source,omitempty"`
+	Path       string         `json:"path"`
+	ID         string         `json:"id,omitempty"`
+	Existing   bool           `json:"existing,omitempty"` // the ID was already captured; nothing was written
+	Title      string         `json:"title,omitempty"`
+	Type       string         `json:"type,omitempty"`
+	Source     string         `json:"source,omitempty"`
+	Truncated  bool           `json:"truncated,omitempty"`  // fetched article was cut at the size limit
+	Duplicates []dedupe.Match `json:"duplicates,omitempty"` // existing notes this capture likely repeats
+}
+
+// CaptureBatchOutput is the JSON output for structured (multi-item) capture.
+type CaptureBatchOutput struct {
+	Notes    []CaptureOutput `json:"notes"`
+	Created  int             `json:"created"`
+	Existing int             `json:"existing"`
+	Errors   []string        `json:"errors,omitempty"`
 }
 
-// CaptureCmd creates a fleeting note in Inbox/ from a body string or stdin.
-// The note gets frontmatter: type: fleeting, created: YYYY-MM-DD, and optional source.
-func CaptureCmd(vaultPath, body, source string, jsonOutput bool) error {
-	if body == "" {
+// CaptureCmd creates notes from a body string or stdin. Plain text becomes
+// one fleeting note in Inbox/ (or the --to folder) with frontmatter type,
+// created and the optional title, source, tags and capture_id. Frontmatter
+// already present in the text is kept, with flags taking precedence.
+// Structured input (JSON or NDJSON capture items) creates one note per item.
+// Items whose ID was captured before are skipped, so scripts can re-run.
+// Likely duplicates of existing notes are reported as warnings; the note is
+// captured regardless. With a template, each capture's text is placed at
+// the template's {{content}} and the template's properties fill in the rest.
+func CaptureCmd(vaultPath string, opts CaptureOptions) error {
+	text := opts.Body
+	if text == "" {
 		data, err := io.ReadAll(os.Stdin)
 		if err != nil {
 			return fmt.Errorf("reading stdin: %w", err)
 		}
-		body = strings.TrimRight(string(data), "\n")
-		if body == "" {
+		text = strings.TrimRight(string(data), "\n")
+		if strings.TrimSpace(text) == "" {
 			return fmt.Errorf("no body provided\n\nUsage: obsidian capture <body> [--source <url>]\n       echo 'text' | obsidian capture")
 		}
 	}
 
-	now := time.Now()
-	filename := fmt.Sprintf("Inbox/%s.md", now.Format("20060102-150405"))
+	var items []CaptureItem
+	switch opts.Format {
+	case "", CaptureFormatAuto:
+		items, _ = parseCaptureItems(text)
+	case CaptureFormatJSON:
+		var err error
+		if items, err = parseCaptureItems(text); err != nil {
+			return fmt.Errorf("parsing capture items: %w", err)
+		}
+	case CaptureFormatText:
+	default:
+		return fmt.Errorf("unknown capture format %q (want auto, text or json)", opts.Format)
+	}
+
+	c, err := newCapturer(vaultPath, opts)
+	if err != nil {
+		return err
+	}
+
+	if items == nil {
+		result, err := c.capture(CaptureItem{ID: opts.ID, Title: opts.Title, Body: text})
+		if err != nil {
+			return err
+		}
+		if opts.JSONOutput {
+			return output.JSON(result)
+		}
+		printCaptureResult(result)
+		return nil
+	}
+
+	if len(items) == 1 {
+		// Flags name a lone item the same way they name text input.
+		items[0].ID = firstNonEmpty(items[0].ID, opts.ID)
+		items[0].Title = firstNonEmpty(items[0].Title, opts.Title)
+	}
+	batch := CaptureBatchOutput{Notes: []CaptureOutput{}}
+	for i, item := range items {
+		result, err := c.capture(item)
+		if err != nil {
+			batch.Errors = append(batch.Errors, fmt.Sprintf("item %d: %v", i+1, err))
+			continue
+		}
+		batch.Notes = append(batch.Notes, result)
+		if result.Existing {
+			batch.Existing++
+		} else {
+			batch.Created++
+		}
+	}
+
+	if opts.JSONOutput {
+		return output.JSON(batch)
+	}
+	printCaptureBatch(batch)
+	return nil
+}
+
+// parseCaptureItems decodes JSON capture input: one object, an array of
+// objects, or a stream of objects (NDJSON). Unknown fields and items
+// without a body are errors, so ordinary JSON text is not mistaken for
+// capture items.
+func parseCaptureItems(text string) ([]CaptureItem, error) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, errors.New("input is not a JSON object or array")
+	}
+
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.DisallowUnknownFields()
+	var items []CaptureItem
+	if strings.HasPrefix(trimmed, "[") {
+		if err := dec.Decode(&items); err != nil {
+			return nil, err
+		}
+		if dec.More() {
+			return nil, errors.New("unexpected data after JSON array")
+		}
+	} else {
+		for {
+			var item CaptureItem
+			err := dec.Decode(&item)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", len(items)+1, err)
+			}
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("no capture items")
+	}
+	for i, item := range items {
+		if strings.TrimSpace(item.Body) == "" {
+			return nil, fmt.Errorf("item %d: body is required", i+1)
+		}
+	}
+	return items, nil
+}
+
+// capturer writes capture notes, tracking capture IDs and duplicate
+// candidates across the items of one run.
+type capturer struct {
+	vaultPath string
+	opts      CaptureOptions
+	now       time.Time
+	ids       map[string]string // capture_id → note path; nil until loaded
+	dupes     *dedupe.Corpus    // nil when the vault could not be scanned
+	tmpl      *noteTemplate     // nil without --template
+	prompt    func(name, def string) (string, error)
+}
+
+func newCapturer(vaultPath string, opts CaptureOptions) (*capturer, error) {
+	c := &capturer{vaultPath: vaultPath, opts: opts, now: time.Now()}
+	if opts.Template != "" {
+		var err error
+		if c.tmpl, err = loadNoteTemplate(vaultPath, opts.Template); err != nil {
+			return nil, err
+		}
+		c.prompt = terminalPrompt()
+	}
+	// Duplicate check is best-effort and never blocks the capture. The same
+	// walk collects capture IDs, so ID lookups need no second pass.
+	ids := make(map[string]string)
+	corpus, err := loadDedupeCorpus(vaultPath, dedupe.DefaultOptions(), func(notePath string, parsed *vault.Note) {
+		if id := frontmatterString(parsed.Frontmatter, captureIDKey); id != "" {
+			ids[id] = notePath
+		}
+	})
+	if err == nil {
+		c.dupes, c.ids = corpus, ids
+	}
+	return c, nil
+}
+
+// captureIDs returns the capture_id → path map, scanning the vault on first
+// use when the duplicate corpus could not be loaded.
+func (c *capturer) captureIDs() (map[string]string, error) {
+	if c.ids == nil {
+		ids, err := loadCaptureIDs(c.vaultPath)
+		if err != nil {
+			return nil, err
+		}
+		c.ids = ids
+	}
+	return c.ids, nil
+}
+
+// loadCaptureIDs maps the capture_id of every vault note to its path.
+func loadCaptureIDs(vaultPath string) (map[string]string, error) {
+	notes, err := vault.ListNotes(vaultPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("listing notes: %w", err)
+	}
+	ids := make(map[string]string)
+	for _, info := range notes {
+		data, err := os.ReadFile(filepath.Join(vaultPath, info.Path))
+		if err != nil || !strings.Contains(string(data), captureIDKey+":") {
+			continue
+		}
+		if id := frontmatterString(vault.ParseNote(string(data)).Frontmatter, captureIDKey); id != "" {
+			ids[id] = info.Path
+		}
+	}
+	return ids, nil
+}
+
+// capture writes one item, filling its source and folder from the options
+// and adding the option tags. An item whose ID is already in the vault is
+// reported as existing and not written again.
+func (c *capturer) capture(item CaptureItem) (CaptureOutput, error) {
+	if item.ID != "" {
+		ids, err := c.captureIDs()
+		if err != nil {
+			return CaptureOutput{}, err
+		}
+		if path, ok := ids[item.ID]; ok {
+			return CaptureOutput{Path: path, ID: item.ID, Existing: true}, nil
+		}
+	}
+	if strings.TrimSpace(item.Body) == "" {
+		return CaptureOutput{}, errors.New("body is required")
+	}
+	item.Source = firstNonEmpty(item.Source, c.opts.Source)
+	item.To = firstNonEmpty(item.To, c.opts.To, "Inbox")
+	item.Tags = append(append([]string{}, c.opts.Tags...), item.Tags...)
+
+	folder, err := captureFolder(item.To)
+	if err != nil {
+		return CaptureOutput{}, err
+	}
+
+	// The note is named before a template is applied, so the template's
+	// {{title}} is the note's title or, failing that, its file name.
+	title := firstNonEmpty(item.Title, frontmatterString(vault.ParseNote(item.Body).Frontmatter, "title"))
+	name := c.now.Format("20060102-150405")
+	if title != "" {
+		name = slugify(title)
+	}
+	filename, err := uniqueNotePath(c.vaultPath, filepath.ToSlash(filepath.Join(folder, name+".md")))
+	if err != nil {
+		return CaptureOutput{}, err
+	}
+
+	if c.tmpl != nil {
+		vars := map[string]string{"source": item.Source}
+		for k, v := range c.opts.Vars {
+			vars[k] = v
+		}
+		item.Body, err = c.tmpl.wrap(item.Body, template.Context{
+			Title:  firstNonEmpty(title, fileTitle(filename)),
+			Folder: filepath.ToSlash(folder),
+			Date:   c.now,
+			Vars:   vars,
+			Prompt: c.prompt,
+		})
+		if err != nil {
+			return CaptureOutput{}, err
+		}
+	}
+	content, title, noteType := buildCaptureContent(item, c.now)
+
+	parsed := vault.ParseNote(content)
+	var duplicates []dedupe.Match
+	if c.dupes != nil {
+		doc := noteDedupeDoc(filename, parsed)
+		duplicates = c.dupes.Find(doc)
+		c.dupes.Add(doc)
+	}
+
+	if err := vault.WriteNote(c.vaultPath, filename, content); err != nil {
+		return CaptureOutput{}, fmt.Errorf("writing capture note: %w", err)
+	}
+	if item.ID != "" {
+		c.ids[item.ID] = filename
+	}
+
+	return CaptureOutput{
+		Path:       filename,
+		ID:         item.ID,
+		Title:      title,
+		Type:       noteType,
+		Source:     frontmatterString(parsed.Frontmatter, "source"),
+		Duplicates: duplicates,
+	}, nil
+}
+
+// buildCaptureContent renders a capture note. Frontmatter at the top of the
+// item body is passed through: its title, type, created, source and tags
+// are used unless the item sets them, and its other properties are copied
+// verbatim after the capture fields. It returns the content, title and type.
+func buildCaptureContent(item CaptureItem, now time.Time) (content, title, noteType string) {
+	body := item.Body
+	var rawFM string
+	fm := map[string]any{}
+	if raw, rest, ok := vault.SplitFrontmatter(body); ok {
+		rawFM = raw
+		fm = vault.ParseNote(body).Frontmatter
+		body = strings.TrimLeft(rest, "\r\n")
+	}
+
+	title = firstNonEmpty(item.Title, frontmatterString(fm, "title"))
+	noteType = firstNonEmpty(frontmatterString(fm, "type"), string(NoteTypeFleeting))
+	created := firstNonEmpty(frontmatterString(fm, "created"), now.Format("2006-01-02"))
+	source := firstNonEmpty(item.Source, frontmatterString(fm, "source"))
+	var tags []string
+	for _, t := range append(extractTagsList(fm), item.Tags...) {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "#")
+		if t != "" && !containsFold(tags, t) {
+			tags = append(tags, t)
+		}
+	}
 
 	var b strings.Builder
 	b.WriteString("---\n")
-	fmt.Fprintf(&b, "type: fleeting\n")
-	fmt.Fprintf(&b, "created: %s\n", now.Format("2006-01-02"))
+	if title != "" {
+		fmt.Fprintf(&b, "title: %s\n", frontmatterValue(title))
+	}
+	fmt.Fprintf(&b, "type: %s\n", noteType)
+	fmt.Fprintf(&b, "created: %s\n", created)
 	if source != "" {
 		fmt.Fprintf(&b, "source: %s\n", source)
 	}
+	if len(tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, t := range tags {
+			fmt.Fprintf(&b, "  - %s\n", t)
+		}
+	}
+	if item.ID != "" {
+		fmt.Fprintf(&b, "%s: %s\n", captureIDKey, frontmatterValue(item.ID))
+	}
+	b.WriteString(passthroughFrontmatter(rawFM, "title", "type", "created", "source", "tags", captureIDKey))
 	b.WriteString("---\n\n")
 	b.WriteString(body)
 	if !strings.HasSuffix(body, "\n") {
 		b.WriteByte('\n')
 	}
+	return b.String(), title, noteType
+}
 
-	if err := vault.WriteNote(vaultPath, filename, b.String()); err != nil {
-		return fmt.Errorf("writing capture note: %w", err)
+// passthroughFrontmatter returns the lines of a raw frontmatter block except
+// the properties named in skip (with their list or nested lines).
+func passthroughFrontmatter(raw string, skip ...string) string {
+	var b strings.Builder
+	dropping := false
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+			key, _, _ := strings.Cut(line, ":")
+			dropping = containsStr(skip, strings.TrimSpace(key))
+		}
+		if !dropping {
+			b.WriteString(line + "\n")
+		}
 	}
+	return b.String()
+}
 
-	if jsonOutput {
-		return output.JSON(CaptureOutput{
-			Path:   filename,
-			Source: source,
-		})
+// captureFolder validates a --to folder: it must stay inside the vault.
+func captureFolder(to string) (string, error) {
+	folder := filepath.Clean(strings.Trim(to, "/"))
+	if filepath.IsAbs(to) || folder == "." || folder == ".." || strings.HasPrefix(folder, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid folder %q: must be a folder inside the vault", to)
 	}
+	return folder, nil
+}
 
-	fmt.Printf("Captured to %s\n", filename)
+// uniqueNotePath returns notePath, or notePath with a -2, -3, ... suffix
+// when a note already exists there. It fails when a candidate cannot be
+// checked, such as when the folder is a file or unreadable.
+func uniqueNotePath(vaultPath, notePath string) (string, error) {
+	ext := filepath.Ext(notePath)
+	base := strings.TrimSuffix(notePath, ext)
+	candidate := notePath
+	for n := 2; ; n++ {
+		_, err := os.Stat(filepath.Join(vaultPath, candidate))
+		if os.IsNotExist(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("checking %s: %w", candidate, err)
+		}
+		candidate = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
+
+func printCaptureResult(result CaptureOutput) {
+	if result.Existing {
+		fmt.Printf("Already captured as %s (id %s)\n", result.Path, result.ID)
+		return
+	}
+	if result.Title != "" {
+		fmt.Printf("Captured %q to %s\n", result.Title, result.Path)
+	} else {
+		fmt.Printf("Captured to %s\n", result.Path)
+	}
+	if result.Truncated {
+		fmt.Fprintf(os.Stderr, "Warning: article truncated; raise fetch_max_chars to keep more\n")
+	}
+	printDuplicateWarnings(result.Path, result.Duplicates)
+}
+
+func printCaptureBatch(batch CaptureBatchOutput) {
+	fmt.Printf("Captured %d notes", batch.Created)
+	if batch.Existing > 0 {
+		fmt.Printf(" (%d already captured)", batch.Existing)
+	}
+	fmt.Println()
+	for _, n := range batch.Notes {
+		if n.Existing {
+			fmt.Printf("  = %s (id %s)\n", n.Path, n.ID)
+		} else {
+			fmt.Printf("  + %s\n", n.Path)
+		}
+	}
+	for _, n := range batch.Notes {
+		printDuplicateWarnings(n.Path, n.Duplicates)
+	}
+	for _, e := range batch.Errors {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", e)
+	}
+}
+
+// CaptureFetchCmd fetches a web page, extracts its main article as markdown
+// and writes it as a reference note in References/ (or the --to folder),
+// bypassing the inbox. The frontmatter records the page title, canonical URL
+// as source, author, published date, site and description. Size and time
+// limits come from config (fetch_max_bytes, fetch_max_chars, fetch_timeout).
+// --title, --tags and --id apply as for text captures; a known --id skips
+// the fetch.
+func CaptureFetchCmd(vaultPath, rawURL string, opts CaptureOptions) error {
+	opts.To = firstNonEmpty(opts.To, typeFolder(string(NoteTypeReference)))
+	c, err := newCapturer(vaultPath, opts)
+	if err != nil {
+		return err
+	}
+	if path, ok := c.ids[opts.ID]; ok && opts.ID != "" {
+		result := CaptureOutput{Path: path, ID: opts.ID, Existing: true}
+		if opts.JSONOutput {
+			return output.JSON(result)
+		}
+		printCaptureResult(result)
+		return nil
+	}
+
+	settings := config.ResolveFetch()
+	page, err := webpage.Fetch(context.Background(), rawURL, webpage.Options{
+		MaxBytes: settings.MaxBytes,
+		MaxChars: settings.MaxChars,
+		Timeout:  settings.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	if page.Markdown == "" {
+		return fmt.Errorf("no article content found at %s", rawURL)
+	}
+
+	title := firstNonEmpty(opts.Title, page.Title, titleFromURL(page.URL))
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "type: %s\n", NoteTypeReference)
+	for _, kv := range [][2]string{
+		{"author", page.Author},
+		{"published", page.Published},
+		{"site", page.Site},
+		{"description", page.Description},
+	} {
+		if kv[1] != "" {
+			fmt.Fprintf(&b, "%s: %s\n", kv[0], frontmatterValue(kv[1]))
+		}
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(page.Markdown)
+	b.WriteByte('\n')
+	if page.Truncated {
+		fmt.Fprintf(&b, "\n> [!note] Truncated\n> The article was cut at %d characters. See the [original](%s).\n", len([]rune(page.Markdown)), page.URL)
+	}
+
+	result, err := c.capture(CaptureItem{ID: opts.ID, Title: title, Body: b.String(), Source: page.URL})
+	if err != nil {
+		return err
+	}
+	result.Truncated = page.Truncated
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+	printCaptureResult(result)
 	return nil
 }
+
+// yamlEscaper escapes a string for a double-quoted YAML scalar.
+var yamlEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// frontmatterValue makes a single-line string safe as a frontmatter value,
+// quoting it when YAML would otherwise misread it.
+func frontmatterValue(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return s
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.ContainsAny(s[:1], "[]{}&*!|>'\"%@`#,?-") {
+		return `"` + yamlEscaper.Replace(s) + `"`
+	}
+	return s
+}
+
+// firstNonEmpty returns the first value that is not blank, trimmed.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+const fetchedPage = `<html><head>
+<title>ignored</title>
+<meta property="og:title" content="Generics: An Introduction">
+<meta property="og:site_name" content="The Go Blog">
+<meta name="author" content="Robert Griesemer">
+<meta property="article:published_time" content="2022-03-22">
+<link rel="canonical" href="https://go.dev/blog/intro-generics">
+</head><body>
+<nav><a href="/">Home</a></nav>
+<article>
+<p>Generics add three new big things to the language: type parameters, type sets, and type inference.</p>
+<p>Functions and types are now permitted to have type parameters, which look like ordinary parameters.</p>
+</article>
+</body></html>`
+
+func TestCaptureFetchCmd(t *testing.T) {
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, fetchedPage)
+	}))
+	defer srv.Close()
+	dir := writeVaultFiles(t, map[string]string{
+		"References/generics-an-introduction.md": "existing\n",
+		"Reading/generics.md":                    "---\nsource: https://go.dev/blog/intro-generics\n---\nSaved earlier.\n",
+	})
+
+	out := captureStdout(t, func() {
+		if err := CaptureFetchCmd(dir, srv.URL+"/blog/intro-generics?utm_source=rss", CaptureOptions{JSONOutput: true}); err != nil {
+			t.Fatalf("CaptureFetchCmd() error: %v", err)
+		}
+	})
+	var result CaptureOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if !strings.HasPrefix(result.Path, "References/generics-an-introduction-") || result.Type != "reference" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Duplicates) != 1 || result.Duplicates[0].ID != "Reading/generics.md" {
+		t.Errorf("duplicates = %+v", result.Duplicates)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, result.Path))
+	if err != nil {
+		t.Fatalf("reading note: %v", err)
+	}
+	note := vault.ParseNote(string(data))
+	for key, want := range map[string]string{
+		"title":     "Generics: An Introduction",
+		"type":      "reference",
+		"source":    "https://go.dev/blog/intro-generics",
+		"author":    "Robert Griesemer",
+		"published": "2022-03-22",
+		"site":      "The Go Blog",
+	} {
+		if got := frontmatterString(note.Frontmatter, key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if !strings.Contains(note.Body, "type parameters, type sets, and type inference") || strings.Contains(note.Body, "Home") {
+		t.Errorf("body = %q", note.Body)
+	}
+}
+
+func TestCaptureCmd_TextOptions(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{})
+	body := "---\ntitle: Piped\nstatus: draft\naliases:\n  - standup\ntags: [meeting]\n---\nDiscussed the release.\n"
+	opts := CaptureOptions{Body: body, Title: "Standup 12 March", Tags: []string{"#work"}, To: "Meetings", ID: "standup-0312", JSONOutput: true}
+
+	out := captureStdout(t, func() {
+		if err := CaptureCmd(dir, opts); err != nil {
+			t.Fatalf("CaptureCmd() error: %v", err)
+		}
+	})
+	var result CaptureOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Path != "Meetings/standup-12-march.md" || result.Existing {
+		t.Fatalf("result = %+v", result)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, result.Path))
+	want := "---\ntitle: Standup 12 March\ntype: fleeting\ncreated: " + time.Now().Format("2006-01-02") +
+		"\ntags:\n  - meeting\n  - work\ncapture_id: standup-0312\nstatus: draft\naliases:\n  - standup\n---\n\nDiscussed the release.\n"
+	if string(data) != want {
+		t.Errorf("note =\n%s\nwant\n%s", data, want)
+	}
+
+	// Re-running with the same ID writes nothing.
+	out = captureStdout(t, func() {
+		if err := CaptureCmd(dir, opts); err != nil {
+			t.Fatalf("CaptureCmd() rerun error: %v", err)
+		}
+	})
+	result = CaptureOutput{}
+	json.Unmarshal([]byte(out), &result)
+	if !result.Existing || result.Path != "Meetings/standup-12-march.md" {
+		t.Errorf("rerun result = %+v", result)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "Meetings")); len(entries) != 1 {
+		t.Errorf("rerun wrote another note: %d files", len(entries))
+	}
+}
+
+func TestCapturer_UnlistableVault(t *testing.T) {
+	// The vault folder does not exist yet, so it cannot be scanned.
+	dir := filepath.Join(t.TempDir(), "vault")
+	c, err := newCapturer(dir, CaptureOptions{})
+	if err != nil {
+		t.Fatalf("newCapturer() error: %v", err)
+	}
+	if c.dupes != nil || c.ids != nil {
+		t.Fatalf("scan results loaded from a missing vault")
+	}
+	if _, err := c.capture(CaptureItem{Body: "plain idea"}); err != nil {
+		t.Fatalf("plain capture error: %v", err)
+	}
+
+	// Capture IDs are scanned on first use, once the vault exists.
+	first, err := c.capture(CaptureItem{Body: "with id", ID: "x-1"})
+	if err != nil || first.Existing {
+		t.Fatalf("first ID capture = %+v, %v", first, err)
+	}
+	again, err := c.capture(CaptureItem{Body: "with id", ID: "x-1"})
+	if err != nil || !again.Existing || again.Path != first.Path {
+		t.Errorf("repeat ID capture = %+v, %v", again, err)
+	}
+}
+
+func TestCaptureCmd_Batch(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/old.md": "---\ncapture_id: a\n---\nAlready here.\n",
+	})
+	input := `{"id": "a", "body": "first"}
+{"id": "b", "title": "Second", "body": "second item", "tags": ["x"]}
+{"body": "third", "to": "Projects"}
+{"body": "   "}
+`
+	out := captureStdout(t, func() {
+		if err := CaptureCmd(dir, CaptureOptions{Body: input, Tags: []string{"batch"}, Format: CaptureFormatJSON, JSONOutput: true}); err == nil {
+			t.Fatal("CaptureCmd() accepted an item without a body")
+		}
+	})
+	if out != "" {
+		t.Errorf("output on error: %s", out)
+	}
+
+	input = strings.Replace(input, `{"body": "   "}`, "", 1)
+	out = captureStdout(t, func() {
+		if err := CaptureCmd(dir, CaptureOptions{Body: input, Tags: []string{"batch"}, JSONOutput: true}); err != nil {
+			t.Fatalf("CaptureCmd() error: %v", err)
+		}
+	})
+	var result CaptureBatchOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Created != 2 || result.Existing != 1 || len(result.Notes) != 3 || len(result.Errors) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+	if n := result.Notes[0]; !n.Existing || n.Path != "Notes/old.md" {
+		t.Errorf("existing item = %+v", n)
+	}
+	if n := result.Notes[1]; n.Path != "Inbox/second.md" || n.ID != "b" {
+		t.Errorf("second item = %+v", n)
+	}
+	if n := result.Notes[2]; !strings.HasPrefix(n.Path, "Projects/") {
+		t.Errorf("third item = %+v", n)
+	}
+	note := vault.ParseNote(mustRead(t, filepath.Join(dir, "Inbox/second.md")))
+	if tags := extractTagsList(note.Frontmatter); strings.Join(tags, ",") != "batch,x" {
+		t.Errorf("tags = %v", tags)
+	}
+}
+
+func TestParseCaptureItems(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		items int
+	}{
+		{`{"body": "one"}`, 1},
+		{`[{"body": "one"}, {"body": "two", "tags": ["a"]}]`, 2},
+		{"{\"body\": \"one\"}\n\n{\"body\": \"two\"}\n", 2},
+		{`{"name": "not a capture item"}`, 0},
+		{`[link](https://example.com) worth reading`, 0},
+		{`{"body": "unterminated"`, 0},
+	} {
+		items, err := parseCaptureItems(tc.input)
+		if len(items) != tc.items || (tc.items == 0) != (err != nil) {
+			t.Errorf("parseCaptureItems(%q) = %d items, %v; want %d", tc.input, len(items), err, tc.items)
+		}
+	}
+}
+
+func TestCaptureFolder(t *testing.T) {
+	for _, to := range []string{"../outside", "/abs", ".", "a/../../b"} {
+		if _, err := captureFolder(to); err == nil {
+			t.Errorf("captureFolder(%q) accepted", to)
+		}
+	}
+	if f, err := captureFolder("Projects/Alpha/"); err != nil || f != filepath.Join("Projects", "Alpha") {
+		t.Errorf("captureFolder() = %q, %v", f, err)
+	}
+}
+
+func TestCaptureCmd_ToFile(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{"Notes.md": "a note, not a folder\n"})
+	done := make(chan error, 1)
+	go func() {
+		done <- CaptureCmd(dir, CaptureOptions{Body: "idea", To: "Notes.md", JSONOutput: true})
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("CaptureCmd() into a file succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CaptureCmd() into a file did not return")
+	}
+}
+
+func TestFrontmatterValue(t *testing.T) {
+	for in, want := range map[string]string{
+		"plain title":           "plain title",
+		`"Quoted": a guide`:     `"\"Quoted\": a guide"`,
+		`Paths: C:\tmp and "x"`: `"Paths: C:\\tmp and \"x\""`,
+		"  spread\n  out ":      "spread out",
+	} {
+		got := frontmatterValue(in)
+		if got != want {
+			t.Errorf("frontmatterValue(%q) = %s, want %s", in, got, want)
+		}
+		fm := vault.ParseNote("---\ntitle: " + got + "\n---\n").Frontmatter
+		if back := frontmatterString(fm, "title"); back != strings.Join(strings.Fields(in), " ") {
+			t.Errorf("%s reads back as %q", got, back)
+		}
+	}
+}
+
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestBuildTriagedContent_KeepsCapturedProperties(t *testing.T) {
+	raw := "type: fleeting\ncapture_id: standup-0312\nauthor: \"Ada\"\naliases:\n  - standup\nstatus: draft\n"
+	parsed := vault.ParseNote("---\n" + raw + "---\nbody\n")
+	content := buildTriagedContent(parsed, raw, "note", nil, map[string]string{"author": "Grace", "project": "Ops: Q3"}, time.Now())
+	for _, want := range []string{"\ncapture_id: standup-0312\n", "\naliases:\n  - standup\n", "\nauthor: Grace\n", "\nproject: \"Ops: Q3\"\n", "\nstatus: processed\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q:\n%s", want, content)
+		}
+	}
+	for _, gone := range []string{"Ada", "draft", "fleeting"} {
+		if strings.Contains(content, gone) {
+			t.Errorf("overridden property %q kept:\n%s", gone, content)
+		}
+	}
+}
@@ -0,0 +1,674 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// Check is one vault health check. Run records what it finds on the maintain
+// output and returns the number of issues; the health score loses Weight
+// points per issue, at most Cap.
+type Check interface {
+	Name() string
+	Description() string
+	Defaults() CheckSettings
+	Run(c *CheckContext, r *MaintainOutput) int
+}
+
+// CheckSettings tune a check. Config keys check_<name>_<setting> override
+// the defaults.
+type CheckSettings struct {
+	Weight    float64  `json:"weight"`              // score points per issue
+	Cap       int      `json:"cap,omitempty"`       // most points the check can cost; 0 = no cap
+	Threshold float64  `json:"threshold,omitempty"` // check-specific: days, bytes, characters
+	Exclude   []string `json:"exclude,omitempty"`   // folders the check skips
+	Pattern   string   `json:"pattern,omitempty"`   // check-specific regular expression
+}
+
+// deduction is the score points issues cost.
+func (s CheckSettings) deduction(issues int) int {
+	d := int(math.Round(s.Weight * float64(issues)))
+	if s.Cap > 0 && d > s.Cap {
+		d = s.Cap
+	}
+	return d
+}
+
+// excluded reports whether a vault path lies in one of the excluded folders.
+func (s CheckSettings) excluded(p string) bool {
+	for _, folder := range s.Exclude {
+		folder = strings.Trim(filepath.ToSlash(folder), "/")
+		if folder != "" && (p == folder || strings.HasPrefix(p, folder+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckContext is what a check runs against. Notes leaves out the check's
+// excluded folders.
+type CheckContext struct {
+	VaultPath string
+	Notes     []maintainNote
+	Resolver  *vault.Resolver
+	Stats     VaultStats
+	Now       time.Time
+	Settings  CheckSettings
+	Required  map[string][]string // lowercased note type -> required properties
+
+	shared *checkShared
+}
+
+// checkShared holds work several checks reuse, done on first use.
+type checkShared struct {
+	all     []maintainNote
+	scanned bool
+	scan    attachmentScan
+	scanErr error
+}
+
+// attachments scans the vault's attachments once for all attachment checks.
+// References from excluded notes still count.
+func (c *CheckContext) attachments() (attachmentScan, error) {
+	s := c.shared
+	if !s.scanned {
+		s.scan, s.scanErr = scanAttachments(c.VaultPath, s.all, c.Resolver)
+		s.scanned = true
+	}
+	return s.scan, s.scanErr
+}
+
+// CheckResult is one check's outcome in the maintain output.
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Issues    int     `json:"issues"`
+	Deduction int     `json:"deduction"`
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// CheckInfo describes a check and its effective settings for --list-checks.
+type CheckInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	CheckSettings
+}
+
+// MissingProperty is a note lacking properties its type requires.
+type MissingProperty struct {
+	Path    string   `json:"path"`
+	Type    string   `json:"type"`
+	Missing []string `json:"missing"`
+}
+
+// DuplicateTitle is a title shared by several notes.
+type DuplicateTitle struct {
+	Title string   `json:"title"`
+	Paths []string `json:"paths"`
+}
+
+// InvalidDate is a date property whose value does not parse.
+type InvalidDate struct {
+	Path     string `json:"path"`
+	Property string `json:"property"`
+	Value    string `json:"value"`
+}
+
+// TagCasing is a tag spelled with different casing across the vault. Notes
+// lists the notes that do not use Preferred, the most common spelling.
+type TagCasing struct {
+	Preferred string   `json:"preferred"`
+	Variants  []string `json:"variants"`
+	Notes     []string `json:"notes"`
+}
+
+// FilenameIssue is a note whose file name breaks a naming rule.
+type FilenameIssue struct {
+	Path    string `json:"path"`
+	Problem string `json:"problem"`
+}
+
+// healthCheck is a Check built from a function.
+type healthCheck struct {
+	name        string
+	description string
+	defaults    CheckSettings
+	run         func(c *CheckContext, r *MaintainOutput) int
+}
+
+func (h healthCheck) Name() string            { return h.name }
+func (h healthCheck) Description() string     { return h.description }
+func (h healthCheck) Defaults() CheckSettings { return h.defaults }
+func (h healthCheck) Run(c *CheckContext, r *MaintainOutput) int {
+	return h.run(c, r)
+}
+
+// Default thresholds.
+const (
+	defaultStaleDays      = 30
+	defaultLargeNoteBytes = 10240
+	defaultMaxNameLength  = 100
+)
+
+// defaultDateProperties matches the frontmatter properties invalid-dates
+// checks.
+const defaultDateProperties = `(?i)^(date|created|updated|modified|due|start|end|published)$|[_-](date|at|on)$`
+
+// healthChecks is the registry of checks, in report order. Checks added
+// after the original health score report issues without costing points
+// until given a weight in config, so upgrading does not lower the score.
+var healthChecks = []Check{
+	healthCheck{"stale", "Notes not modified in threshold days",
+		CheckSettings{Weight: 1, Cap: 20, Threshold: defaultStaleDays}, checkStale},
+	healthCheck{"broken-links", "Links to missing notes, headings or blocks",
+		CheckSettings{Weight: 2, Cap: 20}, checkBrokenLinks},
+	healthCheck{"ambiguous-links", "Links whose name matches several notes",
+		CheckSettings{}, checkAmbiguousLinks},
+	healthCheck{"empty", "Notes with no body",
+		CheckSettings{Weight: 5}, checkEmpty},
+	healthCheck{"large", "Notes larger than threshold bytes",
+		CheckSettings{Threshold: defaultLargeNoteBytes}, checkLarge},
+	healthCheck{"frontmatter", "Notes without frontmatter",
+		CheckSettings{Weight: 3}, checkFrontmatter},
+	healthCheck{"required-properties", "Notes missing the properties their type requires (required_<type> in config)",
+		CheckSettings{Weight: 1, Cap: 10}, checkRequiredProperties},
+	healthCheck{"duplicate-titles", "Titles shared by several notes",
+		CheckSettings{Cap: 10}, checkDuplicateTitles},
+	healthCheck{"invalid-dates", "Date properties (named by pattern) that are not ISO dates",
+		CheckSettings{Cap: 10, Pattern: defaultDateProperties}, checkInvalidDates},
+	healthCheck{"tag-casing", "Tags spelled with different casing",
+		CheckSettings{Cap: 10}, checkTagCasing},
+	healthCheck{"filename-rules", "File names with link-breaking characters, over threshold characters or not matching pattern",
+		CheckSettings{Cap: 10, Threshold: defaultMaxNameLength}, checkFilenames},
+	healthCheck{"inbox", "Inbox notes pending triage",
+		CheckSettings{Weight: 1, Cap: 10}, checkInbox},
+	healthCheck{"orphan-attachments", "Attachments nothing links to",
+		CheckSettings{Weight: 1, Cap: 10}, checkOrphanAttachments},
+	healthCheck{"missing-attachments", "Embeds of attachments that do not exist",
+		CheckSettings{Weight: 2, Cap: 10}, checkMissingAttachments},
+	healthCheck{"duplicate-attachments", "Redundant copies of identical attachments",
+		CheckSettings{Weight: 1, Cap: 5}, checkDuplicateAttachments},
+	healthCheck{"index-coverage", "Percentage of notes missing from the search index",
+		CheckSettings{Weight: 1}, checkIndexCoverage},
+}
+
+// findCheck returns the registered check with the given name.
+func findCheck(name string) (Check, error) {
+	for _, c := range healthChecks {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	names := make([]string, len(healthChecks))
+	for i, c := range healthChecks {
+		names[i] = c.Name()
+	}
+	return nil, fmt.Errorf("unknown check %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// resolveCheck applies the config overrides to a check's defaults.
+func resolveCheck(c Check, cfg config.CheckSettings) (CheckSettings, bool) {
+	s := c.Defaults()
+	o, ok := cfg.Checks[c.Name()]
+	if !ok {
+		return s, true
+	}
+	if o.Weight != nil {
+		s.Weight = *o.Weight
+	}
+	if o.Cap > 0 {
+		s.Cap = o.Cap
+	}
+	if o.Threshold > 0 {
+		s.Threshold = o.Threshold
+	}
+	if len(o.Exclude) > 0 {
+		s.Exclude = o.Exclude
+	}
+	if o.Pattern != "" {
+		s.Pattern = o.Pattern
+	}
+	return s, o.Enabled == nil || *o.Enabled
+}
+
+// warnUnknownChecks warns about config keys naming checks that do not exist.
+func warnUnknownChecks(cfg config.CheckSettings) {
+	var unknown []string
+	for name := range cfg.Checks {
+		if _, err := findCheck(name); err != nil {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fmt.Fprintf(os.Stderr, "Warning: config sets unknown check %q\n", name)
+	}
+}
+
+// listChecks describes every check with its effective settings.
+func listChecks(cfg config.CheckSettings) []CheckInfo {
+	var out []CheckInfo
+	for _, c := range healthChecks {
+		s, enabled := resolveCheck(c, cfg)
+		out = append(out, CheckInfo{Name: c.Name(), Description: c.Description(), Enabled: enabled, CheckSettings: s})
+	}
+	return out
+}
+
+func checkStale(c *CheckContext, r *MaintainOutput) int {
+	staleDays := int(c.Settings.Threshold)
+	for _, n := range c.Notes {
+		modTime := time.Unix(n.info.ModTime, 0)
+		daysOld := int(c.Now.Sub(modTime).Hours() / 24)
+		if daysOld >= staleDays {
+			r.StaleNotes = append(r.StaleNotes, StaleNote{
+				Path:    n.info.Path,
+				LastMod: modTime.Format("2006-01-02"),
+				DaysAgo: daysOld,
+			})
+		}
+	}
+	return len(r.StaleNotes)
+}
+
+// checkBrokenLinks checks wikilinks, markdown links and note embeds; links
+// inside code are already excluded by the parser.
+func checkBrokenLinks(c *CheckContext, r *MaintainOutput) int {
+	for _, n := range c.Notes {
+		for _, link := range noteLinks(n.parsed) {
+			res := c.Resolver.Resolve(n.info.Path, link)
+			reason := ""
+			switch {
+			case res.Status == vault.Missing:
+				reason = "note not found"
+			case res.FragmentMissing && strings.HasPrefix(link.Fragment, "^"):
+				reason = "block not found"
+			case res.FragmentMissing:
+				reason = "heading not found"
+			default:
+				continue
+			}
+			r.BrokenLinks = append(r.BrokenLinks, BrokenLink{
+				Source: n.info.Path,
+				Target: linkDisplayTarget(link),
+				Line:   link.Line,
+				Reason: reason,
+			})
+		}
+	}
+	return len(r.BrokenLinks)
+}
+
+func checkAmbiguousLinks(c *CheckContext, r *MaintainOutput) int {
+	for _, n := range c.Notes {
+		for _, link := range noteLinks(n.parsed) {
+			res := c.Resolver.Resolve(n.info.Path, link)
+			if res.Status == vault.Ambiguous && !res.FragmentMissing {
+				r.AmbiguousLinks = append(r.AmbiguousLinks, AmbiguousLink{
+					Source:     n.info.Path,
+					Target:     linkDisplayTarget(link),
+					Line:       link.Line,
+					ResolvedTo: res.Path,
+					Candidates: res.Candidates,
+				})
+			}
+		}
+	}
+	return len(r.AmbiguousLinks)
+}
+
+// checkEmpty finds notes with only frontmatter and no body content.
+func checkEmpty(c *CheckContext, r *MaintainOutput) int {
+	for _, n := range c.Notes {
+		if strings.TrimSpace(n.parsed.Body) == "" {
+			r.EmptyNotes = append(r.EmptyNotes, n.info.Path)
+		}
+	}
+	return len(r.EmptyNotes)
+}
+
+func checkLarge(c *CheckContext, r *MaintainOutput) int {
+	for _, n := range c.Notes {
+		if float64(n.info.Size) > c.Settings.Threshold {
+			r.LargeNotes = append(r.LargeNotes, LargeNote{
+				Path:      n.info.Path,
+				SizeBytes: n.info.Size,
+			})
+		}
+	}
+	return len(r.LargeNotes)
+}
+
+func checkFrontmatter(c *CheckContext, r *MaintainOutput) int {
+	for _, n := range c.Notes {
+		if !strings.HasPrefix(n.content, "---\n") && !strings.HasPrefix(n.content, "---\r\n") {
+			r.NoFrontmatter = append(r.NoFrontmatter, n.info.Path)
+		}
+	}
+	return len(r.NoFrontmatter)
+}
+
+func checkRequiredProperties(c *CheckContext, r *MaintainOutput) int {
+	for _, n := range c.Notes {
+		noteType := frontmatterString(n.parsed.Frontmatter, "type")
+		var missing []string
+		for _, prop := range c.Required[strings.ToLower(noteType)] {
+			if !propertySet(n.parsed.Frontmatter[prop]) {
+				missing = append(missing, prop)
+			}
+		}
+		if len(missing) > 0 {
+			r.MissingProperties = append(r.MissingProperties, MissingProperty{Path: n.info.Path, Type: noteType, Missing: missing})
+		}
+	}
+	return len(r.MissingProperties)
+}
+
+// propertySet reports whether a frontmatter value is present and not blank.
+func propertySet(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(t) != ""
+	case []string:
+		return len(t) > 0
+	case []any:
+		return len(t) > 0
+	}
+	return true
+}
+
+// noteTitle returns the note's title property, else its first top-level
+// heading, else "".
+func noteTitle(parsed *vault.Note) string {
+	if t := strings.TrimSpace(frontmatterString(parsed.Frontmatter, "title")); t != "" {
+		return t
+	}
+	for _, h := range parsed.Headings {
+		if h.Level == 1 {
+			return strings.TrimSpace(h.Text)
+		}
+	}
+	return ""
+}
+
+// checkDuplicateTitles groups notes by title, ignoring case. Notes without a
+// title property or heading are skipped: file names are already unique per
+// folder, and clashing basenames show up as ambiguous links.
+func checkDuplicateTitles(c *CheckContext, r *MaintainOutput) int {
+	groups := make(map[string]*DuplicateTitle)
+	var order []string
+	for _, n := range c.Notes {
+		title := noteTitle(n.parsed)
+		if title == "" {
+			continue
+		}
+		key := strings.ToLower(title)
+		g, ok := groups[key]
+		if !ok {
+			g = &DuplicateTitle{Title: title}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.Paths = append(g.Paths, n.info.Path)
+	}
+	issues := 0
+	for _, key := range order {
+		if g := groups[key]; len(g.Paths) > 1 {
+			r.DuplicateTitles = append(r.DuplicateTitles, *g)
+			issues += len(g.Paths) - 1
+		}
+	}
+	return issues
+}
+
+// dateLayouts are the date and date-time forms Obsidian's date properties use.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+}
+
+// validDate reports whether s parses with one of dateLayouts.
+func validDate(s string) bool {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func checkInvalidDates(c *CheckContext, r *MaintainOutput) int {
+	re, err := regexp.Compile(c.Settings.Pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid-dates pattern: %v\n", err)
+		return 0
+	}
+	for _, n := range c.Notes {
+		props := make([]string, 0, len(n.parsed.Frontmatter))
+		for k := range n.parsed.Frontmatter {
+			if re.MatchString(k) {
+				props = append(props, k)
+			}
+		}
+		sort.Strings(props)
+		for _, k := range props {
+			v := strings.TrimSpace(frontmatterString(n.parsed.Frontmatter, k))
+			if v != "" && !validDate(v) {
+				r.InvalidDates = append(r.InvalidDates, InvalidDate{Path: n.info.Path, Property: k, Value: v})
+			}
+		}
+	}
+	return len(r.InvalidDates)
+}
+
+// checkTagCasing finds tags used with more than one casing, such as #Project
+// and #project, which Obsidian treats as one tag but displays inconsistently.
+func checkTagCasing(c *CheckContext, r *MaintainOutput) int {
+	type spelling struct {
+		notes []string
+	}
+	byTag := make(map[string]map[string]*spelling) // lowercased -> spelling -> notes
+	for _, n := range c.Notes {
+		seen := make(map[string]bool)
+		for _, tag := range tagSpellings(n.parsed) {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			key := strings.ToLower(tag)
+			if byTag[key] == nil {
+				byTag[key] = make(map[string]*spelling)
+			}
+			if byTag[key][tag] == nil {
+				byTag[key][tag] = &spelling{}
+			}
+			byTag[key][tag].notes = append(byTag[key][tag].notes, n.info.Path)
+		}
+	}
+
+	keys := make([]string, 0, len(byTag))
+	for k, spellings := range byTag {
+		if len(spellings) > 1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	issues := 0
+	for _, k := range keys {
+		var variants []string
+		for v := range byTag[k] {
+			variants = append(variants, v)
+		}
+		sort.Strings(variants)
+		preferred := variants[0]
+		for _, v := range variants[1:] {
+			if len(byTag[k][v].notes) > len(byTag[k][preferred].notes) {
+				preferred = v
+			}
+		}
+		tc := TagCasing{Preferred: preferred, Variants: variants}
+		for _, v := range variants {
+			if v != preferred {
+				tc.Notes = append(tc.Notes, byTag[k][v].notes...)
+			}
+		}
+		sort.Strings(tc.Notes)
+		r.TagCasing = append(r.TagCasing, tc)
+		issues += len(tc.Notes)
+	}
+	return issues
+}
+
+// tagSpellings returns the note's frontmatter and inline tags as written,
+// without '#'. Unlike AllTags it keeps spellings that differ only in case.
+func tagSpellings(parsed *vault.Note) []string {
+	var raw []string
+	for _, t := range extractTagsList(parsed.Frontmatter) {
+		raw = append(raw, strings.FieldsFunc(t, func(r rune) bool { return r == ',' || r == ' ' })...)
+	}
+	for _, t := range parsed.Tags {
+		raw = append(raw, t.Name)
+	}
+	var tags []string
+	for _, t := range raw {
+		if t = strings.TrimPrefix(strings.TrimSpace(t), "#"); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// linkBreakingChars cannot appear in a linkable note name; the rest are not
+// allowed in file names on Windows and Android.
+const (
+	linkBreakingChars = "#^[]|"
+	unportableChars   = `\:*?"<>`
+)
+
+func checkFilenames(c *CheckContext, r *MaintainOutput) int {
+	var re *regexp.Regexp
+	if c.Settings.Pattern != "" {
+		var err error
+		if re, err = regexp.Compile(c.Settings.Pattern); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: filename-rules pattern: %v\n", err)
+		}
+	}
+	maxLen := int(c.Settings.Threshold)
+	for _, n := range c.Notes {
+		name := strings.TrimSuffix(path.Base(n.info.Path), ".md")
+		var problems []string
+		if strings.ContainsAny(name, linkBreakingChars) {
+			problems = append(problems, "contains a character that breaks links ("+linkBreakingChars+")")
+		}
+		if strings.ContainsAny(name, unportableChars) {
+			problems = append(problems, "contains a character not allowed on every platform ("+unportableChars+")")
+		}
+		if name != strings.TrimSpace(name) || strings.HasSuffix(name, ".") {
+			problems = append(problems, "starts or ends with a space or dot")
+		}
+		if maxLen > 0 && utf8.RuneCountInString(name) > maxLen {
+			problems = append(problems, fmt.Sprintf("longer than %d characters", maxLen))
+		}
+		if re != nil && !re.MatchString(name) {
+			problems = append(problems, "does not match "+c.Settings.Pattern)
+		}
+		if len(problems) > 0 {
+			r.FilenameIssues = append(r.FilenameIssues, FilenameIssue{Path: n.info.Path, Problem: strings.Join(problems, "; ")})
+		}
+	}
+	return len(r.FilenameIssues)
+}
+
+// checkInbox counts Inbox notes not yet marked processed.
+func checkInbox(c *CheckContext, r *MaintainOutput) int {
+	for _, n := range c.Notes {
+		if !strings.HasPrefix(n.info.Path, "Inbox/") {
+			continue
+		}
+		if frontmatterString(n.parsed.Frontmatter, "status") == "processed" {
+			continue
+		}
+		r.InboxPending++
+		ageDays, _ := computeAge(frontmatterString(n.parsed.Frontmatter, "created"), n.info.ModTime, c.Now)
+		if ageDays > r.InboxOldestDays {
+			r.InboxOldestDays = ageDays
+		}
+	}
+	return r.InboxPending
+}
+
+func checkOrphanAttachments(c *CheckContext, r *MaintainOutput) int {
+	scan, err := c.attachments()
+	if err != nil {
+		return 0
+	}
+	r.Attachments = scan.totals()
+	for _, o := range scan.orphans() {
+		if !c.Settings.excluded(o.Path) {
+			r.OrphanAttachments = append(r.OrphanAttachments, o.Path)
+		}
+	}
+	return len(r.OrphanAttachments)
+}
+
+func checkMissingAttachments(c *CheckContext, r *MaintainOutput) int {
+	scan, err := c.attachments()
+	if err != nil {
+		return 0
+	}
+	r.Attachments = scan.totals()
+	for _, m := range scan.missing {
+		if !c.Settings.excluded(m.Source) {
+			r.MissingAttachments = append(r.MissingAttachments, m)
+		}
+	}
+	return len(r.MissingAttachments)
+}
+
+// checkDuplicateAttachments counts redundant copies; a group whose copies
+// all lie in excluded folders is skipped.
+func checkDuplicateAttachments(c *CheckContext, r *MaintainOutput) int {
+	scan, err := c.attachments()
+	if err != nil {
+		return 0
+	}
+	r.Attachments = scan.totals()
+	copies := 0
+	for _, g := range findDuplicateAttachments(c.VaultPath, scan.attachments) {
+		for _, p := range g.Paths {
+			if !c.Settings.excluded(p) {
+				r.DuplicateAttachments = append(r.DuplicateAttachments, g)
+				copies += len(g.Paths) - 1
+				break
+			}
+		}
+	}
+	return copies
+}
+
+// checkIndexCoverage returns the percentage of notes not in the index. A
+// vault that was never indexed is not penalized.
+func checkIndexCoverage(c *CheckContext, r *MaintainOutput) int {
+	if c.Stats.TotalNotes == 0 || c.Stats.IndexedNotes == 0 {
+		return 0
+	}
+	coverage := float64(c.Stats.IndexedNotes) / float64(c.Stats.TotalNotes) * 100
+	return max(int(100-coverage), 0)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+)
+
+// writeCheckConfig writes a config file with the given lines.
+func writeCheckConfig(t *testing.T, lines string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv(config.ConfigDirEnv, dir)
+	if err := os.WriteFile(filepath.Join(dir, config.ConfigFile), []byte(lines), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runMaintainJSON(t *testing.T, dir string, opts MaintainOptions) MaintainOutput {
+	t.Helper()
+	opts.JSONOutput = true
+	out := captureStdout(t, func() {
+		if err := MaintainCmd(dir, opts); err != nil {
+			t.Fatalf("MaintainCmd() error: %v", err)
+		}
+	})
+	var result MaintainOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	return result
+}
+
+func TestMaintainChecks(t *testing.T) {
+	writeCheckConfig(t, `required_project = status, due
+check_stale_threshold = 60
+check_stale_exclude = Archive
+check_large_threshold = 20
+check_filename_rules_pattern = ^[a-z0-9 -]+$
+`)
+	dir := writeVaultFiles(t, map[string]string{
+		"Projects/alpha.md":    "---\ntype: Project\nstatus: active\ndue: someday\ntags: [Work]\n---\n# Alpha\nbody #work\n",
+		"Projects/beta.md":     "---\ntype: project\ndue: 2026-01-31\ntitle: alpha\n---\nbody #work\n",
+		"Notes/What? #1.md":    "---\ncreated: 2026-02-30\n---\nshort\n",
+		"Archive/old.md":       "---\n---\nold but archived\n",
+		"Notes/forgotten.md":   "---\n---\nnot touched in a while\n",
+		"Notes/casing note.md": "---\ntags: [work]\n---\nmore #work\n",
+	})
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	for _, p := range []string{"Archive/old.md", "Notes/forgotten.md"} {
+		if err := os.Chtimes(filepath.Join(dir, p), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := runMaintainJSON(t, dir, MaintainOptions{})
+	if len(r.StaleNotes) != 1 || r.StaleNotes[0].Path != "Notes/forgotten.md" {
+		t.Errorf("stale = %+v, want only Notes/forgotten.md", r.StaleNotes)
+	}
+	if want := []MissingProperty{{Path: "Projects/beta.md", Type: "project", Missing: []string{"status"}}}; !reflect.DeepEqual(r.MissingProperties, want) {
+		t.Errorf("missing properties = %+v", r.MissingProperties)
+	}
+	if len(r.DuplicateTitles) != 1 || !reflect.DeepEqual(r.DuplicateTitles[0].Paths, []string{"Projects/alpha.md", "Projects/beta.md"}) {
+		t.Errorf("duplicate titles = %+v", r.DuplicateTitles)
+	}
+	if want := []InvalidDate{
+		{Path: "Notes/What? #1.md", Property: "created", Value: "2026-02-30"},
+		{Path: "Projects/alpha.md", Property: "due", Value: "someday"},
+	}; !reflect.DeepEqual(r.InvalidDates, want) {
+		t.Errorf("invalid dates = %+v", r.InvalidDates)
+	}
+	if len(r.TagCasing) != 1 || r.TagCasing[0].Preferred != "work" || !reflect.DeepEqual(r.TagCasing[0].Notes, []string{"Projects/alpha.md"}) {
+		t.Errorf("tag casing = %+v", r.TagCasing)
+	}
+	if len(r.FilenameIssues) != 1 || r.FilenameIssues[0].Path != "Notes/What? #1.md" {
+		t.Errorf("filename issues = %+v", r.FilenameIssues)
+	}
+	if len(r.LargeNotes) == 0 {
+		t.Error("large threshold from config not applied")
+	}
+
+	// The score is 100 less each check's capped deduction.
+	score := 100
+	for _, c := range r.Checks {
+		score -= c.Deduction
+	}
+	if r.HealthScore != max(score, 0) {
+		t.Errorf("health score = %d, deductions give %d", r.HealthScore, score)
+	}
+	// Checks newer than the original score report without deducting.
+	for _, c := range r.Checks {
+		switch c.Name {
+		case "duplicate-titles", "invalid-dates", "tag-casing", "filename-rules":
+			if c.Deduction != 0 {
+				t.Errorf("%s deducted %d by default", c.Name, c.Deduction)
+			}
+		}
+	}
+
+	// --stale-days overrides the configured threshold.
+	r = runMaintainJSON(t, dir, MaintainOptions{StaleDays: 100, Check: "stale"})
+	if len(r.StaleNotes) != 0 || len(r.Checks) != 1 || r.Checks[0].Threshold != 100 {
+		t.Errorf("--stale-days 100: stale = %+v, checks = %+v", r.StaleNotes, r.Checks)
+	}
+}
+
+func TestMaintainSingleCheck(t *testing.T) {
+	writeCheckConfig(t, "check_empty_weight = 10\ncheck_frontmatter_enabled = false\n")
+	dir := writeVaultFiles(t, map[string]string{
+		"a.md": "no frontmatter\n",
+		"b.md": "---\ntitle: b\n---\n",
+	})
+
+	r := runMaintainJSON(t, dir, MaintainOptions{Check: "empty"})
+	if len(r.Checks) != 1 || r.Checks[0].Name != "empty" || r.HealthScore != 90 {
+		t.Errorf("checks = %+v, score = %d, want only empty at 90", r.Checks, r.HealthScore)
+	}
+	if len(r.NoFrontmatter) != 0 || len(r.EmptyNotes) != 1 {
+		t.Errorf("ran more than the empty check: %+v", r)
+	}
+
+	// Disabled checks are skipped unless asked for by name.
+	r = runMaintainJSON(t, dir, MaintainOptions{})
+	if len(r.NoFrontmatter) != 0 {
+		t.Errorf("disabled frontmatter check ran: %v", r.NoFrontmatter)
+	}
+	r = runMaintainJSON(t, dir, MaintainOptions{Check: "frontmatter"})
+	if len(r.NoFrontmatter) != 1 {
+		t.Errorf("--check frontmatter = %v", r.NoFrontmatter)
+	}
+
+	if err := MaintainCmd(dir, MaintainOptions{Check: "nope", JSONOutput: true}); err == nil {
+		t.Error("unknown check succeeded")
+	}
+}
+
+func TestCheckSettingsDeduction(t *testing.T) {
+	for _, tc := range []struct {
+		s      CheckSettings
+		issues int
+		want   int
+	}{
+		{CheckSettings{Weight: 2, Cap: 20}, 3, 6},
+		{CheckSettings{Weight: 2, Cap: 20}, 30, 20},
+		{CheckSettings{Weight: 5}, 30, 150},
+		{CheckSettings{Weight: 0.5, Cap: 10}, 3, 2},
+		{CheckSettings{}, 100, 0},
+	} {
+		if got := tc.s.deduction(tc.issues); got != tc.want {
+			t.Errorf("%+v.deduction(%d) = %d, want %d", tc.s, tc.issues, got, tc.want)
+		}
+	}
+}
@@ -84,13 +84,12 @@ func ConfigureCmd() error {
 		return fmt.Errorf("vault path does not exist or is not a directory: %s", vaultPath)
 	}
 
-	// Save configuration
-	cfg := &config.Config{
-		GeminiAPIKey: apiKey,
-		VaultPath:    vaultPath,
-	}
+	// Save configuration, preserving settings that are not prompted for here.
+	cfg := *existing
+	cfg.GeminiAPIKey = apiKey
+	cfg.VaultPath = vaultPath
 
-	if err := config.Save(cfg); err != nil {
+	if err := config.Save(&cfg); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
@@ -123,16 +122,25 @@ func ConfigureShowCmd(jsonOutput bool) error {
 	maskedKey := maskKey(cfg.GeminiAPIKey)
 
 	if jsonOutput {
-		return output.JSON(map[string]string{
+		out := map[string]string{
 			"config_path":   config.Path(),
 			"gemini_apikey": maskedKey,
 			"vault_path":    cfg.VaultPath,
-		})
+		}
+		if cfg.LLMProvider != "" {
+			out["llm_provider"] = cfg.LLMProvider
+			out["llm_model"] = cfg.LLMModel
+			out["llm_api_key"] = maskKey(cfg.LLMAPIKey)
+		}
+		return output.JSON(out)
 	}
 
 	fmt.Printf("Config file: %s\n", config.Path())
 	fmt.Printf("Gemini API key: %s\n", maskedKey)
 	fmt.Printf("Vault path: %s\n", cfg.VaultPath)
+	if cfg.LLMProvider != "" {
+		fmt.Printf("LLM provider: %s (model %s, key %s)\n", cfg.LLMProvider, cfg.LLMModel, maskKey(cfg.LLMAPIKey))
+	}
 	return nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/template"
 	"github.com/joeyhipolito/obsidian-cli/internal/vault"
 )
 
@@ -17,22 +18,27 @@ type CreateOptions struct {
 	Status     string
 	Summary    string
 	Tags       []string
-	Template   string // vault-relative path to a note whose body is used as the note body
+	Template   string            // template path, or name in the templates folder
+	Vars       map[string]string // template variables (--var key=value)
 }
 
 // CreateOutput represents the JSON output format for the create command.
 type CreateOutput struct {
-	Path  string `json:"path"`
-	Title string `json:"title,omitempty"`
+	Path   string `json:"path"`
+	Title  string `json:"title,omitempty"`
+	Cursor int    `json:"cursor,omitempty"` // line of the template's {{cursor}}
 }
 
 // CreateCmd creates a new note in the vault with optional frontmatter.
-// Frontmatter fields are written in a deterministic order.
+// Frontmatter fields are written in a deterministic order. A template is
+// rendered with the note's title, folder, the date and --var variables; its
+// properties are kept unless a flag sets them.
 func CreateCmd(vaultPath, notePath string, opts CreateOptions, jsonOutput bool) error {
-	content, err := buildCreateContent(vaultPath, opts)
+	content, err := buildCreateContent(vaultPath, notePath, opts)
 	if err != nil {
 		return err
 	}
+	content, cursor := template.StripCursor(content)
 
 	if err := vault.WriteNote(vaultPath, notePath, content); err != nil {
 		return err
@@ -40,8 +46,9 @@ func CreateCmd(vaultPath, notePath string, opts CreateOptions, jsonOutput bool)
 
 	if jsonOutput {
 		return output.JSON(CreateOutput{
-			Path:  notePath,
-			Title: opts.Title,
+			Path:   notePath,
+			Title:  opts.Title,
+			Cursor: cursor,
 		})
 	}
 
@@ -50,71 +57,65 @@ func CreateCmd(vaultPath, notePath string, opts CreateOptions, jsonOutput bool)
 }
 
 // buildCreateContent assembles the full note content from options.
-func buildCreateContent(vaultPath string, opts CreateOptions) (string, error) {
+func buildCreateContent(vaultPath, notePath string, opts CreateOptions) (string, error) {
 	hasFrontmatter := opts.Title != "" || opts.Type != "" || opts.ContextSet != "" ||
 		opts.Status != "" || opts.Summary != "" || len(opts.Tags) > 0
 
-	var b strings.Builder
-
+	var fm strings.Builder
 	if hasFrontmatter {
-		b.WriteString("---\n")
 		if opts.Title != "" {
-			fmt.Fprintf(&b, "title: %s\n", opts.Title)
+			fmt.Fprintf(&fm, "title: %s\n", opts.Title)
 		}
-		fmt.Fprintf(&b, "created: %s\n", time.Now().Format("2006-01-02"))
+		fmt.Fprintf(&fm, "created: %s\n", time.Now().Format("2006-01-02"))
 		if opts.Type != "" {
-			fmt.Fprintf(&b, "type: %s\n", opts.Type)
+			fmt.Fprintf(&fm, "type: %s\n", opts.Type)
 		}
 		if opts.Status != "" {
-			fmt.Fprintf(&b, "status: %s\n", opts.Status)
+			fmt.Fprintf(&fm, "status: %s\n", opts.Status)
 		}
 		if opts.ContextSet != "" {
-			fmt.Fprintf(&b, "context-set: %s\n", opts.ContextSet)
+			fmt.Fprintf(&fm, "context-set: %s\n", opts.ContextSet)
 		}
 		if opts.Summary != "" {
 			// Quote the summary if it contains YAML-special characters.
 			if strings.ContainsAny(opts.Summary, ":{}[]#&*!|>'\"%@`") {
-				fmt.Fprintf(&b, "summary: \"%s\"\n", strings.ReplaceAll(opts.Summary, "\"", "\\\""))
+				fmt.Fprintf(&fm, "summary: \"%s\"\n", strings.ReplaceAll(opts.Summary, "\"", "\\\""))
 			} else {
-				fmt.Fprintf(&b, "summary: %s\n", opts.Summary)
+				fmt.Fprintf(&fm, "summary: %s\n", opts.Summary)
 			}
 		}
 		if len(opts.Tags) > 0 {
-			b.WriteString("tags:\n")
+			fm.WriteString("tags:\n")
 			for _, tag := range opts.Tags {
-				fmt.Fprintf(&b, "  - %s\n", tag)
+				fmt.Fprintf(&fm, "  - %s\n", tag)
 			}
 		}
-		b.WriteString("---\n")
 	}
 
-	if opts.Template != "" {
-		body, err := loadTemplateBody(vaultPath, opts.Template)
-		if err != nil {
-			return "", err
-		}
+	if opts.Template == "" {
+		var b strings.Builder
 		if hasFrontmatter {
-			b.WriteByte('\n')
+			b.WriteString("---\n" + fm.String() + "---\n")
 		}
-		b.WriteString(body)
-		if !strings.HasSuffix(body, "\n") {
-			b.WriteByte('\n')
+		if opts.Title != "" {
+			b.WriteString("\n# " + opts.Title + "\n")
 		}
-	} else if opts.Title != "" {
-		b.WriteString("\n# ")
-		b.WriteString(opts.Title)
-		b.WriteByte('\n')
+		return b.String(), nil
 	}
 
-	return b.String(), nil
-}
-
-// loadTemplateBody reads a vault note at templatePath and returns its body
-// (frontmatter stripped). Returns an error if the file cannot be read.
-func loadTemplateBody(vaultPath, templatePath string) (string, error) {
-	note, err := vault.ReadNote(vaultPath, templatePath)
+	tmpl, err := loadNoteTemplate(vaultPath, opts.Template)
+	if err != nil {
+		return "", err
+	}
+	rendered, err := tmpl.render(template.Context{
+		Title:  firstNonEmpty(opts.Title, fileTitle(notePath)),
+		Folder: noteFolder(notePath),
+		Date:   time.Now(),
+		Vars:   opts.Vars,
+		Prompt: terminalPrompt(),
+	})
 	if err != nil {
-		return "", fmt.Errorf("loading template %q: %w", templatePath, err)
+		return "", err
 	}
-	return note.Body, nil
+	return rendered.note(fm.String()), nil
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/template"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// defaultDailyFormat is the Daily notes plugin's default file name format.
+const defaultDailyFormat = "YYYY-MM-DD"
+
+// DailyOptions configures the daily command.
+type DailyOptions struct {
+	Date       string // YYYY-MM-DD, today, yesterday, tomorrow or +N/-N days; "" = today
+	Template   string // overrides the Daily notes template
+	Vars       map[string]string
+	JSONOutput bool
+}
+
+// DailyOutput is the JSON output of the daily command.
+type DailyOutput struct {
+	Path     string `json:"path"`
+	Date     string `json:"date"`
+	Created  bool   `json:"created"` // false when the note already existed
+	Template string `json:"template,omitempty"`
+	Cursor   int    `json:"cursor,omitempty"` // line of the template's {{cursor}}
+}
+
+// dailySettings is the Daily notes plugin's .obsidian/daily-notes.json.
+type dailySettings struct {
+	Folder   string `json:"folder"`
+	Format   string `json:"format"`
+	Template string `json:"template"`
+}
+
+// loadDailySettings reads the Daily notes settings; a missing file yields
+// the plugin defaults.
+func loadDailySettings(vaultPath string) (dailySettings, error) {
+	var s dailySettings
+	data, err := os.ReadFile(filepath.Join(vaultPath, ".obsidian", "daily-notes.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return s, fmt.Errorf("parsing daily-notes.json: %w", err)
+		}
+	}
+	s.Folder = strings.Trim(filepath.ToSlash(s.Folder), "/")
+	if s.Format == "" {
+		s.Format = defaultDailyFormat
+	}
+	return s, nil
+}
+
+// DailyCmd creates the daily note for a day, as Obsidian's Daily notes
+// plugin would: in its folder, named by its format, from its template. The
+// template's {{date}} and {{title}} are the note's day and name. An
+// existing note is left alone.
+func DailyCmd(vaultPath string, opts DailyOptions) error {
+	settings, err := loadDailySettings(vaultPath)
+	if err != nil {
+		return err
+	}
+	day, err := parseDailyDate(opts.Date, time.Now())
+	if err != nil {
+		return err
+	}
+	name := day.Format(vault.MomentLayout(settings.Format))
+	notePath := path.Join(settings.Folder, name+".md")
+	result := DailyOutput{Path: notePath, Date: day.Format("2006-01-02")}
+
+	if _, err := os.Stat(filepath.Join(vaultPath, notePath)); err != nil && !os.IsNotExist(err) {
+		return err
+	} else if err != nil {
+		content := ""
+		if tmplName := firstNonEmpty(opts.Template, settings.Template); tmplName != "" {
+			tmpl, err := loadNoteTemplate(vaultPath, tmplName)
+			if err != nil {
+				return err
+			}
+			rendered, err := tmpl.render(template.Context{
+				Title:  fileTitle(notePath),
+				Folder: noteFolder(notePath),
+				Date:   day,
+				Vars:   opts.Vars,
+				Prompt: terminalPrompt(),
+			})
+			if err != nil {
+				return err
+			}
+			content, result.Cursor = template.StripCursor(rendered.note(""))
+			result.Template = tmpl.path
+		}
+		if err := vault.WriteNote(vaultPath, notePath, content); err != nil {
+			return err
+		}
+		result.Created = true
+	}
+
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+	if result.Created {
+		fmt.Printf("Created %s\n", notePath)
+	} else {
+		fmt.Printf("%s already exists\n", notePath)
+	}
+	return nil
+}
+
+// parseDailyDate parses a --date value: YYYY-MM-DD, today, yesterday,
+// tomorrow, or a day offset such as -1 or +7.
+func parseDailyDate(s string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "today":
+		return now, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return now, fmt.Errorf("invalid --date %q (want YYYY-MM-DD, today, yesterday, tomorrow or +N/-N days)", s)
+		}
+		return today.AddDate(0, 0, n), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, now.Location())
+	if err != nil {
+		return now, fmt.Errorf("invalid --date %q (want YYYY-MM-DD, today, yesterday, tomorrow or +N/-N days)", s)
+	}
+	return t, nil
+}
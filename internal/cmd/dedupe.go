@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/dedupe"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// DedupeOptions configures the dedupe command.
+type DedupeOptions struct {
+	Threshold  float64 // text similarity threshold (0 = default)
+	JSONOutput bool
+}
+
+// DedupeGroup is a set of notes linked by likely-duplicate pairs.
+type DedupeGroup struct {
+	Paths []string      `json:"paths"`
+	Pairs []dedupe.Pair `json:"pairs"`
+}
+
+// DedupeOutput is the JSON output for the dedupe command.
+type DedupeOutput struct {
+	Groups []DedupeGroup `json:"groups"`
+	Notes  int           `json:"notes"`
+	Pairs  int           `json:"pairs"`
+}
+
+// DedupeCmd reports likely duplicate notes across the vault: notes with the
+// same source URL, near-identical text, or near-identical index embeddings.
+func DedupeCmd(vaultPath string, opts DedupeOptions) error {
+	dopts := dedupe.DefaultOptions()
+	if opts.Threshold > 0 {
+		dopts.TextThreshold = opts.Threshold
+	}
+	corpus, err := loadDedupeCorpus(vaultPath, dopts, nil)
+	if err != nil {
+		return err
+	}
+
+	pairs := corpus.Pairs()
+	result := DedupeOutput{
+		Groups: groupDuplicatePairs(pairs),
+		Notes:  corpus.Len(),
+		Pairs:  len(pairs),
+	}
+
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+	printDedupeReport(result)
+	return nil
+}
+
+// loadDedupeCorpus indexes every vault note for duplicate lookup. Embeddings
+// come from the search index when it has been built. When each is non-nil it
+// is also called with every note read, so callers that need other per-note
+// data can share the walk.
+func loadDedupeCorpus(vaultPath string, opts dedupe.Options, each func(notePath string, parsed *vault.Note)) (*dedupe.Corpus, error) {
+	notes, err := vault.ListNotes(vaultPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("listing notes: %w", err)
+	}
+
+	embeddings := make(map[string][]float32)
+	dbPath := index.IndexDBPath(vaultPath)
+	if _, err := os.Stat(dbPath); err == nil {
+		if store, err := index.Open(dbPath); err == nil {
+			if rows, err := store.GetAllNoteRows(); err == nil {
+				for _, r := range rows {
+					if r.Embedding != nil {
+						embeddings[r.Path] = r.Embedding
+					}
+				}
+			}
+			store.Close()
+		}
+	}
+
+	corpus := dedupe.NewCorpus(opts)
+	for _, info := range notes {
+		data, err := os.ReadFile(filepath.Join(vaultPath, info.Path))
+		if err != nil {
+			continue
+		}
+		parsed := vault.ParseNote(string(data))
+		doc := noteDedupeDoc(info.Path, parsed)
+		doc.Embedding = embeddings[info.Path]
+		corpus.Add(doc)
+		if each != nil {
+			each(info.Path, parsed)
+		}
+	}
+	return corpus, nil
+}
+
+// noteDedupeDoc builds the duplicate-detection view of a note: its source:
+// and url: properties (plus the URL of a body that is only a link) and its
+// body text without URLs or the Related Notes section triage appends.
+func noteDedupeDoc(notePath string, parsed *vault.Note) dedupe.Doc {
+	doc := dedupe.Doc{
+		ID:    notePath,
+		Title: extractTitle(parsed, strings.TrimSuffix(filepath.Base(notePath), ".md")),
+	}
+	for _, key := range []string{"source", "url"} {
+		if u := frontmatterString(parsed.Frontmatter, key); u != "" {
+			doc.URLs = append(doc.URLs, u)
+		}
+	}
+
+	body := parsed.Body
+	if i := strings.Index(body, "\n## Related Notes\n"); i >= 0 {
+		body = body[:i]
+	}
+	text := titleURLRe.ReplaceAllString(body, " ")
+	if strings.TrimSpace(text) == "" {
+		doc.URLs = append(doc.URLs, titleURLRe.FindAllString(body, -1)...)
+	}
+	doc.Text = text
+	return doc
+}
+
+// groupDuplicatePairs joins pairs that share a note into groups, ordered by
+// their best pair.
+func groupDuplicatePairs(pairs []dedupe.Pair) []DedupeGroup {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(p string) string {
+		if parent[p] == "" || parent[p] == p {
+			parent[p] = p
+			return p
+		}
+		parent[p] = find(parent[p])
+		return parent[p]
+	}
+	for _, p := range pairs {
+		parent[find(p.A)] = find(p.B)
+	}
+
+	byRoot := make(map[string]*DedupeGroup)
+	var order []string
+	for _, p := range pairs { // pairs are best first
+		root := find(p.A)
+		g := byRoot[root]
+		if g == nil {
+			g = &DedupeGroup{}
+			byRoot[root] = g
+			order = append(order, root)
+		}
+		g.Pairs = append(g.Pairs, p)
+		for _, path := range []string{p.A, p.B} {
+			if !containsStr(g.Paths, path) {
+				g.Paths = append(g.Paths, path)
+			}
+		}
+	}
+
+	groups := make([]DedupeGroup, 0, len(order))
+	for _, root := range order {
+		g := byRoot[root]
+		sort.Strings(g.Paths)
+		groups = append(groups, *g)
+	}
+	return groups
+}
+
+// printDuplicateWarnings writes likely duplicates of a new note to stderr.
+func printDuplicateWarnings(notePath string, matches []dedupe.Match) {
+	for i, m := range matches {
+		if i == 3 {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(matches)-i)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s looks like a duplicate of %s (%s)\n", notePath, m.ID, describeMatch(m))
+	}
+}
+
+// describeMatch formats a match's reason and score for reports.
+func describeMatch(m dedupe.Match) string {
+	if m.Reason == dedupe.ReasonURL {
+		return "same source URL"
+	}
+	return fmt.Sprintf("%s similarity %.0f%%", m.Reason, m.Score*100)
+}
+
+func printDedupeReport(result DedupeOutput) {
+	if len(result.Groups) == 0 {
+		fmt.Printf("No duplicates found among %d notes.\n", result.Notes)
+		return
+	}
+
+	fmt.Printf("Likely duplicates: %d groups (%d pairs among %d notes)\n", len(result.Groups), result.Pairs, result.Notes)
+	for i, g := range result.Groups {
+		fmt.Printf("\n%d. %s\n", i+1, strings.Join(g.Paths, ", "))
+		for _, p := range g.Pairs {
+			fmt.Printf("   - %s ↔ %s (%s)\n", p.A, p.B, describeMatch(dedupe.Match{Score: p.Score, Reason: p.Reason}))
+		}
+	}
+}
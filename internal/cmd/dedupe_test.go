@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/dedupe"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+const retroText = "Retro: the deploy pipeline is too slow because integration tests run serially; split them into shards and cache the module downloads between jobs."
+
+func TestDedupeCmd(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Reading/generics.md": "---\nsource: https://go.dev/blog/intro-generics\n---\nNotes on generics.\n",
+		"Inbox/1.md":          "---\nsource: https://go.dev/blog/intro-generics?utm_source=twitter\n---\nRead later\n",
+		"Notes/retro.md":      "# Retro\n\n" + retroText + "\n",
+		"Inbox/2.md":          retroText + " Also pin the Go version.\n\n## Related Notes\n- [[x]]\n",
+		"Notes/other.md":      "Completely different text about sourdough starters and hydration ratios.\n",
+	})
+
+	out := captureStdout(t, func() {
+		if err := DedupeCmd(dir, DedupeOptions{JSONOutput: true}); err != nil {
+			t.Fatalf("DedupeCmd() error: %v", err)
+		}
+	})
+	var result DedupeOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Notes != 5 || result.Pairs != 2 || len(result.Groups) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	got := map[string]string{}
+	for _, g := range result.Groups {
+		got[strings.Join(g.Paths, " ")] = g.Pairs[0].Reason
+	}
+	if got["Inbox/1.md Reading/generics.md"] != dedupe.ReasonURL || got["Inbox/2.md Notes/retro.md"] != dedupe.ReasonText {
+		t.Errorf("groups = %+v", result.Groups)
+	}
+}
+
+func TestNoteDedupeDoc_URLOnlyBody(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{"Inbox/a.md": "https://example.com/post?utm_medium=x\n"})
+	corpus, err := loadDedupeCorpus(dir, dedupe.DefaultOptions(), nil)
+	if err != nil {
+		t.Fatalf("loadDedupeCorpus() error: %v", err)
+	}
+	doc := noteDedupeDoc("Inbox/b.md", vault.ParseNote("---\nsource: http://www.example.com/post/\n---\n"))
+	if m := corpus.Find(doc); len(m) != 1 || m[0].ID != "Inbox/a.md" {
+		t.Errorf("Find() = %+v", m)
+	}
+}
+
+func TestCaptureCmd_ReportsDuplicates(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{"Notes/retro.md": retroText + "\n"})
+	out := captureStdout(t, func() {
+		if err := CaptureCmd(dir, CaptureOptions{Body: retroText, JSONOutput: true}); err != nil {
+			t.Fatalf("CaptureCmd() error: %v", err)
+		}
+	})
+	var result CaptureOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(result.Duplicates) != 1 || result.Duplicates[0].ID != "Notes/retro.md" {
+		t.Errorf("duplicates = %+v", result.Duplicates)
+	}
+	if _, err := os.Stat(filepath.Join(dir, result.Path)); err != nil {
+		t.Errorf("capture not written: %v", err)
+	}
+}
+
+func TestTriageCmd_MergeDuplicates(t *testing.T) {
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/retro.md": "---\ntitle: Retro\n---\n" + retroText + "\n",
+		"Inbox/dup.md":   "---\ntype: fleeting\n---\n" + retroText + "\n",
+		"Inbox/new.md":   "---\ntitle: Fresh\ntype: fleeting\n---\nA brand new thought.\n",
+	})
+
+	out := captureStdout(t, func() {
+		if err := TriageCmd(dir, TriageOptions{Auto: true, MergeDuplicates: true, JSONOutput: true}); err != nil {
+			t.Fatalf("TriageCmd() error: %v", err)
+		}
+	})
+	var result TriageOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	byFrom := map[string]ProcessedNote{}
+	for _, p := range result.Processed {
+		byFrom[p.FromPath] = p
+	}
+	dup := byFrom["Inbox/dup.md"]
+	if dup.Action != "merged" || dup.ToPath != "Notes/retro.md" || dup.Duplicate == nil || dup.Duplicate.Reason != dedupe.ReasonText {
+		t.Errorf("duplicate note = %+v", dup)
+	}
+	if fresh := byFrom["Inbox/new.md"]; fresh.Duplicate != nil || fresh.Action != "" {
+		t.Errorf("unique note = %+v", fresh)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "Notes/retro.md"))
+	if strings.Count(string(data), "integration tests run serially") != 2 {
+		t.Errorf("duplicate not appended:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Inbox/dup.md")); !os.IsNotExist(err) {
+		t.Errorf("merged inbox note still exists: %v", err)
+	}
+}
+
+func TestInteractiveTriage_MergeIntoDuplicate(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/retro.md": retroText + "\n",
+		"Inbox/dup.md":   "---\ntype: fleeting\n---\n" + retroText + "\n",
+	})
+	corpus, err := loadDedupeCorpus(dir, dedupe.DefaultOptions(), nil)
+	if err != nil {
+		t.Fatalf("loadDedupeCorpus() error: %v", err)
+	}
+	result := TriageOutput{Pending: []PendingNote{{Path: "Inbox/dup.md"}}}
+
+	var out bytes.Buffer
+	interactiveTriage(&triageSession{
+		triageContext: &triageContext{dupes: corpus},
+		vaultPath:     dir,
+		now:           time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		in:            bufio.NewReader(strings.NewReader("m\n\n")), // accept the suggested target
+		out:           &out,
+	}, &result)
+
+	if !strings.Contains(out.String(), "Duplicate:   Notes/retro.md") {
+		t.Errorf("duplicate not shown:\n%s", out.String())
+	}
+	if len(result.Processed) != 1 || result.Processed[0].ToPath != "Notes/retro.md" || result.Processed[0].Action != "merged" {
+		t.Errorf("processed = %+v, errors = %v", result.Processed, result.Errors)
+	}
+}
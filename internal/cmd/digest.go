@@ -0,0 +1,550 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/llm"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+const defaultDigestSince = "7d"
+
+// digestFolder is where digest notes are written, one per ISO week.
+const digestFolder = "Digests"
+
+const (
+	digestMaxLinks      = 10 // link suggestions listed
+	digestMaxResurfaced = 5  // old notes resurfaced
+)
+
+// DigestOptions controls the digest command.
+type DigestOptions struct {
+	Since      string // window, a duration like "7d" (default "7d")
+	StaleDays  int    // maintain's staleness threshold for the health score (0 = stale check's setting)
+	Narrative  bool   // ask the configured LLM for a narrative summary
+	DryRun     bool   // print the note instead of writing it
+	JSONOutput bool
+}
+
+// DigestNote is a note listed in a digest.
+type DigestNote struct {
+	Path  string `json:"path"`
+	Title string `json:"title,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Date  string `json:"date"`
+}
+
+// DigestMetric is a health metric compared with the previous digest.
+// Previous is nil when there is no earlier digest recording the metric.
+type DigestMetric struct {
+	Name     string   `json:"name"`
+	Current  float64  `json:"current"`
+	Previous *float64 `json:"previous,omitempty"`
+	Change   float64  `json:"change"`
+}
+
+// DigestOutput is the JSON output of the digest command. The triage,
+// ingest, maintain and health sections use the same structs as the commands
+// that produce them.
+type DigestOutput struct {
+	Path           string            `json:"path"`
+	Week           string            `json:"week"`
+	Since          string            `json:"since"`
+	Until          string            `json:"until"`
+	Created        []DigestNote      `json:"created"`
+	Modified       []DigestNote      `json:"modified"`
+	Triage         TriageOutput      `json:"triage"` // Processed: notes triaged in the window
+	Ingested       AutoCaptureOutput `json:"ingested"`
+	IngestedNotes  []DigestNote      `json:"ingested_notes"`
+	Links          []LinkSuggestion  `json:"link_suggestions"`
+	Resurfaced     []ResurfaceResult `json:"resurfaced"`
+	Maintain       MaintainOutput    `json:"maintain"`
+	Health         HealthOutput      `json:"health"`
+	HealthChanges  []DigestMetric    `json:"health_changes"`
+	PreviousDigest string            `json:"previous_digest,omitempty"`
+	Narrative      string            `json:"narrative,omitempty"`
+	LLMUsage       *llm.Usage        `json:"llm_usage,omitempty"`
+	DryRun         bool              `json:"dry_run,omitempty"`
+}
+
+// digestIngestSources maps the type of notes written by auto-capture to the
+// source that wrote them.
+var digestIngestSources = map[string]string{
+	"intel":    "scout",
+	"learning": "learnings",
+}
+
+// DigestCmd writes a weekly digest note to Digests/YYYY-Www.md summarizing
+// vault activity over the window: notes created and modified, triage,
+// auto-captured scout and learnings items, new link suggestions, a few
+// resurfaced notes, and how the health metrics moved since the previous
+// digest. Re-running in the same week rewrites that week's digest.
+func DigestCmd(vaultPath string, opts DigestOptions) error {
+	if opts.Since == "" {
+		opts.Since = defaultDigestSince
+	}
+	window, err := parseSinceDuration(opts.Since)
+	if err != nil {
+		return fmt.Errorf("invalid --since value %q: %w", opts.Since, err)
+	}
+
+	dbPath := index.IndexDBPath(vaultPath)
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("index not found — run 'obsidian index' first")
+	}
+	store, err := index.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	d, err := buildDigest(vaultPath, store, now.Add(-window), now, opts.StaleDays)
+	if err != nil {
+		return err
+	}
+	d.DryRun = opts.DryRun
+
+	if opts.Narrative {
+		client, err := llm.FromSettings(config.ResolveLLM())
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Warning: LLM disabled: %v\n", err)
+		case client == nil:
+			fmt.Fprintln(os.Stderr, "Warning: no LLM provider configured; skipping the narrative")
+		default:
+			d.Narrative, err = digestNarrative(context.Background(), client, renderDigest(d))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: narrative failed: %v\n", err)
+			}
+			usage := client.Usage()
+			d.LLMUsage = &usage
+		}
+	}
+
+	content := renderDigest(d)
+	if !opts.DryRun {
+		fullPath := filepath.Join(vaultPath, d.Path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return fmt.Errorf("creating %s: %w", digestFolder, err)
+		}
+		snapshotNote(vaultPath, d.Path, "digest")
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("writing digest: %w", err)
+		}
+	}
+
+	if opts.JSONOutput {
+		return output.JSON(d)
+	}
+	if opts.DryRun {
+		fmt.Print(content)
+		return nil
+	}
+	printDigestResult(d)
+	return nil
+}
+
+// buildDigest gathers the digest for the window [since, now].
+func buildDigest(vaultPath string, store *index.Store, since, now time.Time, staleDays int) (DigestOutput, error) {
+	week := digestWeek(now)
+	d := DigestOutput{
+		Path:  filepath.Join(digestFolder, week+".md"),
+		Week:  week,
+		Since: since.Format("2006-01-02"),
+		Until: now.Format("2006-01-02"),
+	}
+
+	rows, err := store.GetAllNoteRows()
+	if err != nil {
+		return d, fmt.Errorf("failed to load notes: %w", err)
+	}
+	notes, err := vault.ListNotes(vaultPath, "")
+	if err != nil {
+		return d, fmt.Errorf("failed to list notes: %w", err)
+	}
+
+	indexed := make(map[string]index.NoteRow, len(rows))
+	for _, r := range rows {
+		indexed[r.Path] = r
+	}
+
+	// Classify every note by its frontmatter dates and the index mod_time.
+	// Notes that are not indexed yet fall back to the file's mod time.
+	active := make(map[string]bool)
+	ingested := make(map[string][]string)
+	for _, n := range loadVaultNotes(vaultPath, notes) {
+		if isDigestNote(n.info.Path) {
+			continue
+		}
+		fm := n.parsed.Frontmatter
+		note := DigestNote{Path: n.info.Path, Title: frontmatterString(fm, "title"), Type: frontmatterString(fm, "type")}
+		modTime := n.info.ModTime
+		if r, ok := indexed[n.info.Path]; ok {
+			modTime = r.ModTime
+			if note.Title == "" {
+				note.Title = r.Title
+			}
+		}
+
+		if triaged := frontmatterDate(fm, "triaged"); triaged != "" && triaged >= d.Since {
+			d.Triage.Processed = append(d.Triage.Processed, ProcessedNote{ToPath: n.info.Path, NoteType: note.Type})
+		}
+
+		if source, ok := digestIngestSources[note.Type]; ok {
+			if date := frontmatterDate(fm, "ingested"); date != "" && date >= d.Since {
+				note.Date = date
+				d.IngestedNotes = append(d.IngestedNotes, note)
+				ingested[source] = append(ingested[source], note.Path)
+				active[note.Path] = true
+			}
+			continue
+		}
+
+		if created := frontmatterDate(fm, "created"); created != "" && created >= d.Since {
+			note.Date = created
+			d.Created = append(d.Created, note)
+			active[note.Path] = true
+			continue
+		}
+		if mod := time.Unix(modTime, 0); !mod.Before(since) {
+			note.Date = mod.Format("2006-01-02")
+			d.Modified = append(d.Modified, note)
+			active[note.Path] = true
+		}
+	}
+	sortDigestNotes(d.Created)
+	sortDigestNotes(d.Modified)
+	sortDigestNotes(d.IngestedNotes)
+	sort.Slice(d.Triage.Processed, func(i, j int) bool { return d.Triage.Processed[i].ToPath < d.Triage.Processed[j].ToPath })
+
+	// Inbox state as triage --list reports it.
+	if inbox, err := vault.ListNotes(vaultPath, "Inbox"); err == nil {
+		d.Triage.Pending, d.Triage.Errors = collectPendingNotes(vaultPath, inbox, 0, now)
+	}
+	d.Triage.Summary = TriageSummary{
+		Total:     len(d.Triage.Pending),
+		Processed: len(d.Triage.Processed),
+		Errors:    len(d.Triage.Errors),
+	}
+
+	for _, source := range []string{"scout", "learnings"} {
+		d.Ingested.Sources = append(d.Ingested.Sources, IngestOutput{Source: source, Created: ingested[source]})
+		d.Ingested.Total.Created += len(ingested[source])
+	}
+
+	// Link suggestions that involve a note touched in the window, leaving
+	// out pairs rejected during enrich review.
+	rejected := rejectedLinkSet(store)
+	var linkable []index.NoteRow
+	for _, r := range rows {
+		if !isDigestNote(r.Path) {
+			linkable = append(linkable, r)
+		}
+	}
+	for _, s := range findLinkSuggestions(linkable) {
+		if len(d.Links) == digestMaxLinks {
+			break
+		}
+		if (active[s.From] || active[s.To]) && !rejected[linkRejection(s.From, s.To)] {
+			d.Links = append(d.Links, s)
+		}
+	}
+
+	// A few notes untouched since before the window. Digests are excluded
+	// after the query, so ask for extra.
+	if old, err := store.RandomOldNotes(since.Unix(), digestMaxResurfaced*2); err == nil {
+		for _, r := range noteRowsToResurfaceResults(old, now) {
+			if len(d.Resurfaced) < digestMaxResurfaced && !isDigestNote(r.Path) {
+				d.Resurfaced = append(d.Resurfaced, r)
+			}
+		}
+	}
+
+	d.Maintain, _, _, err = runMaintainChecks(vaultPath, MaintainOptions{StaleDays: staleDays}, store)
+	if err != nil {
+		return d, err
+	}
+	d.Health, err = computeHealth(vaultPath)
+	if err != nil {
+		return d, err
+	}
+	var previous map[string]float64
+	previous, d.PreviousDigest = previousDigestMetrics(vaultPath, d.Path)
+	d.HealthChanges = digestHealthChanges(d, previous)
+	return d, nil
+}
+
+// digestWeek names the ISO week of t, e.g. 2026-W42.
+func digestWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// isDigestNote reports whether a vault path is in the digest folder.
+func isDigestNote(notePath string) bool {
+	return strings.HasPrefix(notePath, digestFolder+"/")
+}
+
+// frontmatterDate returns the YYYY-MM-DD part of a date property, or "" when
+// the property is missing or not a date.
+func frontmatterDate(fm map[string]any, key string) string {
+	s := frontmatterString(fm, key)
+	if len(s) < 10 {
+		return ""
+	}
+	if _, err := time.Parse("2006-01-02", s[:10]); err != nil {
+		return ""
+	}
+	return s[:10]
+}
+
+// sortDigestNotes orders notes newest first, then by path.
+func sortDigestNotes(notes []DigestNote) {
+	sort.Slice(notes, func(i, j int) bool {
+		if notes[i].Date != notes[j].Date {
+			return notes[i].Date > notes[j].Date
+		}
+		return notes[i].Path < notes[j].Path
+	})
+}
+
+// digestMetricNames lists the tracked health metrics in report order. They
+// are recorded in each digest's frontmatter under these names.
+var digestMetricNames = []string{
+	"health_score",
+	"total_notes",
+	"inbox_pending",
+	"stale_captures",
+	"orphan_notes",
+	"broken_links",
+	"link_density",
+}
+
+// digestMetrics returns the current value of each tracked metric.
+func digestMetrics(d DigestOutput) map[string]float64 {
+	return map[string]float64{
+		"health_score":   float64(d.Maintain.HealthScore),
+		"total_notes":    float64(d.Health.TotalNotes),
+		"inbox_pending":  float64(d.Health.InboxDepth),
+		"stale_captures": float64(d.Health.StaleCaptures),
+		"orphan_notes":   float64(d.Health.OrphanNotes),
+		"broken_links":   float64(len(d.Maintain.BrokenLinks)),
+		"link_density":   roundMetric(d.Health.LinkDensity),
+	}
+}
+
+// digestHealthChanges compares the current metrics with the previous
+// digest's.
+func digestHealthChanges(d DigestOutput, previous map[string]float64) []DigestMetric {
+	current := digestMetrics(d)
+	changes := make([]DigestMetric, 0, len(digestMetricNames))
+	for _, name := range digestMetricNames {
+		m := DigestMetric{Name: name, Current: current[name]}
+		if prev, ok := previous[name]; ok {
+			m.Previous = &prev
+			m.Change = roundMetric(m.Current - prev)
+		}
+		changes = append(changes, m)
+	}
+	return changes
+}
+
+// previousDigestMetrics reads the metrics recorded by the latest digest
+// before the one at current. It returns nil and "" when there is none.
+func previousDigestMetrics(vaultPath, current string) (map[string]float64, string) {
+	digests, err := vault.ListNotes(vaultPath, digestFolder)
+	if err != nil {
+		return nil, ""
+	}
+	var latest string
+	for _, info := range digests {
+		if filepath.Dir(info.Path) == digestFolder && info.Path < filepath.ToSlash(current) && info.Path > latest {
+			latest = info.Path
+		}
+	}
+	if latest == "" {
+		return nil, ""
+	}
+	data, err := os.ReadFile(filepath.Join(vaultPath, latest))
+	if err != nil {
+		return nil, ""
+	}
+	fm := vault.ParseNote(string(data)).Frontmatter
+	metrics := make(map[string]float64)
+	for _, name := range digestMetricNames {
+		if v, err := strconv.ParseFloat(frontmatterString(fm, name), 64); err == nil {
+			metrics[name] = v
+		}
+	}
+	return metrics, latest
+}
+
+func roundMetric(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+const digestNarrativePrompt = `You write the opening paragraph of a weekly digest of a personal knowledge vault. Given the digest below, summarize the week in 3 to 5 sentences of plain prose: what the owner worked on, recurring themes, and anything that needs attention (a growing inbox, falling health score). Mention notes by title, not path. No headings, lists, links or preamble.`
+
+// digestNarrative asks the model for a short prose summary of the digest.
+func digestNarrative(ctx context.Context, client *llm.Client, digest string) (string, error) {
+	resp, err := client.Complete(ctx, llm.Request{
+		System:    digestNarrativePrompt,
+		Messages:  []llm.Message{{Role: "user", Content: digest}},
+		MaxTokens: 400,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Text), nil
+}
+
+// digestLink renders a wikilink to a vault note, aliased to its title.
+func digestLink(notePath, title string) string {
+	target := strings.TrimSuffix(notePath, ".md")
+	if title == "" || title == filepath.Base(target) {
+		return "[[" + target + "]]"
+	}
+	return "[[" + target + "|" + strings.ReplaceAll(title, "|", "-") + "]]"
+}
+
+// renderDigest renders the digest note, frontmatter included.
+func renderDigest(d DigestOutput) string {
+	var b strings.Builder
+	metrics := digestMetrics(d)
+
+	b.WriteString("---\n")
+	b.WriteString("type: digest\n")
+	fmt.Fprintf(&b, "week: %s\n", d.Week)
+	fmt.Fprintf(&b, "since: %s\n", d.Since)
+	fmt.Fprintf(&b, "until: %s\n", d.Until)
+	fmt.Fprintf(&b, "created: %s\n", d.Until)
+	for _, name := range digestMetricNames {
+		fmt.Fprintf(&b, "%s: %s\n", name, formatMetric(metrics[name]))
+	}
+	b.WriteString("tags:\n  - digest\n")
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# Digest %s\n\n", d.Week)
+	fmt.Fprintf(&b, "%s to %s\n\n", d.Since, d.Until)
+	if d.Narrative != "" {
+		b.WriteString(d.Narrative)
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "- %d notes created, %d modified\n", len(d.Created), len(d.Modified))
+	fmt.Fprintf(&b, "- %d notes triaged, %d pending in the inbox\n", d.Triage.Summary.Processed, d.Triage.Summary.Total)
+	fmt.Fprintf(&b, "- %d items ingested\n", d.Ingested.Total.Created)
+	fmt.Fprintf(&b, "- Health score %d", d.Maintain.HealthScore)
+	if len(d.HealthChanges) > 0 && d.HealthChanges[0].Previous != nil {
+		fmt.Fprintf(&b, " (%s since %s)", signedMetric(d.HealthChanges[0].Change), digestLink(d.PreviousDigest, ""))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("\n## Health\n\n")
+	b.WriteString("| Metric | Previous | Now | Change |\n")
+	b.WriteString("| --- | ---: | ---: | ---: |\n")
+	for _, m := range d.HealthChanges {
+		prev, change := "–", "–"
+		if m.Previous != nil {
+			prev, change = formatMetric(*m.Previous), signedMetric(m.Change)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", strings.ReplaceAll(m.Name, "_", " "), prev, formatMetric(m.Current), change)
+	}
+
+	writeDigestNotes(&b, "New notes", d.Created)
+	writeDigestNotes(&b, "Modified notes", d.Modified)
+
+	if len(d.Triage.Processed) > 0 || len(d.Triage.Pending) > 0 {
+		b.WriteString("\n## Triage\n\n")
+		for _, p := range d.Triage.Processed {
+			fmt.Fprintf(&b, "- %s → %s\n", digestLink(p.ToPath, ""), p.NoteType)
+		}
+		if n := len(d.Triage.Pending); n > 0 {
+			oldest := 0
+			for _, p := range d.Triage.Pending {
+				oldest = max(oldest, p.AgeDays)
+			}
+			fmt.Fprintf(&b, "- %d pending in the inbox, oldest %s\n", n, formatAgeLabel(oldest))
+		}
+	}
+
+	if d.Ingested.Total.Created > 0 {
+		b.WriteString("\n## Ingested\n\n")
+		for _, src := range d.Ingested.Sources {
+			if len(src.Created) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "- %s: %d\n", src.Source, len(src.Created))
+			for _, n := range d.IngestedNotes {
+				if digestIngestSources[n.Type] == src.Source {
+					fmt.Fprintf(&b, "  - %s\n", digestLink(n.Path, n.Title))
+				}
+			}
+		}
+	}
+
+	if len(d.Links) > 0 {
+		b.WriteString("\n## Suggested links\n\n")
+		for _, s := range d.Links {
+			fmt.Fprintf(&b, "- %s ↔ %s (%.2f)\n", digestLink(s.From, ""), digestLink(s.To, ""), s.Similarity)
+		}
+	}
+
+	if len(d.Resurfaced) > 0 {
+		b.WriteString("\n## Resurfaced\n\n")
+		for _, r := range d.Resurfaced {
+			fmt.Fprintf(&b, "- %s, %d days old\n", digestLink(r.Path, r.Title), r.AgeDays)
+		}
+	}
+	return b.String()
+}
+
+// writeDigestNotes writes a section listing notes, if there are any.
+func writeDigestNotes(b *strings.Builder, heading string, notes []DigestNote) {
+	if len(notes) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", heading)
+	for _, n := range notes {
+		fmt.Fprintf(b, "- %s", digestLink(n.Path, n.Title))
+		if n.Type != "" {
+			fmt.Fprintf(b, " (%s)", n.Type)
+		}
+		fmt.Fprintf(b, ", %s\n", n.Date)
+	}
+}
+
+func signedMetric(v float64) string {
+	if v > 0 {
+		return "+" + formatMetric(v)
+	}
+	return formatMetric(v)
+}
+
+func printDigestResult(d DigestOutput) {
+	fmt.Printf("Wrote %s (%s to %s)\n", d.Path, d.Since, d.Until)
+	fmt.Printf("  %d created, %d modified, %d triaged, %d ingested, %d link suggestions\n",
+		len(d.Created), len(d.Modified), d.Triage.Summary.Processed, d.Ingested.Total.Created, len(d.Links))
+	fmt.Printf("  Health score %d", d.Maintain.HealthScore)
+	if len(d.HealthChanges) > 0 && d.HealthChanges[0].Previous != nil {
+		fmt.Printf(" (%s since %s)", signedMetric(d.HealthChanges[0].Change), d.PreviousDigest)
+	}
+	fmt.Println()
+}
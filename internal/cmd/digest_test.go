@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/history"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// writeDigestVault builds a vault with activity in the last week and an
+// index covering it.
+func writeDigestVault(t *testing.T, now time.Time) string {
+	t.Helper()
+	today := now.Format("2006-01-02")
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/sharding.md":   "---\ntitle: Sharding\ncreated: " + today + "\n---\nSplitting tables by key.\n",
+		"Notes/indexes.md":    "---\ncreated: 2024-01-10\n---\nB-trees and [[sharding]].\n",
+		"Notes/postgres.md":   "---\ncreated: 2023-05-01\n---\nPartitioning in Postgres.\n",
+		"Ideas/cache.md":      "---\ntype: idea\ncreated: 2024-03-01\nstatus: processed\ntriaged: " + today + "\n---\nCache the index.\n",
+		"Intel/hn-1.md":       "---\ntype: intel\nsource: hn\ningested: " + today + "\n---\n# Vector search\n",
+		"Learnings/go/a.md":   "---\ntype: learning\ncreated: 2024-02-01\ningested: " + today + "\n---\n# Go: Pattern\n",
+		"Inbox/later.md":      "---\ncreated: " + today + "\n---\nRead about raft.\n",
+		"Digests/2026-W01.md": "---\ntype: digest\nhealth_score: 50\ninbox_pending: 3\n---\n",
+	})
+	if err := os.MkdirAll(filepath.Join(dir, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+	old := now.AddDate(0, 0, -90).Unix()
+	for _, row := range []index.NoteRow{
+		{Path: "Notes/sharding.md", Title: "Sharding", ModTime: now.Unix(), Embedding: []float32{1, 0}},
+		{Path: "Notes/indexes.md", Title: "Indexes", ModTime: now.Unix(), Embedding: []float32{0, 1}},
+		{Path: "Notes/postgres.md", Title: "Postgres", ModTime: old, Embedding: []float32{0.95, 0.1}},
+		{Path: "Ideas/cache.md", Title: "Cache", ModTime: old},
+	} {
+		if err := store.UpsertNote(&row); err != nil {
+			t.Fatalf("UpsertNote: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestBuildDigest(t *testing.T) {
+	now := time.Now()
+	dir := writeDigestVault(t, now)
+	store, err := index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+
+	d, err := buildDigest(dir, store, now.AddDate(0, 0, -7), now, 30)
+	if err != nil {
+		t.Fatalf("buildDigest() error: %v", err)
+	}
+	if d.Path != filepath.Join("Digests", digestWeek(now)+".md") {
+		t.Errorf("path = %q", d.Path)
+	}
+	if paths := digestPaths(d.Created); paths != "Inbox/later.md,Notes/sharding.md" {
+		t.Errorf("created = %s", paths)
+	}
+	if paths := digestPaths(d.Modified); paths != "Notes/indexes.md" {
+		t.Errorf("modified = %s", paths)
+	}
+	if len(d.Triage.Processed) != 1 || d.Triage.Processed[0].ToPath != "Ideas/cache.md" || d.Triage.Summary.Total != 1 {
+		t.Errorf("triage = %+v", d.Triage)
+	}
+	if d.Ingested.Total.Created != 2 || len(d.Ingested.Sources) != 2 || d.Ingested.Sources[0].Created[0] != "Intel/hn-1.md" {
+		t.Errorf("ingested = %+v", d.Ingested)
+	}
+	if len(d.Links) != 1 || d.Links[0].From != "Notes/sharding.md" || d.Links[0].To != "Notes/postgres.md" {
+		t.Errorf("links = %+v", d.Links)
+	}
+	for _, r := range d.Resurfaced {
+		if r.Path != "Notes/postgres.md" && r.Path != "Ideas/cache.md" {
+			t.Errorf("resurfaced %s, modified in the window", r.Path)
+		}
+	}
+
+	if d.PreviousDigest != "Digests/2026-W01.md" {
+		t.Fatalf("previous digest = %q", d.PreviousDigest)
+	}
+	for _, m := range d.HealthChanges {
+		switch m.Name {
+		case "health_score":
+			if m.Previous == nil || *m.Previous != 50 || m.Change != m.Current-50 {
+				t.Errorf("health_score = %+v", m)
+			}
+		case "inbox_pending":
+			if m.Current != 1 || m.Change != -2 {
+				t.Errorf("inbox_pending = %+v", m)
+			}
+		case "orphan_notes":
+			if m.Previous != nil {
+				t.Errorf("orphan_notes has a previous value: %+v", m)
+			}
+		}
+	}
+}
+
+func TestDigestCmd(t *testing.T) {
+	now := time.Now()
+	dir := writeDigestVault(t, now)
+
+	out := captureStdout(t, func() {
+		if err := DigestCmd(dir, DigestOptions{JSONOutput: true}); err != nil {
+			t.Fatalf("DigestCmd() error: %v", err)
+		}
+	})
+	var result DigestOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+
+	content := mustRead(t, filepath.Join(dir, result.Path))
+	note := vault.ParseNote(content)
+	if frontmatterString(note.Frontmatter, "week") != result.Week || frontmatterString(note.Frontmatter, "inbox_pending") != "1" {
+		t.Errorf("frontmatter = %v", note.Frontmatter)
+	}
+	for _, want := range []string{
+		"## New notes\n\n- [[Inbox/later]], " + now.Format("2006-01-02") + "\n- [[Notes/sharding|Sharding]], ",
+		"- [[Ideas/cache]] → idea\n",
+		"- scout: 1\n  - [[Intel/hn-1]]\n",
+		"- [[Notes/sharding]] ↔ [[Notes/postgres]] (0.99)\n",
+		"| inbox pending | 3 | 1 | -2 |\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("digest missing %q:\n%s", want, content)
+		}
+	}
+
+	// The digest itself is not reported as activity in the next one.
+	out = captureStdout(t, func() {
+		if err := DigestCmd(dir, DigestOptions{DryRun: true, JSONOutput: true}); err != nil {
+			t.Fatalf("DigestCmd() rerun error: %v", err)
+		}
+	})
+	result = DigestOutput{}
+	json.Unmarshal([]byte(out), &result)
+	for _, n := range append(result.Created, result.Modified...) {
+		if strings.HasPrefix(n.Path, "Digests/") {
+			t.Errorf("digest listed as activity: %s", n.Path)
+		}
+	}
+	if result.PreviousDigest != "Digests/2026-W01.md" {
+		t.Errorf("rerun compared against %q, want the earlier week", result.PreviousDigest)
+	}
+	if _, err := os.Stat(filepath.Join(dir, result.Path)); err != nil {
+		t.Errorf("digest missing after dry run: %v", err)
+	}
+
+	// Regenerating the week's digest keeps the old one in history.
+	if err := os.WriteFile(filepath.Join(dir, result.Path), []byte("edited by hand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		if err := DigestCmd(dir, DigestOptions{JSONOutput: true}); err != nil {
+			t.Fatalf("DigestCmd() rerun error: %v", err)
+		}
+	})
+	store, err := history.Open(dir, 10)
+	if err != nil {
+		t.Fatalf("history.Open() error: %v", err)
+	}
+	if versions := store.Versions(result.Path); len(versions) != 1 || versions[0].Reason != "digest" {
+		t.Errorf("history for %s = %+v, want one digest snapshot", result.Path, versions)
+	}
+}
+
+func TestDigestWeek(t *testing.T) {
+	for date, want := range map[string]string{
+		"2026-10-18": "2026-W42",
+		"2027-01-01": "2026-W53",
+		"2025-12-29": "2026-W01",
+	} {
+		d, _ := time.Parse("2006-01-02", date)
+		if got := digestWeek(d); got != want {
+			t.Errorf("digestWeek(%s) = %s, want %s", date, got, want)
+		}
+	}
+}
+
+func digestPaths(notes []DigestNote) string {
+	var paths []string
+	for _, n := range notes {
+		paths = append(paths, n.Path)
+	}
+	return strings.Join(paths, ",")
+}
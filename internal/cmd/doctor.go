@@ -9,6 +9,7 @@ import (
 
 	"github.com/joeyhipolito/obsidian-cli/internal/config"
 	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/llm"
 	"github.com/joeyhipolito/obsidian-cli/internal/output"
 	"github.com/joeyhipolito/obsidian-cli/internal/vault"
 )
@@ -119,6 +120,9 @@ func DoctorCmd(jsonOutput bool) error {
 			})
 		}
 
+		// LLM provider is optional: triage falls back to local classifiers.
+		checks = append(checks, checkLLMProvider())
+
 		// 5. Check vault path
 		vaultPath := cfg.VaultPath
 		if vaultPath == "" {
@@ -297,3 +301,20 @@ func checkInboxStatus(vaultPath string) DoctorCheck {
 		Message: fmt.Sprintf("%d notes pending triage, oldest: %dd", pending, oldestDays),
 	}
 }
+
+// checkLLMProvider reports the LLM provider triage will use. A misconfigured
+// provider is a warning since triage still works without one.
+func checkLLMProvider() DoctorCheck {
+	check := DoctorCheck{Name: "LLM provider", Status: "ok"}
+	client, err := llm.FromSettings(config.ResolveLLM())
+	switch {
+	case err != nil:
+		check.Status = "warn"
+		check.Message = err.Error()
+	case client == nil:
+		check.Message = "None (triage uses local classifiers)"
+	default:
+		check.Message = fmt.Sprintf("%s (%s)", client.Provider(), client.Model())
+	}
+	return check
+}
@@ -1,15 +1,40 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
 	"github.com/joeyhipolito/obsidian-cli/internal/index"
 	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
 )
 
+// Defaults for where accepted links are written; see enrichFormat.
+const (
+	defaultEnrichHeading    = "## Related Notes"
+	defaultEnrichLinkFormat = "- {link}"
+)
+
+// EnrichOptions controls the enrich command. Apply writes every suggestion;
+// Interactive and AcceptFile write only the accepted ones and remember the
+// rejected ones so they are not suggested again.
+type EnrichOptions struct {
+	Apply       bool   // apply every link suggestion (and tag suggestion with Tags)
+	Interactive bool   // accept or reject each suggestion at a prompt
+	AcceptFile  string // file of accept/reject decisions (see parseAcceptFile)
+	Tags        bool   // also apply tag suggestions with Apply or Interactive
+	Heading     string // section for added links (default from config, then "## Related Notes")
+	LinkFormat  string // line for each added link (default from config, then "- {link}")
+	JSONOutput  bool
+}
+
 // EnrichOutput represents the JSON output format for the enrich command.
 type EnrichOutput struct {
 	LinkSuggestions []LinkSuggestion `json:"link_suggestions"`
@@ -23,6 +48,7 @@ type LinkSuggestion struct {
 	From       string  `json:"from"`
 	To         string  `json:"to"`
 	Similarity float64 `json:"similarity"`
+	Reason     string  `json:"reason,omitempty"`
 }
 
 // TagSuggestion represents a suggested tag for a note.
@@ -33,14 +59,61 @@ type TagSuggestion struct {
 
 // EnrichSummary holds counts for the enrichment report.
 type EnrichSummary struct {
-	LinksFound  int `json:"links_found"`
-	TagsFound   int `json:"tags_found"`
+	LinksFound   int `json:"links_found"`
+	TagsFound    int `json:"tags_found"`
 	OrphansFound int `json:"orphans_found"`
-	Applied     int `json:"applied"`
+	Applied      int `json:"applied"`         // notes given new links
+	TagsApplied  int `json:"tags_applied"`    // notes given new tags
+	Rejected     int `json:"rejected"`        // suggestions rejected this run
+	Hidden       int `json:"hidden_rejected"` // suggestions skipped as rejected earlier
+}
+
+// enrichFormat is how accepted links are written into a note: under Heading,
+// one line per link rendered from Link. Link may use {link} (the wikilink),
+// {note} (its target text), {path}, {reason} and {similarity}.
+type enrichFormat struct {
+	Heading string
+	Link    string
+}
+
+// resolveEnrichFormat combines flags, config and defaults, in that order. A
+// heading without leading #s is made a level-2 heading.
+func resolveEnrichFormat(opts EnrichOptions) enrichFormat {
+	settings := config.ResolveEnrich()
+	f := enrichFormat{
+		Heading: firstNonEmpty(opts.Heading, settings.Heading, defaultEnrichHeading),
+		Link:    firstNonEmpty(opts.LinkFormat, settings.LinkFormat, defaultEnrichLinkFormat),
+	}
+	if !strings.HasPrefix(f.Heading, "#") {
+		f.Heading = "## " + f.Heading
+	}
+	return f
+}
+
+// line renders the list line for a link to target.
+func (f enrichFormat) line(linkText string, s LinkSuggestion, target string) string {
+	return strings.NewReplacer(
+		"{link}", "[["+linkText+"]]",
+		"{note}", linkText,
+		"{path}", target,
+		"{reason}", s.Reason,
+		"{similarity}", fmt.Sprintf("%.2f", s.Similarity),
+	).Replace(f.Link)
 }
 
 // EnrichCmd analyzes the vault index and suggests connections between notes.
-func EnrichCmd(vaultPath string, apply, jsonOutput bool) error {
+// Suggestions rejected in an earlier review are left out.
+func EnrichCmd(vaultPath string, opts EnrichOptions) error {
+	if opts.Interactive && opts.JSONOutput {
+		return fmt.Errorf("--interactive cannot be combined with --json")
+	}
+	if opts.Interactive && opts.AcceptFile != "" {
+		return fmt.Errorf("--interactive cannot be combined with --accept-file")
+	}
+	if opts.Interactive && !stdinIsTerminal() {
+		return fmt.Errorf("--interactive requires a terminal")
+	}
+
 	dbPath := index.IndexDBPath(vaultPath)
 	if _, err := os.Stat(dbPath); err != nil {
 		return fmt.Errorf("index not found — run 'obsidian index' first")
@@ -58,7 +131,7 @@ func EnrichCmd(vaultPath string, apply, jsonOutput bool) error {
 	}
 
 	if len(notes) == 0 {
-		if jsonOutput {
+		if opts.JSONOutput {
 			return output.JSON(EnrichOutput{})
 		}
 		fmt.Println("No indexed notes found. Run 'obsidian index' first.")
@@ -68,28 +141,65 @@ func EnrichCmd(vaultPath string, apply, jsonOutput bool) error {
 	result := EnrichOutput{}
 
 	// Pass 1: Link suggestions via cosine similarity
-	result.LinkSuggestions = findLinkSuggestions(notes)
-	result.Summary.LinksFound = len(result.LinkSuggestions)
-
 	// Pass 2: Tag suggestions via consensus filtering
-	result.TagSuggestions = findTagSuggestions(notes)
+	links, tags := findLinkSuggestions(notes), findTagSuggestions(notes)
+	result.LinkSuggestions, result.TagSuggestions, result.Summary.Hidden, err = withoutRejected(store, links, tags)
+	if err != nil {
+		return err
+	}
+	result.Summary.LinksFound = len(result.LinkSuggestions)
 	result.Summary.TagsFound = len(result.TagSuggestions)
 
 	// Pass 3: Orphan detection
 	result.OrphanNotes = findOrphans(notes)
 	result.Summary.OrphansFound = len(result.OrphanNotes)
 
-	// Apply link suggestions if requested
-	if apply && len(result.LinkSuggestions) > 0 {
-		applied := applyLinkSuggestions(vaultPath, result.LinkSuggestions)
-		result.Summary.Applied = applied
+	// Decide which suggestions to apply.
+	var d enrichDecisions
+	switch {
+	case opts.Interactive:
+		d = interactiveEnrich(&enrichSession{in: bufio.NewReader(os.Stdin), out: os.Stdout}, result.LinkSuggestions, tagsIf(opts.Tags, result.TagSuggestions))
+	case opts.AcceptFile != "":
+		lines, err := parseAcceptFile(opts.AcceptFile)
+		if err != nil {
+			return err
+		}
+		d = decideFromAcceptFile(lines, result.LinkSuggestions, result.TagSuggestions)
+	case opts.Apply:
+		d = enrichDecisions{Links: result.LinkSuggestions, Tags: tagsIf(opts.Tags, result.TagSuggestions)}
+	}
+
+	if err := recordRejections(store, d, time.Now()); err != nil {
+		return err
+	}
+	result.Summary.Rejected = len(d.RejectedLinks) + countTags(d.RejectedTags)
+	snapshots := newNoteHistory(vaultPath)
+	if len(d.Links) > 0 {
+		result.Summary.Applied = applyLinkSuggestions(vaultPath, snapshots, d.Links, resolveEnrichFormat(opts))
 	}
+	if len(d.Tags) > 0 {
+		result.Summary.TagsApplied = applyTagSuggestions(vaultPath, snapshots, d.Tags)
+	}
+	snapshots.save()
 
-	if jsonOutput {
+	if opts.JSONOutput {
 		return output.JSON(result)
 	}
 
-	printEnrichReport(result, apply)
+	if opts.Interactive {
+		printEnrichReviewSummary(result.Summary)
+		return nil
+	}
+	printEnrichReport(result, opts.Apply || opts.AcceptFile != "")
+	return nil
+}
+
+// tagsIf returns tags when include is set, so tag suggestions are only
+// applied or reviewed when asked for.
+func tagsIf(include bool, tags []TagSuggestion) []TagSuggestion {
+	if include {
+		return tags
+	}
 	return nil
 }
 
@@ -98,26 +208,11 @@ func findLinkSuggestions(notes []index.NoteRow) []LinkSuggestion {
 	const threshold = 0.7
 	const maxPerNote = 5
 
-	// Build existing link sets for each note
+	// Resolve each note's outgoing links to vault paths
+	resolver := resolverFromRows(notes)
 	existingLinks := make(map[string]map[string]bool)
 	for _, n := range notes {
-		links := make(map[string]bool)
-		if n.Wikilinks != "" {
-			for _, link := range strings.Split(n.Wikilinks, ", ") {
-				links[strings.ToLower(strings.TrimSpace(link))] = true
-			}
-		}
-		existingLinks[n.Path] = links
-	}
-
-	// Build title-to-path lookup for link resolution
-	titleToPath := make(map[string]string)
-	for _, n := range notes {
-		name := strings.TrimSuffix(filepath.Base(n.Path), ".md")
-		titleToPath[strings.ToLower(name)] = n.Path
-		if n.Title != "" {
-			titleToPath[strings.ToLower(n.Title)] = n.Path
-		}
+		existingLinks[n.Path] = resolvedLinkSet(resolver, n)
 	}
 
 	// All-pairs cosine similarity (i < j to avoid duplicates)
@@ -142,10 +237,8 @@ func findLinkSuggestions(notes []index.NoteRow) []LinkSuggestion {
 			}
 
 			// Check if already linked (either direction)
-			nameI := strings.TrimSuffix(filepath.Base(notes[i].Path), ".md")
-			nameJ := strings.TrimSuffix(filepath.Base(notes[j].Path), ".md")
-			if existingLinks[notes[i].Path][strings.ToLower(nameJ)] ||
-				existingLinks[notes[j].Path][strings.ToLower(nameI)] {
+			if existingLinks[notes[i].Path][notes[j].Path] ||
+				existingLinks[notes[j].Path][notes[i].Path] {
 				continue
 			}
 
@@ -154,6 +247,7 @@ func findLinkSuggestions(notes []index.NoteRow) []LinkSuggestion {
 					From:       notes[i].Path,
 					To:         notes[j].Path,
 					Similarity: sim,
+					Reason:     linkReason(notes[i], notes[j], sim),
 				})
 				counts[notes[i].Path]++
 				counts[notes[j].Path]++
@@ -171,6 +265,23 @@ func findLinkSuggestions(notes []index.NoteRow) []LinkSuggestion {
 	return suggestions
 }
 
+// linkReason explains a link suggestion: the tags both notes share, or how
+// similar they are.
+func linkReason(a, b index.NoteRow, sim float64) string {
+	var shared []string
+	for _, t := range splitIndexList(a.Tags) {
+		for _, u := range splitIndexList(b.Tags) {
+			if strings.EqualFold(t, u) && !containsFold(shared, t) {
+				shared = append(shared, t)
+			}
+		}
+	}
+	if len(shared) > 0 {
+		return "shares #" + strings.Join(shared, ", #")
+	}
+	return fmt.Sprintf("%.0f%% similar", sim*100)
+}
+
 // findTagSuggestions suggests tags for notes based on consensus from similar notes.
 func findTagSuggestions(notes []index.NoteRow) []TagSuggestion {
 	const threshold = 0.7
@@ -218,6 +329,7 @@ func findTagSuggestions(notes []index.NoteRow) []TagSuggestion {
 		}
 
 		if len(newTags) > 0 {
+			sort.Strings(newTags)
 			suggestions = append(suggestions, TagSuggestion{
 				Note: note.Path,
 				Tags: newTags,
@@ -228,22 +340,18 @@ func findTagSuggestions(notes []index.NoteRow) []TagSuggestion {
 	return suggestions
 }
 
-// findOrphans finds notes with no incoming wikilinks.
+// findOrphans finds notes with no incoming wikilinks, markdown links or embeds.
+// Links are resolved like Obsidian does, so a note counts as linked when any
+// link reaches it by filename, partial or full path, or one of its aliases.
 func findOrphans(notes []index.NoteRow) []string {
+	resolver := resolverFromRows(notes)
+
 	// Build set of all notes that are linked TO
 	linked := make(map[string]bool)
 	for _, n := range notes {
-		if n.Wikilinks == "" {
-			continue
-		}
-		for _, link := range strings.Split(n.Wikilinks, ", ") {
-			link = strings.TrimSpace(link)
-			// Strip heading fragments
-			if idx := strings.Index(link, "#"); idx >= 0 {
-				link = link[:idx]
-			}
-			if link != "" {
-				linked[strings.ToLower(link)] = true
+		for target := range resolvedLinkSet(resolver, n) {
+			if target != n.Path {
+				linked[target] = true
 			}
 		}
 	}
@@ -251,8 +359,7 @@ func findOrphans(notes []index.NoteRow) []string {
 	// Find notes that nobody links to
 	var orphans []string
 	for _, n := range notes {
-		name := strings.TrimSuffix(filepath.Base(n.Path), ".md")
-		if !linked[strings.ToLower(name)] && !linked[strings.ToLower(n.Title)] {
+		if !linked[n.Path] {
 			orphans = append(orphans, n.Path)
 		}
 	}
@@ -260,19 +367,67 @@ func findOrphans(notes []index.NoteRow) []string {
 	return orphans
 }
 
-// applyLinkSuggestions appends suggested wikilinks to notes.
-func applyLinkSuggestions(vaultPath string, suggestions []LinkSuggestion) int {
+// resolverFromRows builds a link resolver from indexed notes and their aliases.
+func resolverFromRows(notes []index.NoteRow) *vault.Resolver {
+	resolver := vault.NewResolver()
+	for _, n := range notes {
+		var aliases []string
+		if n.Aliases != "" {
+			aliases = strings.Split(n.Aliases, ", ")
+		}
+		resolver.AddNoteAliases(n.Path, aliases)
+	}
+	return resolver
+}
+
+// resolvedLinkSet returns the vault paths a note's indexed outgoing links
+// resolve to. Ambiguous links count toward Obsidian's best guess.
+func resolvedLinkSet(resolver *vault.Resolver, n index.NoteRow) map[string]bool {
+	links := make(map[string]bool)
+	if n.Wikilinks == "" {
+		return links
+	}
+	for _, target := range strings.Split(n.Wikilinks, ", ") {
+		res := resolver.ResolveTarget(n.Path, strings.TrimSpace(target))
+		if res.Status != vault.Missing {
+			links[res.Path] = true
+		}
+	}
+	return links
+}
+
+// enrichNextHeadingRe finds the heading that ends the links section.
+var enrichNextHeadingRe = regexp.MustCompile(`\n#{1,6} `)
+
+// applyLinkSuggestions appends suggested wikilinks to both notes of each
+// suggestion, under the format's heading. Returns the number of notes changed.
+func applyLinkSuggestions(vaultPath string, snapshots *noteHistory, suggestions []LinkSuggestion, format enrichFormat) int {
+	// Link text is the shortest path that still resolves uniquely.
+	resolver, err := vault.LoadResolver(vaultPath)
+	if err != nil {
+		return 0
+	}
+
 	// Group suggestions by source note
-	byNote := make(map[string][]string)
+	type pendingLink struct {
+		text, line string
+	}
+	byNote := make(map[string][]pendingLink)
+	var order []string
+	add := func(from, to string, s LinkSuggestion) {
+		if _, ok := byNote[from]; !ok {
+			order = append(order, from)
+		}
+		text := resolver.LinkText(to)
+		byNote[from] = append(byNote[from], pendingLink{text, format.line(text, s, to)})
+	}
 	for _, s := range suggestions {
-		toName := strings.TrimSuffix(filepath.Base(s.To), ".md")
-		byNote[s.From] = append(byNote[s.From], toName)
-		fromName := strings.TrimSuffix(filepath.Base(s.From), ".md")
-		byNote[s.To] = append(byNote[s.To], fromName)
+		add(s.From, s.To, s)
+		add(s.To, s.From, s)
 	}
 
 	applied := 0
-	for notePath, links := range byNote {
+	for _, notePath := range order {
 		fullPath := filepath.Join(vaultPath, notePath)
 		data, err := os.ReadFile(fullPath)
 		if err != nil {
@@ -283,10 +438,9 @@ func applyLinkSuggestions(vaultPath string, suggestions []LinkSuggestion) int {
 
 		// Build the links section
 		var linkLines []string
-		for _, link := range links {
-			wikilink := fmt.Sprintf("- [[%s]]", link)
-			if !strings.Contains(content, "[["+link+"]]") {
-				linkLines = append(linkLines, wikilink)
+		for _, link := range byNote[notePath] {
+			if !strings.Contains(content, "[["+link.text+"]]") && !strings.Contains(content, "[["+link.text+"|") {
+				linkLines = append(linkLines, link.line)
 			}
 		}
 
@@ -294,12 +448,13 @@ func applyLinkSuggestions(vaultPath string, suggestions []LinkSuggestion) int {
 			continue
 		}
 
-		// Append to Related Notes section or add one
+		// Append to the links section or add one
 		newLinks := strings.Join(linkLines, "\n")
-		if idx := strings.Index(content, "## Related Notes"); idx >= 0 {
-			sectionStart := idx + len("## Related Notes")
+		if idx := strings.Index(content, format.Heading); idx >= 0 {
+			sectionStart := idx + len(format.Heading)
 			rest := content[sectionStart:]
-			if nextIdx := strings.Index(rest, "\n##"); nextIdx >= 0 {
+			if loc := enrichNextHeadingRe.FindStringIndex(rest); loc != nil {
+				nextIdx := loc[0]
 				// Insert before the next heading; step back past any blank line
 				insertAt := sectionStart + nextIdx
 				if insertAt > 0 && content[insertAt-1] == '\n' {
@@ -307,12 +462,13 @@ func applyLinkSuggestions(vaultPath string, suggestions []LinkSuggestion) int {
 				}
 				content = content[:insertAt] + "\n" + newLinks + content[insertAt:]
 			} else {
-				content += "\n" + newLinks + "\n"
+				content = strings.TrimRight(content, "\n") + "\n" + newLinks + "\n"
 			}
 		} else {
-			content += "\n## Related Notes\n" + newLinks + "\n"
+			content += "\n" + format.Heading + "\n" + newLinks + "\n"
 		}
 
+		snapshots.snapshot(notePath, "enrich")
 		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
 			continue
 		}
@@ -322,6 +478,86 @@ func applyLinkSuggestions(vaultPath string, suggestions []LinkSuggestion) int {
 	return applied
 }
 
+// applyTagSuggestions adds suggested tags to each note's frontmatter tags.
+// Returns the number of notes changed.
+func applyTagSuggestions(vaultPath string, snapshots *noteHistory, suggestions []TagSuggestion) int {
+	applied := 0
+	for _, s := range suggestions {
+		fullPath := filepath.Join(vaultPath, s.Note)
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			continue
+		}
+		content, changed := addFrontmatterTags(string(data), s.Tags)
+		if !changed {
+			continue
+		}
+		snapshots.snapshot(s.Note, "enrich")
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			continue
+		}
+		applied++
+	}
+	return applied
+}
+
+// addFrontmatterTags adds tags missing from a note's frontmatter tags list,
+// editing only the tags property so the rest of the frontmatter keeps its
+// layout. A scalar or inline tags value becomes a block list; a note without
+// frontmatter gets one.
+func addFrontmatterTags(content string, tags []string) (string, bool) {
+	fm, body, ok := vault.SplitFrontmatter(content)
+	if !ok {
+		fm, body = "", content
+	}
+	existing := extractTagsList(vault.ParseNote(content).Frontmatter)
+	if !ok {
+		existing = nil
+	}
+	var added []string
+	for _, t := range tags {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "#")
+		if t != "" && !containsFold(existing, t) && !containsFold(added, t) {
+			added = append(added, t)
+		}
+	}
+	if len(added) == 0 {
+		return content, false
+	}
+
+	// Drop the current tags property (its line and any list items under it)
+	// and write the merged list in its place.
+	var lines []string
+	at := -1
+	inTags := false
+	for _, line := range strings.Split(fm, "\n") {
+		if inTags {
+			if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "- ") || trimmed == "-" || (trimmed != "" && (line[0] == ' ' || line[0] == '\t')) {
+				continue
+			}
+			inTags = false
+		}
+		if strings.HasPrefix(line, "tags:") {
+			at, inTags = len(lines), true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+	list := []string{"tags:"}
+	for _, t := range append(existing, added...) {
+		list = append(list, "  - "+t)
+	}
+	if at < 0 {
+		at = len(lines)
+	}
+	lines = append(lines[:at], append(list, lines[at:]...)...)
+
+	return "---\n" + strings.Join(lines, "\n") + "\n---\n" + body, true
+}
+
 func printEnrichReport(result EnrichOutput, applied bool) {
 	fmt.Println("Enrichment Report")
 	fmt.Println(strings.Repeat("=", 40))
@@ -331,7 +567,11 @@ func printEnrichReport(result EnrichOutput, applied bool) {
 		for _, s := range result.LinkSuggestions {
 			fromName := strings.TrimSuffix(filepath.Base(s.From), ".md")
 			toName := strings.TrimSuffix(filepath.Base(s.To), ".md")
-			fmt.Printf("  \"%s\" → \"%s\" (similarity: %.2f)\n", fromName, toName, s.Similarity)
+			fmt.Printf("  \"%s\" → \"%s\" (similarity: %.2f", fromName, toName, s.Similarity)
+			if s.Reason != "" {
+				fmt.Printf(", %s", s.Reason)
+			}
+			fmt.Println(")")
 		}
 	}
 
@@ -355,5 +595,14 @@ func printEnrichReport(result EnrichOutput, applied bool) {
 	if applied && result.Summary.Applied > 0 {
 		fmt.Printf(", %d notes updated", result.Summary.Applied)
 	}
+	if result.Summary.TagsApplied > 0 {
+		fmt.Printf(", %d notes tagged", result.Summary.TagsApplied)
+	}
+	if result.Summary.Rejected > 0 {
+		fmt.Printf(", %d rejected", result.Summary.Rejected)
+	}
+	if result.Summary.Hidden > 0 {
+		fmt.Printf(" (%d previously rejected hidden)", result.Summary.Hidden)
+	}
 	fmt.Println()
 }
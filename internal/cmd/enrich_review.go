@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+)
+
+// enrichDecisions holds which suggestions to apply and which to remember as
+// rejected. Tag suggestions are split so each holds the tags for one note
+// that were decided the same way.
+type enrichDecisions struct {
+	Links         []LinkSuggestion
+	Tags          []TagSuggestion
+	RejectedLinks []LinkSuggestion
+	RejectedTags  []TagSuggestion
+}
+
+// linkRejection is the blocklist key of a link suggestion. Links are stored
+// with their paths sorted, so rejecting A → B also hides B → A.
+func linkRejection(from, to string) index.Rejection {
+	if to < from {
+		from, to = to, from
+	}
+	return index.Rejection{Source: from, Target: to}
+}
+
+// tagRejection is the blocklist key of a tag suggestion.
+func tagRejection(note, tag string) index.Rejection {
+	return index.Rejection{Source: note, Target: strings.ToLower(strings.TrimPrefix(tag, "#"))}
+}
+
+// withoutRejected drops suggestions rejected in an earlier review, and
+// returns how many were dropped.
+func withoutRejected(store *index.Store, links []LinkSuggestion, tags []TagSuggestion) ([]LinkSuggestion, []TagSuggestion, int, error) {
+	rejectedLinks, err := store.Rejections(index.RejectLink)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	rejectedTags, err := store.Rejections(index.RejectTag)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	hidden := 0
+	var keptLinks []LinkSuggestion
+	for _, l := range links {
+		if rejectedLinks[linkRejection(l.From, l.To)] {
+			hidden++
+			continue
+		}
+		keptLinks = append(keptLinks, l)
+	}
+	var keptTags []TagSuggestion
+	for _, s := range tags {
+		var kept []string
+		for _, t := range s.Tags {
+			if rejectedTags[tagRejection(s.Note, t)] {
+				hidden++
+				continue
+			}
+			kept = append(kept, t)
+		}
+		if len(kept) > 0 {
+			keptTags = append(keptTags, TagSuggestion{Note: s.Note, Tags: kept})
+		}
+	}
+	return keptLinks, keptTags, hidden, nil
+}
+
+// rejectedLinkSet returns the blocklist of link suggestions, or nil when it
+// cannot be read. Callers that only display suggestions use it to hide the
+// ones the user turned down.
+func rejectedLinkSet(store *index.Store) map[index.Rejection]bool {
+	rejected, err := store.Rejections(index.RejectLink)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	return rejected
+}
+
+// recordRejections adds the rejected suggestions to the blocklist.
+func recordRejections(store *index.Store, d enrichDecisions, now time.Time) error {
+	for _, l := range d.RejectedLinks {
+		r := linkRejection(l.From, l.To)
+		if err := store.RejectSuggestion(index.RejectLink, r.Source, r.Target, now); err != nil {
+			return err
+		}
+	}
+	for _, s := range d.RejectedTags {
+		for _, t := range s.Tags {
+			r := tagRejection(s.Note, t)
+			if err := store.RejectSuggestion(index.RejectTag, r.Source, r.Target, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// countTags returns the number of tags across suggestions.
+func countTags(suggestions []TagSuggestion) int {
+	n := 0
+	for _, s := range suggestions {
+		n += len(s.Tags)
+	}
+	return n
+}
+
+// addTag appends tag to note's suggestion in list, creating it if needed.
+func addTag(list []TagSuggestion, note, tag string) []TagSuggestion {
+	for i := range list {
+		if list[i].Note == note {
+			list[i].Tags = append(list[i].Tags, tag)
+			return list
+		}
+	}
+	return append(list, TagSuggestion{Note: note, Tags: []string{tag}})
+}
+
+// acceptLine is one decision from an accept file.
+type acceptLine struct {
+	Line   int
+	Accept bool
+	From   string // the note
+	To     string // the linked note, for link decisions
+	Tag    string // the tag, for tag decisions
+}
+
+// parseAcceptFile reads decisions, one per line:
+//
+//	accept Notes/a.md -> Notes/b.md
+//	reject Notes/a.md -> Notes/c.md
+//	accept Notes/a.md #tag
+//	reject Notes/a.md #tag
+//
+// Blank lines and lines starting with # are ignored. Paths may leave out .md.
+func parseAcceptFile(path string) ([]acceptLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accept file: %w", err)
+	}
+	defer f.Close()
+
+	var lines []acceptLine
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		verb, rest, _ := strings.Cut(text, " ")
+		d := acceptLine{Line: n}
+		switch strings.ToLower(verb) {
+		case "accept":
+			d.Accept = true
+		case "reject":
+		default:
+			return nil, fmt.Errorf("%s:%d: expected accept or reject, got %q", path, n, verb)
+		}
+		rest = strings.TrimSpace(rest)
+		if from, to, ok := strings.Cut(rest, "->"); ok {
+			d.From, d.To = notePath(from), notePath(to)
+		} else if i := strings.LastIndex(rest, " #"); i >= 0 {
+			d.From, d.Tag = notePath(rest[:i]), strings.TrimSpace(rest[i+2:])
+		}
+		if d.From == "" || (d.To == "" && d.Tag == "") {
+			return nil, fmt.Errorf("%s:%d: expected \"<note> -> <note>\" or \"<note> #tag\"", path, n)
+		}
+		lines = append(lines, d)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read accept file: %w", err)
+	}
+	return lines, nil
+}
+
+// notePath normalizes a path from an accept file.
+func notePath(s string) string {
+	s = filepath.ToSlash(strings.TrimSpace(s))
+	if s != "" && !strings.HasSuffix(s, ".md") {
+		s += ".md"
+	}
+	return s
+}
+
+// decideFromAcceptFile matches accept-file decisions against the current
+// suggestions. Decisions for pairs that are no longer suggested are skipped
+// with a warning, so a stale file cannot add arbitrary links.
+func decideFromAcceptFile(lines []acceptLine, links []LinkSuggestion, tags []TagSuggestion) enrichDecisions {
+	linkByKey := make(map[index.Rejection]LinkSuggestion, len(links))
+	for _, l := range links {
+		linkByKey[linkRejection(l.From, l.To)] = l
+	}
+	suggestedTag := make(map[index.Rejection]string)
+	for _, s := range tags {
+		for _, t := range s.Tags {
+			suggestedTag[tagRejection(s.Note, t)] = t
+		}
+	}
+
+	var d enrichDecisions
+	seenLinks := make(map[index.Rejection]bool)
+	seenTags := make(map[index.Rejection]bool)
+	for _, line := range lines {
+		if line.To != "" {
+			key := linkRejection(line.From, line.To)
+			l, ok := linkByKey[key]
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Warning: line %d: %s -> %s is not a current suggestion\n", line.Line, line.From, line.To)
+				continue
+			}
+			if seenLinks[key] {
+				continue
+			}
+			seenLinks[key] = true
+			if line.Accept {
+				d.Links = append(d.Links, l)
+			} else {
+				d.RejectedLinks = append(d.RejectedLinks, l)
+			}
+			continue
+		}
+
+		key := tagRejection(line.From, line.Tag)
+		tag, ok := suggestedTag[key]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Warning: line %d: #%s for %s is not a current suggestion\n", line.Line, line.Tag, line.From)
+			continue
+		}
+		if seenTags[key] {
+			continue
+		}
+		seenTags[key] = true
+		if line.Accept {
+			d.Tags = addTag(d.Tags, line.From, tag)
+		} else {
+			d.RejectedTags = addTag(d.RejectedTags, line.From, tag)
+		}
+	}
+	return d
+}
+
+// enrichSession is an interactive review of enrich suggestions.
+type enrichSession struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prompts until the user picks accept, reject, skip or quit, and returns
+// the key. End of input counts as quit.
+func (s *enrichSession) ask() string {
+	for {
+		fmt.Fprint(s.out, "[a]ccept [r]eject [s]kip [q]uit: ")
+		line, err := s.in.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(s.out)
+			return "q"
+		}
+		switch key := strings.ToLower(strings.TrimSpace(line)); key {
+		case "a", "r", "s", "q":
+			return key
+		}
+	}
+}
+
+// interactiveEnrich asks about each link suggestion, then each suggested
+// tag, and collects the answers.
+func interactiveEnrich(s *enrichSession, links []LinkSuggestion, tags []TagSuggestion) enrichDecisions {
+	var d enrichDecisions
+	total := len(links) + countTags(tags)
+	n := 0
+	for _, l := range links {
+		n++
+		fmt.Fprintf(s.out, "\n[%d/%d] link %s ↔ %s (%.2f", n, total, l.From, l.To, l.Similarity)
+		if l.Reason != "" {
+			fmt.Fprintf(s.out, ", %s", l.Reason)
+		}
+		fmt.Fprintln(s.out, ")")
+		switch s.ask() {
+		case "a":
+			d.Links = append(d.Links, l)
+		case "r":
+			d.RejectedLinks = append(d.RejectedLinks, l)
+		case "q":
+			return d
+		}
+	}
+	for _, t := range tags {
+		for _, tag := range t.Tags {
+			n++
+			fmt.Fprintf(s.out, "\n[%d/%d] tag #%s for %s\n", n, total, tag, t.Note)
+			switch s.ask() {
+			case "a":
+				d.Tags = addTag(d.Tags, t.Note, tag)
+			case "r":
+				d.RejectedTags = addTag(d.RejectedTags, t.Note, tag)
+			case "q":
+				return d
+			}
+		}
+	}
+	return d
+}
+
+// printEnrichReviewSummary reports what an interactive review changed.
+func printEnrichReviewSummary(summary EnrichSummary) {
+	fmt.Printf("\nReview done: %d notes linked, %d notes tagged, %d suggestions rejected\n",
+		summary.Applied, summary.TagsApplied, summary.Rejected)
+}
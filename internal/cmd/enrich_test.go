@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
 )
 
 func TestApplyLinkSuggestions_InsertsBeforeNextHeading(t *testing.T) {
@@ -30,7 +35,7 @@ func TestApplyLinkSuggestions_InsertsBeforeNextHeading(t *testing.T) {
 		{From: noteA, To: noteB, Similarity: 0.9},
 	}
 
-	applied := applyLinkSuggestions(dir, suggestions)
+	applied := applyLinkSuggestions(dir, newNoteHistory(dir), suggestions, enrichFormat{Heading: defaultEnrichHeading, Link: defaultEnrichLinkFormat})
 	if applied != 2 {
 		t.Fatalf("expected 2 applied, got %d", applied)
 	}
@@ -56,3 +61,146 @@ func TestApplyLinkSuggestions_InsertsBeforeNextHeading(t *testing.T) {
 		t.Errorf("note B content mismatch:\ngot:  %q\nwant: %q", string(gotB), wantB)
 	}
 }
+
+func TestFindOrphans_AliasesAndPaths(t *testing.T) {
+	notes := []index.NoteRow{
+		{Path: "Notes/go.md", Title: "Go", Aliases: "golang, Go Lang", Wikilinks: "Projects/plan"},
+		{Path: "Projects/plan.md", Title: "Plan", Wikilinks: "golang#Concurrency"},
+		{Path: "Notes/lonely.md", Title: "Lonely"},
+	}
+
+	got := findOrphans(notes)
+	if len(got) != 1 || got[0] != "Notes/lonely.md" {
+		t.Errorf("findOrphans() = %v, want [Notes/lonely.md]", got)
+	}
+}
+
+// writeEnrichVault builds an indexed vault of three notes where every pair
+// is similar enough to be suggested, and the first two share a tag.
+func writeEnrichVault(t *testing.T) (string, *index.Store) {
+	t.Helper()
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/a.md": "---\ntags: [db]\n---\nA.\n",
+		"Notes/b.md": "---\ntags:\n  - db\n---\nB.\n",
+		"Notes/c.md": "C.\n",
+	})
+	if err := os.MkdirAll(filepath.Join(dir, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	for _, row := range []index.NoteRow{
+		{Path: "Notes/a.md", Title: "a", Tags: "db", Body: "A.", Embedding: []float32{1, 0}},
+		{Path: "Notes/b.md", Title: "b", Tags: "db", Body: "B.", Embedding: []float32{0.9, 0.1}},
+		{Path: "Notes/c.md", Title: "c", Body: "C.", Embedding: []float32{0.8, 0.2}},
+	} {
+		if err := store.UpsertNote(&row); err != nil {
+			t.Fatalf("UpsertNote: %v", err)
+		}
+	}
+	return dir, store
+}
+
+func runEnrich(t *testing.T, dir string, opts EnrichOptions) EnrichOutput {
+	t.Helper()
+	opts.JSONOutput = true
+	out := captureStdout(t, func() {
+		if err := EnrichCmd(dir, opts); err != nil {
+			t.Fatalf("EnrichCmd() error: %v", err)
+		}
+	})
+	var result EnrichOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	return result
+}
+
+func TestEnrichCmd_AcceptFileAndRejections(t *testing.T) {
+	dir, _ := writeEnrichVault(t)
+
+	first := runEnrich(t, dir, EnrichOptions{})
+	if first.Summary.LinksFound != 3 {
+		t.Fatalf("links = %+v", first.LinkSuggestions)
+	}
+	if r := first.LinkSuggestions[0].Reason; r != "shares #db" {
+		t.Errorf("reason = %q", r)
+	}
+
+	decisions := filepath.Join(t.TempDir(), "decisions.txt")
+	if err := os.WriteFile(decisions, []byte("# reviewed\naccept Notes/a -> Notes/b\nreject Notes/c.md -> Notes/a.md\naccept Notes/x -> Notes/y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := runEnrich(t, dir, EnrichOptions{AcceptFile: decisions, LinkFormat: "- {link} ({reason})"})
+	if result.Summary.Applied != 2 || result.Summary.Rejected != 1 {
+		t.Errorf("summary = %+v", result.Summary)
+	}
+	if got := mustRead(t, filepath.Join(dir, "Notes/a.md")); !strings.HasSuffix(got, "\n## Related Notes\n- [[b]] (shares #db)\n") {
+		t.Errorf("a.md = %q", got)
+	}
+	if got := mustRead(t, filepath.Join(dir, "Notes/c.md")); got != "C.\n" {
+		t.Errorf("c.md changed: %q", got)
+	}
+
+	// The rejected pair is remembered in either direction.
+	again := runEnrich(t, dir, EnrichOptions{})
+	for _, s := range again.LinkSuggestions {
+		if linkRejection(s.From, s.To) == linkRejection("Notes/a.md", "Notes/c.md") {
+			t.Errorf("rejected pair suggested again: %+v", s)
+		}
+	}
+	if again.Summary.Hidden != 1 {
+		t.Errorf("hidden = %d, want 1", again.Summary.Hidden)
+	}
+}
+
+func TestEnrichCmd_ApplyTagsAndHeading(t *testing.T) {
+	dir, _ := writeEnrichVault(t)
+	result := runEnrich(t, dir, EnrichOptions{Apply: true, Tags: true, Heading: "See also"})
+	if result.Summary.TagsApplied != 1 {
+		t.Fatalf("summary = %+v, tags = %+v", result.Summary, result.TagSuggestions)
+	}
+	got := mustRead(t, filepath.Join(dir, "Notes/c.md"))
+	if want := "---\ntags:\n  - db\n---\nC.\n\n## See also\n- [[b]]\n- [[a]]\n"; got != want {
+		t.Errorf("c.md = %q, want %q", got, want)
+	}
+}
+
+func TestAddFrontmatterTags(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"block", "---\ntitle: T\ntags:\n  - a\nstatus: x\n---\nBody\n", "---\ntitle: T\ntags:\n  - a\n  - b\nstatus: x\n---\nBody\n"},
+		{"inline", "---\ntags: [a]\n---\nBody\n", "---\ntags:\n  - a\n  - b\n---\nBody\n"},
+		{"scalar", "---\ntags: a\ntitle: T\n---\nBody\n", "---\ntags:\n  - a\n  - b\ntitle: T\n---\nBody\n"},
+		{"missing", "---\ntitle: T\n---\nBody\n", "---\ntitle: T\ntags:\n  - b\n---\nBody\n"},
+		{"no frontmatter", "Body\n", "---\ntags:\n  - b\n---\nBody\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := addFrontmatterTags(tt.in, []string{"#b"})
+			if !changed || got != tt.want {
+				t.Errorf("addFrontmatterTags() = %q, %v; want %q", got, changed, tt.want)
+			}
+		})
+	}
+	if _, changed := addFrontmatterTags("---\ntags: [B]\n---\n", []string{"b"}); changed {
+		t.Error("existing tag added again")
+	}
+}
+
+func TestParseAcceptFile_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "d.txt")
+	for _, content := range []string{"maybe a -> b\n", "accept a\n"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parseAcceptFile(path); err == nil {
+			t.Errorf("parseAcceptFile(%q) succeeded", content)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+const (
+	// autoRepairConfidence is the confidence at or above which a link repair
+	// is applied without asking.
+	autoRepairConfidence = 0.85
+	// minRepairScore is the lowest candidate score worth proposing.
+	minRepairScore = 0.5
+	// embeddingRepairWeight scales cosine similarity so that semantic evidence
+	// alone never reaches autoRepairConfidence.
+	embeddingRepairWeight = 0.8
+	// minRepairSimilarity is the cosine similarity below which embedding
+	// neighbours are ignored.
+	minRepairSimilarity = 0.6
+	// repairContextLines is the number of lines on each side of a broken link
+	// used as its surrounding text.
+	repairContextLines = 2
+)
+
+// Link repair statuses.
+const (
+	RepairApplied  = "applied"  // rewritten in the source note
+	RepairProposed = "proposed" // dry run or non-interactive: left for review
+	RepairSkipped  = "skipped"  // declined interactively
+	RepairNoMatch  = "no-match" // no candidate scored above minRepairScore
+	RepairFailed   = "failed"   // the note could not be rewritten
+)
+
+// LinkRepair is a proposed or applied fix for one broken link.
+type LinkRepair struct {
+	Source        string   `json:"source"`
+	Line          int      `json:"line,omitempty"`
+	Target        string   `json:"target"`                   // broken target as written, with fragment
+	Suggestion    string   `json:"suggestion,omitempty"`     // replacement link target
+	SuggestedPath string   `json:"suggested_path,omitempty"` // vault path of the suggested note
+	Confidence    float64  `json:"confidence"`
+	Methods       []string `json:"methods,omitempty"` // evidence: filename, alias, embedding
+	Status        string   `json:"status"`
+}
+
+// repairCandidate accumulates evidence that a broken link meant a given note.
+type repairCandidate struct {
+	path    string
+	score   float64
+	methods []string
+}
+
+// linkRepairer proposes repairs for broken links.
+type linkRepairer struct {
+	resolver *vault.Resolver
+	notes    []string            // vault paths of every note
+	aliases  map[string][]string // note path -> aliases
+	rows     []index.NoteRow     // indexed notes with embeddings
+	embed    func(text string) ([]float32, error)
+}
+
+// fixBrokenLinks proposes a repair for every link whose target note does not
+// exist. High-confidence repairs are applied; the rest are confirmed
+// interactively when stdin is a terminal, and reported as proposed otherwise.
+func fixBrokenLinks(vaultPath string, loaded []maintainNote, resolver *vault.Resolver, store *index.Store, opts MaintainOptions) []LinkRepair {
+	r := &linkRepairer{
+		resolver: resolver,
+		aliases:  make(map[string][]string),
+	}
+	for _, n := range loaded {
+		r.notes = append(r.notes, n.info.Path)
+		r.aliases[n.info.Path] = n.parsed.Aliases
+	}
+
+	// Semantic candidates need both the index and an embedding API key.
+	if store != nil {
+		client := index.NewEmbeddingClient(config.ResolveAPIKey())
+		if client.IsAvailable() {
+			if rows, err := store.GetAllNoteRows(); err == nil {
+				r.rows = rows
+				r.embed = func(text string) ([]float32, error) {
+					return client.Embed(context.Background(), text)
+				}
+			}
+		}
+	}
+
+	var prompt func(LinkRepair) bool
+	if !opts.JSONOutput && !opts.DryRun && stdinIsTerminal() {
+		reader := bufio.NewReader(os.Stdin)
+		prompt = func(rep LinkRepair) bool { return confirmLinkRepair(reader, rep) }
+	}
+
+	var repairs []LinkRepair
+	for _, n := range loaded {
+		lines := strings.Split(n.content, "\n")
+		var accepted []LinkRepair
+		var acceptedLinks []vault.Link
+
+		for _, link := range noteLinks(n.parsed) {
+			if link.Target == "" || resolver.Resolve(n.info.Path, link).Status != vault.Missing {
+				continue
+			}
+			rep := r.propose(n.info.Path, link, linkContext(lines, link.Line))
+			switch {
+			case rep.Suggestion == "":
+				rep.Status = RepairNoMatch
+			case opts.DryRun:
+				rep.Status = RepairProposed
+			case rep.Confidence >= autoRepairConfidence:
+				rep.Status = RepairApplied
+			case prompt == nil:
+				rep.Status = RepairProposed
+			case prompt(rep):
+				rep.Status = RepairApplied
+			default:
+				rep.Status = RepairSkipped
+			}
+			if rep.Status == RepairApplied {
+				accepted = append(accepted, rep)
+				acceptedLinks = append(acceptedLinks, link)
+			}
+			repairs = append(repairs, rep)
+		}
+
+		if len(accepted) == 0 {
+			continue
+		}
+		if err := applyLinkRepairs(vaultPath, n.info.Path, n.content, accepted, acceptedLinks); err != nil {
+			for i := range repairs {
+				if repairs[i].Source == n.info.Path && repairs[i].Status == RepairApplied {
+					repairs[i].Status = RepairFailed
+				}
+			}
+		}
+	}
+	return repairs
+}
+
+// propose scores every note as a replacement for a broken link using fuzzy
+// filename matching, alias matching and the embedding nearest neighbours of
+// the surrounding text, and returns the best candidate.
+func (r *linkRepairer) propose(source string, link vault.Link, surrounding string) LinkRepair {
+	rep := LinkRepair{
+		Source: source,
+		Line:   link.Line,
+		Target: linkDisplayTarget(link),
+	}
+
+	candidates := make(map[string]*repairCandidate)
+	add := func(notePath, method string, score float64) {
+		if score < minRepairScore || notePath == source {
+			return
+		}
+		c, ok := candidates[notePath]
+		if !ok {
+			c = &repairCandidate{path: notePath}
+			candidates[notePath] = c
+		}
+		if score > c.score {
+			c.score = score
+		}
+		if !containsStr(c.methods, method) {
+			c.methods = append(c.methods, method)
+		}
+	}
+
+	want := normalizeLinkName(link.Target)
+	for _, p := range r.notes {
+		add(p, "filename", nameSimilarity(want, normalizeLinkName(p)))
+		for _, alias := range r.aliases[p] {
+			add(p, "alias", nameSimilarity(want, normalizeLinkName(alias)))
+		}
+	}
+
+	if r.embed != nil && strings.TrimSpace(surrounding) != "" {
+		if emb, err := r.embed(surrounding); err == nil {
+			for _, row := range r.rows {
+				if row.Embedding == nil {
+					continue
+				}
+				sim := float64(index.CosineSimilarity(emb, row.Embedding))
+				if sim >= minRepairSimilarity {
+					add(row.Path, "embedding", sim*embeddingRepairWeight)
+				}
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return rep
+	}
+
+	ranked := make([]*repairCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		// Independent signals agreeing on the same note add confidence.
+		c.score += 0.05 * float64(len(c.methods)-1)
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].path < ranked[j].path
+	})
+
+	best := ranked[0]
+	confidence := best.score
+	// A close runner-up means the choice is a coin toss.
+	if len(ranked) > 1 {
+		if gap := best.score - ranked[1].score; gap < 0.1 {
+			confidence -= 0.1 - gap
+		}
+	}
+	confidence = min(confidence, 0.99)
+
+	rep.SuggestedPath = best.path
+	rep.Suggestion = repairTargetText(r.resolver, link, best.path)
+	rep.Confidence = float64(int(confidence*100+0.5)) / 100
+	rep.Methods = best.methods
+	return rep
+}
+
+// repairTargetText returns the text that replaces a broken link's target:
+// the shortest unique link text for wikilinks, the vault path for markdown links.
+func repairTargetText(resolver *vault.Resolver, link vault.Link, notePath string) string {
+	if link.Kind == vault.LinkMarkdown {
+		return filepath.ToSlash(notePath)
+	}
+	return resolver.LinkText(notePath)
+}
+
+// applyLinkRepairs rewrites the accepted links in a single note.
+func applyLinkRepairs(vaultPath, notePath, content string, repairs []LinkRepair, links []vault.Link) error {
+	updated, n := vault.RewriteLinks(content, func(l vault.Link) (string, bool) {
+		for i, want := range links {
+			if l.Line == want.Line && l.Kind == want.Kind && l.Target == want.Target {
+				return repairs[i].Suggestion, true
+			}
+		}
+		return "", false
+	})
+	if n == 0 {
+		return fmt.Errorf("no links rewritten in %s", notePath)
+	}
+	snapshotNote(vaultPath, notePath, "maintain --fix-links")
+	return os.WriteFile(filepath.Join(vaultPath, notePath), []byte(updated), 0644)
+}
+
+// linkContext returns the text around a 1-based line, used to find notes
+// semantically related to what a broken link was about.
+func linkContext(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	from := max(line-1-repairContextLines, 0)
+	to := min(line+repairContextLines, len(lines))
+	return strings.Join(lines[from:to], "\n")
+}
+
+var linkNameSepRe = regexp.MustCompile(`[\s\-_.]+`)
+
+// normalizeLinkName reduces a link target, filename or alias to comparable
+// words: basename only, no .md, lowercase, separators collapsed to spaces.
+func normalizeLinkName(s string) string {
+	s = strings.TrimSuffix(path.Base(filepath.ToSlash(s)), ".md")
+	s = linkNameSepRe.ReplaceAllString(strings.ToLower(s), " ")
+	return strings.TrimSpace(s)
+}
+
+// nameSimilarity returns 1 - editDistance/maxLen for two normalized names.
+func nameSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance computes the Levenshtein distance between two rune slices.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// confirmLinkRepair asks whether to apply a low-confidence repair.
+func confirmLinkRepair(reader *bufio.Reader, rep LinkRepair) bool {
+	fmt.Printf("%s:%d [[%s]] -> [[%s]] (confidence %.2f, %s). Apply? [y/N]: ",
+		rep.Source, rep.Line, rep.Target, rep.Suggestion, rep.Confidence, strings.Join(rep.Methods, "+"))
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+func printLinkRepairReport(repairs []LinkRepair, dryRun bool) {
+	if len(repairs) == 0 {
+		fmt.Println("\nLink Repairs: no broken note links")
+		return
+	}
+
+	applied := 0
+	fmt.Printf("\nLink Repairs: %d\n", len(repairs))
+	for _, rep := range repairs {
+		source := rep.Source
+		if rep.Line > 0 {
+			source = fmt.Sprintf("%s:%d", rep.Source, rep.Line)
+		}
+		if rep.Status == RepairNoMatch {
+			fmt.Printf("  - %s [[%s]]: no match\n", source, rep.Target)
+			continue
+		}
+		if rep.Status == RepairApplied {
+			applied++
+		}
+		fmt.Printf("  - %s [[%s]] -> [[%s]] %.2f (%s) %s\n",
+			source, rep.Target, rep.Suggestion, rep.Confidence, strings.Join(rep.Methods, "+"), rep.Status)
+	}
+	if dryRun {
+		fmt.Println("\nDry run: no links rewritten.")
+		return
+	}
+	fmt.Printf("\nRepaired: %d links\n", applied)
+}
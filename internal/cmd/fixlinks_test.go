@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+func writeVaultFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		full := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"kitten", "sitting", 3},
+		{"golang", "golang", 0},
+		{"meeting", "meetings", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLinkRepairer_Propose(t *testing.T) {
+	resolver := vault.NewResolver()
+	resolver.AddNoteAliases("Notes/error-handling.md", nil)
+	resolver.AddNoteAliases("Notes/k8s.md", []string{"Kubernetes"})
+	resolver.AddNoteAliases("Notes/concurrency.md", nil)
+
+	r := &linkRepairer{
+		resolver: resolver,
+		notes:    []string{"Notes/error-handling.md", "Notes/k8s.md", "Notes/concurrency.md"},
+		aliases:  map[string][]string{"Notes/k8s.md": {"Kubernetes"}},
+	}
+
+	// Typo in the filename: high confidence.
+	rep := r.propose("a.md", vault.Link{Kind: vault.LinkWiki, Target: "Error Handeling"}, "")
+	if rep.SuggestedPath != "Notes/error-handling.md" || rep.Confidence < autoRepairConfidence {
+		t.Errorf("typo repair = %+v, want error-handling with high confidence", rep)
+	}
+
+	// Misspelled alias.
+	rep = r.propose("a.md", vault.Link{Kind: vault.LinkWiki, Target: "Kubernets"}, "")
+	if rep.SuggestedPath != "Notes/k8s.md" || !containsStr(rep.Methods, "alias") {
+		t.Errorf("alias repair = %+v, want k8s via alias", rep)
+	}
+
+	// Nothing close.
+	rep = r.propose("a.md", vault.Link{Kind: vault.LinkWiki, Target: "Quantum Gardening"}, "")
+	if rep.Suggestion != "" {
+		t.Errorf("unrelated target got suggestion %+v", rep)
+	}
+}
+
+func TestLinkRepairer_ProposeEmbedding(t *testing.T) {
+	r := &linkRepairer{
+		resolver: vault.NewResolver(),
+		notes:    []string{"Notes/threads.md", "Notes/cooking.md"},
+		rows: []index.NoteRow{
+			{Path: "Notes/threads.md", Embedding: []float32{1, 0}},
+			{Path: "Notes/cooking.md", Embedding: []float32{0, 1}},
+		},
+		embed: func(string) ([]float32, error) { return []float32{1, 0.1}, nil },
+	}
+	r.resolver.AddNoteAliases("Notes/threads.md", nil)
+
+	rep := r.propose("a.md", vault.Link{Kind: vault.LinkWiki, Target: "Parallelism"}, "goroutines and [[Parallelism]]")
+	if rep.SuggestedPath != "Notes/threads.md" {
+		t.Fatalf("embedding repair = %+v, want threads", rep)
+	}
+	// Semantic evidence alone must not be applied without asking.
+	if rep.Confidence >= autoRepairConfidence {
+		t.Errorf("embedding-only confidence = %.2f, want below %.2f", rep.Confidence, autoRepairConfidence)
+	}
+}
+
+func TestMaintainCmd_FixLinks(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/error-handling.md": "---\ntitle: Errors\n---\n# Errors\n",
+		"Notes/source.md":         "---\ntitle: Source\n---\nSee [[Error Handeling#Wrapping|errors]] and [[Unrelated Topic]].\n",
+	})
+
+	out := captureStdout(t, func() {
+		if err := MaintainCmd(dir, MaintainOptions{StaleDays: 30, FixLinks: true, JSONOutput: true}); err != nil {
+			t.Fatalf("MaintainCmd() error: %v", err)
+		}
+	})
+	if !strings.Contains(out, `"status": "applied"`) || !strings.Contains(out, `"status": "no-match"`) {
+		t.Errorf("unexpected repair report:\n%s", out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Notes/source.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "See [[error-handling#Wrapping|errors]] and [[Unrelated Topic]]."
+	if !strings.Contains(string(data), want) {
+		t.Errorf("source note not repaired:\n%s", data)
+	}
+}
+
+func TestMaintainCmd_FixLinksDryRun(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/error-handling.md": "# Errors\n",
+		"Notes/source.md":         "See [[error handlng]].\n",
+	})
+
+	captureStdout(t, func() {
+		if err := MaintainCmd(dir, MaintainOptions{StaleDays: 30, FixLinks: true, DryRun: true, JSONOutput: true}); err != nil {
+			t.Fatalf("MaintainCmd() error: %v", err)
+		}
+	})
+	data, _ := os.ReadFile(filepath.Join(dir, "Notes/source.md"))
+	if string(data) != "See [[error handlng]].\n" {
+		t.Errorf("dry run modified the note:\n%s", data)
+	}
+}
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/graph"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+)
+
+// Defaults for the graph command.
+const (
+	graphDefaultSemanticThreshold = 0.80
+	graphDefaultTop               = 10
+	graphCommunityHubs            = 3
+)
+
+// GraphOptions controls the graph command.
+type GraphOptions struct {
+	Action            string  // "stats" (default) or "export"
+	Semantic          bool    // add edges between notes with similar embeddings
+	SemanticThreshold float64 // minimum embedding similarity for a semantic edge (default 0.80)
+	Top               int     // stats: notes and communities listed (default 10)
+	Format            string  // export: graphml (default), dot or json
+	Output            string  // export: file to write instead of stdout
+	JSONOutput        bool
+}
+
+// GraphNodeStats holds the measures of one note.
+type GraphNodeStats struct {
+	Path        string  `json:"path"`
+	Title       string  `json:"title"`
+	InDegree    int     `json:"in_degree"`
+	OutDegree   int     `json:"out_degree"`
+	PageRank    float64 `json:"pagerank"`
+	Betweenness float64 `json:"betweenness"`
+	Community   int     `json:"community"`
+}
+
+// GraphCommunity summarizes a community of closely linked notes.
+type GraphCommunity struct {
+	ID    int      `json:"id"`
+	Size  int      `json:"size"`
+	Label string   `json:"label,omitempty"` // tag most members share
+	Hubs  []string `json:"hubs"`            // members with the highest PageRank
+}
+
+// GraphStatsOutput represents the JSON output format for graph stats.
+type GraphStatsOutput struct {
+	Notes            int              `json:"notes"`
+	LinkEdges        int              `json:"link_edges"`
+	SemanticEdges    int              `json:"semantic_edges"`
+	Density          float64          `json:"density"` // link edges over possible directed links
+	Components       int              `json:"components"`
+	LargestComponent int              `json:"largest_component"`
+	Isolated         int              `json:"isolated"`
+	TopPageRank      []GraphNodeStats `json:"top_pagerank"`
+	TopBetweenness   []GraphNodeStats `json:"top_betweenness"`
+	TopInDegree      []GraphNodeStats `json:"top_in_degree"`
+	TopOutDegree     []GraphNodeStats `json:"top_out_degree"`
+	Communities      []GraphCommunity `json:"communities"`
+}
+
+// GraphExportOutput represents the JSON output format for graph export to a
+// file.
+type GraphExportOutput struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	Nodes  int    `json:"nodes"`
+	Edges  int    `json:"edges"`
+}
+
+// GraphCmd analyzes or exports the vault's link graph.
+func GraphCmd(vaultPath string, opts GraphOptions) error {
+	switch opts.Action {
+	case "", "stats", "export":
+	default:
+		return fmt.Errorf("unknown graph action %q (want stats or export)", opts.Action)
+	}
+	if opts.SemanticThreshold < 0 || opts.SemanticThreshold > 1 {
+		return fmt.Errorf("--threshold must be between 0 and 1, got %v", opts.SemanticThreshold)
+	}
+
+	dbPath := index.IndexDBPath(vaultPath)
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("index not found — run 'obsidian index' first")
+	}
+
+	store, err := index.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer store.Close()
+
+	rows, err := store.GetAllNoteRows()
+	if err != nil {
+		return fmt.Errorf("failed to load notes: %w", err)
+	}
+
+	threshold := opts.SemanticThreshold
+	if threshold == 0 {
+		threshold = graphDefaultSemanticThreshold
+	}
+	g := buildNoteGraph(rows, opts.Semantic, threshold)
+	m := graph.Analyze(g)
+
+	if opts.Action == "export" {
+		return graphExport(g, m, opts)
+	}
+
+	result := graphStats(g, m, opts.Top)
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+	printGraphStats(result)
+	return nil
+}
+
+// buildNoteGraph builds the graph of indexed notes, ordered by path. Each
+// resolved wikilink to another note is an edge; with semantic, so is each
+// unlinked pair of notes whose embeddings are at least threshold similar.
+func buildNoteGraph(rows []index.NoteRow, semantic bool, threshold float64) *graph.Graph {
+	rows = append([]index.NoteRow(nil), rows...)
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Path < rows[j].Path })
+
+	g := &graph.Graph{}
+	pos := make(map[string]int, len(rows))
+	for i, r := range rows {
+		pos[r.Path] = i
+		g.Nodes = append(g.Nodes, graph.Node{
+			Path:  r.Path,
+			Title: firstNonEmpty(r.Title, strings.TrimSuffix(filepath.Base(r.Path), ".md")),
+			Tags:  splitIndexList(r.Tags),
+		})
+	}
+
+	resolver := resolverFromRows(rows)
+	linked := make(map[[2]int]bool)
+	for i, r := range rows {
+		var targets []int
+		for p := range resolvedLinkSet(resolver, r) {
+			if j, ok := pos[p]; ok && j != i {
+				targets = append(targets, j)
+			}
+		}
+		sort.Ints(targets)
+		for _, j := range targets {
+			g.Edges = append(g.Edges, graph.Edge{From: i, To: j, Kind: graph.EdgeLink, Weight: 1})
+			linked[[2]int{min(i, j), max(i, j)}] = true
+		}
+	}
+
+	if semantic {
+		for i := range rows {
+			if rows[i].Embedding == nil {
+				continue
+			}
+			for j := i + 1; j < len(rows); j++ {
+				if rows[j].Embedding == nil || linked[[2]int{i, j}] {
+					continue
+				}
+				if sim := float64(index.CosineSimilarity(rows[i].Embedding, rows[j].Embedding)); sim >= threshold {
+					g.Edges = append(g.Edges, graph.Edge{From: i, To: j, Kind: graph.EdgeSemantic, Weight: sim})
+				}
+			}
+		}
+	}
+	return g
+}
+
+// graphStats summarizes the graph's measures, listing top notes of each.
+func graphStats(g *graph.Graph, m graph.Metrics, top int) GraphStatsOutput {
+	if top <= 0 {
+		top = graphDefaultTop
+	}
+	n := len(g.Nodes)
+	result := GraphStatsOutput{
+		Notes:          n,
+		Components:     len(m.Components),
+		TopPageRank:    []GraphNodeStats{},
+		TopBetweenness: []GraphNodeStats{},
+		TopInDegree:    []GraphNodeStats{},
+		TopOutDegree:   []GraphNodeStats{},
+		Communities:    []GraphCommunity{},
+	}
+	for _, e := range g.Edges {
+		if e.Kind == graph.EdgeSemantic {
+			result.SemanticEdges++
+		} else {
+			result.LinkEdges++
+		}
+	}
+	if n > 1 {
+		result.Density = float64(result.LinkEdges) / float64(n*(n-1))
+	}
+	if len(m.Components) > 0 {
+		result.LargestComponent = len(m.Components[0])
+	}
+	for _, c := range m.Components {
+		if len(c) == 1 {
+			result.Isolated++
+		}
+	}
+
+	stats := func(i int) GraphNodeStats {
+		return GraphNodeStats{
+			Path:        g.Nodes[i].Path,
+			Title:       g.Nodes[i].Title,
+			InDegree:    m.InDegree[i],
+			OutDegree:   m.OutDegree[i],
+			PageRank:    m.PageRank[i],
+			Betweenness: m.Betweenness[i],
+			Community:   m.Community[i],
+		}
+	}
+	// topBy lists the notes with the highest positive score, ties by path.
+	topBy := func(score func(i int) float64) []GraphNodeStats {
+		order := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			if score(i) > 0 {
+				order = append(order, i)
+			}
+		}
+		sort.SliceStable(order, func(a, b int) bool { return score(order[a]) > score(order[b]) })
+		out := []GraphNodeStats{}
+		for _, i := range order[:min(top, len(order))] {
+			out = append(out, stats(i))
+		}
+		return out
+	}
+	result.TopPageRank = topBy(func(i int) float64 { return m.PageRank[i] })
+	result.TopBetweenness = topBy(func(i int) float64 { return m.Betweenness[i] })
+	result.TopInDegree = topBy(func(i int) float64 { return float64(m.InDegree[i]) })
+	result.TopOutDegree = topBy(func(i int) float64 { return float64(m.OutDegree[i]) })
+
+	// Communities come largest first; singletons are isolated notes.
+	for id, members := range m.Communities {
+		if len(members) < 2 || len(result.Communities) == top {
+			break
+		}
+		hubs := append([]int(nil), members...)
+		sort.SliceStable(hubs, func(a, b int) bool { return m.PageRank[hubs[a]] > m.PageRank[hubs[b]] })
+		c := GraphCommunity{ID: id, Size: len(members), Label: communityLabel(g, members)}
+		for _, i := range hubs[:min(graphCommunityHubs, len(hubs))] {
+			c.Hubs = append(c.Hubs, g.Nodes[i].Path)
+		}
+		result.Communities = append(result.Communities, c)
+	}
+	return result
+}
+
+// communityLabel returns the tag carried by the most members, if at least
+// half of them carry it. Ties go to the alphabetically first tag.
+func communityLabel(g *graph.Graph, members []int) string {
+	counts := make(map[string]int)
+	for _, i := range members {
+		for _, t := range g.Nodes[i].Tags {
+			counts[strings.ToLower(t)]++
+		}
+	}
+	best := ""
+	for t, c := range counts {
+		if c > counts[best] || (c == counts[best] && t < best) {
+			best = t
+		}
+	}
+	if best == "" || counts[best]*2 < len(members) {
+		return ""
+	}
+	return best
+}
+
+// graphExport writes the graph to opts.Output, or stdout.
+func graphExport(g *graph.Graph, m graph.Metrics, opts GraphOptions) error {
+	format := opts.Format
+	if format == "" {
+		format = graph.FormatGraphML
+		if opts.JSONOutput && opts.Output == "" {
+			format = graph.FormatJSON
+		}
+	}
+
+	if !containsFold(graph.Formats, format) {
+		return fmt.Errorf("unknown graph format %q (want %s)", format, strings.Join(graph.Formats, ", "))
+	}
+	if opts.Output == "" {
+		return graph.Write(os.Stdout, format, g, m)
+	}
+
+	f, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", opts.Output, err)
+	}
+	if err := graph.Write(f, format, g, m); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.Output, err)
+	}
+
+	result := GraphExportOutput{Path: opts.Output, Format: strings.ToLower(format), Nodes: len(g.Nodes), Edges: len(g.Edges)}
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+	fmt.Printf("Wrote %s graph (%d notes, %d edges) to %s\n", result.Format, result.Nodes, result.Edges, result.Path)
+	return nil
+}
+
+func printGraphStats(result GraphStatsOutput) {
+	fmt.Println("Vault Graph")
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Printf("Notes:        %d\n", result.Notes)
+	fmt.Printf("Links:        %d (density %.4f)\n", result.LinkEdges, result.Density)
+	if result.SemanticEdges > 0 {
+		fmt.Printf("Semantic:     %d edges\n", result.SemanticEdges)
+	}
+	fmt.Printf("Components:   %d (largest %d notes, %d isolated)\n", result.Components, result.LargestComponent, result.Isolated)
+
+	section := func(title string, list []GraphNodeStats, value func(GraphNodeStats) string) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		for i, s := range list {
+			fmt.Printf("  %2d. %-40s %s\n", i+1, s.Path, value(s))
+		}
+	}
+	section("Hubs (PageRank)", result.TopPageRank, func(s GraphNodeStats) string {
+		return fmt.Sprintf("%.4f  in %d  out %d", s.PageRank, s.InDegree, s.OutDegree)
+	})
+	section("Bridges (betweenness)", result.TopBetweenness, func(s GraphNodeStats) string {
+		return fmt.Sprintf("%.4f", s.Betweenness)
+	})
+	section("Most linked (in-degree)", result.TopInDegree, func(s GraphNodeStats) string {
+		return fmt.Sprintf("%d", s.InDegree)
+	})
+	section("Most linking (out-degree)", result.TopOutDegree, func(s GraphNodeStats) string {
+		return fmt.Sprintf("%d", s.OutDegree)
+	})
+
+	if len(result.Communities) > 0 {
+		fmt.Println("\nCommunities:")
+		for _, c := range result.Communities {
+			label := ""
+			if c.Label != "" {
+				label = " #" + c.Label
+			}
+			fmt.Printf("  %2d. %d notes%s — %s\n", c.ID+1, c.Size, label, strings.Join(c.Hubs, ", "))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/graph"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+)
+
+func graphTestRows() []index.NoteRow {
+	return []index.NoteRow{
+		{Path: "Notes/hub.md", Title: "Hub", Tags: "db"},
+		{Path: "Notes/a.md", Title: "A", Tags: "db", Wikilinks: "hub, b", Embedding: []float32{1, 0}},
+		{Path: "Notes/b.md", Title: "B", Tags: "db", Wikilinks: "hub", Embedding: []float32{0.9, 0.1}},
+		{Path: "Notes/c.md", Title: "C", Wikilinks: "Notes/hub, missing, c"},
+		{Path: "Notes/lonely.md", Title: "Lonely", Embedding: []float32{0.95, 0.05}},
+	}
+}
+
+func TestBuildNoteGraph(t *testing.T) {
+	g := buildNoteGraph(graphTestRows(), false, 0.8)
+	if g.Nodes[0].Path != "Notes/a.md" {
+		t.Errorf("nodes not sorted by path: %+v", g.Nodes)
+	}
+	var edges []string
+	for _, e := range g.Edges {
+		edges = append(edges, g.Nodes[e.From].Title+"->"+g.Nodes[e.To].Title)
+	}
+	// Missing targets and self-links are dropped.
+	if got := strings.Join(edges, " "); got != "A->B A->Hub B->Hub C->Hub" {
+		t.Errorf("edges = %s", got)
+	}
+
+	// Similar notes that are not linked gain semantic edges.
+	g = buildNoteGraph(graphTestRows(), true, 0.9)
+	var semantic []string
+	for _, e := range g.Edges {
+		if e.Kind == graph.EdgeSemantic {
+			semantic = append(semantic, g.Nodes[e.From].Title+"-"+g.Nodes[e.To].Title)
+		}
+	}
+	if got := strings.Join(semantic, " "); got != "A-Lonely B-Lonely" {
+		t.Errorf("semantic edges = %s", got)
+	}
+}
+
+func TestGraphCmd(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+	for _, row := range graphTestRows() {
+		if err := store.UpsertNote(&row); err != nil {
+			t.Fatalf("UpsertNote: %v", err)
+		}
+	}
+
+	out := captureStdout(t, func() {
+		if err := GraphCmd(dir, GraphOptions{JSONOutput: true}); err != nil {
+			t.Fatalf("GraphCmd() error: %v", err)
+		}
+	})
+	var stats GraphStatsOutput
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if stats.Notes != 5 || stats.LinkEdges != 4 || stats.Components != 2 || stats.Isolated != 1 || stats.LargestComponent != 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(stats.TopPageRank) == 0 || stats.TopPageRank[0].Path != "Notes/hub.md" || stats.TopInDegree[0].InDegree != 3 {
+		t.Errorf("top = %+v / %+v", stats.TopPageRank, stats.TopInDegree)
+	}
+	if len(stats.Communities) != 2 || stats.Communities[1].Label != "db" || stats.Communities[1].Hubs[0] != "Notes/hub.md" {
+		t.Errorf("communities = %+v", stats.Communities)
+	}
+
+	file := filepath.Join(t.TempDir(), "vault.dot")
+	captureStdout(t, func() {
+		if err := GraphCmd(dir, GraphOptions{Action: "export", Format: "dot", Output: file}); err != nil {
+			t.Fatalf("GraphCmd(export) error: %v", err)
+		}
+	})
+	if got := mustRead(t, file); !strings.HasPrefix(got, "digraph vault {") || !strings.Contains(got, `"Notes/c.md" -> "Notes/hub.md"`) {
+		t.Errorf("DOT export:\n%s", got)
+	}
+
+	png := filepath.Join(t.TempDir(), "vault.png")
+	if err := GraphCmd(dir, GraphOptions{Action: "export", Format: "png", Output: png}); err == nil {
+		t.Error("export with unknown format succeeded")
+	}
+	if _, err := os.Stat(png); !os.IsNotExist(err) {
+		t.Error("export with unknown format created the file")
+	}
+}
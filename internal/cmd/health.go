@@ -24,11 +24,43 @@ type HealthOutput struct {
 	LinkDensity            float64        `json:"link_density"`
 }
 
-// HealthCmd reports vault diagnostics without modifying any content.
-func HealthCmd(vaultPath string, jsonOutput bool) error {
+// HealthOptions configures the health command.
+type HealthOptions struct {
+	Trend      string  // show recorded metrics over this window, e.g. "90d"
+	Threshold  float64 // with Trend: percent change that counts as a regression (default 5)
+	JSONOutput bool
+}
+
+// HealthCmd reports vault diagnostics without modifying any content. Each
+// run's metrics are recorded in the index, when there is one, for --trend.
+func HealthCmd(vaultPath string, opts HealthOptions) error {
+	jsonOutput := opts.JSONOutput
+	if opts.Trend != "" {
+		return healthTrend(vaultPath, opts)
+	}
+
+	result, err := computeHealth(vaultPath)
+	if err != nil {
+		return err
+	}
+	if store := openIndexIfExists(vaultPath); store != nil {
+		recordMetrics(store, metricsHealth, healthMetrics(result))
+		store.Close()
+	}
+
+	if jsonOutput {
+		return output.JSON(result)
+	}
+
+	printHealthReport(result)
+	return nil
+}
+
+// computeHealth gathers the health metrics of a vault.
+func computeHealth(vaultPath string) (HealthOutput, error) {
 	notes, err := vault.ListNotes(vaultPath, "")
 	if err != nil {
-		return fmt.Errorf("failed to list notes: %w", err)
+		return HealthOutput{}, fmt.Errorf("failed to list notes: %w", err)
 	}
 
 	result := HealthOutput{
@@ -36,28 +68,41 @@ func HealthCmd(vaultPath string, jsonOutput bool) error {
 		ClassificationDist: noteClassificationDist(notes),
 	}
 
+	// Parse every note once; aliases must be known before links are counted.
+	parsedByPath := make(map[string]*vault.Note, len(notes))
+	resolver := vault.NewResolver()
+	for _, info := range notes {
+		fullPath := filepath.Join(vaultPath, info.Path)
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			continue
+		}
+		parsed := vault.ParseNote(string(data))
+		parsedByPath[info.Path] = parsed
+		resolver.AddNote(info.Path, parsed)
+	}
+
 	// Build inbound link map and total link count across all notes.
+	// Links, markdown links and note embeds all count as inbound references.
 	inboundLinks := make(map[string]int)
 	totalLinks := 0
 	now := time.Now()
 
 	for _, info := range notes {
-		fullPath := filepath.Join(vaultPath, info.Path)
-		data, err := os.ReadFile(fullPath)
-		if err != nil {
+		parsed, ok := parsedByPath[info.Path]
+		if !ok {
 			continue
 		}
-		parsed := vault.ParseNote(string(data))
-		totalLinks += len(parsed.Wikilinks)
-		for _, link := range parsed.Wikilinks {
-			target := link
-			if idx := strings.Index(target, "#"); idx >= 0 {
-				target = target[:idx]
+		totalLinks += len(parsed.Links)
+		for _, link := range noteLinks(parsed) {
+			if link.Target == "" {
+				continue // same-note [[#heading]] link
 			}
-			target = strings.TrimSpace(strings.ToLower(target))
-			if target != "" {
-				inboundLinks[target]++
+			res := resolver.Resolve(info.Path, link)
+			if res.Status == vault.Missing {
+				continue
 			}
+			inboundLinks[strings.ToLower(strings.TrimSuffix(res.Path, ".md"))]++
 		}
 	}
 
@@ -84,13 +129,7 @@ func HealthCmd(vaultPath string, jsonOutput bool) error {
 			}
 		}
 	}
-
-	if jsonOutput {
-		return output.JSON(result)
-	}
-
-	printHealthReport(result)
-	return nil
+	return result, nil
 }
 
 // countOrphans returns the number of notes with no inbound wikilinks.
@@ -140,7 +179,7 @@ func printHealthReport(r HealthOutput) {
 	}
 	fmt.Println()
 	fmt.Printf("  Orphan notes:     %d\n", r.OrphanNotes)
-	fmt.Printf("  Link density:     %.2f wikilinks/note\n", r.LinkDensity)
+	fmt.Printf("  Link density:     %.2f links/note\n", r.LinkDensity)
 
 	if len(r.ClassificationDist) > 0 {
 		fmt.Println("\nClassification Distribution:")
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/joeyhipolito/obsidian-cli/internal/vault"
@@ -168,3 +171,35 @@ func TestAvgWikilinkDensity(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthCmd_CountsAliasAndMarkdownLinks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Notes/go.md":      "---\naliases: [golang]\n---\nSee [plan](../Projects/plan.md).\n",
+		"Projects/plan.md": "Uses [[golang]].\n```\n[[Notes/lonely]]\n```\n",
+		"Notes/lonely.md":  "Nobody links here.\n",
+	}
+	for p, c := range files {
+		full := filepath.Join(dir, p)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := captureStdout(t, func() {
+		if err := HealthCmd(dir, HealthOptions{JSONOutput: true}); err != nil {
+			t.Fatalf("HealthCmd() error: %v", err)
+		}
+	})
+
+	var got HealthOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if got.OrphanNotes != 1 {
+		t.Errorf("OrphanNotes = %d, want 1 (only Notes/lonely.md)", got.OrphanNotes)
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+)
+
+// Sources of recorded metrics.
+const (
+	metricsMaintain = "maintain"
+	metricsHealth   = "health"
+)
+
+// defaultTrendThreshold is the percent a metric may worsen over the trend
+// window before it is flagged.
+const defaultTrendThreshold = 5
+
+// trendMetric is a metric health --trend shows. Direction is +1 when higher
+// is better, -1 when lower is better and 0 when neither; only metrics with a
+// direction can regress.
+type trendMetric struct {
+	name      string
+	label     string
+	direction int
+}
+
+var trendMetrics = []trendMetric{
+	{"health_score", "Health score", 1},
+	{"broken_links", "Broken links", -1},
+	{"orphan_notes", "Orphan notes", -1},
+	{"inbox_depth", "Inbox depth", -1},
+	{"total_notes", "Notes", 0},
+}
+
+// HealthTrendOutput is the JSON output of health --trend.
+type HealthTrendOutput struct {
+	Since       string        `json:"since"`
+	Threshold   float64       `json:"threshold"` // percent
+	Days        int           `json:"days"`      // days with recorded metrics
+	Series      []TrendSeries `json:"series"`
+	Regressions []string      `json:"regressions"` // metrics of regressed series
+}
+
+// TrendSeries is one metric over the window, one point per day with its
+// last recorded value. First and Last are the earliest and latest recorded
+// values, so runs on the same day still show a change.
+type TrendSeries struct {
+	Metric    string       `json:"metric"`
+	Points    []TrendPoint `json:"points"`
+	First     float64      `json:"first"`
+	Last      float64      `json:"last"`
+	Change    float64      `json:"change"`
+	Regressed bool         `json:"regressed"`
+}
+
+// TrendPoint is a metric's value on a day.
+type TrendPoint struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+}
+
+// openIndexIfExists opens the vault's index, or returns nil when the vault
+// has none or it cannot be opened.
+func openIndexIfExists(vaultPath string) *index.Store {
+	dbPath := index.IndexDBPath(vaultPath)
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil
+	}
+	store, err := index.Open(dbPath)
+	if err != nil {
+		return nil
+	}
+	return store
+}
+
+// recordMetrics stores a run's metrics for health --trend. Failing to record
+// does not fail the run.
+func recordMetrics(store *index.Store, source string, values map[string]float64) {
+	if err := store.RecordMetrics(source, time.Now(), values); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// maintainMetrics are the values of a maintain run kept for trends.
+func maintainMetrics(r MaintainOutput) map[string]float64 {
+	return map[string]float64{
+		"health_score":        float64(r.HealthScore),
+		"total_notes":         float64(r.Stats.TotalNotes),
+		"indexed_notes":       float64(r.Stats.IndexedNotes),
+		"stale_notes":         float64(len(r.StaleNotes)),
+		"broken_links":        float64(len(r.BrokenLinks)),
+		"ambiguous_links":     float64(len(r.AmbiguousLinks)),
+		"empty_notes":         float64(len(r.EmptyNotes)),
+		"no_frontmatter":      float64(len(r.NoFrontmatter)),
+		"inbox_depth":         float64(r.InboxPending),
+		"orphan_attachments":  float64(len(r.OrphanAttachments)),
+		"missing_attachments": float64(len(r.MissingAttachments)),
+	}
+}
+
+// healthMetrics are the values of a health run kept for trends.
+func healthMetrics(r HealthOutput) map[string]float64 {
+	return map[string]float64{
+		"total_notes":    float64(r.TotalNotes),
+		"inbox_depth":    float64(r.InboxDepth),
+		"stale_captures": float64(r.StaleCaptures),
+		"orphan_notes":   float64(r.OrphanNotes),
+		"link_density":   r.LinkDensity,
+	}
+}
+
+// healthTrend shows the recorded metrics over the --trend window. It returns
+// an error after reporting when a metric regressed, so cron can alert.
+func healthTrend(vaultPath string, opts HealthOptions) error {
+	window, err := parseSinceDuration(opts.Trend)
+	if err != nil {
+		return fmt.Errorf("invalid --trend value %q: %w", opts.Trend, err)
+	}
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = defaultTrendThreshold
+	}
+
+	store := openIndexIfExists(vaultPath)
+	if store == nil {
+		return fmt.Errorf("index not found — run 'obsidian index' first")
+	}
+	defer store.Close()
+
+	since := time.Now().Add(-window)
+	points, err := store.MetricsSince(since)
+	if err != nil {
+		return err
+	}
+	result := buildHealthTrend(points, threshold)
+	result.Since = since.Format(time.RFC3339)
+
+	if opts.JSONOutput {
+		if err := output.JSON(result); err != nil {
+			return err
+		}
+	} else {
+		printHealthTrend(result, opts.Trend)
+	}
+	if len(result.Regressions) > 0 {
+		return fmt.Errorf("%d metric(s) regressed more than %g%%: %s",
+			len(result.Regressions), threshold, strings.Join(result.Regressions, ", "))
+	}
+	return nil
+}
+
+// buildHealthTrend turns recorded points into daily series. When maintain
+// and health both record a metric the same day, the later value is kept.
+func buildHealthTrend(points []index.MetricPoint, threshold float64) HealthTrendOutput {
+	result := HealthTrendOutput{Threshold: threshold, Series: []TrendSeries{}, Regressions: []string{}}
+
+	daily := make(map[string]map[string]float64) // metric -> date -> value
+	first := make(map[string]float64)
+	last := make(map[string]float64)
+	days := make(map[string]bool)
+	for _, p := range points {
+		if _, ok := first[p.Name]; !ok {
+			first[p.Name] = p.Value
+		}
+		last[p.Name] = p.Value
+		date := p.Time.Local().Format("2006-01-02")
+		if daily[p.Name] == nil {
+			daily[p.Name] = make(map[string]float64)
+		}
+		daily[p.Name][date] = p.Value // points are oldest first
+		days[date] = true
+	}
+	result.Days = len(days)
+
+	for _, m := range trendMetrics {
+		byDate := daily[m.name]
+		if len(byDate) == 0 {
+			continue
+		}
+		dates := make([]string, 0, len(byDate))
+		for d := range byDate {
+			dates = append(dates, d)
+		}
+		sort.Strings(dates)
+
+		s := TrendSeries{Metric: m.name}
+		for _, d := range dates {
+			s.Points = append(s.Points, TrendPoint{Date: d, Value: byDate[d]})
+		}
+		s.First, s.Last = first[m.name], last[m.name]
+		s.Change = s.Last - s.First
+		s.Regressed = regressed(s.First, s.Last, m.direction, threshold)
+		if s.Regressed {
+			result.Regressions = append(result.Regressions, m.name)
+		}
+		result.Series = append(result.Series, s)
+	}
+	return result
+}
+
+// regressed reports whether a metric moved from first to last in its worse
+// direction by more than threshold percent of first. A metric that starts
+// at zero regresses on any worsening.
+func regressed(first, last float64, direction int, threshold float64) bool {
+	worse := (first - last) * float64(direction)
+	return worse > 0 && worse > math.Abs(first)*threshold/100
+}
+
+// sparkBlocks are the sparkline levels, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as one block per value, scaled between their
+// minimum and maximum.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+func printHealthTrend(r HealthTrendOutput, window string) {
+	days := "days"
+	if r.Days == 1 {
+		days = "day"
+	}
+	header := fmt.Sprintf("Health Trend (last %s, %d %s recorded)", window, r.Days, days)
+	fmt.Println(header)
+	fmt.Println(strings.Repeat("=", 40))
+
+	if len(r.Series) == 0 {
+		fmt.Println("\nNo metrics recorded in this window. Each 'obsidian maintain' and")
+		fmt.Println("'obsidian health' run records a snapshot; schedule one from cron.")
+		return
+	}
+
+	labels := make(map[string]string, len(trendMetrics))
+	for _, m := range trendMetrics {
+		labels[m.name] = m.label
+	}
+	fmt.Println()
+	for _, s := range r.Series {
+		values := make([]float64, len(s.Points))
+		for i, p := range s.Points {
+			values[i] = p.Value
+		}
+		change := formatMetric(roundMetric(s.Change))
+		if s.Change > 0 {
+			change = "+" + change
+		}
+		flag := ""
+		if s.Regressed {
+			flag = "  REGRESSED"
+		}
+		fmt.Printf("  %-13s %s  %s → %s (%s)%s\n",
+			labels[s.Metric], sparkline(values), formatMetric(roundMetric(s.First)), formatMetric(roundMetric(s.Last)), change, flag)
+	}
+
+	if len(r.Regressions) > 0 {
+		fmt.Printf("\nRegressions beyond %g%%: %d\n", r.Threshold, len(r.Regressions))
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+)
+
+func TestBuildHealthTrend(t *testing.T) {
+	day := func(d int, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, time.Local) }
+	points := []index.MetricPoint{
+		{Time: day(1, 9), Source: metricsMaintain, Name: "health_score", Value: 90},
+		{Time: day(1, 9), Source: metricsMaintain, Name: "broken_links", Value: 0},
+		{Time: day(1, 9), Source: metricsMaintain, Name: "total_notes", Value: 100},
+		{Time: day(2, 9), Source: metricsMaintain, Name: "health_score", Value: 88},
+		{Time: day(2, 18), Source: metricsMaintain, Name: "health_score", Value: 80},
+		{Time: day(2, 18), Source: metricsMaintain, Name: "broken_links", Value: 3},
+		{Time: day(2, 18), Source: metricsMaintain, Name: "total_notes", Value: 140},
+		{Time: day(2, 19), Source: metricsHealth, Name: "orphan_notes", Value: 10},
+	}
+	r := buildHealthTrend(points, 5)
+	if r.Days != 2 {
+		t.Errorf("Days = %d, want 2", r.Days)
+	}
+	if len(r.Series) != 4 || r.Series[0].Metric != "health_score" {
+		t.Fatalf("series = %+v", r.Series)
+	}
+	// The day's last value is kept.
+	if want := []TrendPoint{{"2026-03-01", 90}, {"2026-03-02", 80}}; !reflect.DeepEqual(r.Series[0].Points, want) {
+		t.Errorf("health points = %+v", r.Series[0].Points)
+	}
+	// Score and broken links got worse; more notes is not a regression.
+	if want := []string{"health_score", "broken_links"}; !reflect.DeepEqual(r.Regressions, want) {
+		t.Errorf("regressions = %v, want %v", r.Regressions, want)
+	}
+
+	if r := buildHealthTrend(points, 20); len(r.Regressions) != 1 || r.Regressions[0] != "broken_links" {
+		t.Errorf("regressions at 20%% = %v, want only broken_links", r.Regressions)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 7, 3.5, 7}); got != "▁█▄█" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := sparkline([]float64{5, 5}); got != "▁▁" {
+		t.Errorf("flat sparkline = %q", got)
+	}
+}
+
+func TestHealthCmdRecordsMetrics(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Inbox/idea.md": "---\nstatus: pending\n---\nan idea\n",
+		"Notes/a.md":    "---\ntitle: A\n---\nlinks [[missing]]\n",
+	})
+	if err := os.MkdirAll(filepath.Join(dir, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	captureStdout(t, func() {
+		if err := HealthCmd(dir, HealthOptions{JSONOutput: true}); err != nil {
+			t.Fatalf("HealthCmd() error: %v", err)
+		}
+		if err := MaintainCmd(dir, MaintainOptions{JSONOutput: true}); err != nil {
+			t.Fatalf("MaintainCmd() error: %v", err)
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := HealthCmd(dir, HealthOptions{Trend: "7d", JSONOutput: true}); err != nil {
+			t.Fatalf("HealthCmd(trend) error: %v", err)
+		}
+	})
+	var trend HealthTrendOutput
+	if err := json.Unmarshal([]byte(out), &trend); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	got := make(map[string]float64)
+	for _, s := range trend.Series {
+		got[s.Metric] = s.Last
+	}
+	if got["inbox_depth"] != 1 || got["broken_links"] != 1 || got["total_notes"] != 2 || got["health_score"] == 0 {
+		t.Errorf("trend = %+v", trend.Series)
+	}
+}
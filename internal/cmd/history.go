@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/history"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+)
+
+// HistoryOutput is the JSON output for the history command.
+type HistoryOutput struct {
+	Path     string            `json:"path"`
+	Versions []history.Version `json:"versions"`
+}
+
+// DiffOutput is the JSON output for the diff command.
+type DiffOutput struct {
+	Path string `json:"path"`
+	Rev  int    `json:"rev"`
+	Diff string `json:"diff"`
+}
+
+// HistoryCmd lists the stored snapshots of a note, newest first.
+func HistoryCmd(vaultPath, notePath string, jsonOutput bool) error {
+	store, err := history.Open(vaultPath, config.ResolveHistoryRetention())
+	if err != nil {
+		return err
+	}
+
+	key := history.NormalizePath(notePath)
+	versions := store.Versions(key)
+
+	if jsonOutput {
+		if versions == nil {
+			versions = []history.Version{}
+		}
+		return output.JSON(HistoryOutput{Path: key, Versions: versions})
+	}
+
+	if len(versions) == 0 {
+		fmt.Printf("No history for %s\n", key)
+		return nil
+	}
+
+	fmt.Printf("History: %s (%d versions)\n\n", key, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		reason := v.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		fmt.Printf("  rev %-4d %s  %s  %7d B  %s\n",
+			v.Rev,
+			time.Unix(v.Time, 0).Format("2006-01-02 15:04:05"),
+			v.ShortHash(),
+			v.Size,
+			reason,
+		)
+	}
+	return nil
+}
+
+// DiffCmd prints a unified diff between a stored snapshot of a note and its
+// current content. rev 0 selects the most recent snapshot.
+func DiffCmd(vaultPath, notePath string, rev int, jsonOutput bool) error {
+	store, err := history.Open(vaultPath, config.ResolveHistoryRetention())
+	if err != nil {
+		return err
+	}
+
+	key := history.NormalizePath(notePath)
+	versions := store.Versions(key)
+	if len(versions) == 0 {
+		return fmt.Errorf("no history for %s", key)
+	}
+
+	v := versions[len(versions)-1]
+	if rev != 0 {
+		var ok bool
+		v, ok = store.Version(key, rev)
+		if !ok {
+			return fmt.Errorf("revision %d not found for %s (run 'obsidian history %s')", rev, key, key)
+		}
+	}
+
+	old, err := store.Read(v)
+	if err != nil {
+		return err
+	}
+
+	// A deleted or moved note diffs against empty content.
+	current, err := os.ReadFile(filepath.Join(vaultPath, key))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot read note: %w", err)
+	}
+
+	diff := history.UnifiedDiff(
+		fmt.Sprintf("%s@rev%d", key, v.Rev),
+		key,
+		string(old),
+		string(current),
+	)
+
+	if jsonOutput {
+		return output.JSON(DiffOutput{Path: key, Rev: v.Rev, Diff: diff})
+	}
+
+	if diff == "" {
+		fmt.Printf("No changes since rev %d\n", v.Rev)
+		return nil
+	}
+	fmt.Print(diff)
+	return nil
+}
+
+// snapshotNote records the current content of notePath in the history store
+// before a command modifies or removes it. Best-effort: a failure to snapshot
+// is reported on stderr but never blocks the write. Commands that write many
+// notes use a noteHistory instead.
+func snapshotNote(vaultPath, notePath, reason string) {
+	h := newNoteHistory(vaultPath)
+	h.snapshot(notePath, reason)
+	h.save()
+}
+
+// noteHistory snapshots notes for a command that writes many of them. The
+// history store is opened on the first snapshot and written once by save, so
+// a batch costs one log rewrite and garbage collection rather than one per
+// note. Like snapshotNote it is best-effort.
+type noteHistory struct {
+	vaultPath string
+	store     *history.Store
+	failed    bool // the store could not be opened; already reported
+	dirty     bool // snapshots added since the last save
+}
+
+// newNoteHistory returns a snapshotter for a run over vaultPath.
+func newNoteHistory(vaultPath string) *noteHistory {
+	return &noteHistory{vaultPath: vaultPath}
+}
+
+// snapshot records the current content of notePath, if it exists.
+func (h *noteHistory) snapshot(notePath, reason string) {
+	key := history.NormalizePath(notePath)
+	data, err := os.ReadFile(filepath.Join(h.vaultPath, key))
+	if err != nil || h.failed {
+		return // nothing to snapshot (new note), or no store
+	}
+
+	if h.store == nil {
+		h.store, err = history.Open(h.vaultPath, config.ResolveHistoryRetention())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: history snapshot skipped: %v\n", err)
+			h.failed = true
+			return
+		}
+	}
+	_, added, err := h.store.Snapshot(key, data, reason, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: history snapshot skipped: %v\n", err)
+		return
+	}
+	h.dirty = h.dirty || added
+}
+
+// save writes the snapshots taken so far to the history log. It is safe to
+// call on a nil snapshotter or when nothing was recorded.
+func (h *noteHistory) save() {
+	if h == nil || !h.dirty {
+		return
+	}
+	h.dirty = false
+	if err := h.store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: history snapshot skipped: %v\n", err)
+	}
+}
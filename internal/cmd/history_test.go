@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/history"
+)
+
+func TestNoteHistory_SavesBatchOnce(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"a.md": "alpha\n",
+		"b.md": "beta\n",
+	})
+
+	h := newNoteHistory(dir)
+	h.snapshot("a.md", "test")
+	h.snapshot("b.md", "test")
+	h.snapshot("new.md", "test") // does not exist yet
+
+	store, err := history.Open(dir, 10)
+	if err != nil {
+		t.Fatalf("history.Open() error: %v", err)
+	}
+	if paths := store.Paths(); len(paths) != 0 {
+		t.Errorf("log written before save: %v", paths)
+	}
+
+	h.save()
+	store, err = history.Open(dir, 10)
+	if err != nil {
+		t.Fatalf("history.Open() error: %v", err)
+	}
+	if paths := store.Paths(); len(paths) != 2 || paths[0] != "a.md" || paths[1] != "b.md" {
+		t.Errorf("Paths() = %v, want [a.md b.md]", paths)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/joeyhipolito/obsidian-cli/internal/config"
@@ -91,7 +93,7 @@ func IndexCmd(vaultPath string, jsonOutput bool) error {
 		title := extractTitle(parsed, info.Name)
 		tags := extractTags(parsed)
 		headings := extractHeadingTexts(parsed)
-		wikilinks := strings.Join(parsed.Wikilinks, ", ")
+		wikilinks := strings.Join(outgoingNoteLinks(info.Path, parsed), ", ")
 
 		row := &index.NoteRow{
 			Path:      info.Path,
@@ -99,6 +101,7 @@ func IndexCmd(vaultPath string, jsonOutput bool) error {
 			Tags:      tags,
 			Headings:  headings,
 			Wikilinks: wikilinks,
+			Aliases:   strings.Join(parsed.Aliases, ", "),
 			Body:      parsed.Body,
 			ModTime:   info.ModTime,
 		}
@@ -141,6 +144,15 @@ func IndexCmd(vaultPath string, jsonOutput bool) error {
 		}
 	}
 
+	// Notes new to the index by body, to recognise moved notes below
+	indexedBefore, _ := store.GetAllPaths()
+	newByBody := make(map[string]string)
+	for _, w := range toIndex {
+		if !indexedBefore[w.row.Path] && strings.TrimSpace(w.row.Body) != "" {
+			newByBody[w.row.Body] = w.row.Path
+		}
+	}
+
 	// Write all notes to the index
 	for _, w := range toIndex {
 		if err := store.UpsertNote(w.row); err != nil {
@@ -163,6 +175,15 @@ func IndexCmd(vaultPath string, jsonOutput bool) error {
 	if err == nil {
 		for path := range indexedPaths {
 			if !vaultPaths[path] {
+				// A moved note keeps its review schedule; a deleted one loses it.
+				body, _ := store.GetBody(path)
+				if to, ok := newByBody[body]; ok && body != "" {
+					if err := store.MoveReview(path, to); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: keeping review schedule of %s: %v\n", path, err)
+					}
+				} else if err := store.DeleteReview(path); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: removing review schedule of %s: %v\n", path, err)
+				}
 				if err := store.DeleteNote(path); err == nil {
 					stats.NotesRemoved++
 				}
@@ -197,16 +218,42 @@ func extractTitle(note *vault.Note, fallback string) string {
 	return fallback
 }
 
-// extractTags gets tags from frontmatter as a comma-separated string.
+// extractTags gets frontmatter and inline body tags as a comma-separated string.
 func extractTags(note *vault.Note) string {
-	switch v := note.Frontmatter["tags"].(type) {
-	case []string:
-		return strings.Join(v, ", ")
-	case string:
-		return v
-	default:
-		return ""
+	return strings.Join(note.AllTags(), ", ")
+}
+
+// outgoingNoteLinks returns the distinct note targets a note links to or embeds:
+// wikilink targets, markdown link paths (without .md) and embedded notes.
+// Heading and block fragments are dropped; attachments are skipped.
+// Explicitly relative markdown links (./ and ../) are rewritten as vault
+// paths so they can be resolved without knowing the linking note.
+func outgoingNoteLinks(sourcePath string, note *vault.Note) []string {
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(l vault.Link) {
+		if l.Target == "" || l.IsAttachment() {
+			return
+		}
+		target := strings.TrimSuffix(l.Target, ".md")
+		if l.Kind == vault.LinkMarkdown && strings.HasPrefix(target, ".") {
+			dir := path.Dir(filepath.ToSlash(sourcePath))
+			if rel := path.Join(dir, target); !strings.HasPrefix(rel, "..") {
+				target = rel
+			}
+		}
+		if !seen[strings.ToLower(target)] {
+			seen[strings.ToLower(target)] = true
+			targets = append(targets, target)
+		}
+	}
+	for _, l := range note.Links {
+		add(l)
+	}
+	for _, l := range note.Embeds {
+		add(l)
 	}
+	return targets
 }
 
 // extractHeadingTexts gets all heading texts as a newline-separated string.
@@ -7,8 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
 	"github.com/joeyhipolito/obsidian-cli/internal/index"
 	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/schema"
 	"github.com/joeyhipolito/obsidian-cli/internal/vault"
 )
 
@@ -17,6 +19,7 @@ type MaintainOutput struct {
 	Stats          VaultStats  `json:"stats"`
 	StaleNotes     []StaleNote `json:"stale_notes"`
 	BrokenLinks    []BrokenLink `json:"broken_links"`
+	AmbiguousLinks []AmbiguousLink `json:"ambiguous_links"`
 	EmptyNotes     []string    `json:"empty_notes"`
 	LargeNotes     []LargeNote `json:"large_notes"`
 	NoFrontmatter  []string    `json:"no_frontmatter"`
@@ -24,6 +27,38 @@ type MaintainOutput struct {
 	InboxOldestDays int        `json:"inbox_oldest_days"`
 	HealthScore    int         `json:"health_score"`
 	Fixed          int         `json:"fixed"`
+	LinkRepairs    []LinkRepair `json:"link_repairs,omitempty"`
+
+	Attachments          AttachmentTotals `json:"attachments"`
+	OrphanAttachments    []string         `json:"orphan_attachments"`
+	MissingAttachments   []BrokenLink     `json:"missing_attachments"`
+	DuplicateAttachments []DuplicateGroup `json:"duplicate_attachments"`
+
+	MissingProperties []MissingProperty `json:"missing_properties"`
+	DuplicateTitles   []DuplicateTitle  `json:"duplicate_titles"`
+	InvalidDates      []InvalidDate     `json:"invalid_dates"`
+	TagCasing         []TagCasing       `json:"tag_casing"`
+	FilenameIssues    []FilenameIssue   `json:"filename_issues"`
+
+	Checks []CheckResult `json:"checks"` // checks run, with their score deductions
+}
+
+// MaintainOptions configures the maintain command.
+type MaintainOptions struct {
+	StaleDays  int    // overrides the stale check's threshold; 0 = config or 30
+	Check      string // run only this check
+	ListChecks bool   // list the checks and their settings instead
+	Fix        bool // add frontmatter to notes missing it
+	FixLinks   bool // propose and apply repairs for broken links
+	DryRun     bool // with FixLinks: report proposed repairs without writing
+	JSONOutput bool
+}
+
+// maintainNote is a vault note read and parsed once for all checks.
+type maintainNote struct {
+	info    vault.NoteInfo
+	content string
+	parsed  *vault.Note
 }
 
 // VaultStats holds overall vault statistics.
@@ -41,10 +76,23 @@ type StaleNote struct {
 	DaysAgo  int    `json:"days_ago"`
 }
 
-// BrokenLink represents a wikilink pointing to a nonexistent note.
+// BrokenLink represents a wikilink, markdown link or note embed pointing to a
+// nonexistent note, or to a heading or block the target note does not have.
 type BrokenLink struct {
 	Source string `json:"source"`
 	Target string `json:"target"`
+	Line   int    `json:"line,omitempty"`
+	Reason string `json:"reason,omitempty"` // note not found, heading not found, block not found
+}
+
+// AmbiguousLink represents a link whose basename or alias matches several
+// notes. Obsidian opens ResolvedTo; a path-qualified link removes the doubt.
+type AmbiguousLink struct {
+	Source     string   `json:"source"`
+	Target     string   `json:"target"`
+	Line       int      `json:"line,omitempty"`
+	ResolvedTo string   `json:"resolved_to"`
+	Candidates []string `json:"candidates"`
 }
 
 // LargeNote represents a note exceeding the size threshold.
@@ -54,189 +102,192 @@ type LargeNote struct {
 }
 
 // MaintainCmd performs vault health checks and reports issues.
-func MaintainCmd(vaultPath string, staleDays int, fix, jsonOutput bool) error {
-	notes, err := vault.ListNotes(vaultPath, "")
-	if err != nil {
-		return fmt.Errorf("failed to list notes: %w", err)
+func MaintainCmd(vaultPath string, opts MaintainOptions) error {
+	fix, jsonOutput := opts.Fix, opts.JSONOutput
+	if opts.ListChecks {
+		return printCheckList(listChecks(config.ResolveChecks()), jsonOutput)
 	}
 
-	result := MaintainOutput{}
-	result.Stats.TotalNotes = len(notes)
-
 	// Get index stats if available
+	var store *index.Store
 	dbPath := index.IndexDBPath(vaultPath)
 	if _, err := os.Stat(dbPath); err == nil {
-		store, err := index.Open(dbPath)
-		if err == nil {
+		if s, err := index.Open(dbPath); err == nil {
+			store = s
 			defer store.Close()
-			if count, err := store.NoteCount(); err == nil {
-				result.Stats.IndexedNotes = count
-			}
-			if count, err := store.EmbeddingCount(); err == nil {
-				result.Stats.WithEmbeddings = count
-			}
 		}
 	}
 
-	// Build lookup set of all note names (for broken link detection)
-	noteNames := make(map[string]bool)
-	for _, n := range notes {
-		name := strings.TrimSuffix(filepath.Base(n.Path), ".md")
-		noteNames[strings.ToLower(name)] = true
-		// Also add full path without extension for path-based links
-		pathNoExt := strings.TrimSuffix(n.Path, ".md")
-		noteNames[strings.ToLower(pathNoExt)] = true
+	result, loaded, resolver, err := runMaintainChecks(vaultPath, opts, store)
+	if err != nil {
+		return err
+	}
+	// A single check's score is not comparable with full runs.
+	if store != nil && opts.Check == "" {
+		recordMetrics(store, metricsMaintain, maintainMetrics(result))
 	}
 
-	now := time.Now()
-	var totalSize int64
+	// Apply fixes if requested
+	if fix {
+		result.Fixed = applyFixes(vaultPath, result)
+	}
+	if opts.FixLinks {
+		result.LinkRepairs = fixBrokenLinks(vaultPath, loaded, resolver, store, opts)
+	}
 
-	for _, info := range notes {
-		totalSize += info.Size
+	if jsonOutput {
+		return output.JSON(result)
+	}
 
-		// Read note content for checks
-		fullPath := filepath.Join(vaultPath, info.Path)
-		data, err := os.ReadFile(fullPath)
+	printMaintainReport(result, fix)
+	if opts.FixLinks {
+		printLinkRepairReport(result.LinkRepairs, opts.DryRun)
+	}
+	return nil
+}
+
+// runMaintainChecks runs the enabled health checks, or only opts.Check, and
+// scores the vault. It also returns the parsed notes and link resolver so
+// fixes can reuse them. store may be nil when the vault has no index.
+func runMaintainChecks(vaultPath string, opts MaintainOptions, store *index.Store) (MaintainOutput, []maintainNote, *vault.Resolver, error) {
+	checks := healthChecks
+	if opts.Check != "" {
+		c, err := findCheck(opts.Check)
 		if err != nil {
-			continue
-		}
-		content := string(data)
-
-		// Check: stale notes
-		modTime := time.Unix(info.ModTime, 0)
-		daysOld := int(now.Sub(modTime).Hours() / 24)
-		if daysOld >= staleDays {
-			result.StaleNotes = append(result.StaleNotes, StaleNote{
-				Path:    info.Path,
-				LastMod: modTime.Format("2006-01-02"),
-				DaysAgo: daysOld,
-			})
-		}
-
-		// Check: broken wikilinks
-		parsed := vault.ParseNote(content)
-		for _, link := range parsed.Wikilinks {
-			// Strip heading fragments
-			target := link
-			if idx := strings.Index(target, "#"); idx >= 0 {
-				target = target[:idx]
-			}
-			target = strings.TrimSpace(target)
-			if target == "" {
-				continue
-			}
-			if !noteNames[strings.ToLower(target)] {
-				result.BrokenLinks = append(result.BrokenLinks, BrokenLink{
-					Source: info.Path,
-					Target: link,
-				})
-			}
+			return MaintainOutput{}, nil, nil, err
 		}
+		checks = []Check{c}
+	}
 
-		// Check: empty notes (only frontmatter, no body content)
-		body := strings.TrimSpace(parsed.Body)
-		if body == "" {
-			result.EmptyNotes = append(result.EmptyNotes, info.Path)
-		}
+	notes, err := vault.ListNotes(vaultPath, "")
+	if err != nil {
+		return MaintainOutput{}, nil, nil, fmt.Errorf("failed to list notes: %w", err)
+	}
 
-		// Check: large notes (>10KB)
-		if info.Size > 10240 {
-			result.LargeNotes = append(result.LargeNotes, LargeNote{
-				Path:      info.Path,
-				SizeBytes: info.Size,
-			})
+	result := MaintainOutput{}
+	result.Stats.TotalNotes = len(notes)
+	if store != nil {
+		if count, err := store.NoteCount(); err == nil {
+			result.Stats.IndexedNotes = count
 		}
-
-		// Check: missing frontmatter
-		if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
-			result.NoFrontmatter = append(result.NoFrontmatter, info.Path)
+		if count, err := store.EmbeddingCount(); err == nil {
+			result.Stats.WithEmbeddings = count
 		}
 	}
 
+	// Read and parse every note up front: aliases must be known before links
+	// can be checked.
+	loaded := loadVaultNotes(vaultPath, notes)
+
+	// Resolve links the way Obsidian does: paths, basenames, aliases and attachments.
+	resolver := vault.NewResolver()
+	var totalSize int64
+	for _, n := range loaded {
+		resolver.AddNote(n.info.Path, n.parsed)
+		totalSize += n.info.Size
+	}
+	_ = resolver.AddAttachments(vaultPath)
 	if len(notes) > 0 {
 		result.Stats.AvgSizeBytes = int(totalSize / int64(len(notes)))
 	}
 
-	// Scan inbox for pending triage
-	inboxDir := filepath.Join(vaultPath, "Inbox")
-	if _, err := os.Stat(inboxDir); err == nil {
-		if inboxNotes, err := vault.ListNotes(vaultPath, "Inbox"); err == nil {
-			for _, info := range inboxNotes {
-				fullPath := filepath.Join(vaultPath, info.Path)
-				data, err := os.ReadFile(fullPath)
-				if err != nil {
-					continue
-				}
-				parsed := vault.ParseNote(string(data))
-				if frontmatterString(parsed.Frontmatter, "status") == "processed" {
-					continue
-				}
-				result.InboxPending++
-				ageDays, _ := computeAge(frontmatterString(parsed.Frontmatter, "created"), info.ModTime, now)
-				if ageDays > result.InboxOldestDays {
-					result.InboxOldestDays = ageDays
-				}
+	cfg := config.ResolveChecks()
+	warnUnknownChecks(cfg)
+	required := make(map[string][]string, len(cfg.RequiredProperties))
+	for t, props := range cfg.RequiredProperties {
+		required[strings.ToLower(t)] = props
+	}
+	// Vault schemas (see validate) add the types the config does not list.
+	if set, err := schema.Load(vaultPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else {
+		for t, sc := range set.Schemas {
+			if _, ok := required[t]; !ok {
+				required[t] = sc.Required
 			}
 		}
 	}
 
-	// Calculate health score
-	result.HealthScore = calculateHealthScore(result)
-
-	// Apply fixes if requested
-	if fix {
-		result.Fixed = applyFixes(vaultPath, result)
-	}
-
-	if jsonOutput {
-		return output.JSON(result)
-	}
-
-	printMaintainReport(result, fix)
-	return nil
-}
-
-// calculateHealthScore computes a 0-100 health score.
-func calculateHealthScore(r MaintainOutput) int {
+	shared := &checkShared{all: loaded}
+	now := time.Now()
 	score := 100
-
-	// Stale notes: -1 each, capped at -20
-	staleDeduct := len(r.StaleNotes)
-	if staleDeduct > 20 {
-		staleDeduct = 20
+	for _, c := range checks {
+		settings, enabled := resolveCheck(c, cfg)
+		if !enabled && opts.Check == "" {
+			continue
+		}
+		if c.Name() == "stale" && opts.StaleDays > 0 {
+			settings.Threshold = float64(opts.StaleDays)
+		}
+		ctx := &CheckContext{
+			VaultPath: vaultPath,
+			Resolver:  resolver,
+			Stats:     result.Stats,
+			Now:       now,
+			Settings:  settings,
+			Required:  required,
+			shared:    shared,
+		}
+		for _, n := range loaded {
+			if !settings.excluded(n.info.Path) {
+				ctx.Notes = append(ctx.Notes, n)
+			}
+		}
+		issues := c.Run(ctx, &result)
+		deduction := settings.deduction(issues)
+		score -= deduction
+		result.Checks = append(result.Checks, CheckResult{
+			Name:      c.Name(),
+			Issues:    issues,
+			Deduction: deduction,
+			Threshold: settings.Threshold,
+		})
 	}
-	score -= staleDeduct
+	result.HealthScore = max(score, 0)
+	return result, loaded, resolver, nil
+}
 
-	// Broken links: -2 each, capped at -20
-	brokenDeduct := len(r.BrokenLinks) * 2
-	if brokenDeduct > 20 {
-		brokenDeduct = 20
+// loadVaultNotes reads and parses the given notes. Unreadable notes are skipped.
+func loadVaultNotes(vaultPath string, notes []vault.NoteInfo) []maintainNote {
+	var loaded []maintainNote
+	for _, info := range notes {
+		data, err := os.ReadFile(filepath.Join(vaultPath, info.Path))
+		if err != nil {
+			continue
+		}
+		loaded = append(loaded, maintainNote{
+			info:    info,
+			content: string(data),
+			parsed:  vault.ParseNote(string(data)),
+		})
 	}
-	score -= brokenDeduct
-
-	// Empty notes: -5 each
-	score -= len(r.EmptyNotes) * 5
-
-	// Missing frontmatter: -3 each
-	score -= len(r.NoFrontmatter) * 3
+	return loaded
+}
 
-	// Index coverage
-	if r.Stats.TotalNotes > 0 && r.Stats.IndexedNotes > 0 {
-		coverage := float64(r.Stats.IndexedNotes) / float64(r.Stats.TotalNotes) * 100
-		score -= int(100 - coverage)
+// noteLinks returns the links and embeds of a note that point at other notes.
+// Attachment embeds and links (images, PDFs, ...) are excluded.
+func noteLinks(parsed *vault.Note) []vault.Link {
+	var links []vault.Link
+	for _, l := range parsed.Links {
+		if !l.IsAttachment() {
+			links = append(links, l)
+		}
 	}
-
-	// Inbox backlog: -1 per pending note, capped at -10
-	inboxDeduct := r.InboxPending
-	if inboxDeduct > 10 {
-		inboxDeduct = 10
+	for _, l := range parsed.Embeds {
+		if !l.IsAttachment() {
+			links = append(links, l)
+		}
 	}
-	score -= inboxDeduct
+	return links
+}
 
-	if score < 0 {
-		score = 0
+// linkDisplayTarget renders a link target the way it appears in the note,
+// including any #fragment.
+func linkDisplayTarget(l vault.Link) string {
+	if l.Fragment != "" {
+		return l.Target + "#" + l.Fragment
 	}
-	return score
+	return l.Target
 }
 
 // applyFixes adds frontmatter to notes missing it.
@@ -251,6 +302,7 @@ func applyFixes(vaultPath string, r MaintainOutput) int {
 
 		// Prepend empty frontmatter
 		content := "---\n---\n" + string(data)
+		snapshotNote(vaultPath, notePath, "maintain --fix")
 		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
 			continue
 		}
@@ -286,7 +338,8 @@ func printMaintainReport(result MaintainOutput, fixed bool) {
 
 	// Stale notes
 	if len(result.StaleNotes) > 0 {
-		fmt.Printf("\nStale Notes (not modified in 30+ days): %d\n", len(result.StaleNotes))
+		days, _ := result.checkThreshold("stale")
+		fmt.Printf("\nStale Notes (not modified in %.0f+ days): %d\n", days, len(result.StaleNotes))
 		for _, s := range result.StaleNotes {
 			fmt.Printf("  - %s (last: %s, %d days ago)\n", s.Path, s.LastMod, s.DaysAgo)
 		}
@@ -294,9 +347,30 @@ func printMaintainReport(result MaintainOutput, fixed bool) {
 
 	// Broken links
 	if len(result.BrokenLinks) > 0 {
-		fmt.Printf("\nBroken Wikilinks: %d\n", len(result.BrokenLinks))
+		fmt.Printf("\nBroken Links: %d\n", len(result.BrokenLinks))
 		for _, bl := range result.BrokenLinks {
-			fmt.Printf("  - %s links to [[%s]] (not found)\n", bl.Source, bl.Target)
+			source := bl.Source
+			if bl.Line > 0 {
+				source = fmt.Sprintf("%s:%d", bl.Source, bl.Line)
+			}
+			reason := bl.Reason
+			if reason == "" {
+				reason = "not found"
+			}
+			fmt.Printf("  - %s links to [[%s]] (%s)\n", source, bl.Target, reason)
+		}
+	}
+
+	// Ambiguous links
+	if len(result.AmbiguousLinks) > 0 {
+		fmt.Printf("\nAmbiguous Links: %d\n", len(result.AmbiguousLinks))
+		for _, al := range result.AmbiguousLinks {
+			source := al.Source
+			if al.Line > 0 {
+				source = fmt.Sprintf("%s:%d", al.Source, al.Line)
+			}
+			fmt.Printf("  - %s links to [[%s]] -> %s (also: %s)\n",
+				source, al.Target, al.ResolvedTo, strings.Join(al.Candidates[1:], ", "))
 		}
 	}
 
@@ -310,7 +384,8 @@ func printMaintainReport(result MaintainOutput, fixed bool) {
 
 	// Large notes
 	if len(result.LargeNotes) > 0 {
-		fmt.Printf("\nLarge Notes (>10KB): %d\n", len(result.LargeNotes))
+		size, _ := result.checkThreshold("large")
+		fmt.Printf("\nLarge Notes (>%s): %d\n", formatBytes(int64(size)), len(result.LargeNotes))
 		for _, ln := range result.LargeNotes {
 			fmt.Printf("  - %s (%.1f KB)\n", ln.Path, float64(ln.SizeBytes)/1024)
 		}
@@ -324,13 +399,66 @@ func printMaintainReport(result MaintainOutput, fixed bool) {
 		}
 	}
 
+	if len(result.MissingProperties) > 0 {
+		fmt.Printf("\nMissing Required Properties: %d\n", len(result.MissingProperties))
+		for _, m := range result.MissingProperties {
+			fmt.Printf("  - %s (type %s): %s\n", m.Path, m.Type, strings.Join(m.Missing, ", "))
+		}
+	}
+	if len(result.DuplicateTitles) > 0 {
+		fmt.Printf("\nDuplicate Titles: %d\n", len(result.DuplicateTitles))
+		for _, d := range result.DuplicateTitles {
+			fmt.Printf("  - %q: %s\n", d.Title, strings.Join(d.Paths, ", "))
+		}
+	}
+	if len(result.InvalidDates) > 0 {
+		fmt.Printf("\nInvalid Dates: %d\n", len(result.InvalidDates))
+		for _, d := range result.InvalidDates {
+			fmt.Printf("  - %s: %s = %q\n", d.Path, d.Property, d.Value)
+		}
+	}
+	if len(result.TagCasing) > 0 {
+		fmt.Printf("\nInconsistent Tag Casing: %d\n", len(result.TagCasing))
+		for _, tc := range result.TagCasing {
+			fmt.Printf("  - #%s (also %s): %s\n", tc.Preferred, strings.Join(otherVariants(tc), ", "), strings.Join(tc.Notes, ", "))
+		}
+	}
+	if len(result.FilenameIssues) > 0 {
+		fmt.Printf("\nFile Name Issues: %d\n", len(result.FilenameIssues))
+		for _, f := range result.FilenameIssues {
+			fmt.Printf("  - %s: %s\n", f.Path, f.Problem)
+		}
+	}
+
+	// Attachments
+	if result.Attachments.Count > 0 {
+		fmt.Printf("\nAttachments: %d (%s)\n", result.Attachments.Count, formatBytes(result.Attachments.TotalBytes))
+		if len(result.OrphanAttachments) > 0 {
+			fmt.Printf("  Unreferenced: %d\n", len(result.OrphanAttachments))
+			for _, p := range result.OrphanAttachments {
+				fmt.Printf("    - %s\n", p)
+			}
+		}
+		for _, g := range result.DuplicateAttachments {
+			fmt.Printf("  Duplicate: %s\n", strings.Join(g.Paths, ", "))
+		}
+	}
+	if len(result.MissingAttachments) > 0 {
+		fmt.Printf("\nMissing Attachments: %d\n", len(result.MissingAttachments))
+		for _, m := range result.MissingAttachments {
+			fmt.Printf("  - %s:%d embeds %s (not found)\n", m.Source, m.Line, m.Target)
+		}
+	}
+
 	// Inbox triage status
-	fmt.Println("\nInbox:")
-	if result.InboxPending == 0 {
-		fmt.Println("  Clear (no notes pending triage)")
-	} else {
-		fmt.Printf("  %d notes pending triage, oldest: %dd  → run: obsidian triage --auto\n",
-			result.InboxPending, result.InboxOldestDays)
+	if _, ok := result.checkThreshold("inbox"); ok {
+		fmt.Println("\nInbox:")
+		if result.InboxPending == 0 {
+			fmt.Println("  Clear (no notes pending triage)")
+		} else {
+			fmt.Printf("  %d notes pending triage, oldest: %dd  → run: obsidian triage --auto\n",
+				result.InboxPending, result.InboxOldestDays)
+		}
 	}
 
 	if fixed && result.Fixed > 0 {
@@ -338,4 +466,61 @@ func printMaintainReport(result MaintainOutput, fixed bool) {
 	}
 
 	fmt.Printf("\nHealth Score: %d/100\n", result.HealthScore)
+	for _, c := range result.Checks {
+		if c.Deduction > 0 {
+			fmt.Printf("  -%d %s (%d)\n", c.Deduction, c.Name, c.Issues)
+		}
+	}
+}
+
+// checkThreshold returns the threshold a check ran with, and whether it ran.
+func (r MaintainOutput) checkThreshold(name string) (float64, bool) {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c.Threshold, true
+		}
+	}
+	return 0, false
+}
+
+// otherVariants returns a tag's spellings other than the preferred one.
+func otherVariants(tc TagCasing) []string {
+	var out []string
+	for _, v := range tc.Variants {
+		if v != tc.Preferred {
+			out = append(out, "#"+v)
+		}
+	}
+	return out
+}
+
+// printCheckList prints the registered checks and their effective settings.
+func printCheckList(checks []CheckInfo, jsonOutput bool) error {
+	if jsonOutput {
+		return output.JSON(checks)
+	}
+	fmt.Println("Health Checks")
+	fmt.Println(strings.Repeat("=", 40))
+	for _, c := range checks {
+		state := ""
+		if !c.Enabled {
+			state = " (disabled)"
+		}
+		fmt.Printf("\n%s%s\n  %s\n", c.Name, state, c.Description)
+		settings := fmt.Sprintf("  weight %g", c.Weight)
+		if c.Cap > 0 {
+			settings += fmt.Sprintf(", cap %d", c.Cap)
+		}
+		if c.Threshold > 0 {
+			settings += fmt.Sprintf(", threshold %g", c.Threshold)
+		}
+		if len(c.Exclude) > 0 {
+			settings += ", excludes " + strings.Join(c.Exclude, ", ")
+		}
+		if c.Pattern != "" {
+			settings += ", pattern " + c.Pattern
+		}
+		fmt.Println(settings)
+	}
+	return nil
 }
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// minMentionRunes is the shortest title or alias looked for. Shorter names
+// (such as "Go") match too much ordinary text.
+const minMentionRunes = 3
+
+// MentionsOptions controls the mentions command.
+type MentionsOptions struct {
+	Path       string // note or folder to look in (default: whole vault)
+	Apply      bool   // convert mentions into wikilinks
+	JSONOutput bool
+}
+
+// Mention is a plain-text occurrence of another note's title or alias.
+type Mention struct {
+	Source string `json:"source"` // note the text is in
+	Target string `json:"target"` // note it names
+	Text   string `json:"text"`   // the text as written
+	Line   int    `json:"line"`
+	Link   string `json:"link"` // wikilink --apply writes in its place
+}
+
+// MentionsSummary holds counts for the mentions report.
+type MentionsSummary struct {
+	Mentions     int `json:"mentions"`
+	Notes        int `json:"notes"` // notes with at least one mention
+	Applied      int `json:"applied"`
+	NotesChanged int `json:"notes_changed"`
+}
+
+// MentionsOutput represents the JSON output format for the mentions command.
+type MentionsOutput struct {
+	Mentions []Mention       `json:"mentions"`
+	Summary  MentionsSummary `json:"summary"`
+}
+
+// mentionTerm is a name a note can be mentioned by.
+type mentionTerm struct {
+	Text   string
+	Target string
+}
+
+// MentionsCmd finds unlinked mentions: titles, filenames and aliases of notes
+// written as plain text in other notes, outside code, links and tags. With
+// Apply they become wikilinks, keeping the text as written.
+func MentionsCmd(vaultPath string, opts MentionsOptions) error {
+	dbPath := index.IndexDBPath(vaultPath)
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("index not found — run 'obsidian index' first")
+	}
+
+	store, err := index.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer store.Close()
+
+	rows, err := store.GetAllNoteRows()
+	if err != nil {
+		return fmt.Errorf("failed to load notes: %w", err)
+	}
+
+	filter := strings.TrimSuffix(filepath.ToSlash(opts.Path), "/")
+	inScope := 0
+	for _, r := range rows {
+		if mentionInScope(r.Path, filter) {
+			inScope++
+		}
+	}
+	if filter != "" && inScope == 0 {
+		return fmt.Errorf("no indexed notes match %s", opts.Path)
+	}
+
+	// The index narrows each name to the notes whose text contains it; the
+	// notes themselves are then scanned to skip code and existing links.
+	bySource := make(map[string][]mentionTerm)
+	for _, term := range mentionTerms(rows) {
+		paths, err := store.NotesMentioning(term.Text)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		for _, p := range paths {
+			if p != term.Target && mentionInScope(p, filter) {
+				bySource[p] = append(bySource[p], term)
+			}
+		}
+	}
+	sources := make([]string, 0, len(bySource))
+	for p := range bySource {
+		sources = append(sources, p)
+	}
+	sort.Strings(sources)
+
+	resolver := resolverFromRows(rows)
+	result := MentionsOutput{Mentions: []Mention{}}
+	snapshots := newNoteHistory(vaultPath)
+	for _, source := range sources {
+		fullPath := filepath.Join(vaultPath, source)
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		updated, found := linkMentions(string(data), source, bySource[source], resolver)
+		if len(found) == 0 {
+			continue
+		}
+		result.Mentions = append(result.Mentions, found...)
+		result.Summary.Notes++
+
+		if !opts.Apply {
+			continue
+		}
+		snapshots.snapshot(source, "mentions")
+		if err := os.WriteFile(fullPath, []byte(updated), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write %s: %v\n", source, err)
+			continue
+		}
+		result.Summary.Applied += len(found)
+		result.Summary.NotesChanged++
+	}
+	snapshots.save()
+	result.Summary.Mentions = len(result.Mentions)
+
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+	printMentionsReport(result, opts.Apply)
+	return nil
+}
+
+// mentionInScope reports whether a note is the filter note or under the
+// filter folder. An empty filter matches every note.
+func mentionInScope(notePath, filter string) bool {
+	return filter == "" || notePath == filter || notePath == filter+".md" ||
+		strings.HasPrefix(notePath, filter+"/")
+}
+
+// mentionTerms returns the names notes can be mentioned by: title, filename
+// and aliases. Names shared by more than one note are left out, as a link
+// could not say which was meant. Longer names come first so "Vector Search"
+// wins over "Search".
+func mentionTerms(rows []index.NoteRow) []mentionTerm {
+	targets := make(map[string]map[string]bool) // lower-case name -> notes
+	spelling := make(map[string]string)         // lower-case name -> first spelling seen
+	for _, r := range rows {
+		names := []string{r.Title, strings.TrimSuffix(filepath.Base(r.Path), ".md")}
+		names = append(names, splitIndexList(r.Aliases)...)
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if utf8.RuneCountInString(name) < minMentionRunes {
+				continue
+			}
+			key := strings.ToLower(name)
+			if targets[key] == nil {
+				targets[key] = make(map[string]bool)
+				spelling[key] = name
+			}
+			targets[key][r.Path] = true
+		}
+	}
+
+	var terms []mentionTerm
+	for key, paths := range targets {
+		if len(paths) != 1 {
+			continue
+		}
+		for p := range paths {
+			terms = append(terms, mentionTerm{Text: spelling[key], Target: p})
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if li, lj := len(terms[i].Text), len(terms[j].Text); li != lj {
+			return li > lj
+		}
+		return terms[i].Text < terms[j].Text
+	})
+	return terms
+}
+
+// linkMentions finds the terms in content's prose and returns the content
+// with each one turned into a wikilink, and the mentions found. Terms are
+// tried in order, so longer ones must come first.
+func linkMentions(content, source string, terms []mentionTerm, resolver *vault.Resolver) (string, []Mention) {
+	if len(terms) == 0 {
+		return content, nil
+	}
+	targetOf := make(map[string]string, len(terms))
+	alts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		key := strings.ToLower(t.Text)
+		if _, ok := targetOf[key]; ok {
+			continue
+		}
+		targetOf[key] = t.Target
+		alts = append(alts, regexp.QuoteMeta(t.Text))
+	}
+	re := regexp.MustCompile(`(?i)` + strings.Join(alts, "|"))
+
+	var found []Mention
+	updated, _ := vault.RewriteProse(content, func(line int, prose string) []vault.ProseEdit {
+		var edits []vault.ProseEdit
+		for _, m := range re.FindAllStringIndex(prose, -1) {
+			if !mentionBoundary(prose, m[0], m[1]) {
+				continue
+			}
+			text := prose[m[0]:m[1]]
+			target := targetOf[strings.ToLower(text)]
+			link := "[[" + text + "]]"
+			if lt := resolver.LinkText(target); lt != text {
+				link = "[[" + lt + "|" + text + "]]"
+			}
+			found = append(found, Mention{Source: source, Target: target, Text: text, Line: line, Link: link})
+			edits = append(edits, vault.ProseEdit{Start: m[0], End: m[1], Text: link})
+		}
+		return edits
+	})
+	return updated, found
+}
+
+// mentionBoundary reports whether s[start:end] is a whole word or phrase:
+// not preceded or followed by a letter, digit or underscore.
+func mentionBoundary(s string, start, end int) bool {
+	isWord := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(s[:start]); isWord(r) {
+			return false
+		}
+	}
+	if end < len(s) {
+		if r, _ := utf8.DecodeRuneInString(s[end:]); isWord(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func printMentionsReport(result MentionsOutput, applied bool) {
+	if len(result.Mentions) == 0 {
+		fmt.Println("No unlinked mentions found.")
+		return
+	}
+
+	source := ""
+	for _, m := range result.Mentions {
+		if m.Source != source {
+			source = m.Source
+			fmt.Printf("\n%s\n", source)
+		}
+		fmt.Printf("  %d: %q → %s\n", m.Line, m.Text, m.Link)
+	}
+
+	if applied {
+		fmt.Printf("\nLinked %d mentions in %d notes\n", result.Summary.Applied, result.Summary.NotesChanged)
+		return
+	}
+	fmt.Printf("\n%d unlinked mentions in %d notes (use --apply to link them)\n", result.Summary.Mentions, result.Summary.Notes)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+)
+
+func TestMentionsCmd(t *testing.T) {
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	files := map[string]string{
+		"Notes/Vector Search.md": "---\naliases: [ANN]\n---\nApproximate nearest neighbours.\n",
+		"Notes/Search.md":        "Plain search.\n",
+		"Projects/plan.md": "---\ntitle: Plan\n---\n" +
+			"We need vector search and ann here.\n" +
+			"Already [[Vector Search]], `vector search` and #search stay.\n" +
+			"```\nvector search\n```\n" +
+			"Searching is not Search-able; search is.\n",
+		"Projects/Other/Search.md": "Duplicate basename.\n",
+	}
+	dir := writeVaultFiles(t, files)
+	if err := os.MkdirAll(filepath.Join(dir, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+	for _, row := range []index.NoteRow{
+		{Path: "Notes/Vector Search.md", Title: "Vector Search", Aliases: "ANN", Body: "Approximate nearest neighbours."},
+		{Path: "Notes/Search.md", Title: "Search", Body: "Plain search."},
+		{Path: "Projects/plan.md", Title: "Plan", Body: files["Projects/plan.md"][len("---\ntitle: Plan\n---\n"):]},
+		{Path: "Projects/Other/Search.md", Title: "Other search", Body: "Duplicate basename."},
+	} {
+		if err := store.UpsertNote(&row); err != nil {
+			t.Fatalf("UpsertNote: %v", err)
+		}
+	}
+
+	out := captureStdout(t, func() {
+		if err := MentionsCmd(dir, MentionsOptions{Path: "Projects/", Apply: true, JSONOutput: true}); err != nil {
+			t.Fatalf("MentionsCmd() error: %v", err)
+		}
+	})
+	var result MentionsOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	// "Search" names two notes, so only the unique names are linked.
+	if result.Summary.Mentions != 2 || result.Summary.NotesChanged != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	if m := result.Mentions[0]; m.Line != 4 || m.Target != "Notes/Vector Search.md" || m.Link != "[[Vector Search|vector search]]" {
+		t.Errorf("mention = %+v", m)
+	}
+
+	got := mustRead(t, filepath.Join(dir, "Projects/plan.md"))
+	want := "---\ntitle: Plan\n---\n" +
+		"We need [[Vector Search|vector search]] and [[Vector Search|ann]] here.\n" +
+		"Already [[Vector Search]], `vector search` and #search stay.\n" +
+		"```\nvector search\n```\n" +
+		"Searching is not Search-able; search is.\n"
+	if got != want {
+		t.Errorf("plan.md =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMentionTerms(t *testing.T) {
+	terms := mentionTerms([]index.NoteRow{
+		{Path: "a/Go.md", Title: "Go", Aliases: "golang"},
+		{Path: "b/Vector Search.md", Title: "Vector Search"},
+		{Path: "c/Search.md", Title: "Search"},
+		{Path: "d/Search.md", Title: "Finding"},
+	})
+	var got []string
+	for _, term := range terms {
+		got = append(got, term.Text+"="+term.Target)
+	}
+	want := []string{"Vector Search=b/Vector Search.md", "Finding=d/Search.md", "golang=a/Go.md"}
+	if len(got) != len(want) {
+		t.Fatalf("mentionTerms() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("mentionTerms()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
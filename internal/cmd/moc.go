@@ -0,0 +1,499 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// mocFolder is where new maps of content are written.
+const mocFolder = "MOCs"
+
+// The generated part of a MOC sits between these markers. Everything outside
+// them is left alone when the MOC is refreshed.
+const (
+	mocStartMarker = "<!-- moc:start -->"
+	mocEndMarker   = "<!-- moc:end -->"
+)
+
+const (
+	defaultMocLimit   = 50 // notes taken from a query search
+	defaultMocMinSize = 2  // smallest sub-topic
+)
+
+// MocOptions controls the moc command.
+type MocOptions struct {
+	Target     string // "#tag" or "tag:x", "folder/" or "folder:x", otherwise a search query
+	Title      string // MOC title (default derived from the target)
+	Path       string // vault-relative path (default MOCs/<slug>.md)
+	Limit      int    // query results to include (default 50)
+	Algorithm  string // clustering algorithm (default: promote's)
+	MinSize    int    // smallest sub-topic (default 2)
+	DryRun     bool   // print the note instead of writing it
+	JSONOutput bool
+}
+
+// MocEntry is a note listed in a MOC.
+type MocEntry struct {
+	Path    string `json:"path"`
+	Title   string `json:"title"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// MocSection is a sub-topic of a MOC.
+type MocSection struct {
+	Name    string     `json:"name"`
+	Entries []MocEntry `json:"entries"`
+}
+
+// MocOutput is the JSON output of the moc command.
+type MocOutput struct {
+	Path     string       `json:"path"`
+	Title    string       `json:"title"`
+	Source   string       `json:"source"` // tag:x, folder:x or query:x
+	Sections []MocSection `json:"sections"`
+	Notes    int          `json:"notes"`
+	Created  bool         `json:"created"` // a new note rather than a refresh
+	DryRun   bool         `json:"dry_run,omitempty"`
+}
+
+// mocSource is what a MOC collects: notes with a tag, notes in a folder, or
+// the results of a search.
+type mocSource struct {
+	Kind  string // tag, folder or query
+	Value string
+}
+
+func (s mocSource) String() string { return s.Kind + ":" + s.Value }
+
+// parseMocTarget interprets the moc argument. "#go" and "tag:go" name a tag,
+// "Projects/" and "folder:Projects" a folder, as does the name of an existing
+// vault folder; anything else is a search query ("query:" forces one).
+func parseMocTarget(vaultPath, target string) (mocSource, error) {
+	target = strings.TrimSpace(target)
+	var s mocSource
+	switch {
+	case strings.HasPrefix(target, "#"):
+		s = mocSource{"tag", strings.TrimPrefix(target, "#")}
+	case strings.HasPrefix(target, "tag:"):
+		s = mocSource{"tag", strings.TrimPrefix(strings.TrimPrefix(target, "tag:"), "#")}
+	case strings.HasPrefix(target, "folder:"):
+		s = mocSource{"folder", strings.TrimPrefix(target, "folder:")}
+	case strings.HasPrefix(target, "query:"):
+		s = mocSource{"query", strings.TrimPrefix(target, "query:")}
+	case strings.HasSuffix(target, "/"):
+		s = mocSource{"folder", target}
+	default:
+		s = mocSource{"query", target}
+		if info, err := os.Stat(filepath.Join(vaultPath, target)); err == nil && info.IsDir() {
+			s.Kind = "folder"
+		}
+	}
+	if s.Kind == "folder" {
+		s.Value = strings.Trim(filepath.ToSlash(s.Value), "/")
+	}
+	if strings.TrimSpace(s.Value) == "" {
+		return s, fmt.Errorf("moc requires a tag, folder or search query")
+	}
+	return s, nil
+}
+
+// MocCmd generates or refreshes a Map-of-Content note for a tag, folder or
+// search query. Matching notes are grouped into sub-topics with promote's
+// clustering, each named from the tags or headings its notes share, and
+// listed with a one-line summary. Only the part between the moc markers is
+// rewritten, so hand-written text around it survives a refresh.
+func MocCmd(vaultPath string, opts MocOptions) error {
+	source, err := parseMocTarget(vaultPath, opts.Target)
+	if err != nil {
+		return err
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultMocLimit
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = defaultMocMinSize
+	}
+	params, err := resolvePromoteParams(PromoteOptions{Algorithm: opts.Algorithm, MinSize: opts.MinSize})
+	if err != nil {
+		return err
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = mocTitle(source)
+	}
+	notePath := opts.Path
+	if notePath == "" {
+		notePath = mocFolder + "/" + slugify(title) + ".md"
+	}
+	if !strings.HasSuffix(notePath, ".md") {
+		notePath += ".md"
+	}
+
+	dbPath := index.IndexDBPath(vaultPath)
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("index not found — run 'obsidian index' first")
+	}
+	store, err := index.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer store.Close()
+
+	rows, err := mocMatches(store, source, opts.Limit)
+	if err != nil {
+		return err
+	}
+	kept := rows[:0]
+	for _, r := range rows {
+		if r.Path != notePath {
+			kept = append(kept, r)
+		}
+	}
+	rows = kept
+	if len(rows) == 0 {
+		return fmt.Errorf("no notes match %s", source)
+	}
+
+	sections, err := buildMocSections(vaultPath, rows, source, params)
+	if err != nil {
+		return err
+	}
+	result := MocOutput{
+		Path:     notePath,
+		Title:    title,
+		Source:   source.String(),
+		Sections: sections,
+		Notes:    len(rows),
+		DryRun:   opts.DryRun,
+	}
+
+	fullPath := filepath.Join(vaultPath, notePath)
+	block := renderMocBlock(sections)
+	var content string
+	existing, err := os.ReadFile(fullPath)
+	switch {
+	case err == nil:
+		content = replaceMocBlock(string(existing), block)
+	case os.IsNotExist(err):
+		result.Created = true
+		content = newMocNote(title, source, block, time.Now())
+	default:
+		return fmt.Errorf("reading %s: %w", notePath, err)
+	}
+
+	if !opts.DryRun {
+		if result.Created {
+			if err := vault.WriteNote(vaultPath, notePath, content); err != nil {
+				return err
+			}
+		} else if content != string(existing) {
+			snapshotNote(vaultPath, notePath, "moc")
+			if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+				return fmt.Errorf("writing %s: %w", notePath, err)
+			}
+		}
+	}
+
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+	if opts.DryRun {
+		fmt.Print(content)
+		return nil
+	}
+	verb := "Refreshed"
+	if result.Created {
+		verb = "Created"
+	}
+	fmt.Printf("%s %s: %d notes in %d sections\n", verb, notePath, result.Notes, len(sections))
+	return nil
+}
+
+// mocTitle derives a MOC title from its source: the tag or folder name, or
+// the query, with the first letter upper-cased.
+func mocTitle(s mocSource) string {
+	name := s.Value
+	if s.Kind != "query" {
+		name = name[strings.LastIndex(name, "/")+1:]
+	}
+	return upperFirst(strings.TrimSpace(name))
+}
+
+// mocMatches returns the indexed notes a MOC collects. Queries use hybrid
+// search when an embedding API key is configured and keyword search
+// otherwise. Tags match case-insensitively and include nested tags, so "go"
+// matches "go/concurrency".
+func mocMatches(store *index.Store, s mocSource, limit int) ([]index.NoteRow, error) {
+	rows, err := store.GetAllNoteRows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+
+	var matched []index.NoteRow
+	switch s.Kind {
+	case "tag":
+		for _, r := range rows {
+			if mocHasTag(splitIndexList(r.Tags), s.Value) {
+				matched = append(matched, r)
+			}
+		}
+	case "folder":
+		for _, r := range rows {
+			if strings.HasPrefix(r.Path, s.Value+"/") {
+				matched = append(matched, r)
+			}
+		}
+	default:
+		var results []index.SearchResult
+		embedClient := index.NewEmbeddingClient(config.ResolveAPIKey())
+		if embedClient.IsAvailable() {
+			queryEmb, embErr := embedClient.Embed(context.Background(), s.Value)
+			if embErr == nil {
+				results, err = store.SearchHybrid(s.Value, queryEmb, limit)
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: embedding failed, falling back to keyword search: %v\n", embErr)
+				results, err = store.SearchKeyword(s.Value, limit)
+			}
+		} else {
+			results, err = store.SearchKeyword(s.Value, limit)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		byPath := make(map[string]index.NoteRow, len(rows))
+		for _, r := range rows {
+			byPath[r.Path] = r
+		}
+		for _, res := range results {
+			if r, ok := byPath[res.Path]; ok {
+				matched = append(matched, r)
+			}
+		}
+	}
+	return matched, nil
+}
+
+// splitIndexList splits a comma-separated index column.
+func splitIndexList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// mocHasTag reports whether tags include tag or a tag nested under it.
+func mocHasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) || strings.HasPrefix(strings.ToLower(t), strings.ToLower(tag)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// buildMocSections clusters the matched notes into named sub-topics. Notes
+// that fit no cluster are listed last under "Other"; when nothing clusters,
+// a single unnamed section holds every note. For a tag MOC the tag itself is
+// ignored, since every note shares it.
+func buildMocSections(vaultPath string, rows []index.NoteRow, s mocSource, params promoteParams) ([]MocSection, error) {
+	notes := make([]*promoteNoteInfo, len(rows))
+	headings := make(map[string][]string, len(rows))
+	summaries := make(map[string]string, len(rows))
+	for i, r := range rows {
+		summaries[r.Path] = mocSummary(vaultPath, r)
+		var tags []string
+		for _, t := range splitIndexList(r.Tags) {
+			if s.Kind != "tag" || !strings.EqualFold(t, s.Value) {
+				tags = append(tags, t)
+			}
+		}
+		notes[i] = &promoteNoteInfo{Path: r.Path, Title: r.Title, Tags: tags, Embedding: r.Embedding, Body: r.Body}
+		headings[r.Path] = strings.Split(r.Headings, "\n")
+	}
+
+	_, groups, err := detectClusters(notes, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []MocSection
+	used := make(map[string]bool)
+	names := make(map[string]int)
+	for _, g := range groups {
+		name := mocSectionName(g, headings)
+		if names[strings.ToLower(name)]++; names[strings.ToLower(name)] > 1 {
+			name = fmt.Sprintf("%s (%d)", name, names[strings.ToLower(name)])
+		}
+		sections = append(sections, MocSection{Name: name, Entries: mocEntries(g, summaries)})
+		for _, n := range g {
+			used[n.Path] = true
+		}
+	}
+
+	var rest []*promoteNoteInfo
+	for _, n := range notes {
+		if !used[n.Path] {
+			rest = append(rest, n)
+		}
+	}
+	if len(rest) > 0 {
+		name := "Other"
+		if len(sections) == 0 {
+			name = ""
+		}
+		sections = append(sections, MocSection{Name: name, Entries: mocEntries(rest, summaries)})
+	}
+	return sections, nil
+}
+
+// mocSectionName names a sub-topic from, in order of preference, the tags
+// every note shares, a heading most of its notes share, a tag at least two
+// of its notes share, or the first note's title.
+func mocSectionName(notes []*promoteNoteInfo, headings map[string][]string) string {
+	if common := findCommonTags(notes); len(common) > 0 {
+		if len(common) > 2 {
+			common = common[:2]
+		}
+		for i, t := range common {
+			common[i] = upperFirst(t)
+		}
+		return strings.Join(common, " & ")
+	}
+
+	if h := mocMostShared(notes, func(n *promoteNoteInfo) []string {
+		var out []string
+		for _, h := range headings[n.Path] {
+			if h = strings.TrimSpace(h); h != "" && !strings.EqualFold(h, n.Title) {
+				out = append(out, h)
+			}
+		}
+		return out
+	}); h != "" {
+		return h
+	}
+
+	if t := mocMostShared(notes, func(n *promoteNoteInfo) []string { return n.Tags }); t != "" {
+		return upperFirst(t)
+	}
+	return notes[0].Title
+}
+
+// mocMostShared returns the value, compared case-insensitively, that the most
+// notes have, as long as at least two do. Ties go to the value seen first.
+func mocMostShared(notes []*promoteNoteInfo, values func(*promoteNoteInfo) []string) string {
+	counts := make(map[string]int)
+	first := make(map[string]string)
+	var order []string
+	for _, n := range notes {
+		seen := make(map[string]bool)
+		for _, v := range values(n) {
+			key := strings.ToLower(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, ok := first[key]; !ok {
+				first[key] = v
+				order = append(order, key)
+			}
+			counts[key]++
+		}
+	}
+	best := ""
+	for _, key := range order {
+		if counts[key] >= 2 && (best == "" || counts[key] > counts[best]) {
+			best = key
+		}
+	}
+	return first[best]
+}
+
+// mocSummary returns a one-line summary of a note: its summary or
+// description property when set, otherwise its first sentence.
+func mocSummary(vaultPath string, r index.NoteRow) string {
+	body := r.Body
+	if note, err := vault.ReadNote(vaultPath, r.Path); err == nil {
+		if s := firstNonEmpty(frontmatterString(note.Frontmatter, "summary"), frontmatterString(note.Frontmatter, "description")); s != "" {
+			return truncateRunes(s, maxSummaryRunes)
+		}
+		body = note.Body
+	}
+	return extractiveSummary(body)
+}
+
+// mocEntries lists notes by title with their summaries.
+func mocEntries(notes []*promoteNoteInfo, summaries map[string]string) []MocEntry {
+	entries := make([]MocEntry, len(notes))
+	for i, n := range notes {
+		entries[i] = MocEntry{Path: n.Path, Title: n.Title, Summary: summaries[n.Path]}
+	}
+	sort.SliceStable(entries, func(a, b int) bool {
+		return strings.ToLower(entries[a].Title) < strings.ToLower(entries[b].Title)
+	})
+	return entries
+}
+
+// renderMocBlock renders the generated part of a MOC, markers included.
+func renderMocBlock(sections []MocSection) string {
+	var b strings.Builder
+	b.WriteString(mocStartMarker + "\n")
+	for i, sec := range sections {
+		if i > 0 || sec.Name != "" {
+			b.WriteString("\n")
+		}
+		if sec.Name != "" {
+			fmt.Fprintf(&b, "## %s\n\n", sec.Name)
+		}
+		for _, e := range sec.Entries {
+			line := "- " + digestLink(e.Path, e.Title)
+			if e.Summary != "" {
+				line += " — " + e.Summary
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	b.WriteString(mocEndMarker + "\n")
+	return b.String()
+}
+
+// replaceMocBlock swaps the generated block of an existing MOC for block.
+// A note without markers gets the block appended.
+func replaceMocBlock(content, block string) string {
+	start := strings.Index(content, mocStartMarker)
+	end := strings.Index(content, mocEndMarker)
+	if start < 0 || end < start {
+		return strings.TrimRight(content, "\n") + "\n\n" + block
+	}
+	end += len(mocEndMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start] + block + content[end:]
+}
+
+// newMocNote renders a new MOC note around the generated block.
+func newMocNote(title string, s mocSource, block string, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString("type: moc\n")
+	fmt.Fprintf(&b, "moc-source: %s\n", frontmatterValue(s.String()))
+	fmt.Fprintf(&b, "created: %s\n", now.Format("2006-01-02"))
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	b.WriteString(block)
+	return b.String()
+}
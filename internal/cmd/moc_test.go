@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+)
+
+func TestParseMocTarget(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{"Projects/a.md": "A\n"})
+	tests := []struct {
+		in   string
+		want mocSource
+	}{
+		{"#databases", mocSource{"tag", "databases"}},
+		{"tag:#go/concurrency", mocSource{"tag", "go/concurrency"}},
+		{"Projects", mocSource{"folder", "Projects"}},
+		{"Areas/Work/", mocSource{"folder", "Areas/Work"}},
+		{"folder:Projects", mocSource{"folder", "Projects"}},
+		{"vector search", mocSource{"query", "vector search"}},
+		{"query:Projects", mocSource{"query", "Projects"}},
+	}
+	for _, tt := range tests {
+		got, err := parseMocTarget(dir, tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseMocTarget(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseMocTarget(dir, "#"); err == nil {
+		t.Error("parseMocTarget(#) succeeded")
+	}
+}
+
+// writeMocVault builds a vault of database notes on two sub-topics, indexed
+// with embeddings.
+func writeMocVault(t *testing.T) (string, *index.Store) {
+	t.Helper()
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	dir := writeVaultFiles(t, map[string]string{
+		"Notes/sharding.md":     "---\ntags: [db, scaling]\n---\nSplitting tables by key. More detail.\n",
+		"Notes/partitioning.md": "---\ntags: [db, scaling]\ndescription: Range and hash partitions\n---\nBody.\n",
+		"Notes/btree.md":        "---\ntags: [db, indexing]\n---\n## Lookups\n\nBalanced trees.\n",
+		"Notes/lsm.md":          "---\ntags: [db, indexing]\n---\nLog-structured merges.\n",
+		"Notes/misc.md":         "---\ntags: [db]\n---\nLoose ends.\n",
+		"Notes/go.md":           "---\ntags: [go]\n---\nNot a database.\n",
+	})
+	if err := os.MkdirAll(filepath.Join(dir, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	for _, row := range []index.NoteRow{
+		{Path: "Notes/sharding.md", Title: "Sharding", Tags: "db, scaling", Body: "Splitting tables by key.", Embedding: []float32{1, 0}},
+		{Path: "Notes/partitioning.md", Title: "Partitioning", Tags: "db, scaling", Body: "Body.", Embedding: []float32{0.95, 0.05}},
+		{Path: "Notes/btree.md", Title: "B-trees", Tags: "db, indexing", Headings: "Lookups", Body: "Balanced trees.", Embedding: []float32{0, 1}},
+		{Path: "Notes/lsm.md", Title: "LSM trees", Tags: "db, indexing", Body: "Log-structured merges.", Embedding: []float32{0.05, 0.95}},
+		{Path: "Notes/misc.md", Title: "Misc", Tags: "db", Body: "Loose ends."},
+		{Path: "Notes/go.md", Title: "Go", Tags: "go", Body: "Not a database."},
+	} {
+		if err := store.UpsertNote(&row); err != nil {
+			t.Fatalf("UpsertNote: %v", err)
+		}
+	}
+	return dir, store
+}
+
+func TestMocCmd(t *testing.T) {
+	dir, store := writeMocVault(t)
+
+	out := captureStdout(t, func() {
+		if err := MocCmd(dir, MocOptions{Target: "#db", JSONOutput: true}); err != nil {
+			t.Fatalf("MocCmd() error: %v", err)
+		}
+	})
+	var result MocOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Path != "MOCs/db.md" || !result.Created || result.Notes != 5 || result.Source != "tag:db" {
+		t.Errorf("result = %+v", result)
+	}
+	var names []string
+	for _, sec := range result.Sections {
+		names = append(names, sec.Name)
+	}
+	if got := strings.Join(names, ","); got != "Indexing,Scaling,Other" {
+		t.Errorf("sections = %s", got)
+	}
+
+	content := mustRead(t, filepath.Join(dir, result.Path))
+	for _, want := range []string{
+		"type: moc\nmoc-source: tag:db\n",
+		"# Db\n\n" + mocStartMarker + "\n\n## Indexing\n\n- [[Notes/btree|B-trees]] — Balanced trees.\n",
+		"- [[Notes/partitioning|Partitioning]] — Range and hash partitions\n- [[Notes/sharding|Sharding]] — Splitting tables by key.\n",
+		"## Other\n\n- [[Notes/misc|Misc]] — Loose ends.\n" + mocEndMarker + "\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("MOC missing %q:\n%s", want, content)
+		}
+	}
+
+	// Hand-written text around the markers survives a refresh that picks up
+	// a new note.
+	edited := strings.Replace(content, "# Db\n\n", "# Db\n\nMy intro.\n\n", 1) + "\n## Reading list\n\n- Designing Data-Intensive Applications\n"
+	if err := os.WriteFile(filepath.Join(dir, result.Path), []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertNote(&index.NoteRow{Path: "Notes/wal.md", Title: "WAL", Tags: "db", Body: "Write-ahead logs."}); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		if err := MocCmd(dir, MocOptions{Target: "tag:db"}); err != nil {
+			t.Fatalf("MocCmd() refresh error: %v", err)
+		}
+	})
+	refreshed := mustRead(t, filepath.Join(dir, result.Path))
+	for _, want := range []string{"My intro.\n\n" + mocStartMarker, "- [[Notes/wal|WAL]] — Write-ahead logs.\n", mocEndMarker + "\n\n## Reading list\n"} {
+		if !strings.Contains(refreshed, want) {
+			t.Errorf("refreshed MOC missing %q:\n%s", want, refreshed)
+		}
+	}
+	if strings.Count(refreshed, mocStartMarker) != 1 {
+		t.Errorf("refresh duplicated the generated block:\n%s", refreshed)
+	}
+}
+
+func TestMocCmd_FolderDryRun(t *testing.T) {
+	dir, _ := writeMocVault(t)
+	out := captureStdout(t, func() {
+		if err := MocCmd(dir, MocOptions{Target: "Notes/", Title: "All notes", DryRun: true}); err != nil {
+			t.Fatalf("MocCmd() error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "# All notes\n") || !strings.Contains(out, "[[Notes/go|Go]]") {
+		t.Errorf("dry run output:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "MOCs/all-notes.md")); !os.IsNotExist(err) {
+		t.Error("dry run wrote the MOC")
+	}
+}
+
+func TestReplaceMocBlock(t *testing.T) {
+	block := mocStartMarker + "\n- new\n" + mocEndMarker + "\n"
+	got := replaceMocBlock("intro\n"+mocStartMarker+"\n- old\n"+mocEndMarker+"\noutro\n", block)
+	if want := "intro\n" + block + "outro\n"; got != want {
+		t.Errorf("replace = %q, want %q", got, want)
+	}
+	got = replaceMocBlock("hand-written\n", block)
+	if want := "hand-written\n\n" + block; got != want {
+		t.Errorf("append = %q, want %q", got, want)
+	}
+}
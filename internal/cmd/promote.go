@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,22 +11,107 @@ import (
 	"strings"
 	"time"
 
+	"github.com/joeyhipolito/obsidian-cli/internal/cluster"
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
 	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/llm"
 	"github.com/joeyhipolito/obsidian-cli/internal/output"
 	"github.com/joeyhipolito/obsidian-cli/internal/vault"
 )
 
+const promoteArchiveFolder = "Archive"
+
+// Clustering defaults, used when neither flags nor config set a value.
 const (
-	promoteMinClusterSize      = 3
-	promoteTagJaccardThreshold = 0.25
-	promoteSemanticThreshold   = 0.80
-	promoteArchiveFolder       = "Archive"
+	promoteDefaultAlgorithm         = cluster.Agglomerative
+	promoteDefaultTagThreshold      = 0.25
+	promoteDefaultSemanticThreshold = 0.80
+	promoteDefaultMinSize           = 3
+	promoteDefaultMaxSize           = 15
 )
 
 // PromoteOptions holds flags for the promote command.
+// Select, All and MinScore choose clusters without prompting, so promotion
+// can be scripted; they refer to clusters as numbered by --dry-run.
 type PromoteOptions struct {
+	Select     []int   // cluster numbers to promote (1-based)
+	All        bool    // promote every cluster
+	MinScore   float64 // only promote clusters scoring at least this
+	Synthesize bool    // merge sources into one deduplicated note instead of stacking them
+	Summarize  bool    // add an LLM summary at the top; implies Synthesize
 	DryRun     bool
 	JSONOutput bool
+
+	// Clustering overrides; zero values fall back to config, then defaults.
+	Algorithm         string
+	TagThreshold      float64
+	SemanticThreshold float64
+	Linkage           float64
+	MinSize           int
+	MaxSize           int
+}
+
+// selecting reports whether clusters are chosen by flags rather than at a prompt.
+func (o PromoteOptions) selecting() bool {
+	return len(o.Select) > 0 || o.All || o.MinScore > 0
+}
+
+// canonicalStyle selects how promoteCluster writes the canonical note.
+type canonicalStyle struct {
+	Synthesize bool
+	// Summarize returns the summary shown at the top of a synthesized note;
+	// nil for none.
+	Summarize func(notes []*promoteNoteInfo) string
+}
+
+// promoteParams controls how notes are grouped into clusters.
+type promoteParams struct {
+	Algorithm         cluster.Algorithm
+	TagThreshold      float64 // minimum tag Jaccard for two notes to be related
+	SemanticThreshold float64 // minimum embedding cosine for two notes to be related
+	Linkage           float64 // agglomerative: minimum average similarity to merge
+	MinSize           int
+	MaxSize           int
+}
+
+// resolvePromoteParams combines flags, config and defaults, in that order.
+func resolvePromoteParams(opts PromoteOptions) (promoteParams, error) {
+	settings := config.ResolvePromote()
+	pickFloat := func(vals ...float64) float64 {
+		for _, v := range vals {
+			if v > 0 {
+				return v
+			}
+		}
+		return 0
+	}
+	pickInt := func(vals ...int) int {
+		for _, v := range vals {
+			if v > 0 {
+				return v
+			}
+		}
+		return 0
+	}
+	p := promoteParams{
+		Algorithm:         promoteDefaultAlgorithm,
+		TagThreshold:      pickFloat(opts.TagThreshold, settings.TagThreshold, promoteDefaultTagThreshold),
+		SemanticThreshold: pickFloat(opts.SemanticThreshold, settings.SemanticThreshold, promoteDefaultSemanticThreshold),
+		Linkage:           pickFloat(opts.Linkage, settings.Linkage, cluster.DefaultLinkage),
+		MinSize:           pickInt(opts.MinSize, settings.MinSize, promoteDefaultMinSize),
+		MaxSize:           pickInt(opts.MaxSize, settings.MaxSize, promoteDefaultMaxSize),
+	}
+	if name := firstNonEmpty(opts.Algorithm, settings.Algorithm); name != "" {
+		alg, err := cluster.ParseAlgorithm(name)
+		if err != nil {
+			return promoteParams{}, err
+		}
+		p.Algorithm = alg
+	}
+	if p.MaxSize < p.MinSize {
+		return promoteParams{}, fmt.Errorf("max cluster size %d is below min size %d", p.MaxSize, p.MinSize)
+	}
+	return p, nil
 }
 
 // promoteNoteInfo holds metadata for a note used in clustering.
@@ -74,6 +160,10 @@ type PromoteSummary struct {
 
 // PromoteCmd runs cluster detection and optionally promotes clusters into canonical notes.
 func PromoteCmd(vaultPath string, opts PromoteOptions) error {
+	params, err := resolvePromoteParams(opts)
+	if err != nil {
+		return err
+	}
 	notes, err := collectNotesForClustering(vaultPath)
 	if err != nil {
 		return fmt.Errorf("loading notes: %w", err)
@@ -88,13 +178,43 @@ func PromoteCmd(vaultPath string, opts PromoteOptions) error {
 		}
 	}
 
-	clusters, clusterNotes := detectClusters(notes, promoteTagJaccardThreshold, promoteSemanticThreshold, promoteMinClusterSize)
+	clusters, clusterNotes, err := detectClusters(notes, params)
+	if err != nil {
+		return err
+	}
 
 	result := PromoteOutput{
 		Clusters: clusters,
 		Summary:  PromoteSummary{ClustersFound: len(clusters)},
 	}
 
+	if opts.selecting() {
+		selected, err := selectClusters(clusters, opts)
+		if err != nil {
+			return err
+		}
+		style := promoteStyle(opts)
+		now := time.Now()
+		for _, idx := range selected {
+			if opts.DryRun {
+				result.Promoted = append(result.Promoted, planPromotion(clusterNotes[idx], style, now))
+				continue
+			}
+			p, err := promoteCluster(vaultPath, clusterNotes[idx], now, style)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not promote cluster %d: %v\n", idx+1, err)
+				continue
+			}
+			result.Promoted = append(result.Promoted, p)
+		}
+		result.Summary.ClustersPromoted = len(result.Promoted)
+		if opts.JSONOutput {
+			return output.JSON(result)
+		}
+		printPromoteReport(result.Promoted)
+		return nil
+	}
+
 	if opts.JSONOutput {
 		return output.JSON(result)
 	}
@@ -105,11 +225,11 @@ func PromoteCmd(vaultPath string, opts PromoteOptions) error {
 	}
 
 	if len(clusters) == 0 {
-		fmt.Println("No clusters found (need 3+ related notes by tag overlap or semantic similarity).")
+		fmt.Printf("No clusters found (need %d+ related notes by tag overlap or semantic similarity).\n", params.MinSize)
 		return nil
 	}
 
-	promoted, err := interactivePromote(vaultPath, clusters, clusterNotes, time.Now())
+	promoted, err := interactivePromote(vaultPath, clusters, clusterNotes, time.Now(), promoteStyle(opts))
 	if err != nil {
 		return err
 	}
@@ -196,75 +316,87 @@ func loadEmbeddingsInto(store *index.Store, notes []*promoteNoteInfo) {
 	}
 }
 
-// detectClusters finds groups of 3+ related notes by tag Jaccard and semantic similarity.
-// Returns the Cluster slice (for output) and a parallel slice of raw note groups (for promotion).
-func detectClusters(notes []*promoteNoteInfo, jaccardThreshold, semanticThreshold float64, minSize int) ([]Cluster, [][]*promoteNoteInfo) {
-	n := len(notes)
-	adj := make([][]int, n)
-
-	for i := 0; i < n; i++ {
-		for j := i + 1; j < n; j++ {
-			related := false
-
-			// Tag Jaccard similarity (only when at least one note has tags).
-			if len(notes[i].Tags) > 0 || len(notes[j].Tags) > 0 {
-				if tagJaccard(notes[i].Tags, notes[j].Tags) >= jaccardThreshold {
-					related = true
-				}
-			}
-
-			// Semantic similarity (only when both notes have embeddings).
-			if !related && notes[i].Embedding != nil && notes[j].Embedding != nil {
-				sim := float64(index.CosineSimilarity(notes[i].Embedding, notes[j].Embedding))
-				if sim >= semanticThreshold {
-					related = true
-				}
-			}
-
-			if related {
-				adj[i] = append(adj[i], j)
-				adj[j] = append(adj[j], i)
-			}
-		}
+// detectClusters groups related notes with the configured algorithm.
+// Returns the Cluster slice (for output) and a parallel slice of raw note
+// groups (for promotion). Clusters are ordered by score, then by first path,
+// and notes within a cluster by path, so the numbering shown by --dry-run is
+// stable across runs for --select.
+func detectClusters(notes []*promoteNoteInfo, params promoteParams) ([]Cluster, [][]*promoteNoteInfo, error) {
+	sorted := append([]*promoteNoteInfo(nil), notes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	groups, err := cluster.Run(params.Algorithm, promoteSimilarity(sorted, params), cluster.Options{
+		MinSize: params.MinSize,
+		MaxSize: params.MaxSize,
+		Linkage: params.Linkage,
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// BFS to find connected components.
-	visited := make([]bool, n)
 	var clusters []Cluster
 	var clusterNotes [][]*promoteNoteInfo
-
-	for start := 0; start < n; start++ {
-		if visited[start] {
-			continue
-		}
-		var component []int
-		queue := []int{start}
-		visited[start] = true
-		for len(queue) > 0 {
-			curr := queue[0]
-			queue = queue[1:]
-			component = append(component, curr)
-			for _, neighbor := range adj[curr] {
-				if !visited[neighbor] {
-					visited[neighbor] = true
-					queue = append(queue, neighbor)
-				}
-			}
+	for _, g := range groups {
+		noteList := make([]*promoteNoteInfo, len(g))
+		for i, idx := range g {
+			noteList[i] = sorted[idx]
 		}
+		clusters = append(clusters, buildClusterInfo(noteList))
+		clusterNotes = append(clusterNotes, noteList)
+	}
 
-		if len(component) < minSize {
-			continue
-		}
+	order := make([]int, len(clusters))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return clusters[order[a]].Score > clusters[order[b]].Score
+	})
+	outClusters := make([]Cluster, len(order))
+	outNotes := make([][]*promoteNoteInfo, len(order))
+	for i, idx := range order {
+		outClusters[i], outNotes[i] = clusters[idx], clusterNotes[idx]
+	}
+	return outClusters, outNotes, nil
+}
 
-		noteList := make([]*promoteNoteInfo, len(component))
-		for i, idx := range component {
-			noteList[i] = notes[idx]
+// promoteSimilarity builds the pairwise similarity matrix for clustering.
+// Two notes are related when their tag Jaccard (if either has tags) or their
+// embedding cosine (if both have embeddings) reaches its threshold. A related
+// pair scores from 0.5 at the threshold to 1 for a perfect match, taking the
+// stronger signal, so the two signals are comparable whatever their
+// thresholds; unrelated pairs score 0.
+func promoteSimilarity(notes []*promoteNoteInfo, params promoteParams) [][]float64 {
+	rescale := func(s, threshold float64) float64 {
+		if s < threshold {
+			return 0
 		}
-		clusters = append(clusters, buildClusterInfo(noteList))
-		clusterNotes = append(clusterNotes, noteList)
+		if threshold >= 1 {
+			return 1
+		}
+		return min(0.5+0.5*(s-threshold)/(1-threshold), 1)
 	}
 
-	return clusters, clusterNotes
+	n := len(notes)
+	sim := make([][]float64, n)
+	for i := range sim {
+		sim[i] = make([]float64, n)
+		sim[i][i] = 1
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			var s float64
+			if len(notes[i].Tags) > 0 || len(notes[j].Tags) > 0 {
+				s = rescale(tagJaccard(notes[i].Tags, notes[j].Tags), params.TagThreshold)
+			}
+			if notes[i].Embedding != nil && notes[j].Embedding != nil {
+				cos := float64(index.CosineSimilarity(notes[i].Embedding, notes[j].Embedding))
+				s = max(s, rescale(cos, params.SemanticThreshold))
+			}
+			sim[i][j], sim[j][i] = s, s
+		}
+	}
+	return sim
 }
 
 // buildClusterInfo creates a Cluster descriptor from a list of notes.
@@ -365,8 +497,64 @@ func tagJaccard(a, b []string) float64 {
 	return float64(intersection) / float64(len(union))
 }
 
+// selectClusters returns the indexes of the clusters chosen by opts.Select or
+// opts.All, keeping those that score at least opts.MinScore. MinScore alone
+// selects from every cluster.
+func selectClusters(clusters []Cluster, opts PromoteOptions) ([]int, error) {
+	var selected []int
+	if len(opts.Select) > 0 && !opts.All {
+		seen := make(map[int]bool)
+		for _, n := range opts.Select {
+			if n < 1 || n > len(clusters) {
+				return nil, fmt.Errorf("no cluster %d (found %d)", n, len(clusters))
+			}
+			if !seen[n] {
+				seen[n] = true
+				selected = append(selected, n-1)
+			}
+		}
+	} else {
+		for i := range clusters {
+			selected = append(selected, i)
+		}
+	}
+
+	var kept []int
+	for _, idx := range selected {
+		if clusters[idx].Score >= opts.MinScore {
+			kept = append(kept, idx)
+		}
+	}
+	return kept, nil
+}
+
+// promoteStyle returns the canonical note style for opts. The LLM is only
+// set up when a summary was asked for and the run writes notes.
+func promoteStyle(opts PromoteOptions) canonicalStyle {
+	style := canonicalStyle{Synthesize: opts.Synthesize || opts.Summarize}
+	if !opts.Summarize || opts.DryRun {
+		return style
+	}
+	client, err := llm.FromSettings(config.ResolveLLM())
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: LLM disabled: %v\n", err)
+	case client == nil:
+		fmt.Fprintln(os.Stderr, "Warning: no LLM provider configured; skipping summaries")
+	default:
+		style.Summarize = func(notes []*promoteNoteInfo) string {
+			summary, err := promoteSummary(context.Background(), client, synthesizeBody(notes))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: summary failed: %v\n", err)
+			}
+			return summary
+		}
+	}
+	return style
+}
+
 // interactivePromote displays clusters and prompts the user to select which to promote.
-func interactivePromote(vaultPath string, clusters []Cluster, clusterNotes [][]*promoteNoteInfo, now time.Time) ([]PromotedCluster, error) {
+func interactivePromote(vaultPath string, clusters []Cluster, clusterNotes [][]*promoteNoteInfo, now time.Time, style canonicalStyle) ([]PromotedCluster, error) {
 	printClusters(clusters)
 
 	fmt.Printf("\nFound %d cluster(s). Enter cluster numbers to promote (e.g. \"1 2\"), \"all\", or \"none\": ", len(clusters))
@@ -399,7 +587,7 @@ func interactivePromote(vaultPath string, clusters []Cluster, clusterNotes [][]*
 
 	var promoted []PromotedCluster
 	for _, idx := range toPromote {
-		p, promErr := promoteCluster(vaultPath, clusterNotes[idx], now)
+		p, promErr := promoteCluster(vaultPath, clusterNotes[idx], now, style)
 		if promErr != nil {
 			fmt.Printf("  Error promoting cluster %d: %v\n", idx+1, promErr)
 			continue
@@ -410,8 +598,8 @@ func interactivePromote(vaultPath string, clusters []Cluster, clusterNotes [][]*
 }
 
 // promoteCluster merges a cluster of notes into a single canonical note and archives the sources.
-func promoteCluster(vaultPath string, notes []*promoteNoteInfo, now time.Time) (PromotedCluster, error) {
-	canonicalPath, content := buildCanonicalNote(notes, now)
+func promoteCluster(vaultPath string, notes []*promoteNoteInfo, now time.Time, style canonicalStyle) (PromotedCluster, error) {
+	canonicalPath, content := buildPromotedNote(notes, now, style)
 
 	// Deconflict if the canonical path already exists.
 	fullCanonical := filepath.Join(vaultPath, canonicalPath)
@@ -447,29 +635,35 @@ func promoteCluster(vaultPath string, notes []*promoteNoteInfo, now time.Time) (
 	}, nil
 }
 
+// planPromotion describes what promoteCluster would do, without writing.
+// SourcePaths are the notes that would be archived.
+func planPromotion(notes []*promoteNoteInfo, style canonicalStyle, now time.Time) PromotedCluster {
+	canonicalPath, _ := buildPromotedNote(notes, now, canonicalStyle{Synthesize: style.Synthesize})
+	p := PromotedCluster{CanonicalPath: canonicalPath, DryRun: true}
+	for _, n := range notes {
+		p.SourcePaths = append(p.SourcePaths, n.Path)
+	}
+	return p
+}
+
+// buildPromotedNote builds the canonical note in the given style.
+func buildPromotedNote(notes []*promoteNoteInfo, now time.Time, style canonicalStyle) (string, string) {
+	if !style.Synthesize {
+		return buildCanonicalNote(notes, now)
+	}
+	summary := ""
+	if style.Summarize != nil {
+		summary = style.Summarize(notes)
+	}
+	return buildSynthesizedNote(notes, now, summary)
+}
+
 // buildCanonicalNote creates the merged note content and returns (vault-relative path, content).
 func buildCanonicalNote(notes []*promoteNoteInfo, now time.Time) (string, string) {
 	title := deriveClusterTitle(notes)
-	allTags := mergeUniqueTags(notes)
 
 	var b strings.Builder
-	b.WriteString("---\n")
-	fmt.Fprintf(&b, "title: %s\n", title)
-	b.WriteString("type: note\n")
-	b.WriteString("status: active\n")
-	fmt.Fprintf(&b, "created: %s\n", now.Format("2006-01-02"))
-	b.WriteString("promoted-from:\n")
-	for _, n := range notes {
-		name := strings.TrimSuffix(filepath.Base(n.Path), ".md")
-		fmt.Fprintf(&b, "  - '[[%s]]'\n", name)
-	}
-	if len(allTags) > 0 {
-		b.WriteString("tags:\n")
-		for _, t := range allTags {
-			fmt.Fprintf(&b, "  - %s\n", t)
-		}
-	}
-	b.WriteString("---\n\n")
+	writeCanonicalFrontmatter(&b, title, notes, now)
 	fmt.Fprintf(&b, "# %s\n\n", title)
 
 	for i, n := range notes {
@@ -486,6 +680,30 @@ func buildCanonicalNote(notes []*promoteNoteInfo, now time.Time) (string, string
 	return "Notes/" + slug + ".md", b.String()
 }
 
+// writeCanonicalFrontmatter writes the frontmatter of a canonical note, which
+// links back to its sources and carries all their tags.
+func writeCanonicalFrontmatter(b *strings.Builder, title string, notes []*promoteNoteInfo, now time.Time) {
+	allTags := mergeUniqueTags(notes)
+
+	b.WriteString("---\n")
+	fmt.Fprintf(b, "title: %s\n", title)
+	b.WriteString("type: note\n")
+	b.WriteString("status: active\n")
+	fmt.Fprintf(b, "created: %s\n", now.Format("2006-01-02"))
+	b.WriteString("promoted-from:\n")
+	for _, n := range notes {
+		name := strings.TrimSuffix(filepath.Base(n.Path), ".md")
+		fmt.Fprintf(b, "  - '[[%s]]'\n", name)
+	}
+	if len(allTags) > 0 {
+		b.WriteString("tags:\n")
+		for _, t := range allTags {
+			fmt.Fprintf(b, "  - %s\n", t)
+		}
+	}
+	b.WriteString("---\n\n")
+}
+
 // deriveClusterTitle picks a title for the canonical note.
 // Uses the most-common tag, falling back to the first note's title.
 func deriveClusterTitle(notes []*promoteNoteInfo) string {
@@ -549,6 +767,7 @@ func archiveSourceNote(vaultPath string, n *promoteNoteInfo, canonicalName strin
 	if err := os.WriteFile(fullArchive, []byte(updatedContent), 0644); err != nil {
 		return "", fmt.Errorf("writing archive note: %w", err)
 	}
+	snapshotNote(vaultPath, n.Path, "promote")
 	if err := os.Remove(filepath.Join(vaultPath, n.Path)); err != nil {
 		return "", fmt.Errorf("removing original: %w", err)
 	}
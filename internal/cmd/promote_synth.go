@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/dedupe"
+	"github.com/joeyhipolito/obsidian-cli/internal/llm"
+)
+
+// Paragraphs are dropped from a synthesized note when their word 3-gram
+// Jaccard similarity to a kept paragraph reaches promoteDupJaccard, or when
+// promoteDupContained of their 3-grams already appear in one.
+const (
+	promoteDupJaccard   = 0.8
+	promoteDupContained = 0.9
+)
+
+// promoteSummaryMaxInput caps the merged text sent to the LLM for a summary.
+const promoteSummaryMaxInput = 12000
+
+var synthHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+// synthSection is a heading of the synthesized note with the paragraphs kept
+// under it. The root section holds text that comes before any heading.
+type synthSection struct {
+	heading    string
+	level      int // relative depth below the note title, 0 for the root
+	paragraphs []string
+	sources    []string // note names that contributed kept paragraphs
+	children   []*synthSection
+}
+
+// child returns the subsection with the given heading, creating it if needed.
+// Headings match case-insensitively.
+func (s *synthSection) child(heading string) *synthSection {
+	for _, c := range s.children {
+		if strings.EqualFold(c.heading, heading) {
+			return c
+		}
+	}
+	c := &synthSection{heading: heading, level: s.level + 1}
+	s.children = append(s.children, c)
+	return c
+}
+
+// synthesizer merges note bodies into one section tree, collapsing repeated
+// paragraphs across all sections.
+type synthesizer struct {
+	root *synthSection
+	kept []map[uint64]bool // shingles of every kept paragraph
+}
+
+func newSynthesizer() *synthesizer {
+	return &synthesizer{root: &synthSection{}}
+}
+
+// add merges one note's body. Headings are placed by their path from the top
+// of the note, with levels taken relative to the note's shallowest heading,
+// so "## Setup" in one note and "# Setup" in another merge. A leading H1 that
+// repeats the note title is dropped.
+func (s *synthesizer) add(name, title, body string) {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) > 0 {
+		if m := synthHeadingRe.FindStringSubmatch(lines[0]); m != nil && len(m[1]) == 1 && strings.EqualFold(m[2], title) {
+			lines = lines[1:]
+		}
+	}
+
+	// Shallowest heading level outside code, so levels can be made relative.
+	minLevel := 7
+	inFence := false
+	for _, line := range lines {
+		if isFenceLine(line) {
+			inFence = !inFence
+			continue
+		}
+		if m := synthHeadingRe.FindStringSubmatch(line); m != nil && !inFence {
+			minLevel = min(minLevel, len(m[1]))
+		}
+	}
+
+	stack := []*synthSection{s.root}
+	var para []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(para, "\n")); text != "" {
+			s.addParagraph(stack[len(stack)-1], name, text)
+		}
+		para = nil
+	}
+
+	inFence = false
+	for _, line := range lines {
+		if isFenceLine(line) {
+			inFence = !inFence
+			para = append(para, line)
+			continue
+		}
+		if inFence {
+			para = append(para, line)
+			continue
+		}
+		if m := synthHeadingRe.FindStringSubmatch(line); m != nil {
+			flush()
+			depth := len(m[1]) - minLevel + 1
+			// A skipped level (# then ###) nests under the current section.
+			for len(stack) > depth {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, stack[len(stack)-1].child(m[2]))
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		para = append(para, line)
+	}
+	flush()
+}
+
+// addParagraph keeps a paragraph unless it repeats one already kept.
+func (s *synthesizer) addParagraph(sec *synthSection, name, text string) {
+	shingles := dedupe.Shingles(text)
+	for _, k := range s.kept {
+		if isDuplicateParagraph(shingles, k) {
+			return
+		}
+	}
+	s.kept = append(s.kept, shingles)
+	sec.paragraphs = append(sec.paragraphs, text)
+	if !containsStr(sec.sources, name) {
+		sec.sources = append(sec.sources, name)
+	}
+}
+
+// isDuplicateParagraph reports whether a paragraph with shingle set a
+// repeats, or is contained in, the kept paragraph with shingle set b.
+func isDuplicateParagraph(a, b map[uint64]bool) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	shared := 0
+	for h := range a {
+		if b[h] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	return float64(shared)/float64(union) >= promoteDupJaccard ||
+		float64(shared)/float64(len(a)) >= promoteDupContained
+}
+
+// render writes the section tree as markdown. Each section with text is
+// followed by the notes it came from.
+func (s *synthesizer) render(b *strings.Builder) {
+	var walk func(sec *synthSection)
+	walk = func(sec *synthSection) {
+		if sec.level > 0 {
+			fmt.Fprintf(b, "%s %s\n\n", strings.Repeat("#", min(sec.level+1, 6)), sec.heading)
+		}
+		for _, p := range sec.paragraphs {
+			b.WriteString(p)
+			b.WriteString("\n\n")
+		}
+		if len(sec.sources) > 0 {
+			links := make([]string, len(sec.sources))
+			for i, name := range sec.sources {
+				links[i] = "[[" + name + "]]"
+			}
+			fmt.Fprintf(b, "*Sources: %s*\n\n", strings.Join(links, ", "))
+		}
+		for _, c := range sec.children {
+			walk(c)
+		}
+	}
+	walk(s.root)
+}
+
+// isFenceLine reports whether a line opens or closes a fenced code block.
+func isFenceLine(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~")
+}
+
+// synthesizeBody merges the source notes' bodies into one deduplicated body.
+func synthesizeBody(notes []*promoteNoteInfo) string {
+	s := newSynthesizer()
+	for _, n := range notes {
+		s.add(strings.TrimSuffix(filepath.Base(n.Path), ".md"), n.Title, n.Body)
+	}
+	var b strings.Builder
+	s.render(&b)
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// buildSynthesizedNote creates a canonical note that merges the sources
+// instead of stacking them: matching headings are combined, repeated
+// paragraphs appear once, and every section credits the notes it came from.
+// A non-empty summary is shown at the top. Returns (vault-relative path,
+// content).
+func buildSynthesizedNote(notes []*promoteNoteInfo, now time.Time, summary string) (string, string) {
+	title := deriveClusterTitle(notes)
+
+	var b strings.Builder
+	writeCanonicalFrontmatter(&b, title, notes, now)
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if summary = strings.TrimSpace(summary); summary != "" {
+		b.WriteString("> [!summary]\n")
+		for _, line := range strings.Split(summary, "\n") {
+			fmt.Fprintf(&b, "> %s\n", line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(synthesizeBody(notes))
+
+	return "Notes/" + slugify(title) + ".md", b.String()
+}
+
+const promoteSummaryPrompt = `You summarize a group of related notes from a personal knowledge vault that are being merged into one. Write 2 to 4 sentences of plain prose capturing the main ideas and conclusions. No headings, lists, links or preamble.`
+
+// promoteSummary asks the model for a short summary of merged note text.
+func promoteSummary(ctx context.Context, client *llm.Client, text string) (string, error) {
+	resp, err := client.Complete(ctx, llm.Request{
+		System:    promoteSummaryPrompt,
+		Messages:  []llm.Message{{Role: "user", Content: truncateRunes(text, promoteSummaryMaxInput)}},
+		MaxTokens: 300,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Text), nil
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/cluster"
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
 )
 
 // ─── tagJaccard ──────────────────────────────────────────────────────────────
@@ -149,6 +154,24 @@ func TestDeriveClusterTitle_DefaultWhenNoTagsOrTitle(t *testing.T) {
 
 // ─── detectClusters ──────────────────────────────────────────────────────────
 
+var testPromoteParams = promoteParams{
+	Algorithm:         cluster.Agglomerative,
+	TagThreshold:      0.25,
+	SemanticThreshold: 0.80,
+	Linkage:           cluster.DefaultLinkage,
+	MinSize:           3,
+	MaxSize:           15,
+}
+
+func mustDetectClusters(t *testing.T, notes []*promoteNoteInfo, params promoteParams) ([]Cluster, [][]*promoteNoteInfo) {
+	t.Helper()
+	clusters, groups, err := detectClusters(notes, params)
+	if err != nil {
+		t.Fatalf("detectClusters() error: %v", err)
+	}
+	return clusters, groups
+}
+
 func TestDetectClusters_TagBased(t *testing.T) {
 	// Three notes sharing the "go" tag should form one cluster.
 	notes := []*promoteNoteInfo{
@@ -157,7 +180,7 @@ func TestDetectClusters_TagBased(t *testing.T) {
 		{Path: "c.md", Tags: []string{"go"}},
 		{Path: "d.md", Tags: []string{"rust"}}, // unrelated — should stay out
 	}
-	clusters, noteGroups := detectClusters(notes, 0.25, 0.80, 3)
+	clusters, noteGroups := mustDetectClusters(t, notes, testPromoteParams)
 	if len(clusters) != 1 {
 		t.Fatalf("detectClusters() found %d clusters, want 1", len(clusters))
 	}
@@ -176,14 +199,14 @@ func TestDetectClusters_BelowMinSize(t *testing.T) {
 		{Path: "b.md", Tags: []string{"go"}},
 		{Path: "c.md", Tags: []string{"rust"}},
 	}
-	clusters, _ := detectClusters(notes, 0.25, 0.80, 3)
+	clusters, _ := mustDetectClusters(t, notes, testPromoteParams)
 	if len(clusters) != 0 {
 		t.Errorf("detectClusters() found %d clusters, want 0 (below min size)", len(clusters))
 	}
 }
 
 func TestDetectClusters_EmptyInput(t *testing.T) {
-	clusters, groups := detectClusters(nil, 0.25, 0.80, 3)
+	clusters, groups := mustDetectClusters(t, nil, testPromoteParams)
 	if len(clusters) != 0 || len(groups) != 0 {
 		t.Errorf("expected empty result for nil input, got clusters=%d groups=%d", len(clusters), len(groups))
 	}
@@ -199,7 +222,7 @@ func TestDetectClusters_SemanticBased(t *testing.T) {
 		// Orthogonal note — should not be included.
 		{Path: "d.md", Embedding: []float32{0, 1, 0}},
 	}
-	clusters, _ := detectClusters(notes, 0.25, 0.80, 3)
+	clusters, _ := mustDetectClusters(t, notes, testPromoteParams)
 	if len(clusters) != 1 {
 		t.Fatalf("detectClusters() found %d clusters, want 1", len(clusters))
 	}
@@ -218,12 +241,102 @@ func TestDetectClusters_MultipleClusters(t *testing.T) {
 		{Path: "e.md", Tags: []string{"rust"}},
 		{Path: "f.md", Tags: []string{"rust"}},
 	}
-	clusters, _ := detectClusters(notes, 0.25, 0.80, 3)
+	clusters, _ := mustDetectClusters(t, notes, testPromoteParams)
 	if len(clusters) != 2 {
 		t.Errorf("detectClusters() found %d clusters, want 2", len(clusters))
 	}
 }
 
+func TestDetectClusters_BridgeNote(t *testing.T) {
+	// One note tagged with both topics relates to each cluster. Connected
+	// components chain everything together; average linkage keeps the two
+	// topics apart.
+	notes := []*promoteNoteInfo{
+		{Path: "a.md", Tags: []string{"go"}},
+		{Path: "b.md", Tags: []string{"go"}},
+		{Path: "c.md", Tags: []string{"go"}},
+		{Path: "bridge.md", Tags: []string{"go", "rust"}},
+		{Path: "d.md", Tags: []string{"rust"}},
+		{Path: "e.md", Tags: []string{"rust"}},
+		{Path: "f.md", Tags: []string{"rust"}},
+	}
+	components := testPromoteParams
+	components.Algorithm = cluster.Components
+	if clusters, _ := mustDetectClusters(t, notes, components); len(clusters) != 1 {
+		t.Errorf("components found %d clusters, want 1", len(clusters))
+	}
+
+	for _, alg := range []cluster.Algorithm{cluster.Agglomerative, cluster.Density} {
+		params := testPromoteParams
+		params.Algorithm = alg
+		clusters, _ := mustDetectClusters(t, notes, params)
+		if len(clusters) != 2 {
+			t.Fatalf("%s found %d clusters, want 2", alg, len(clusters))
+		}
+		for _, c := range clusters {
+			if len(c.CommonTags) != 1 {
+				t.Errorf("%s mixed topics: %+v", alg, c.Notes)
+			}
+		}
+	}
+}
+
+func TestDetectClusters_MaxSizeAndOrder(t *testing.T) {
+	var notes []*promoteNoteInfo
+	for _, name := range []string{"g", "e", "c", "a", "f", "d", "b"} {
+		notes = append(notes, &promoteNoteInfo{Path: name + ".md", Tags: []string{"go", name}})
+	}
+	// A tighter, higher-scoring cluster listed last.
+	for _, name := range []string{"x", "y", "z"} {
+		notes = append(notes, &promoteNoteInfo{Path: name + ".md", Embedding: []float32{1, 0}, Tags: []string{"db"}})
+	}
+	params := testPromoteParams
+	params.MaxSize = 4
+	clusters, _ := mustDetectClusters(t, notes, params)
+
+	var got []string
+	for _, c := range clusters {
+		if len(c.Notes) > 4 {
+			t.Errorf("cluster of %d notes exceeds max size 4", len(c.Notes))
+		}
+		var paths []string
+		for _, n := range c.Notes {
+			paths = append(paths, n.Path)
+		}
+		got = append(got, strings.Join(paths, ","))
+	}
+	want := "x.md,y.md,z.md | a.md,b.md,c.md,d.md | e.md,f.md,g.md"
+	if strings.Join(got, " | ") != want {
+		t.Errorf("clusters = %s, want %s", strings.Join(got, " | "), want)
+	}
+}
+
+func TestResolvePromoteParams(t *testing.T) {
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	for _, env := range []string{"ALGORITHM", "TAG_THRESHOLD", "SEMANTIC_THRESHOLD", "LINKAGE", "MIN_SIZE", "MAX_SIZE"} {
+		t.Setenv("OBSIDIAN_PROMOTE_"+env, "")
+	}
+	if err := config.Save(&config.Config{VaultPath: "/v", PromoteAlgorithm: "louvain", PromoteTagThreshold: 0.5, PromoteMaxSize: 8}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := resolvePromoteParams(PromoteOptions{TagThreshold: 0.3, MinSize: 4})
+	if err != nil {
+		t.Fatalf("resolvePromoteParams() error: %v", err)
+	}
+	want := promoteParams{Algorithm: cluster.Louvain, TagThreshold: 0.3, SemanticThreshold: 0.80, Linkage: cluster.DefaultLinkage, MinSize: 4, MaxSize: 8}
+	if p != want {
+		t.Errorf("params = %+v, want %+v", p, want)
+	}
+
+	if _, err := resolvePromoteParams(PromoteOptions{Algorithm: "kmeans"}); err == nil {
+		t.Error("unknown algorithm accepted")
+	}
+	if _, err := resolvePromoteParams(PromoteOptions{MinSize: 10}); err == nil {
+		t.Error("min size above max size accepted")
+	}
+}
+
 // ─── buildCanonicalNote ──────────────────────────────────────────────────────
 
 func TestBuildCanonicalNote_Structure(t *testing.T) {
@@ -422,7 +535,7 @@ func TestPromoteCluster_CreatesCanonicalAndArchives(t *testing.T) {
 	}
 
 	now := time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC)
-	result, err := promoteCluster(vaultDir, noteInfos, now)
+	result, err := promoteCluster(vaultDir, noteInfos, now, canonicalStyle{})
 	if err != nil {
 		t.Fatalf("promoteCluster() error: %v", err)
 	}
@@ -481,3 +594,89 @@ func TestCollectNotesForClustering_SkipsPromotedNotes(t *testing.T) {
 		t.Errorf("unexpected note path: %s", notes[0].Path)
 	}
 }
+
+// ─── selection and synthesis ─────────────────────────────────────────────────
+
+func TestSelectClusters(t *testing.T) {
+	clusters := []Cluster{{Score: 0.9}, {Score: 0.4}, {Score: 0.7}}
+	for _, tc := range []struct {
+		opts PromoteOptions
+		want []int
+	}{
+		{PromoteOptions{Select: []int{3, 1, 3}}, []int{2, 0}},
+		{PromoteOptions{All: true}, []int{0, 1, 2}},
+		{PromoteOptions{MinScore: 0.6}, []int{0, 2}},
+		{PromoteOptions{Select: []int{1, 2}, MinScore: 0.6}, []int{0}},
+	} {
+		got, err := selectClusters(clusters, tc.opts)
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("selectClusters(%+v) = %v, %v; want %v", tc.opts, got, err, tc.want)
+		}
+	}
+	if _, err := selectClusters(clusters, PromoteOptions{Select: []int{4}}); err == nil {
+		t.Error("selectClusters() accepted a cluster number out of range")
+	}
+}
+
+func TestSynthesizeBody(t *testing.T) {
+	notes := []*promoteNoteInfo{
+		{Path: "Notes/pool-a.md", Title: "Pool A", Body: "# Pool A\n\nConnection pools bound the number of open connections to the database.\n\n## Sizing\n\nStart with twice the core count.\n\n```sh\n# not a heading\n```\n"},
+		{Path: "Notes/pool-b.md", Title: "Pool B", Body: "Connection pools bound the number of open connections to the database!\n\n# sizing\n\nStart with twice the core count.\n\nMeasure wait time before growing the pool.\n\n## Metrics\n\nTrack idle and in-use counts.\n"},
+		{Path: "Notes/pool-c.md", Title: "Pool C", Body: "## Timeouts\n\nSet an acquire timeout.\n"},
+	}
+	got := synthesizeBody(notes)
+	want := "Connection pools bound the number of open connections to the database.\n\n" +
+		"*Sources: [[pool-a]]*\n\n" +
+		"## Sizing\n\n" +
+		"Start with twice the core count.\n\n" +
+		"```sh\n# not a heading\n```\n\n" +
+		"Measure wait time before growing the pool.\n\n" +
+		"*Sources: [[pool-a]], [[pool-b]]*\n\n" +
+		"### Metrics\n\n" +
+		"Track idle and in-use counts.\n\n" +
+		"*Sources: [[pool-b]]*\n\n" +
+		"## Timeouts\n\n" +
+		"Set an acquire timeout.\n\n" +
+		"*Sources: [[pool-c]]*\n"
+	if got != want {
+		t.Errorf("synthesizeBody() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBuildSynthesizedNote_Summary(t *testing.T) {
+	notes := []*promoteNoteInfo{
+		{Path: "a.md", Title: "A", Tags: []string{"go"}, Body: "Alpha."},
+		{Path: "b.md", Title: "B", Tags: []string{"go"}, Body: "Beta."},
+	}
+	path, content := buildSynthesizedNote(notes, time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC), "Two notes.")
+	if path != "Notes/go.md" {
+		t.Errorf("path = %q", path)
+	}
+	if !strings.Contains(content, "promoted-from:\n  - '[[a]]'\n  - '[[b]]'\n") ||
+		!strings.Contains(content, "# Go\n\n> [!summary]\n> Two notes.\n\nAlpha.\n\nBeta.\n\n*Sources: [[a]], [[b]]*\n") {
+		t.Errorf("content =\n%s", content)
+	}
+}
+
+func TestPromoteCmd_SelectDryRun(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Ideas/a.md": "---\ntags:\n  - go\n---\nA.\n",
+		"Ideas/b.md": "---\ntags:\n  - go\n---\nB.\n",
+		"Ideas/c.md": "---\ntags:\n  - go\n---\nC.\n",
+	})
+	out := captureStdout(t, func() {
+		if err := PromoteCmd(dir, PromoteOptions{Select: []int{1}, Synthesize: true, DryRun: true, JSONOutput: true}); err != nil {
+			t.Fatalf("PromoteCmd() error: %v", err)
+		}
+	})
+	var result PromoteOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(result.Promoted) != 1 || !result.Promoted[0].DryRun || result.Promoted[0].CanonicalPath != "Notes/go.md" || len(result.Promoted[0].SourcePaths) != 3 {
+		t.Errorf("promoted = %+v", result.Promoted)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Notes/go.md")); !os.IsNotExist(err) {
+		t.Error("dry run wrote the canonical note")
+	}
+}
@@ -14,10 +14,16 @@ type ReadOutput struct {
 	Body        string          `json:"body"`
 	Headings    []vault.Heading `json:"headings,omitempty"`
 	Wikilinks   []string        `json:"wikilinks,omitempty"`
+	Links       []vault.Link    `json:"links,omitempty"`
+	Embeds      []vault.Link    `json:"embeds,omitempty"`
+	Tags        []vault.Tag     `json:"tags,omitempty"`
+	Aliases     []string        `json:"aliases,omitempty"`
+	BlockIDs    []vault.BlockID `json:"block_ids,omitempty"`
 }
 
 // ReadCmd reads a note's content from the vault.
-// In JSON mode, returns parsed frontmatter, body, headings, and wikilinks.
+// In JSON mode, returns parsed frontmatter, body, headings, links, embeds,
+// inline tags, aliases and block IDs.
 // In text mode, prints the body content.
 func ReadCmd(vaultPath, notePath string, jsonOutput bool) error {
 	note, err := vault.ReadNote(vaultPath, notePath)
@@ -32,6 +38,11 @@ func ReadCmd(vaultPath, notePath string, jsonOutput bool) error {
 			Body:        note.Body,
 			Headings:    note.Headings,
 			Wikilinks:   note.Wikilinks,
+			Links:       note.Links,
+			Embeds:      note.Embeds,
+			Tags:        note.Tags,
+			Aliases:     note.Aliases,
+			BlockIDs:    note.BlockIDs,
 		})
 	}
 
@@ -43,6 +43,7 @@ type ResurfaceOutput struct {
 // ResurfaceCmd surfaces old notes that match the query or are randomly selected.
 // In query mode, it runs a hybrid search and filters to notes older than the threshold.
 // In random mode (opts.Random), it returns randomly selected old notes.
+// Notes reviewed (obsidian review) within the threshold are left out.
 func ResurfaceCmd(vaultPath, query string, opts ResurfaceOptions) error {
 	if opts.Limit <= 0 {
 		opts.Limit = defaultResurfaceLimit
@@ -192,12 +193,17 @@ func resurfaceByQuery(store *index.Store, query string, cutoff int64, limit int,
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch note ages: %w", err)
 	}
+	// Notes reviewed within the window were seen recently; leave them out.
+	reviewed, err := store.ReviewedSince(cutoff)
+	if err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 	var results []ResurfaceResult
 	for _, r := range searchResults {
 		mt, ok := modTimes[r.Path]
-		if !ok || mt == 0 || mt > cutoff {
+		if !ok || mt == 0 || mt > cutoff || reviewed[r.Path] {
 			continue
 		}
 		ageDays := int(now.Unix()-mt) / 86400
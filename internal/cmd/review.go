@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/review"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+const defaultReviewLimit = 20
+const defaultReviewNew = 5
+
+// reviewNewMinAge keeps notes out of review until they are a week old, the
+// same default as resurface.
+const reviewNewMinAge = 7 * 24 * time.Hour
+
+// ReviewOptions controls the review command.
+type ReviewOptions struct {
+	Limit       int // max notes shown (default 20)
+	New         int // never-reviewed notes introduced per session (default 5, -1 for none)
+	Interactive bool
+	JSONOutput  bool
+}
+
+// ReviewItem is a note due for review.
+type ReviewItem struct {
+	Path        string  `json:"path"`
+	Title       string  `json:"title"`
+	Snippet     string  `json:"snippet"`
+	New         bool    `json:"new"` // never reviewed
+	Due         string  `json:"due,omitempty"`
+	OverdueDays int     `json:"overdue_days,omitempty"`
+	Interval    int     `json:"interval_days,omitempty"`
+	Ease        float64 `json:"ease,omitempty"`
+	Reps        int     `json:"reps,omitempty"`
+	Lapses      int     `json:"lapses,omitempty"`
+
+	body string
+}
+
+// ReviewGradeOutput is the result of grading one review.
+type ReviewGradeOutput struct {
+	Path     string  `json:"path"`
+	Grade    string  `json:"grade"`
+	Interval int     `json:"interval_days"`
+	Ease     float64 `json:"ease"`
+	Due      string  `json:"due"`
+	Reps     int     `json:"reps"`
+	Lapses   int     `json:"lapses"`
+}
+
+// ReviewOutput is the JSON envelope for the review command.
+type ReviewOutput struct {
+	Date     string              `json:"date"`
+	Due      []ReviewItem        `json:"due"`
+	Reviewed []ReviewGradeOutput `json:"reviewed,omitempty"`
+}
+
+// ReviewCmd lists the notes due for spaced-repetition review today: notes
+// whose scheduled review has come up, most overdue first, followed by a few
+// never-reviewed notes older than a week. With opts.Interactive each note is
+// shown and graded in turn.
+func ReviewCmd(vaultPath string, opts ReviewOptions) error {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultReviewLimit
+	}
+	if opts.New == 0 {
+		opts.New = defaultReviewNew
+	}
+	if opts.Interactive && opts.JSONOutput {
+		return fmt.Errorf("--interactive cannot be combined with --json")
+	}
+	if opts.Interactive && !stdinIsTerminal() {
+		return fmt.Errorf("--interactive requires a terminal")
+	}
+
+	dbPath := index.IndexDBPath(vaultPath)
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("index not found — run 'obsidian index' first")
+	}
+	store, err := index.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	items, err := dueReviewItems(store, now, opts)
+	if err != nil {
+		return err
+	}
+	result := ReviewOutput{Date: now.Format("2006-01-02"), Due: items}
+
+	if opts.Interactive {
+		result.Reviewed = interactiveReview(&reviewSession{
+			store: store,
+			now:   now,
+			in:    bufio.NewReader(os.Stdin),
+			out:   os.Stdout,
+		}, items)
+		fmt.Printf("\nReviewed %d of %d notes.\n", len(result.Reviewed), len(items))
+		return nil
+	}
+
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+	printReviewList(result)
+	return nil
+}
+
+// dueReviewItems returns the scheduled reviews due today followed by up to
+// opts.New never-reviewed notes, at most opts.Limit in all.
+func dueReviewItems(store *index.Store, now time.Time, opts ReviewOptions) ([]ReviewItem, error) {
+	rows, err := store.DueReviews(review.EndOfDay(now))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ReviewItem, 0, len(rows))
+	for _, r := range rows {
+		if len(items) >= opts.Limit {
+			return items, nil
+		}
+		items = append(items, ReviewItem{
+			Path:        r.Path,
+			Title:       r.Title,
+			Snippet:     excerptBody(r.Body, 200),
+			Due:         r.State.Due.Format("2006-01-02"),
+			OverdueDays: max(0, int(now.Sub(r.State.Due).Hours()/24)),
+			Interval:    r.State.Interval,
+			Ease:        r.State.Ease,
+			Reps:        r.State.Reps,
+			Lapses:      r.State.Lapses,
+			body:        r.Body,
+		})
+	}
+
+	if n := min(opts.New, opts.Limit-len(items)); n > 0 {
+		fresh, err := store.UnreviewedNotes(now.Add(-reviewNewMinAge).Unix(), n)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range fresh {
+			items = append(items, ReviewItem{
+				Path:    r.Path,
+				Title:   r.Title,
+				Snippet: excerptBody(r.Body, 200),
+				New:     true,
+				body:    r.Body,
+			})
+		}
+	}
+	return items, nil
+}
+
+// ReviewGradeCmd records a review of one note and schedules the next one.
+// The note can be named by path or as in a wikilink.
+func ReviewGradeCmd(vaultPath, name, gradeName string, jsonOutput bool) error {
+	grade, err := review.ParseGrade(gradeName)
+	if err != nil {
+		return err
+	}
+	resolver, err := vault.LoadResolver(vaultPath)
+	if err != nil {
+		return fmt.Errorf("loading vault: %w", err)
+	}
+	res := resolver.ResolveLoose(strings.TrimSuffix(name, ".md"))
+	switch res.Status {
+	case vault.Missing:
+		return fmt.Errorf("note not found: %s", name)
+	case vault.Ambiguous:
+		return fmt.Errorf("%q is ambiguous: %s", name, strings.Join(res.Candidates, ", "))
+	}
+
+	dbPath := index.IndexDBPath(vaultPath)
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("index not found — run 'obsidian index' first")
+	}
+	store, err := index.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer store.Close()
+	if mt, err := store.GetModTime(res.Path); err != nil || mt == 0 {
+		return fmt.Errorf("%s is not indexed\n\nRun 'obsidian index' first", res.Path)
+	}
+
+	graded, err := gradeReview(store, res.Path, grade, time.Now())
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return output.JSON(graded)
+	}
+	fmt.Printf("%s: %s — next review %s (in %s)\n", graded.Path, graded.Grade, graded.Due, formatInterval(graded.Interval))
+	return nil
+}
+
+// gradeReview applies a grade to a note's stored review state.
+func gradeReview(store *index.Store, notePath string, grade review.Grade, now time.Time) (ReviewGradeOutput, error) {
+	st, _, err := store.GetReview(notePath)
+	if err != nil {
+		return ReviewGradeOutput{}, err
+	}
+	st = review.Schedule(st, grade, now)
+	if err := store.SaveReview(notePath, st); err != nil {
+		return ReviewGradeOutput{}, err
+	}
+	return ReviewGradeOutput{
+		Path:     notePath,
+		Grade:    grade.String(),
+		Interval: st.Interval,
+		Ease:     st.Ease,
+		Due:      st.Due.Format("2006-01-02"),
+		Reps:     st.Reps,
+		Lapses:   st.Lapses,
+	}, nil
+}
+
+// reviewSession holds what interactive review needs across notes.
+type reviewSession struct {
+	store *index.Store
+	now   time.Time
+	in    *bufio.Reader
+	out   io.Writer
+}
+
+// interactiveReview shows each due note with the interval every grade would
+// give, and records the grade the user picks.
+func interactiveReview(s *reviewSession, items []ReviewItem) []ReviewGradeOutput {
+	var graded []ReviewGradeOutput
+	for i, item := range items {
+		fmt.Fprintf(s.out, "\n[%d/%d] %s", i+1, len(items), item.Path)
+		if item.Title != "" {
+			fmt.Fprintf(s.out, " — %s", item.Title)
+		}
+		fmt.Fprintln(s.out)
+		printReviewPreview(s.out, item.body)
+
+		st, _, err := s.store.GetReview(item.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		var choices []string
+		for _, g := range []review.Grade{review.Again, review.Hard, review.Good, review.Easy} {
+			next := review.Schedule(st, g, s.now)
+			choices = append(choices, fmt.Sprintf("[%s]%s %s", g.String()[:1], g.String()[1:], formatInterval(next.Interval)))
+		}
+
+		for {
+			fmt.Fprintf(s.out, "%s  [s]kip [q]uit: ", strings.Join(choices, "  "))
+			line, err := s.in.ReadString('\n')
+			if err != nil && line == "" {
+				fmt.Fprintln(s.out)
+				return graded
+			}
+			key := strings.ToLower(strings.TrimSpace(line))
+			if key == "q" {
+				return graded
+			}
+			if key == "s" {
+				break
+			}
+			grade, err := review.ParseGrade(key)
+			if err != nil {
+				continue
+			}
+			result, err := gradeReview(s.store, item.Path, grade, s.now)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				break
+			}
+			fmt.Fprintf(s.out, "  Next review %s\n", result.Due)
+			graded = append(graded, result)
+			break
+		}
+	}
+	return graded
+}
+
+// printReviewPreview writes the first lines of a note body.
+func printReviewPreview(w io.Writer, body string) {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	for i, l := range lines {
+		if i == triagePreviewLines {
+			fmt.Fprintf(w, "  │ … (%d more lines)\n", len(lines)-i)
+			break
+		}
+		fmt.Fprintf(w, "  │ %s\n", l)
+	}
+}
+
+// formatInterval renders a day count compactly: 3d, 5w, 4mo, 1.5y.
+func formatInterval(days int) string {
+	switch {
+	case days < 14:
+		return fmt.Sprintf("%dd", days)
+	case days < 60:
+		return fmt.Sprintf("%dw", int(math.Round(float64(days)/7)))
+	case days < 365:
+		return fmt.Sprintf("%dmo", int(math.Round(float64(days)/30)))
+	default:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(days)/365), ".0") + "y"
+	}
+}
+
+func printReviewList(result ReviewOutput) {
+	if len(result.Due) == 0 {
+		fmt.Println("Nothing due for review today.")
+		return
+	}
+	fmt.Printf("Due for review %s (%d notes)\n\n", result.Date, len(result.Due))
+	for i, item := range result.Due {
+		fmt.Printf("  %d. %s", i+1, item.Path)
+		if item.Title != "" {
+			fmt.Printf(" — %s", item.Title)
+		}
+		switch {
+		case item.New:
+			fmt.Print("  (new)")
+		case item.OverdueDays > 0:
+			fmt.Printf("  (overdue %dd, interval %s)", item.OverdueDays, formatInterval(item.Interval))
+		default:
+			fmt.Printf("  (interval %s)", formatInterval(item.Interval))
+		}
+		fmt.Println()
+		if item.Snippet != "" {
+			fmt.Printf("     %s\n", strings.ReplaceAll(item.Snippet, "\n", " "))
+		}
+	}
+	fmt.Println("\nGrade with: obsidian review <path> again|hard|good|easy, or obsidian review --interactive")
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/review"
+)
+
+func TestReviewScheduling(t *testing.T) {
+	store := openResurfaceTestStore(t)
+	defer store.Close()
+
+	now := time.Now()
+	old := now.Add(-30 * 24 * time.Hour).Unix()
+	for _, n := range []index.NoteRow{
+		{Path: "a.md", Title: "A", Body: "alpha", ModTime: old},
+		{Path: "b.md", Title: "B", Body: "beta", ModTime: old},
+		{Path: "c.md", Title: "C", Body: "gamma", ModTime: now.Unix()}, // too new to introduce
+	} {
+		if err := store.UpsertNote(&n); err != nil {
+			t.Fatalf("UpsertNote failed: %v", err)
+		}
+	}
+
+	items, err := dueReviewItems(store, now, ReviewOptions{Limit: 10, New: 5})
+	if err != nil {
+		t.Fatalf("dueReviewItems() error: %v", err)
+	}
+	if len(items) != 2 || !items[0].New || !items[1].New {
+		t.Fatalf("first session items = %+v", items)
+	}
+
+	// a.md is graded Good today; b.md was last graded long ago and is overdue.
+	if _, err := gradeReview(store, "a.md", review.Good, now); err != nil {
+		t.Fatalf("gradeReview() error: %v", err)
+	}
+	past := now.AddDate(0, 0, -10)
+	if _, err := gradeReview(store, "b.md", review.Good, past); err != nil {
+		t.Fatalf("gradeReview() error: %v", err)
+	}
+
+	items, _ = dueReviewItems(store, now, ReviewOptions{Limit: 10, New: 5})
+	if len(items) != 1 || items[0].Path != "b.md" || items[0].New || items[0].OverdueDays < 8 {
+		t.Errorf("due items = %+v", items)
+	}
+	if items, _ := dueReviewItems(store, now.AddDate(0, 0, 1), ReviewOptions{Limit: 10, New: -1}); len(items) != 2 {
+		t.Errorf("due tomorrow = %+v", items)
+	}
+
+	// Resurface leaves out the note reviewed today.
+	rows, err := store.RandomOldNotes(now.Add(-7*24*time.Hour).Unix(), 10)
+	if err != nil {
+		t.Fatalf("RandomOldNotes failed: %v", err)
+	}
+	for _, r := range rows {
+		if r.Path == "a.md" {
+			t.Error("recently reviewed note resurfaced")
+		}
+	}
+	if len(rows) != 1 {
+		t.Errorf("RandomOldNotes = %d rows, want 1 (b.md)", len(rows))
+	}
+
+	// Removing a note from the index keeps its schedule.
+	store.DeleteNote("b.md")
+	if _, ok, _ := store.GetReview("b.md"); !ok {
+		t.Error("DeleteNote dropped the review state")
+	}
+}
+
+func TestIndexKeepsReviewsOfMovedNotes(t *testing.T) {
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	t.Setenv("GEMINI_API_KEY", "")
+	dir := writeVaultFiles(t, map[string]string{
+		"Inbox/idea.md":      "---\ntitle: Idea\n---\nA thought worth keeping.\n",
+		"Inbox/gone.md":      "To be deleted.\n",
+		".obsidian/app.json": "{}",
+	})
+	reindex := func() {
+		t.Helper()
+		captureStdout(t, func() {
+			if err := IndexCmd(dir, true); err != nil {
+				t.Fatalf("IndexCmd() error: %v", err)
+			}
+		})
+	}
+	reindex()
+
+	store, err := index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, p := range []string{"Inbox/idea.md", "Inbox/gone.md"} {
+		if _, err := gradeReview(store, p, review.Good, now); err != nil {
+			t.Fatalf("gradeReview() error: %v", err)
+		}
+	}
+	store.Close()
+
+	// Moved and retitled in frontmatter: same body, new path.
+	os.MkdirAll(filepath.Join(dir, "Ideas"), 0755)
+	os.WriteFile(filepath.Join(dir, "Ideas/idea.md"), []byte("---\ntitle: Idea\ntype: idea\n---\nA thought worth keeping.\n"), 0644)
+	os.Remove(filepath.Join(dir, "Inbox/idea.md"))
+	os.Remove(filepath.Join(dir, "Inbox/gone.md"))
+	reindex()
+
+	store, err = index.Open(index.IndexDBPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if st, ok, _ := store.GetReview("Ideas/idea.md"); !ok || st.Reps != 1 {
+		t.Errorf("moved note review = %+v, %v", st, ok)
+	}
+	for _, p := range []string{"Inbox/idea.md", "Inbox/gone.md"} {
+		if _, ok, _ := store.GetReview(p); ok {
+			t.Errorf("review state of %s survived", p)
+		}
+	}
+}
+
+func TestReviewGradeCmd_Errors(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"A/plan.md": "one\n",
+		"B/plan.md": "two\n",
+		"solo.md":   "three\n",
+	})
+
+	err := ReviewGradeCmd(dir, "plan", "good", false)
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") || !strings.Contains(err.Error(), "A/plan.md") || !strings.Contains(err.Error(), "B/plan.md") {
+		t.Errorf("ambiguous name error = %v", err)
+	}
+	err = ReviewGradeCmd(dir, "nothing", "good", false)
+	if err == nil || !strings.Contains(err.Error(), "note not found") {
+		t.Errorf("missing name error = %v", err)
+	}
+	err = ReviewGradeCmd(dir, "solo", "good", false)
+	if err == nil || !strings.Contains(err.Error(), "index not found") {
+		t.Errorf("unindexed vault error = %v", err)
+	}
+	if _, statErr := os.Stat(index.IndexDBPath(dir)); statErr == nil {
+		t.Error("grading created an index database")
+	}
+}
+
+func TestInteractiveReview(t *testing.T) {
+	store := openResurfaceTestStore(t)
+	defer store.Close()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	items := []ReviewItem{{Path: "a.md", body: "alpha"}, {Path: "b.md", body: "beta"}, {Path: "c.md"}}
+
+	var out bytes.Buffer
+	graded := interactiveReview(&reviewSession{
+		store: store,
+		now:   now,
+		in:    bufio.NewReader(strings.NewReader("x\ne\ns\nq\n")),
+		out:   &out,
+	}, items)
+
+	if len(graded) != 1 || graded[0].Path != "a.md" || graded[0].Grade != "easy" || graded[0].Due != "2026-03-05" {
+		t.Errorf("graded = %+v", graded)
+	}
+	if !strings.Contains(out.String(), "[a]gain 1d  [h]ard 1d  [g]ood 1d  [e]asy 4d") {
+		t.Errorf("grade choices not shown:\n%s", out.String())
+	}
+	if _, ok, _ := store.GetReview("b.md"); ok {
+		t.Error("skipped note was graded")
+	}
+}
+
+func TestFormatInterval(t *testing.T) {
+	for days, want := range map[int]string{1: "1d", 13: "13d", 21: "3w", 90: "3mo", 365: "1y", 550: "1.5y"} {
+		if got := formatInterval(days); got != want {
+			t.Errorf("formatInterval(%d) = %q, want %q", days, got, want)
+		}
+	}
+}
@@ -71,6 +71,7 @@ func SyncCmd(vaultPath, websitePath string, dryRun, force, jsonOutput bool) erro
 		// Determine if create or update
 		if _, err := os.Stat(fullPath); err == nil {
 			stats.Updated = append(stats.Updated, notePath)
+			snapshotNote(vaultPath, filepath.Join("Projects", "Website", notePath), "sync")
 		} else {
 			stats.Created = append(stats.Created, notePath)
 		}
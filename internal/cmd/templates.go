@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/template"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// contentVar is the template variable that holds the text of a captured or
+// triaged note.
+const contentVar = "content"
+
+// noteTemplate is a vault template ready to render new notes.
+type noteTemplate struct {
+	path     string // vault-relative
+	src      string
+	settings template.Settings
+}
+
+// renderedTemplate is a rendered template split at its frontmatter.
+type renderedTemplate struct {
+	frontmatter string // raw properties, "" when the template has none
+	body        string
+}
+
+// loadNoteTemplate finds a template by path or by name in the vault's
+// templates folder and reads it.
+func loadNoteTemplate(vaultPath, name string) (*noteTemplate, error) {
+	settings, err := template.LoadSettings(vaultPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading template settings: %w", err)
+	}
+	rel, err := settings.Resolve(vaultPath, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(vaultPath, rel))
+	if err != nil {
+		return nil, fmt.Errorf("loading template %q: %w", name, err)
+	}
+	return &noteTemplate{path: rel, src: string(data), settings: settings}, nil
+}
+
+// render expands the template with the vault's date and time formats.
+func (t *noteTemplate) render(ctx template.Context) (renderedTemplate, error) {
+	ctx.DateFormat = firstNonEmpty(ctx.DateFormat, t.settings.DateFormat)
+	ctx.TimeFormat = firstNonEmpty(ctx.TimeFormat, t.settings.TimeFormat)
+	out, err := template.Render(t.src, ctx)
+	if err != nil {
+		return renderedTemplate{}, fmt.Errorf("template %s: %w", t.path, err)
+	}
+	if fm, body, ok := vault.SplitFrontmatter(out); ok {
+		return renderedTemplate{frontmatter: fm, body: strings.TrimLeft(body, "\r\n")}, nil
+	}
+	return renderedTemplate{body: out}, nil
+}
+
+// note assembles a new note from a rendered template. properties are raw
+// frontmatter lines that win over the template's.
+func (r renderedTemplate) note(properties string) string {
+	properties = mergeTemplateFrontmatter(properties, r.frontmatter)
+	body := r.body
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	switch {
+	case properties == "":
+		return body
+	case strings.TrimSpace(body) == "":
+		return "---\n" + properties + "---\n"
+	}
+	return "---\n" + properties + "---\n\n" + body
+}
+
+// wrap renders the template around existing note text: the text goes where
+// the template has {{content}}, or after the template body. The text's own
+// frontmatter wins over the template's, and tags are combined. Cursor
+// markers are dropped.
+func (t *noteTemplate) wrap(text string, ctx template.Context) (string, error) {
+	raw, body := "", text
+	if fm, rest, ok := vault.SplitFrontmatter(text); ok {
+		raw, body = fm, strings.TrimLeft(rest, "\r\n")
+	}
+	vars := map[string]string{}
+	for k, v := range ctx.Vars {
+		vars[k] = v
+	}
+	vars[contentVar] = strings.TrimRight(body, "\n")
+	ctx.Vars = vars
+
+	rendered, err := t.render(ctx)
+	if err != nil {
+		return "", err
+	}
+	out := rendered.body
+	if !template.References(t.src, contentVar) {
+		switch {
+		case strings.TrimSpace(out) == "":
+			out = body
+		case strings.TrimSpace(body) != "":
+			out = strings.TrimRight(out, "\n") + "\n\n" + body
+		}
+	}
+	if fm := mergeTemplateFrontmatter(raw, rendered.frontmatter); fm != "" {
+		out = "---\n" + fm + "---\n\n" + out
+	}
+	out, _ = template.StripCursor(out)
+	return out, nil
+}
+
+// mergeTemplateFrontmatter adds a template's properties to a note's raw
+// frontmatter. The note's values win, except tags, which are combined; a
+// note without tags keeps the template's as written.
+func mergeTemplateFrontmatter(noteFM, templateFM string) string {
+	noteTags := extractTagsList(parseRawFrontmatter(noteFM))
+	tags := append([]string{}, noteTags...)
+	for _, t := range extractTagsList(parseRawFrontmatter(templateFM)) {
+		if !containsFold(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	var skip []string
+	for _, k := range frontmatterKeys(noteFM) {
+		if k != "tags" || len(noteTags) > 0 {
+			skip = append(skip, k)
+		}
+	}
+
+	var b strings.Builder
+	switch {
+	case len(noteTags) == 0:
+		b.WriteString(passthroughFrontmatter(noteFM, "tags"))
+	case len(tags) > len(noteTags):
+		b.WriteString(passthroughFrontmatter(noteFM, "tags"))
+		b.WriteString("tags:\n")
+		for _, t := range tags {
+			fmt.Fprintf(&b, "  - %s\n", t)
+		}
+		skip = append(skip, "tags")
+	default:
+		b.WriteString(passthroughFrontmatter(noteFM))
+		skip = append(skip, "tags")
+	}
+	b.WriteString(passthroughFrontmatter(templateFM, skip...))
+	return b.String()
+}
+
+// parseRawFrontmatter parses frontmatter lines without their delimiters.
+func parseRawFrontmatter(raw string) map[string]any {
+	if strings.TrimSpace(raw) == "" {
+		return map[string]any{}
+	}
+	return vault.ParseNote("---\n" + strings.TrimRight(raw, "\r\n") + "\n---\n").Frontmatter
+}
+
+// frontmatterKeys returns the top-level property names of raw frontmatter.
+func frontmatterKeys(raw string) []string {
+	var keys []string
+	for _, line := range strings.Split(raw, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '-' || line[0] == '#' {
+			continue
+		}
+		if key, _, ok := strings.Cut(line, ":"); ok {
+			keys = append(keys, strings.TrimSpace(key))
+		}
+	}
+	return keys
+}
+
+// noteFolder returns the vault-relative folder of a note path, "" at the
+// vault root.
+func noteFolder(notePath string) string {
+	dir := path.Dir(filepath.ToSlash(notePath))
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// fileTitle returns a note's file name without .md, as Obsidian titles it.
+func fileTitle(notePath string) string {
+	return strings.TrimSuffix(path.Base(filepath.ToSlash(notePath)), ".md")
+}
+
+// terminalPrompt asks for unset template variables on stderr when stdin is
+// a terminal. It returns nil otherwise, so defaults apply and a variable
+// without one fails the render.
+func terminalPrompt() func(name, def string) (string, error) {
+	if !stdinIsTerminal() {
+		return nil
+	}
+	in := bufio.NewReader(os.Stdin)
+	return func(name, def string) (string, error) {
+		if def != "" {
+			fmt.Fprintf(os.Stderr, "%s [%s]: ", name, def)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: ", name)
+		}
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading %s: %w", name, err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+		return def, nil
+	}
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+const meetingTemplate = `---
+type: meeting
+project: "{{project|none}}"
+tags: [meeting]
+---
+# {{title}}
+
+{{#if agenda}}
+Agenda: {{agenda}}
+{{/if}}
+## Notes
+{{cursor}}
+`
+
+func TestCreateCmd_Template(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		".obsidian/templates.json": `{"folder": "Templates"}`,
+		"Templates/meeting.md":     meetingTemplate,
+	})
+
+	out := captureStdout(t, func() {
+		err := CreateCmd(dir, "Meetings/Kickoff.md", CreateOptions{
+			Template: "meeting",
+			Tags:     []string{"q3"},
+			Vars:     map[string]string{"project": "apollo", "agenda": "scope"},
+		}, true)
+		if err != nil {
+			t.Fatalf("CreateCmd() error: %v", err)
+		}
+	})
+	var result CreateOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+
+	content := mustRead(t, filepath.Join(dir, "Meetings/Kickoff.md"))
+	want := "---\ncreated: " + time.Now().Format("2006-01-02") + "\ntags:\n  - q3\n  - meeting\ntype: meeting\nproject: \"apollo\"\n---\n\n" +
+		"# Kickoff\n\nAgenda: scope\n## Notes\n\n"
+	if content != want {
+		t.Errorf("note =\n%q\nwant\n%q", content, want)
+	}
+	if result.Cursor != 14 {
+		t.Errorf("cursor = %d, want 14", result.Cursor)
+	}
+
+	// Without flags the template's frontmatter is kept as written.
+	captureStdout(t, func() {
+		if err := CreateCmd(dir, "Meetings/Retro.md", CreateOptions{Template: "Templates/meeting.md"}, true); err != nil {
+			t.Fatalf("CreateCmd() error: %v", err)
+		}
+	})
+	content = mustRead(t, filepath.Join(dir, "Meetings/Retro.md"))
+	if !strings.HasPrefix(content, "---\ntype: meeting\nproject: \"none\"\ntags: [meeting]\n---\n\n# Retro\n\n## Notes\n") {
+		t.Errorf("note without flags =\n%s", content)
+	}
+
+	if err := CreateCmd(dir, "x.md", CreateOptions{Template: "missing"}, true); err == nil {
+		t.Error("CreateCmd() with a missing template succeeded")
+	}
+}
+
+func TestCaptureCmd_Template(t *testing.T) {
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	dir := writeVaultFiles(t, map[string]string{
+		"Templates/log.md": "---\ntype: log\ntags: [daily-log]\n---\n## {{title}}: {{date:YYYY}} log ({{source}})\n\n{{content}}\n\n— {{mood|fine}}\n",
+	})
+
+	out := captureStdout(t, func() {
+		err := CaptureCmd(dir, CaptureOptions{
+			Body:       "---\ntags: [work]\n---\nShipped the release.",
+			Source:     "slack",
+			Template:   "Templates/log",
+			Vars:       map[string]string{"mood": "great"},
+			JSONOutput: true,
+		})
+		if err != nil {
+			t.Fatalf("CaptureCmd() error: %v", err)
+		}
+	})
+	var result CaptureOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Type != "log" || !strings.HasPrefix(result.Path, "Inbox/") {
+		t.Errorf("result = %+v", result)
+	}
+
+	note := vault.ParseNote(mustRead(t, filepath.Join(dir, result.Path)))
+	if tags := extractTagsList(note.Frontmatter); strings.Join(tags, ",") != "work,daily-log" {
+		t.Errorf("tags = %v", tags)
+	}
+	// Without a title, {{title}} is the generated note name.
+	wantBody := "## " + fileTitle(result.Path) + ": " + time.Now().Format("2006") + " log (slack)\n\nShipped the release.\n\n— great\n"
+	if body := strings.TrimLeft(note.Body, "\n"); body != wantBody {
+		t.Errorf("body = %q, want %q", body, wantBody)
+	}
+}
+
+func TestTriageCmd_RuleTemplate(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	dir := writeVaultFiles(t, map[string]string{
+		".obsidian/triage-rules.toml": `
+[[rule]]
+name = "meetings"
+destination = "Meetings/"
+template = "Templates/meeting-triage"
+[rule.match]
+tags = ["meeting"]
+[rule.set]
+project = "team-sync"
+`,
+		"Templates/meeting-triage.md": "---\nattendees: []\n---\n# {{title}} ({{date:YYYY-MM-DD}})\n\nProject: {{project}}\n\n{{content}}\n",
+		"Inbox/standup.md":            "---\ntitle: Standup\ncreated: 2025-11-04\n---\nWeekly #meeting sync.\n",
+	})
+
+	captureStdout(t, func() {
+		if err := TriageCmd(dir, TriageOptions{Auto: true, JSONOutput: true}); err != nil {
+			t.Fatalf("TriageCmd() error: %v", err)
+		}
+	})
+	content := mustRead(t, filepath.Join(dir, "Meetings/standup.md"))
+	note := vault.ParseNote(content)
+	if frontmatterString(note.Frontmatter, "status") != "processed" || !strings.Contains(content, "\nattendees: []\n") {
+		t.Errorf("frontmatter not merged:\n%s", content)
+	}
+	if !strings.HasPrefix(strings.TrimLeft(note.Body, "\n"), "# Standup (2025-11-04)\n\nProject: team-sync\n\nWeekly #meeting sync.\n") {
+		t.Errorf("body = %q", note.Body)
+	}
+}
+
+func TestDailyCmd(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		".obsidian/daily-notes.json": `{"folder": "Journal", "format": "YYYY/MM/YYYY-MM-DD", "template": "Templates/Daily"}`,
+		"Templates/Daily.md":         "---\ndate: {{date}}\n---\n# <% tp.date.now(\"dddd D MMMM\") %>\n\n[[<% tp.date.yesterday() %>]] · [[{{tomorrow}}]]\n\n<% tp.file.cursor() %>\n",
+	})
+
+	run := func(opts DailyOptions) DailyOutput {
+		t.Helper()
+		opts.JSONOutput = true
+		out := captureStdout(t, func() {
+			if err := DailyCmd(dir, opts); err != nil {
+				t.Fatalf("DailyCmd() error: %v", err)
+			}
+		})
+		var result DailyOutput
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		return result
+	}
+
+	result := run(DailyOptions{Date: "2026-03-05"})
+	if !result.Created || result.Path != "Journal/2026/03/2026-03-05.md" || result.Template != "Templates/Daily.md" || result.Cursor != 9 {
+		t.Errorf("result = %+v", result)
+	}
+	want := "---\ndate: 2026-03-05\n---\n\n# Thursday 5 March\n\n[[2026-03-04]] · [[2026-03-06]]\n\n\n"
+	if got := mustRead(t, filepath.Join(dir, result.Path)); got != want {
+		t.Errorf("daily note =\n%q\nwant\n%q", got, want)
+	}
+
+	if again := run(DailyOptions{Date: "2026-03-05"}); again.Created {
+		t.Errorf("existing daily note was created again: %+v", again)
+	}
+}
+
+func TestParseDailyDate(t *testing.T) {
+	now := time.Date(2026, 3, 5, 14, 30, 0, 0, time.UTC)
+	for in, want := range map[string]string{
+		"":           "2026-03-05",
+		"today":      "2026-03-05",
+		"yesterday":  "2026-03-04",
+		"tomorrow":   "2026-03-06",
+		"+7":         "2026-03-12",
+		"-1":         "2026-03-04",
+		"2025-12-31": "2025-12-31",
+	} {
+		got, err := parseDailyDate(in, now)
+		if err != nil || got.Format("2006-01-02") != want {
+			t.Errorf("parseDailyDate(%q) = %v, %v; want %s", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"next week", "+x", "2026-13-01"} {
+		if _, err := parseDailyDate(in, now); err == nil {
+			t.Errorf("parseDailyDate(%q) succeeded", in)
+		}
+	}
+}
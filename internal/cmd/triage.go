@@ -1,20 +1,24 @@
 package cmd
 
 import (
-	"bytes"
+	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/joeyhipolito/obsidian-cli/internal/classify"
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/dedupe"
 	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/llm"
 	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/rules"
+	"github.com/joeyhipolito/obsidian-cli/internal/template"
 	"github.com/joeyhipolito/obsidian-cli/internal/vault"
 )
 
@@ -42,11 +46,17 @@ func isValidNoteType(t NoteType) bool {
 // to override the regex fallback.
 const llmConfidenceThreshold = 0.75
 
+// learnedConfidenceThreshold is the minimum calibrated confidence for the
+// learned classifier to override the regex fallback.
+const learnedConfidenceThreshold = 0.6
+
 // LLMClassifyResult holds the structured output from an LLM classifier.
 type LLMClassifyResult struct {
 	Type       NoteType `json:"type"`
 	Confidence float64  `json:"confidence"`
 	Entities   []string `json:"entities"`
+	Title      string   `json:"title,omitempty"`   // concise title for untitled notes
+	Summary    string   `json:"summary,omitempty"` // one-line summary
 }
 
 // LLMClassifier classifies a note's text content.
@@ -55,39 +65,9 @@ type LLMClassifier interface {
 	Classify(ctx context.Context, content string) (LLMClassifyResult, error)
 }
 
-// HaikuClassifier classifies notes using Claude Haiku via the Anthropic Messages API.
-type HaikuClassifier struct {
-	apiKey     string
-	httpClient *http.Client
-}
-
-// NewHaikuClassifier returns a classifier backed by Claude Haiku.
-// Returns nil when apiKey is empty so callers can use nil as a "no LLM" sentinel.
-func NewHaikuClassifier(apiKey string) *HaikuClassifier {
-	if apiKey == "" {
-		return nil
-	}
-	return &HaikuClassifier{
-		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: 15 * time.Second},
-	}
-}
-
-// anthropicMessagesResponse is the JSON shape returned by the Anthropic Messages API.
-type anthropicMessagesResponse struct {
-	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"content"`
-	Error *struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
-}
-
 const classifySystemPrompt = `Classify the note below. Respond with JSON only — no prose, no code fences.
 
-Schema: {"type":"task"|"reference"|"idea"|"note"|"fleeting","confidence":0.0–1.0,"entities":["…up to 5 key topics…"]}
+Schema: {"type":"task"|"reference"|"idea"|"note"|"fleeting","confidence":0.0–1.0,"entities":["…up to 5 key topics…"],"title":"…","summary":"…"}
 
 Rules:
 - task: actionable items, checkboxes, TODOs, follow-ups
@@ -96,70 +76,63 @@ Rules:
 - idea: single concept, insight, or brainstorm
 - fleeting: unclear, fragmentary, or unclassifiable capture
 - confidence: how sure you are (1.0 = certain)
-- entities: key named topics that may correspond to existing vault notes`
-
-// Classify sends the note content to Claude Haiku and returns a structured result.
-func (h *HaikuClassifier) Classify(ctx context.Context, content string) (LLMClassifyResult, error) {
-	prompt := classifySystemPrompt + "\n\nNote:\n" + content
-
-	reqBody := map[string]any{
-		"model":      "claude-haiku-4-5-20251001",
-		"max_tokens": 256,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
-		},
-	}
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return LLMClassifyResult{}, fmt.Errorf("marshal request: %w", err)
-	}
+- entities: key named topics that may correspond to existing vault notes
+- title: a concise, specific title of at most 8 words, no trailing punctuation
+- summary: one sentence of at most 25 words saying what the note is about`
 
-	req, err := http.NewRequestWithContext(ctx, "POST",
-		"https://api.anthropic.com/v1/messages", bytes.NewReader(jsonBody))
-	if err != nil {
-		return LLMClassifyResult{}, fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", h.apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
-
-	resp, err := h.httpClient.Do(req)
-	if err != nil {
-		return LLMClassifyResult{}, fmt.Errorf("http request: %w", err)
-	}
-	defer resp.Body.Close()
+// ClientClassifier classifies notes with the configured LLM provider.
+type ClientClassifier struct {
+	client *llm.Client
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return LLMClassifyResult{}, fmt.Errorf("read response: %w", err)
+// NewClientClassifier returns a classifier backed by client.
+// Returns nil when client is nil so callers can use nil as a "no LLM" sentinel.
+func NewClientClassifier(client *llm.Client) LLMClassifier {
+	if client == nil {
+		return nil
 	}
+	return &ClientClassifier{client: client}
+}
 
-	var apiResp anthropicMessagesResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return LLMClassifyResult{}, fmt.Errorf("decode response: %w", err)
-	}
-	if apiResp.Error != nil {
-		return LLMClassifyResult{}, fmt.Errorf("API error: %s", apiResp.Error.Message)
+// Classify sends the note content to the model and returns a structured
+// result. Replies that are not valid JSON or use an unknown type are repaired
+// by asking the model again.
+func (c *ClientClassifier) Classify(ctx context.Context, content string) (LLMClassifyResult, error) {
+	req := llm.Request{
+		System:    classifySystemPrompt,
+		Messages:  []llm.Message{{Role: "user", Content: "Note:\n" + content}},
+		MaxTokens: 256,
 	}
-	if len(apiResp.Content) == 0 || apiResp.Content[0].Type != "text" {
-		return LLMClassifyResult{}, fmt.Errorf("unexpected response format")
-	}
-
 	var result LLMClassifyResult
-	if err := json.Unmarshal([]byte(apiResp.Content[0].Text), &result); err != nil {
-		return LLMClassifyResult{}, fmt.Errorf("parse JSON in response: %w", err)
+	err := c.client.CompleteJSON(ctx, req, &result, func() error {
+		if !isValidNoteType(result.Type) {
+			return fmt.Errorf("type %q is not one of task, reference, idea, note, fleeting", result.Type)
+		}
+		if result.Confidence < 0 || result.Confidence > 1 {
+			return fmt.Errorf("confidence %v is outside 0.0–1.0", result.Confidence)
+		}
+		return nil
+	})
+	if err != nil {
+		return LLMClassifyResult{}, err
 	}
 	return result, nil
 }
 
 // TriageOptions holds flags for the triage command.
 type TriageOptions struct {
-	List       bool
-	Auto       bool
-	Older      string // duration string like "7d", "24h" — parsed by parseSinceDuration
-	DryRun     bool
-	JSONOutput bool
-	Quiet      bool // suppress all output when nothing was processed (cron-friendly)
+	List            bool
+	Auto            bool
+	Interactive     bool   // review each note: accept, retype, move, tag, skip, delete, merge
+	Titles          bool   // generate title, summary and slug for untitled notes
+	Summaries       bool   // generate a summary for notes that have none, titled or not
+	MergeDuplicates bool   // --auto: append notes that duplicate an existing note to it
+	Train           bool   // cross-validate and save the learned classifier
+	Eval            bool   // cross-validate the learned classifier without saving
+	Older           string // duration string like "7d", "24h" — parsed by parseSinceDuration
+	DryRun          bool
+	JSONOutput      bool
+	Quiet           bool // suppress all output when nothing was processed (cron-friendly)
 }
 
 // PendingNote represents a note in the inbox awaiting triage.
@@ -177,8 +150,20 @@ type ProcessedNote struct {
 	ToPath     string   `json:"to_path"`
 	NoteType   string   `json:"note_type"`
 	LinksAdded []string `json:"links_added,omitempty"`
-	DryRun     bool     `json:"dry_run,omitempty"`
-	Appended   bool     `json:"appended,omitempty"` // true when content was appended to canonical
+	Rule       string   `json:"rule,omitempty"`     // routing rule that fired, if any
+	Title      string   `json:"title,omitempty"`    // generated title (--titles)
+	TitleBy    string   `json:"title_by,omitempty"` // "llm" or "extractive"
+	Summary    string   `json:"summary,omitempty"`  // generated summary (--titles, --summaries)
+	// Duplicate is the existing note this one most likely repeats.
+	Duplicate *dedupe.Match `json:"duplicate,omitempty"`
+	// ClassifiedBy is the source of NoteType: frontmatter, rule, llm, learned
+	// or regex. Empty when the type was changed interactively.
+	ClassifiedBy string   `json:"classified_by,omitempty"`
+	Confidence   float64  `json:"confidence,omitempty"` // learned classifier confidence
+	TagsAdded    []string `json:"tags_added,omitempty"` // tags added by the routing rule
+	Action       string   `json:"action,omitempty"`     // "merged" or "deleted" (--interactive)
+	DryRun       bool     `json:"dry_run,omitempty"`
+	Appended     bool     `json:"appended,omitempty"` // true when content was appended to canonical
 }
 
 // TriageSummary holds aggregate counts for the triage run.
@@ -195,14 +180,27 @@ type TriageOutput struct {
 	Processed []ProcessedNote `json:"processed"`
 	Errors    []string        `json:"errors"`
 	Summary   TriageSummary   `json:"summary"`
+	LLMUsage  *llm.Usage      `json:"llm_usage,omitempty"` // model calls and tokens, when an LLM was used
 }
 
 // TriageCmd triages notes in the Inbox/ folder.
-// --list shows pending notes with age; --auto classifies, enriches, and moves them.
-// Default mode (neither flag) is equivalent to --list.
+// --list shows pending notes with age; --auto classifies, enriches, and moves them;
+// --interactive proposes the same steps one note at a time and records decisions;
+// --train and --eval fit the learned classifier on past decisions.
+// Default mode (no flag) is equivalent to --list.
 func TriageCmd(vaultPath string, opts TriageOptions) error {
-	// Default to --list when neither mode is specified.
-	if !opts.List && !opts.Auto {
+	if opts.Train || opts.Eval {
+		return triageTrainCmd(vaultPath, opts.Eval, opts.JSONOutput)
+	}
+	if opts.Interactive && opts.JSONOutput {
+		return fmt.Errorf("--interactive cannot be combined with --json")
+	}
+	if opts.Interactive && !stdinIsTerminal() {
+		return fmt.Errorf("--interactive requires a terminal")
+	}
+
+	// Default to --list when no mode is specified.
+	if !opts.List && !opts.Auto && !opts.Interactive {
 		opts.List = true
 	}
 
@@ -230,12 +228,112 @@ func TriageCmd(vaultPath string, opts TriageOptions) error {
 	result := TriageOutput{}
 	now := time.Now()
 
+	// Routing rules are optional; a malformed file is an error rather than
+	// being silently ignored.
+	routing, err := rules.Load(vaultPath)
+	if err != nil {
+		return err
+	}
+
 	// Collect pending notes (skip already-processed ones; apply --older filter).
+	result.Pending, result.Errors = collectPendingNotes(vaultPath, notes, olderDuration, now)
+
+	result.Summary.Total = len(result.Pending)
+
+	// --auto / --interactive: classify, enrich, rewrite frontmatter, move each pending note.
+	if opts.Auto || opts.Interactive {
+		tc := &triageContext{routing: routing, titles: opts.Titles, summaries: opts.Summaries, mergeDuplicates: opts.MergeDuplicates}
+
+		// Open index for wikilink enrichment (best-effort; skipped if not built).
+		dbPath := index.IndexDBPath(vaultPath)
+		if _, err := os.Stat(dbPath); err == nil {
+			if s, err := index.Open(dbPath); err == nil {
+				tc.store = s
+				defer tc.store.Close()
+			}
+		}
+
+		// LLM classifier from the configured provider; nil → local fallback.
+		client, err := llm.FromSettings(config.ResolveLLM())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: LLM disabled: %v\n", err)
+		}
+		tc.llm = NewClientClassifier(client)
+
+		// Duplicate detection against the rest of the vault (best-effort).
+		if corpus, err := loadDedupeCorpus(vaultPath, dedupe.DefaultOptions(), nil); err == nil {
+			tc.dupes = corpus
+		}
+
+		// Learned classifier from triage --train (optional).
+		if model, err := classify.Load(triageModelPath(vaultPath)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring triage model: %v\n", err)
+		} else {
+			tc.model = model
+		}
+
+		if opts.Interactive {
+			interactiveTriage(&triageSession{
+				triageContext: tc,
+				vaultPath:     vaultPath,
+				dryRun:        opts.DryRun,
+				now:           now,
+				in:            bufio.NewReader(os.Stdin),
+				out:           os.Stdout,
+			}, &result)
+		} else {
+			for _, pending := range result.Pending {
+				processed, err := triageNote(vaultPath, pending, tc, opts.DryRun, now)
+				if err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", pending.Path, err))
+					result.Summary.Errors++
+					continue
+				}
+				result.Processed = append(result.Processed, processed)
+				result.Summary.Processed++
+			}
+		}
+		tc.history.save()
+		result.Summary.Skipped = result.Summary.Total - result.Summary.Processed - result.Summary.Errors
+		if client != nil {
+			if u := client.Usage(); u.Calls > 0 {
+				result.LLMUsage = &u
+			}
+		}
+	}
+
+	if opts.JSONOutput {
+		return output.JSON(result)
+	}
+
+	// --quiet: skip all output when the run produced nothing worth reporting.
+	// Errors still surface so cron email catches failures.
+	if opts.Quiet && result.Summary.Processed == 0 && len(result.Errors) == 0 {
+		return nil
+	}
+
+	if opts.Auto || opts.Interactive {
+		if opts.Interactive {
+			fmt.Println()
+		}
+		printTriageAutoReport(result, opts.DryRun)
+	} else {
+		printTriageListReport(result)
+	}
+	return nil
+}
+
+// collectPendingNotes returns the inbox notes still awaiting triage, skipping
+// notes with status: processed and, when older is set, notes younger than it.
+// Unreadable notes are reported as errors.
+func collectPendingNotes(vaultPath string, notes []vault.NoteInfo, older time.Duration, now time.Time) ([]PendingNote, []string) {
+	var pending []PendingNote
+	var errs []string
 	for _, info := range notes {
 		fullPath := filepath.Join(vaultPath, info.Path)
 		data, err := os.ReadFile(fullPath)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", info.Path, err))
+			errs = append(errs, fmt.Sprintf("%s: %v", info.Path, err))
 			continue
 		}
 
@@ -252,14 +350,14 @@ func TriageCmd(vaultPath string, opts TriageOptions) error {
 		ageDays, createdLabel := computeAge(created, info.ModTime, now)
 
 		// Apply --older filter: skip notes younger than the threshold.
-		if olderDuration > 0 {
-			thresholdDays := int(olderDuration.Hours() / 24)
+		if older > 0 {
+			thresholdDays := int(older.Hours() / 24)
 			if ageDays < thresholdDays {
 				continue
 			}
 		}
 
-		result.Pending = append(result.Pending, PendingNote{
+		pending = append(pending, PendingNote{
 			Path:     info.Path,
 			Type:     noteType,
 			AgeDays:  ageDays,
@@ -267,117 +365,264 @@ func TriageCmd(vaultPath string, opts TriageOptions) error {
 			Created:  createdLabel,
 		})
 	}
+	return pending, errs
+}
 
-	result.Summary.Total = len(result.Pending)
+// triageContext bundles the optional helpers used to classify, route and link
+// notes. Any field may be nil.
+type triageContext struct {
+	store     *index.Store    // search index for link suggestions
+	llm       LLMClassifier   // network classifier
+	routing   *rules.Set      // user routing rules
+	model     *classify.Model // classifier learned from past triage
+	titles    bool            // generate titles and summaries for untitled notes
+	summaries bool            // generate summaries for titled notes too
+	dupes     *dedupe.Corpus  // vault notes, for duplicate detection
+
+	mergeDuplicates bool // --auto appends duplicates to the existing note
+
+	resolver *vault.Resolver // vault link resolver, loaded on first use
+	history  *noteHistory    // snapshots of overwritten notes, saved after the run
+}
 
-	// --auto: classify, enrich, rewrite frontmatter, move each pending note.
-	if opts.Auto {
-		// Open index for wikilink enrichment (best-effort; skipped if not built).
-		var store *index.Store
-		dbPath := index.IndexDBPath(vaultPath)
-		if _, err := os.Stat(dbPath); err == nil {
-			if s, err := index.Open(dbPath); err == nil {
-				store = s
-				defer store.Close()
-			}
+// vaultResolver returns the run's link resolver, walking the vault the first
+// time it is needed rather than once per note.
+func (tc *triageContext) vaultResolver(vaultPath string) (*vault.Resolver, error) {
+	if tc.resolver == nil {
+		r, err := vault.LoadResolver(vaultPath)
+		if err != nil {
+			return nil, err
 		}
+		tc.resolver = r
+	}
+	return tc.resolver, nil
+}
 
-		// Create Haiku classifier if ANTHROPIC_API_KEY is set; nil → regex fallback.
-		var llm LLMClassifier
-		if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
-			llm = NewHaikuClassifier(apiKey)
-		}
+// snapshots returns the run's history snapshotter, created on first use.
+func (tc *triageContext) snapshots(vaultPath string) *noteHistory {
+	if tc.history == nil {
+		tc.history = newNoteHistory(vaultPath)
+	}
+	return tc.history
+}
 
-		for _, pending := range result.Pending {
-			processed, err := triageNote(vaultPath, pending, store, llm, opts.DryRun, now)
-			if err != nil {
-				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", pending.Path, err))
-				result.Summary.Errors++
-				continue
-			}
-			result.Processed = append(result.Processed, processed)
-			result.Summary.Processed++
+// triagePlan is the proposed outcome for one inbox note, before anything is
+// written. Interactive review edits the plan; --auto applies it as-is.
+type triagePlan struct {
+	pending      PendingNote
+	parsed       *vault.Note
+	rawFM        string // the note's frontmatter as written
+	proposedType string // type from the LLM, routing rule or regex classifier
+	noteType     string
+	toPath       string
+	linksAdded   []string
+	classifiedBy string   // frontmatter, rule, llm, learned or regex
+	confidence   float64  // learned classifier confidence
+	origTags     []string // frontmatter tags before routing and edits
+	rule         *rules.Rule
+	props        map[string]string
+	title        string // generated title, when the note had none
+	titleBy      string // "llm" or "extractive"
+	summary      string // generated summary
+	duplicate    *dedupe.Match
+}
+
+// triageNote classifies, enriches, rewrites frontmatter, and moves a single note.
+// When llm is non-nil and returns a confident result, it overrides the regex classifier.
+// Routing rules are matched after the LLM and before the regex classifier; the
+// first matching rule may set the type, destination, tags and properties.
+func triageNote(vaultPath string, pending PendingNote, tc *triageContext, dryRun bool, now time.Time) (ProcessedNote, error) {
+	plan, err := planTriage(vaultPath, pending, tc, now)
+	if err != nil {
+		return ProcessedNote{}, err
+	}
+	merge := tc.mergeDuplicates && plan.duplicate != nil
+	if dryRun {
+		result := plan.processed()
+		if merge {
+			result.ToPath, result.Appended, result.Action = plan.duplicate.ID, true, "merged"
 		}
-		result.Summary.Skipped = result.Summary.Total - result.Summary.Processed - result.Summary.Errors
+		result.DryRun = true
+		return result, nil
 	}
-
-	if opts.JSONOutput {
-		return output.JSON(result)
+	if merge {
+		return mergeTriagePlan(vaultPath, plan, plan.duplicate.ID, tc.snapshots(vaultPath), now)
 	}
-
-	// --quiet: skip all output when the run produced nothing worth reporting.
-	// Errors still surface so cron email catches failures.
-	if opts.Quiet && result.Summary.Processed == 0 && len(result.Errors) == 0 {
-		return nil
+	processed, err := applyTriagePlan(vaultPath, plan, tc.snapshots(vaultPath), now)
+	if err == nil {
+		tc.moveReview(processed)
 	}
+	return processed, err
+}
 
-	if opts.Auto {
-		printTriageAutoReport(result, opts.DryRun)
-	} else {
-		printTriageListReport(result)
+// moveReview carries a moved note's review schedule to its new path, so the
+// next index run does not drop it. Appended notes have no schedule of their
+// own to keep.
+func (tc *triageContext) moveReview(processed ProcessedNote) {
+	if tc.store == nil || processed.Appended || processed.FromPath == processed.ToPath {
+		return
+	}
+	if err := tc.store.MoveReview(processed.FromPath, processed.ToPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
-	return nil
 }
 
-// triageNote classifies, enriches, rewrites frontmatter, and moves a single note.
-// When llm is non-nil and returns a confident result, it overrides the regex classifier.
-func triageNote(vaultPath string, pending PendingNote, store *index.Store, llm LLMClassifier, dryRun bool, now time.Time) (ProcessedNote, error) {
-	fullPath := filepath.Join(vaultPath, pending.Path)
-	data, err := os.ReadFile(fullPath)
+// planTriage reads a pending note and computes its type, destination, links
+// and rule actions (steps 1-3 of triage).
+func planTriage(vaultPath string, pending PendingNote, tc *triageContext, now time.Time) (*triagePlan, error) {
+	data, err := os.ReadFile(filepath.Join(vaultPath, pending.Path))
 	if err != nil {
-		return ProcessedNote{}, fmt.Errorf("reading note: %w", err)
+		return nil, fmt.Errorf("reading note: %w", err)
 	}
 
 	parsed := vault.ParseNote(string(data))
+	plan := &triagePlan{
+		pending:  pending,
+		parsed:   parsed,
+		origTags: extractTagsList(parsed.Frontmatter),
+	}
+	plan.rawFM, _, _ = vault.SplitFrontmatter(string(data))
 
-	// Step 1: Classify note type — LLM when available, regex fallback otherwise.
+	// Step 1: Classify note type — LLM when available, then routing rules,
+	// then the learned classifier and regex fallback.
 	ctx := context.Background()
-	noteType, llmEntities := classifyWithLLM(ctx, parsed, llm)
+	llmResult, llmOK := llmClassify(ctx, parsed, tc.llm)
+	var llmType string
+	var llmEntities []string
+	if llmOK {
+		llmType, llmEntities = string(llmResult.Type), llmResult.Entities
+	}
+	plan.rule = tc.routing.Match(routingInput(parsed, llmType))
+	switch {
+	case plan.rule != nil && plan.rule.Type != "":
+		plan.noteType, plan.classifiedBy = plan.rule.Type, "rule"
+	case llmType != "":
+		plan.noteType, plan.classifiedBy = llmType, "llm"
+	default:
+		plan.noteType, plan.classifiedBy, plan.confidence = classifyLocal(parsed, tc.model)
+	}
+	plan.proposedType = plan.noteType
+
+	// Likely duplicates outside the inbox are offered as merge targets.
+	if tc.dupes != nil {
+		for _, m := range tc.dupes.FindID(pending.Path) {
+			if !strings.HasPrefix(m.ID, "Inbox/") {
+				plan.duplicate = &m
+				break
+			}
+		}
+	}
 
 	// Step 2: Find wikilink suggestions.
 	// Entity-based matches (from LLM) take priority; cosine-similarity fills the rest.
-	var linksAdded []string
-	entityLinks := matchEntitiesAgainstVault(vaultPath, llmEntities)
-	linksAdded = append(linksAdded, entityLinks...)
-
-	if store != nil {
-		for _, s := range enrichSingleNote(store, pending.Path) {
-			toName := strings.TrimSuffix(filepath.Base(s.To), ".md")
-			// Avoid duplicates from entity matching.
-			if !containsStr(linksAdded, toName) {
-				linksAdded = append(linksAdded, toName)
+	var targets []string
+	if tc.store != nil {
+		for _, s := range enrichSingleNote(tc.store, pending.Path) {
+			targets = append(targets, s.To)
+		}
+	}
+	if len(llmEntities) > 0 || len(targets) > 0 {
+		resolver, err := tc.vaultResolver(vaultPath)
+		if err == nil {
+			targets = append(matchEntitiesAgainstVault(resolver, llmEntities), targets...)
+		}
+		// Deduplicate on the vault path, then write each as link text.
+		seen := make(map[string]bool)
+		for _, target := range targets {
+			if seen[target] {
+				continue
+			}
+			seen[target] = true
+			if resolver != nil {
+				plan.linksAdded = append(plan.linksAdded, resolver.LinkText(target))
+			} else {
+				plan.linksAdded = append(plan.linksAdded, strings.TrimSuffix(filepath.Base(target), ".md"))
 			}
 		}
 	}
 
-	// Step 3: Compute destination path.
-	toPath := triageDestination(pending.Path, noteType, parsed)
+	// Step 3: Name untitled notes, compute destination path and apply rule actions.
+	if tc.titles || tc.summaries {
+		plan.planNaming(llmResult, tc.titles, tc.summaries)
+	}
+	if plan.rule != nil {
+		applyRuleTags(parsed, plan.rule)
+		plan.props = plan.rule.Set
+	}
+	plan.toPath = plan.destination(now)
+	return plan, nil
+}
 
-	// Step 4: Build updated note content.
-	newContent := buildTriagedContent(parsed, noteType, linksAdded, now)
+// destination computes the target path for the plan's current type: the
+// rule's destination template when one fired, else triageDestination.
+func (p *triagePlan) destination(now time.Time) string {
+	if p.rule != nil {
+		if dest := routedDestination(p.rule, p.pending, p.noteType, p.parsed, now); dest != "" {
+			return dest
+		}
+	}
+	return triageDestination(p.pending.Path, p.noteType, p.parsed)
+}
 
-	if dryRun {
-		return ProcessedNote{
-			FromPath:   pending.Path,
-			ToPath:     toPath,
-			NoteType:   noteType,
-			LinksAdded: linksAdded,
-			DryRun:     true,
-		}, nil
+// tags returns the note's current frontmatter tags.
+func (p *triagePlan) tags() []string {
+	return extractTagsList(p.parsed.Frontmatter)
+}
+
+// processed returns the ProcessedNote describing the plan.
+func (p *triagePlan) processed() ProcessedNote {
+	var tagsAdded []string
+	for _, t := range p.tags() {
+		if !containsFold(p.origTags, t) {
+			tagsAdded = append(tagsAdded, t)
+		}
 	}
+	result := ProcessedNote{
+		FromPath:   p.pending.Path,
+		ToPath:     p.toPath,
+		NoteType:   p.noteType,
+		LinksAdded: p.linksAdded,
+		TagsAdded:  tagsAdded,
+		Title:      p.title,
+		TitleBy:    p.titleBy,
+		Summary:    p.summary,
+		Duplicate:  p.duplicate,
+	}
+	if p.noteType == p.proposedType {
+		result.ClassifiedBy = p.classifiedBy
+		result.Confidence = p.confidence
+	}
+	if p.rule != nil {
+		result.Rule = p.rule.Name
+	}
+	return result
+}
+
+// applyTriagePlan writes the triaged note to its destination and removes the
+// original (steps 4-6 of triage).
+func applyTriagePlan(vaultPath string, plan *triagePlan, snapshots *noteHistory, now time.Time) (ProcessedNote, error) {
+	// Step 4: Build updated note content.
+	newContent := buildTriagedContent(plan.parsed, plan.rawFM, plan.noteType, plan.linksAdded, plan.props, now)
 
 	// Step 5: Write to destination.
 	// If a canonical note already exists at the destination, append to it instead
 	// of creating a duplicate or deconflicting with a timestamp suffix.
-	targetFull := filepath.Join(vaultPath, toPath)
-	appended := false
+	result := plan.processed()
+	targetFull := filepath.Join(vaultPath, plan.toPath)
 	if _, err := os.Stat(targetFull); err == nil {
 		// Canonical exists — append the new body to it.
-		if err := appendToCanonical(targetFull, parsed.Body, now); err != nil {
+		snapshots.snapshot(plan.toPath, "triage")
+		if err := appendToCanonical(targetFull, plan.parsed.Body, now); err != nil {
 			return ProcessedNote{}, fmt.Errorf("appending to canonical: %w", err)
 		}
-		appended = true
+		result.Appended = true
 	} else {
+		if plan.rule != nil && plan.rule.Template != "" {
+			var err error
+			if newContent, err = applyRuleTemplate(vaultPath, plan, newContent, now); err != nil {
+				return ProcessedNote{}, err
+			}
+		}
 		if err := os.MkdirAll(filepath.Dir(targetFull), 0755); err != nil {
 			return ProcessedNote{}, fmt.Errorf("creating target directory: %w", err)
 		}
@@ -387,17 +632,114 @@ func triageNote(vaultPath string, pending PendingNote, store *index.Store, llm L
 	}
 
 	// Step 6: Remove original.
-	if err := os.Remove(fullPath); err != nil {
-		return ProcessedNote{}, fmt.Errorf("removing original note: %w", err)
+	if err := removeInboxNote(vaultPath, plan.pending.Path, snapshots, "triage"); err != nil {
+		return ProcessedNote{}, err
+	}
+	return result, nil
+}
+
+// mergeTriagePlan appends the note's body to an existing note and removes
+// the original.
+func mergeTriagePlan(vaultPath string, plan *triagePlan, target string, snapshots *noteHistory, now time.Time) (ProcessedNote, error) {
+	targetFull := filepath.Join(vaultPath, target)
+	snapshots.snapshot(target, "triage merge")
+	if err := appendToCanonical(targetFull, plan.parsed.Body, now); err != nil {
+		return ProcessedNote{}, fmt.Errorf("appending to %s: %w", target, err)
+	}
+	if err := removeInboxNote(vaultPath, plan.pending.Path, snapshots, "triage merge"); err != nil {
+		return ProcessedNote{}, err
+	}
+	processed := plan.processed()
+	processed.ToPath = target
+	processed.Appended = true
+	processed.Action = "merged"
+	return processed, nil
+}
+
+// removeInboxNote snapshots and deletes a triaged inbox note.
+func removeInboxNote(vaultPath, notePath string, snapshots *noteHistory, reason string) error {
+	snapshots.snapshot(notePath, reason)
+	if err := os.Remove(filepath.Join(vaultPath, notePath)); err != nil {
+		return fmt.Errorf("removing original note: %w", err)
+	}
+	return nil
+}
+
+// routingInput assembles what routing rules match against: frontmatter, body,
+// frontmatter and inline tags, source and the LLM classification.
+func routingInput(parsed *vault.Note, llmType string) rules.Input {
+	tags := extractTagsList(parsed.Frontmatter)
+	for _, t := range parsed.Tags {
+		tags = append(tags, t.Name)
+	}
+	return rules.Input{
+		Frontmatter: parsed.Frontmatter,
+		Body:        parsed.Body,
+		Tags:        tags,
+		Source:      frontmatterString(parsed.Frontmatter, "source"),
+		LLMType:     llmType,
+	}
+}
+
+// routedDestination renders a rule's destination template for a note. Date
+// tokens use the note's created date, falling back to now.
+func routedDestination(rule *rules.Rule, pending PendingNote, noteType string, parsed *vault.Note, now time.Time) string {
+	return rule.RenderDestination(rules.DestinationVars{
+		Date:   createdDate(pending, now),
+		Type:   noteType,
+		Slug:   noteSlug(pending.Path, parsed),
+		Domain: rules.SourceDomain(frontmatterString(parsed.Frontmatter, "source")),
+	})
+}
+
+// createdDate is a pending note's created date, falling back to now.
+func createdDate(pending PendingNote, now time.Time) time.Time {
+	if t, err := time.Parse("2006-01-02", pending.Created); err == nil {
+		return t
+	}
+	return now
+}
+
+// applyRuleTemplate renders the rule's note template around triaged
+// content. The note's properties, the rule's set values, type and content
+// are template variables; dates are the note's created date, as in
+// destinations. Nobody is prompted, so variables need defaults.
+func applyRuleTemplate(vaultPath string, plan *triagePlan, content string, now time.Time) (string, error) {
+	tmpl, err := loadNoteTemplate(vaultPath, plan.rule.Template)
+	if err != nil {
+		return "", fmt.Errorf("rule %q: %w", plan.rule.Name, err)
+	}
+	vars := make(map[string]string)
+	for k := range plan.parsed.Frontmatter {
+		if v := frontmatterString(plan.parsed.Frontmatter, k); v != "" {
+			vars[k] = v
+		}
+	}
+	for k, v := range plan.props {
+		vars[k] = v
 	}
+	vars["type"] = plan.noteType
+	return tmpl.wrap(content, template.Context{
+		Title:  fileTitle(plan.toPath),
+		Folder: noteFolder(plan.toPath),
+		Date:   createdDate(plan.pending, now),
+		Vars:   vars,
+	})
+}
 
-	return ProcessedNote{
-		FromPath:   pending.Path,
-		ToPath:     toPath,
-		NoteType:   noteType,
-		LinksAdded: linksAdded,
-		Appended:   appended,
-	}, nil
+// applyRuleTags merges a rule's add_tags into the parsed note's frontmatter tags.
+func applyRuleTags(parsed *vault.Note, rule *rules.Rule) {
+	tags := extractTagsList(parsed.Frontmatter)
+	changed := false
+	for _, t := range rule.AddTags {
+		if !containsFold(tags, t) {
+			tags = append(tags, t)
+			changed = true
+		}
+	}
+	if changed {
+		parsed.Frontmatter["tags"] = tags
+	}
 }
 
 // classifyWithLLM classifies a note using the LLM when available and confident.
@@ -405,19 +747,29 @@ func triageNote(vaultPath string, pending PendingNote, store *index.Store, llm L
 // has confidence below llmConfidenceThreshold. Also returns extracted entities
 // from the LLM for vault matching (nil on regex fallback).
 func classifyWithLLM(ctx context.Context, parsed *vault.Note, llm LLMClassifier) (noteType string, entities []string) {
-	if llm != nil {
-		content := buildLLMContent(parsed)
-		result, err := llm.Classify(ctx, content)
-		if err == nil &&
-			result.Confidence >= llmConfidenceThreshold &&
-			isValidNoteType(result.Type) &&
-			result.Type != NoteTypeFleeting {
-			return string(result.Type), result.Entities
-		}
+	if result, ok := llmClassify(ctx, parsed, llm); ok {
+		return string(result.Type), result.Entities
 	}
 	return classifyNoteType(parsed), nil
 }
 
+// llmClassify asks the classifier about a note. result is the reply when the
+// call succeeded (its title and summary are usable either way); ok reports
+// whether the type is confident, valid and not fleeting.
+func llmClassify(ctx context.Context, parsed *vault.Note, classifier LLMClassifier) (result LLMClassifyResult, ok bool) {
+	if classifier == nil {
+		return LLMClassifyResult{}, false
+	}
+	result, err := classifier.Classify(ctx, buildLLMContent(parsed))
+	if err != nil {
+		return LLMClassifyResult{}, false
+	}
+	ok = result.Confidence >= llmConfidenceThreshold &&
+		isValidNoteType(result.Type) &&
+		result.Type != NoteTypeFleeting
+	return result, ok
+}
+
 // buildLLMContent assembles the text sent to the LLM for classification.
 // Includes salient frontmatter fields followed by the note body.
 func buildLLMContent(parsed *vault.Note) string {
@@ -435,32 +787,19 @@ func buildLLMContent(parsed *vault.Note) string {
 	return sb.String()
 }
 
-// matchEntitiesAgainstVault finds vault notes whose slugified filenames match
-// any of the given entity names. Returns note display names (without .md extension).
-func matchEntitiesAgainstVault(vaultPath string, entities []string) []string {
-	if len(entities) == 0 {
-		return nil
-	}
-
-	notes, err := vault.ListNotes(vaultPath, "")
-	if err != nil {
-		return nil
-	}
-
-	// Build a set of slugified entity names for O(1) lookup.
-	entitySlugs := make(map[string]bool, len(entities))
-	for _, e := range entities {
-		entitySlugs[slugify(e)] = true
-	}
-
+// matchEntitiesAgainstVault finds vault notes matching any of the given entity
+// names, by filename, partial path, alias or slugified filename. Returns the
+// vault path of each match.
+func matchEntitiesAgainstVault(resolver *vault.Resolver, entities []string) []string {
 	var matches []string
 	seen := make(map[string]bool)
-	for _, n := range notes {
-		name := strings.TrimSuffix(filepath.Base(n.Path), ".md")
-		if entitySlugs[slugify(name)] && !seen[name] {
-			matches = append(matches, name)
-			seen[name] = true
+	for _, e := range entities {
+		res := resolver.ResolveLoose(e)
+		if res.Status == vault.Missing || seen[res.Path] {
+			continue
 		}
+		seen[res.Path] = true
+		matches = append(matches, res.Path)
 	}
 	return matches
 }
@@ -485,6 +824,16 @@ func appendToCanonical(destFull, newBody string, now time.Time) error {
 	return os.WriteFile(destFull, append(existing, []byte(entry.String())...), 0644)
 }
 
+// containsFold reports whether s is in the slice, ignoring case.
+func containsFold(slice []string, s string) bool {
+	for _, v := range slice {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
 // containsStr reports whether s is in the slice.
 func containsStr(slice []string, s string) bool {
 	for _, v := range slice {
@@ -534,6 +883,23 @@ func classifyNoteType(parsed *vault.Note) string {
 	return "idea"
 }
 
+// classifyLocal classifies a note without the network: an existing
+// non-fleeting frontmatter type is kept, then the learned model is used when
+// its calibrated confidence reaches learnedConfidenceThreshold, then the regex
+// rules of classifyNoteType.
+func classifyLocal(parsed *vault.Note, model *classify.Model) (noteType, by string, confidence float64) {
+	if existing := frontmatterString(parsed.Frontmatter, "type"); existing != "" && existing != "fleeting" {
+		return existing, "frontmatter", 0
+	}
+	if model != nil {
+		p := model.Predict(buildLLMContent(parsed))
+		if p.Confidence >= learnedConfidenceThreshold && isValidNoteType(NoteType(p.Label)) && p.Label != string(NoteTypeFleeting) {
+			return p.Label, "learned", p.Confidence
+		}
+	}
+	return classifyNoteType(parsed), "regex", 0
+}
+
 // typeFolder maps a note type to its target vault folder.
 func typeFolder(noteType string) string {
 	switch noteType {
@@ -597,26 +963,39 @@ func deconflictPath(path string) string {
 
 // buildTriagedContent rewrites a note's frontmatter with the classified type,
 // sets status: processed and triaged date, preserves all other existing fields,
-// and appends a ## Related Notes section for any wikilink suggestions.
-func buildTriagedContent(parsed *vault.Note, noteType string, linksAdded []string, now time.Time) string {
+// and appends a ## Related Notes section for any wikilink suggestions. props
+// are properties set by a routing rule; they override title, created and
+// source and are written in sorted order after tags. rawFM is the note's
+// frontmatter as written; properties not set here are copied from it
+// verbatim after the rest.
+func buildTriagedContent(parsed *vault.Note, rawFM, noteType string, linksAdded []string, props map[string]string, now time.Time) string {
 	var b strings.Builder
 	b.WriteString("---\n")
 
+	field := func(key string) string {
+		if v, ok := props[key]; ok {
+			return v
+		}
+		return frontmatterString(parsed.Frontmatter, key)
+	}
+
 	// Deterministic field order: title → created → type → status → triaged → source → tags → extras.
-	if title := frontmatterString(parsed.Frontmatter, "title"); title != "" {
-		fmt.Fprintf(&b, "title: %s\n", title)
+	if title := field("title"); title != "" {
+		fmt.Fprintf(&b, "title: %s\n", frontmatterValue(title))
 	}
-	if created := frontmatterString(parsed.Frontmatter, "created"); created != "" {
+	if created := field("created"); created != "" {
 		fmt.Fprintf(&b, "created: %s\n", created)
 	}
 	fmt.Fprintf(&b, "type: %s\n", noteType)
 	b.WriteString("status: processed\n")
 	fmt.Fprintf(&b, "triaged: %s\n", now.Format("2006-01-02"))
 
-	if source := frontmatterString(parsed.Frontmatter, "source"); source != "" {
-		fmt.Fprintf(&b, "source: %s\n", source)
+	if source := field("source"); source != "" {
+		fmt.Fprintf(&b, "source: %s\n", frontmatterValue(source))
+	}
+	if summary := field("summary"); summary != "" {
+		fmt.Fprintf(&b, "summary: %s\n", frontmatterValue(summary))
 	}
-
 	// Preserve tags list.
 	if tags, ok := parsed.Frontmatter["tags"]; ok {
 		switch v := tags.(type) {
@@ -634,6 +1013,24 @@ func buildTriagedContent(parsed *vault.Note, noteType string, linksAdded []strin
 		}
 	}
 
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		switch k {
+		case "title", "created", "source", "summary":
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", k, frontmatterValue(props[k]))
+	}
+
+	// Everything else, such as capture_id, aliases or fetched page metadata,
+	// is kept as written.
+	written := []string{"title", "created", "type", "status", "triaged", "source", "summary", "tags"}
+	b.WriteString(passthroughFrontmatter(rawFM, append(written, keys...)...))
+
 	b.WriteString("---\n")
 
 	// Append body (ensure leading newline between frontmatter and body).
@@ -673,19 +1070,15 @@ func enrichSingleNoteFromRows(notes []index.NoteRow, notePath string) []LinkSugg
 	const threshold = 0.7
 	const maxLinks = 5
 
-	// Locate the target note's embedding and its existing wikilinks.
+	// Locate the target note's embedding and the notes it already links to.
 	var targetEmb []float32
-	existingLinks := make(map[string]bool)
+	var existingLinks map[string]bool
 	for _, n := range notes {
 		if n.Path != notePath {
 			continue
 		}
 		targetEmb = n.Embedding
-		if n.Wikilinks != "" {
-			for _, link := range strings.Split(n.Wikilinks, ", ") {
-				existingLinks[strings.ToLower(strings.TrimSpace(link))] = true
-			}
-		}
+		existingLinks = resolvedLinkSet(resolverFromRows(notes), n)
 		break
 	}
 	if targetEmb == nil {
@@ -707,7 +1100,7 @@ func enrichSingleNoteFromRows(notes []index.NoteRow, notePath string) []LinkSugg
 		}
 
 		toName := strings.ToLower(strings.TrimSuffix(filepath.Base(n.Path), ".md"))
-		if existingLinks[toName] || toName == targetName {
+		if existingLinks[n.Path] || toName == targetName {
 			continue
 		}
 
@@ -828,23 +1221,49 @@ func printTriageAutoReport(result TriageOutput, dryRun bool) {
 			prefix := "✓"
 			if p.DryRun {
 				prefix = "→"
+			} else if p.Action == "deleted" {
+				prefix = "✗"
 			} else if p.Appended {
 				prefix = "+"
 			}
+			if p.Action == "deleted" {
+				fmt.Printf("  %s %s deleted\n", prefix, filepath.Base(p.FromPath))
+				continue
+			}
 			line := fmt.Sprintf("  %s %s → %s (type: %s",
 				prefix,
 				filepath.Base(p.FromPath),
 				p.ToPath,
 				p.NoteType,
 			)
+			if p.Rule != "" {
+				line += ", rule: " + p.Rule
+			}
+			if p.Title != "" {
+				line += fmt.Sprintf(", title: %q (%s)", p.Title, p.TitleBy)
+			}
+			if p.ClassifiedBy == "learned" {
+				line += fmt.Sprintf(", learned %.0f%%", p.Confidence*100)
+			}
+			if len(p.TagsAdded) > 0 {
+				line += ", tags: +" + strings.Join(p.TagsAdded, " +")
+			}
 			if len(p.LinksAdded) > 0 {
 				line += ", links: " + strings.Join(p.LinksAdded, ", ")
 			}
-			if p.Appended {
+			if p.Duplicate != nil && p.Action != "merged" {
+				line += fmt.Sprintf(", duplicate of %s? %s", p.Duplicate.ID, describeMatch(*p.Duplicate))
+			}
+			if p.Action == "merged" {
+				line += ", merged"
+			} else if p.Appended {
 				line += ", appended to canonical"
 			}
 			line += ")"
 			fmt.Println(line)
+			if p.Summary != "" {
+				fmt.Printf("      summary: %s\n", p.Summary)
+			}
 		}
 	}
 
@@ -857,4 +1276,8 @@ func printTriageAutoReport(result TriageOutput, dryRun bool) {
 
 	fmt.Printf("\nSummary: %d processed, %d errors (of %d total)\n",
 		result.Summary.Processed, result.Summary.Errors, result.Summary.Total)
+	if u := result.LLMUsage; u != nil {
+		fmt.Printf("LLM: %d calls (%d retries, %d failed), %d input + %d output tokens\n",
+			u.Calls, u.Retries, u.Failures, u.InputTokens, u.OutputTokens)
+	}
 }
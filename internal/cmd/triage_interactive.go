@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// Triage decisions recorded in TriageLabel.Action.
+const (
+	triageActionAccept = "accept"
+	triageActionMerge  = "merge"
+	triageActionDelete = "delete"
+	triageActionSkip   = "skip"
+)
+
+// triageLabelsFile is the NDJSON file, under .obsidian/, where interactive
+// triage decisions are appended for use as classifier training labels.
+const triageLabelsFile = "triage-labels.jsonl"
+
+// triagePreviewLines is how many body lines are shown per note.
+const triagePreviewLines = 8
+
+// maxLabelContent caps the note text stored with each label.
+const maxLabelContent = 4000
+
+// TriageLabel is one recorded interactive triage decision. ProposedType and
+// Type differ when the note was retyped, which makes the label a correction.
+type TriageLabel struct {
+	Time         string   `json:"time"`
+	Path         string   `json:"path"` // inbox path at decision time
+	Action       string   `json:"action"`
+	ProposedType string   `json:"proposed_type"`
+	Type         string   `json:"type,omitempty"`
+	ProposedPath string   `json:"proposed_path,omitempty"`
+	ToPath       string   `json:"to_path,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Rule         string   `json:"rule,omitempty"`
+	Content      string   `json:"content"` // title, source and body, as sent to the LLM
+}
+
+// triageLabelsPath returns the location of the labels file for a vault.
+func triageLabelsPath(vaultPath string) string {
+	return filepath.Join(vaultPath, ".obsidian", triageLabelsFile)
+}
+
+// recordTriageLabel appends a decision to the vault's labels file.
+func recordTriageLabel(vaultPath string, label TriageLabel) error {
+	path := triageLabelsPath(vaultPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(label)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// triageSession holds what interactive review needs across notes.
+type triageSession struct {
+	*triageContext
+	vaultPath string
+	dryRun    bool
+	now       time.Time
+	in        *bufio.Reader
+	out       io.Writer
+}
+
+// interactiveTriage steps through pending notes, showing each proposal and
+// applying the user's decision. Processed notes and errors are added to result.
+func interactiveTriage(s *triageSession, result *TriageOutput) {
+	for i, pending := range result.Pending {
+		plan, err := planTriage(s.vaultPath, pending, s.triageContext, s.now)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", pending.Path, err))
+			result.Summary.Errors++
+			continue
+		}
+		proposedPath := plan.toPath
+
+		fmt.Fprintf(s.out, "\n[%d/%d] ", i+1, len(result.Pending))
+		action, processed, quit := s.review(plan)
+		if quit {
+			return
+		}
+
+		if action != triageActionSkip && !s.dryRun {
+			switch action {
+			case triageActionAccept:
+				processed, err = applyTriagePlan(s.vaultPath, plan, s.snapshots(s.vaultPath), s.now)
+				if err == nil {
+					s.moveReview(processed)
+				}
+			case triageActionMerge:
+				processed, err = mergeTriagePlan(s.vaultPath, plan, processed.ToPath, s.snapshots(s.vaultPath), s.now)
+			case triageActionDelete:
+				err = removeInboxNote(s.vaultPath, pending.Path, s.snapshots(s.vaultPath), "triage delete")
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", pending.Path, err))
+				result.Summary.Errors++
+				continue
+			}
+		}
+
+		if !s.dryRun {
+			label := TriageLabel{
+				Time:         s.now.Format(time.RFC3339),
+				Path:         pending.Path,
+				Action:       action,
+				ProposedType: plan.proposedType,
+				ProposedPath: proposedPath,
+				Tags:         plan.tags(),
+				Content:      truncateRunes(buildLLMContent(plan.parsed), maxLabelContent),
+			}
+			if action == triageActionAccept || action == triageActionMerge {
+				label.Type = plan.noteType
+				label.ToPath = processed.ToPath
+			}
+			if plan.rule != nil {
+				label.Rule = plan.rule.Name
+			}
+			if err := recordTriageLabel(s.vaultPath, label); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not record triage decision: %v\n", err)
+			}
+		}
+
+		if action == triageActionSkip {
+			continue
+		}
+		processed.DryRun = s.dryRun
+		result.Processed = append(result.Processed, processed)
+		result.Summary.Processed++
+	}
+}
+
+// review shows a plan and reads key presses until the user accepts, merges,
+// deletes or skips the note. The plan is updated in place by retype,
+// destination and tag edits. quit is true when the user quits or input ends.
+func (s *triageSession) review(plan *triagePlan) (action string, processed ProcessedNote, quit bool) {
+	for {
+		printTriagePlan(s.out, plan)
+		key, ok := s.prompt("[A]ccept [t]ype [d]estination [g] tags [s]kip [x] delete [m]erge [q]uit: ")
+		if !ok {
+			return "", ProcessedNote{}, true
+		}
+
+		switch strings.ToLower(key) {
+		case "a", "":
+			return triageActionAccept, plan.processed(), false
+
+		case "t":
+			answer, ok := s.prompt("Type (task, reference, idea, note): ")
+			if !ok {
+				return "", ProcessedNote{}, true
+			}
+			t := NoteType(strings.ToLower(answer))
+			if !isValidNoteType(t) || t == NoteTypeFleeting {
+				fmt.Fprintf(s.out, "  Unknown type %q\n", answer)
+				continue
+			}
+			// Follow the new type unless the destination was set by hand.
+			auto := plan.toPath == plan.destination(s.now)
+			plan.noteType = string(t)
+			if auto {
+				plan.toPath = plan.destination(s.now)
+			}
+
+		case "d":
+			answer, ok := s.prompt("Destination (folder/ or path): ")
+			if !ok {
+				return "", ProcessedNote{}, true
+			}
+			if dest := interactiveDestination(answer, noteSlug(plan.pending.Path, plan.parsed)); dest != "" {
+				plan.toPath = dest
+			}
+
+		case "g":
+			answer, ok := s.prompt(fmt.Sprintf("Tags, comma-separated [%s]: ", strings.Join(plan.tags(), ", ")))
+			if !ok {
+				return "", ProcessedNote{}, true
+			}
+			if strings.TrimSpace(answer) != "" {
+				plan.parsed.Frontmatter["tags"] = parseTagList(answer)
+			}
+
+		case "s":
+			fmt.Fprintln(s.out, "  Skipped")
+			return triageActionSkip, ProcessedNote{}, false
+
+		case "x":
+			answer, ok := s.prompt(fmt.Sprintf("Delete %s? [y/N]: ", plan.pending.Path))
+			if !ok {
+				return "", ProcessedNote{}, true
+			}
+			if strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes") {
+				return triageActionDelete, ProcessedNote{
+					FromPath: plan.pending.Path,
+					NoteType: plan.noteType,
+					Action:   "deleted",
+				}, false
+			}
+
+		case "m":
+			msg := "Merge into note: "
+			if plan.duplicate != nil {
+				msg = fmt.Sprintf("Merge into note [%s]: ", plan.duplicate.ID)
+			}
+			answer, ok := s.prompt(msg)
+			if !ok {
+				return "", ProcessedNote{}, true
+			}
+			if answer == "" && plan.duplicate != nil {
+				answer = plan.duplicate.ID
+			}
+			target, err := s.mergeTarget(answer, plan.pending.Path)
+			if err != nil {
+				fmt.Fprintf(s.out, "  %v\n", err)
+				continue
+			}
+			processed := plan.processed()
+			processed.ToPath = target
+			processed.Appended = true
+			processed.Action = "merged"
+			return triageActionMerge, processed, false
+
+		case "q":
+			return "", ProcessedNote{}, true
+
+		default:
+			fmt.Fprintf(s.out, "  Unknown key %q\n", key)
+		}
+		fmt.Fprintln(s.out)
+	}
+}
+
+// prompt writes a prompt and reads one trimmed line. ok is false at end of input.
+func (s *triageSession) prompt(msg string) (string, bool) {
+	fmt.Fprint(s.out, msg)
+	line, err := s.in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(s.out)
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// mergeTarget resolves a user-entered note name to an existing vault note.
+func (s *triageSession) mergeTarget(name, fromPath string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("no note given")
+	}
+	resolver, err := s.vaultResolver(s.vaultPath)
+	if err != nil {
+		return "", err
+	}
+	res := resolver.ResolveLoose(name)
+	switch {
+	case res.Status == vault.Missing:
+		return "", fmt.Errorf("no note matches %q", name)
+	case res.Status == vault.Ambiguous:
+		return "", fmt.Errorf("%q is ambiguous: %s", name, strings.Join(res.Candidates, ", "))
+	case res.Path == fromPath:
+		return "", fmt.Errorf("cannot merge a note into itself")
+	}
+	return res.Path, nil
+}
+
+// printTriagePlan shows a note's preview and the proposed triage outcome.
+func printTriagePlan(w io.Writer, plan *triagePlan) {
+	fmt.Fprintf(w, "%s (%s)\n", plan.pending.Path, formatAgeLabel(plan.pending.AgeDays))
+	if title := frontmatterString(plan.parsed.Frontmatter, "title"); title != "" {
+		if plan.title != "" {
+			title += fmt.Sprintf(" (generated, %s)", plan.titleBy)
+		}
+		fmt.Fprintf(w, "  Title: %s\n", title)
+	}
+	fmt.Fprintln(w, "  ---")
+	for _, line := range previewLines(plan.parsed.Body, triagePreviewLines) {
+		fmt.Fprintf(w, "  | %s\n", line)
+	}
+	fmt.Fprintln(w, "  ---")
+	typeLabel := plan.noteType
+	if plan.noteType != plan.proposedType {
+		typeLabel += fmt.Sprintf(" (proposed: %s)", plan.proposedType)
+	}
+	fmt.Fprintf(w, "  Type:        %s\n", typeLabel)
+	fmt.Fprintf(w, "  Destination: %s\n", plan.toPath)
+	if plan.rule != nil {
+		fmt.Fprintf(w, "  Rule:        %s\n", plan.rule.Name)
+	}
+	if plan.summary != "" {
+		fmt.Fprintf(w, "  Summary:     %s\n", plan.summary)
+	}
+	if plan.duplicate != nil {
+		fmt.Fprintf(w, "  Duplicate:   %s (%s) — press m to merge\n", plan.duplicate.ID, describeMatch(*plan.duplicate))
+	}
+	if tags := plan.tags(); len(tags) > 0 {
+		fmt.Fprintf(w, "  Tags:        %s\n", strings.Join(tags, ", "))
+	}
+	if len(plan.linksAdded) > 0 {
+		fmt.Fprintf(w, "  Links:       %s\n", strings.Join(plan.linksAdded, ", "))
+	}
+}
+
+// previewLines returns up to n non-blank body lines, each truncated to 100 runes.
+func previewLines(body string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, truncateRunes(line, 100))
+		if len(lines) == n {
+			break
+		}
+	}
+	return lines
+}
+
+// interactiveDestination normalises a user-entered destination: a folder
+// ("Projects/") receives the note's slug, ".md" is appended when missing, and
+// the result is kept inside the vault. Returns "" for empty input.
+func interactiveDestination(input, slug string) string {
+	input = strings.TrimSpace(filepath.ToSlash(input))
+	if input == "" {
+		return ""
+	}
+	if strings.HasSuffix(input, "/") {
+		input += slug
+	}
+	dest := strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+input)), "/")
+	if !strings.HasSuffix(dest, ".md") {
+		dest += ".md"
+	}
+	return dest
+}
+
+// parseTagList splits comma- or space-separated tags, dropping '#' prefixes,
+// blanks and case-insensitive duplicates.
+func parseTagList(s string) []string {
+	var tags []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		f = strings.TrimPrefix(strings.TrimSpace(f), "#")
+		if f != "" && !containsFold(tags, f) {
+			tags = append(tags, f)
+		}
+	}
+	return tags
+}
+
+// truncateRunes shortens s to at most n runes.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
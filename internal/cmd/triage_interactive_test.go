@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInteractiveTriage(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Inbox/a.md":      "---\ntitle: Fix Build\ntype: fleeting\n---\n- [ ] fix the build\n",
+		"Inbox/b.md":      "---\ntitle: Later\ntype: fleeting\n---\nsomething\n",
+		"Inbox/c.md":      "---\ntitle: Junk\ntype: fleeting\n---\nasdf\n",
+		"Inbox/d.md":      "---\ntitle: More Go\ntype: fleeting\n---\nMore on errors.\n",
+		"Notes/go-err.md": "# Go errors\n",
+	})
+	pending := []PendingNote{{Path: "Inbox/a.md"}, {Path: "Inbox/b.md"}, {Path: "Inbox/c.md"}, {Path: "Inbox/d.md"}}
+	result := TriageOutput{Pending: pending}
+
+	// a: retype to reference, add tags, move to Projects/, accept.
+	// b: skip. c: delete (confirmed). d: merge into go-err.
+	input := strings.Join([]string{
+		"t", "reference",
+		"g", "#build, ci",
+		"d", "Projects/",
+		"a",
+		"s",
+		"x", "y",
+		"m", "go-err",
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	interactiveTriage(&triageSession{
+		triageContext: &triageContext{},
+		vaultPath:     dir,
+		now:           time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		in:            bufio.NewReader(strings.NewReader(input)),
+		out:           &out,
+	}, &result)
+
+	if result.Summary.Processed != 3 || len(result.Errors) != 0 {
+		t.Fatalf("summary = %+v, errors = %v\n%s", result.Summary, result.Errors, out.String())
+	}
+
+	moved, err := os.ReadFile(filepath.Join(dir, "Projects/fix-build.md"))
+	if err != nil {
+		t.Fatalf("accepted note not moved: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(string(moved), "type: reference") || !strings.Contains(string(moved), "  - build\n  - ci\n") {
+		t.Errorf("accepted note content:\n%s", moved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Inbox/b.md")); err != nil {
+		t.Errorf("skipped note should remain: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Inbox/c.md")); !os.IsNotExist(err) {
+		t.Errorf("deleted note still exists: %v", err)
+	}
+	merged, _ := os.ReadFile(filepath.Join(dir, "Notes/go-err.md"))
+	if !strings.Contains(string(merged), "More on errors.") {
+		t.Errorf("merge target content:\n%s", merged)
+	}
+
+	data, err := os.ReadFile(triageLabelsPath(dir))
+	if err != nil {
+		t.Fatalf("labels not recorded: %v", err)
+	}
+	var actions []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var l TriageLabel
+		if err := json.Unmarshal([]byte(line), &l); err != nil {
+			t.Fatalf("invalid label %q: %v", line, err)
+		}
+		actions = append(actions, l.Action)
+		if l.Path == "Inbox/a.md" && (l.ProposedType != "task" || l.Type != "reference" || l.ToPath != "Projects/fix-build.md") {
+			t.Errorf("retype label = %+v", l)
+		}
+	}
+	if strings.Join(actions, ",") != "accept,skip,delete,merge" {
+		t.Errorf("label actions = %v", actions)
+	}
+}
+
+func TestInteractiveDestination(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Projects/", "Projects/slug.md"},
+		{"Projects/x", "Projects/x.md"},
+		{"../outside/x.md", "outside/x.md"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := interactiveDestination(tt.in, "slug"); got != tt.want {
+			t.Errorf("interactiveDestination(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -2,13 +2,16 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/joeyhipolito/obsidian-cli/internal/index"
+	"github.com/joeyhipolito/obsidian-cli/internal/llm"
 	"github.com/joeyhipolito/obsidian-cli/internal/vault"
 )
 
@@ -364,6 +367,30 @@ func TestClassifyWithLLM_InvalidTypeFromLLM_FallsBackToRegex(t *testing.T) {
 	}
 }
 
+func TestClientClassifier_RepairsInvalidReply(t *testing.T) {
+	provider := &llm.Scripted{Replies: []llm.ScriptedReply{
+		{Text: `{"type":"todo","confidence":0.9,"entities":[]}`, Usage: llm.Usage{InputTokens: 100, OutputTokens: 10}},
+		{Text: "```json\n{\"type\":\"task\",\"confidence\":0.9,\"entities\":[\"Billing\"]}\n```", Usage: llm.Usage{InputTokens: 130, OutputTokens: 12}},
+	}}
+	client := llm.New(provider, llm.Options{})
+	result, err := NewClientClassifier(client).Classify(context.Background(), "- [ ] fix billing export")
+	if err != nil {
+		t.Fatalf("Classify() error: %v", err)
+	}
+	if result.Type != NoteTypeTask || len(result.Entities) != 1 {
+		t.Errorf("Classify() = %+v, want task with one entity", result)
+	}
+	if req := provider.Requests[0]; req.System != classifySystemPrompt || req.MaxTokens != 256 {
+		t.Errorf("request = %+v", req)
+	}
+	if u := client.Usage(); u.Calls != 2 || u.InputTokens != 230 || u.OutputTokens != 22 {
+		t.Errorf("Usage() = %+v", u)
+	}
+	if NewClientClassifier(nil) != nil {
+		t.Error("NewClientClassifier(nil) should return a nil interface")
+	}
+}
+
 func TestMatchEntitiesAgainstVault(t *testing.T) {
 	vaultDir := t.TempDir()
 	notesDir := filepath.Join(vaultDir, "Notes")
@@ -377,7 +404,11 @@ func TestMatchEntitiesAgainstVault(t *testing.T) {
 	}
 
 	entities := []string{"Golang Error Handling", "Concurrency", "NonExistent"}
-	matches := matchEntitiesAgainstVault(vaultDir, entities)
+	resolver, err := vault.LoadResolver(vaultDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches := matchEntitiesAgainstVault(resolver, entities)
 
 	if len(matches) != 2 {
 		t.Fatalf("matchEntitiesAgainstVault() = %v (len %d), want 2 matches", matches, len(matches))
@@ -387,7 +418,7 @@ func TestMatchEntitiesAgainstVault(t *testing.T) {
 	for _, m := range matches {
 		matchSet[m] = true
 	}
-	for _, want := range []string{"golang-error-handling", "concurrency"} {
+	for _, want := range []string{"Notes/golang-error-handling.md", "Notes/concurrency.md"} {
 		if !matchSet[want] {
 			t.Errorf("matchEntitiesAgainstVault() missing %q, got %v", want, matches)
 		}
@@ -395,7 +426,11 @@ func TestMatchEntitiesAgainstVault(t *testing.T) {
 }
 
 func TestMatchEntitiesAgainstVault_Empty(t *testing.T) {
-	got := matchEntitiesAgainstVault(t.TempDir(), nil)
+	resolver, err := vault.LoadResolver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := matchEntitiesAgainstVault(resolver, nil)
 	if got != nil {
 		t.Errorf("expected nil for empty entities, got %v", got)
 	}
@@ -481,3 +516,94 @@ func TestFrontmatterString(t *testing.T) {
 		t.Errorf("expected empty string for non-string value, got %q", got)
 	}
 }
+
+func TestTriageCmd_RoutingRules(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	dir := writeVaultFiles(t, map[string]string{
+		".obsidian/triage-rules.toml": `
+[[rule]]
+name = "meetings"
+destination = "Meetings/YYYY/"
+add_tags = ["meeting-notes"]
+[rule.match]
+tags = ["meeting"]
+[rule.set]
+project = "team-sync"
+`,
+		"Inbox/standup.md": "---\ntitle: Standup\ncreated: 2025-11-04\ntype: fleeting\n---\nWeekly #meeting/weekly with the team.\n",
+		"Inbox/idea.md":    "---\ntitle: Idea\ntype: fleeting\n---\nA loose thought.\n",
+	})
+
+	out := captureStdout(t, func() {
+		if err := TriageCmd(dir, TriageOptions{Auto: true, JSONOutput: true}); err != nil {
+			t.Fatalf("TriageCmd() error: %v", err)
+		}
+	})
+	var result TriageOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+
+	byFrom := map[string]ProcessedNote{}
+	for _, p := range result.Processed {
+		byFrom[p.FromPath] = p
+	}
+	meeting := byFrom["Inbox/standup.md"]
+	if meeting.Rule != "meetings" || meeting.ToPath != "Meetings/2025/standup.md" {
+		t.Errorf("routed note = %+v", meeting)
+	}
+	if idea := byFrom["Inbox/idea.md"]; idea.Rule != "" || idea.ToPath != "Ideas/idea.md" {
+		t.Errorf("unrouted note = %+v", idea)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Meetings/2025/standup.md"))
+	if err != nil {
+		t.Fatalf("routed note not written: %v", err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "  - meeting-notes\n") || !strings.Contains(content, "project: team-sync\n") {
+		t.Errorf("rule actions not applied:\n%s", content)
+	}
+}
+
+func TestTriageCmd_InvalidRules(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		".obsidian/triage-rules.toml": "[[rule]]\nname = broken\n",
+		"Inbox/a.md":                  "hello\n",
+	})
+	err := TriageCmd(dir, TriageOptions{Auto: true, DryRun: true, JSONOutput: true})
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("TriageCmd() error = %v, want parse error with line number", err)
+	}
+}
+
+func TestPlanTriage_DedupesLinksByPath(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Projects/A/plan.md": "# Plan A\n",
+		"Projects/B/plan.md": "# Plan B\n",
+		"Inbox/idea.md":      "An idea for plan A.\n",
+	})
+	store := openResurfaceTestStore(t)
+	defer store.Close()
+	for _, row := range []index.NoteRow{
+		{Path: "Inbox/idea.md", Embedding: []float32{1, 0, 0}},
+		{Path: "Projects/A/plan.md", Embedding: []float32{1, 0, 0}},
+	} {
+		if err := store.UpsertNote(&row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tc := &triageContext{store: store, llm: &mockLLMClassifier{result: LLMClassifyResult{
+		Type: NoteTypeIdea, Confidence: 0.9, Entities: []string{"A/plan"},
+	}}}
+
+	// The entity and the similar note are the same file, linked once by its
+	// path-qualified link text.
+	plan, err := planTriage(dir, PendingNote{Path: "Inbox/idea.md"}, tc, time.Now())
+	if err != nil {
+		t.Fatalf("planTriage() error: %v", err)
+	}
+	if !reflect.DeepEqual(plan.linksAdded, []string{"A/plan"}) {
+		t.Errorf("linksAdded = %v, want [A/plan]", plan.linksAdded)
+	}
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// Limits for generated titles and summaries.
+const (
+	maxTitleWords    = 8
+	maxTitleRunes    = 80
+	maxSummaryRunes  = 200
+	minClauseWords   = 3 // a leading clause shorter than this is not used as the title
+	titleSourceLLM   = "llm"
+	titleSourceLocal = "extractive"
+)
+
+var (
+	titleURLRe      = regexp.MustCompile(`https?://[^\s)>\]]+`)
+	titleMDLinkRe   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	titleWikiLinkRe = regexp.MustCompile(`!?\[\[([^\]|#]*)(?:#[^\]|]*)?(?:\|([^\]]*))?\]\]`)
+	titleListRe     = regexp.MustCompile(`^\s*(?:[-*+]\s+(?:\[[ xX]\]\s+)?|\d+[.)]\s+|>\s*)`)
+	titleSentenceRe = regexp.MustCompile(`[.!?](?:\s|$)`)
+	titleClauseRe   = regexp.MustCompile(`\s+[—–-]\s+|[,;:]\s+|\s+\(`)
+	titleHeadingRe  = regexp.MustCompile(`^#{1,6}(?:\s|$)`)
+)
+
+// noteTitled reports whether a note already has a title: a title: property
+// or an H1 heading.
+func noteTitled(parsed *vault.Note) bool {
+	if frontmatterString(parsed.Frontmatter, "title") != "" {
+		return true
+	}
+	for _, h := range parsed.Headings {
+		if h.Level == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// planNaming fills in a generated title and summary. titles names untitled
+// notes and summarizes them; summaries adds a summary to every note,
+// including titled ones. The LLM's suggestions are used when present;
+// otherwise they are extracted from the body. Existing titles, H1s and
+// summaries are kept. The title is written to the note's frontmatter so the
+// destination slug derives from it.
+func (p *triagePlan) planNaming(suggested LLMClassifyResult, titles, summaries bool) {
+	titled := noteTitled(p.parsed)
+	if titles && !titled {
+		if title := cleanGeneratedText(suggested.Title, maxTitleRunes); title != "" {
+			p.title, p.titleBy = title, titleSourceLLM
+		} else if title := extractiveTitle(p.parsed.Body); title != "" {
+			p.title, p.titleBy = title, titleSourceLocal
+		}
+		if p.title != "" {
+			p.parsed.Frontmatter["title"] = p.title
+		}
+	}
+
+	// A titled note's frontmatter is only touched when asked for summaries.
+	if !summaries && (titled || !titles) {
+		return
+	}
+	if frontmatterString(p.parsed.Frontmatter, "summary") == "" {
+		summary := cleanGeneratedText(suggested.Summary, maxSummaryRunes)
+		if summary == "" {
+			summary = extractiveSummary(p.parsed.Body)
+		}
+		// A one-sentence note needs no summary repeating its title.
+		title := firstNonEmpty(p.title, frontmatterString(p.parsed.Frontmatter, "title"))
+		if summary != "" && !strings.EqualFold(strings.TrimRight(summary, ".!?"), title) {
+			p.summary = summary
+			p.parsed.Frontmatter["summary"] = summary
+		}
+	}
+}
+
+// extractiveTitle derives a title from the body: the leading clause of the first
+// sentence, cut to maxTitleWords. A body that is only a URL is titled from the
+// URL's last path segment. Returns "" when nothing usable is found.
+func extractiveTitle(body string) string {
+	sentence := firstSentence(body)
+	if sentence == "" {
+		return titleFromURL(body)
+	}
+	sentence = strings.TrimRight(sentence, ".!?")
+	if loc := titleClauseRe.FindStringIndex(sentence); loc != nil {
+		if clause := sentence[:loc[0]]; len(strings.Fields(clause)) >= minClauseWords {
+			sentence = clause
+		}
+	}
+	words := strings.Fields(sentence)
+	if len(words) > maxTitleWords {
+		words = words[:maxTitleWords]
+	}
+	title := strings.Join(words, " ")
+	title = strings.TrimRight(title, ",;:-–—")
+	return cleanGeneratedText(upperFirst(title), maxTitleRunes)
+}
+
+// extractiveSummary returns the first sentence of the body as a one-line summary.
+func extractiveSummary(body string) string {
+	return cleanGeneratedText(firstSentence(body), maxSummaryRunes)
+}
+
+// firstSentence returns the first sentence of the note's prose, with markdown
+// syntax, links and URLs stripped. Headings, code blocks and the Related
+// Notes section are skipped.
+func firstSentence(body string) string {
+	var parts []string
+	inCode := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		if titleHeadingRe.MatchString(trimmed) {
+			if trimmed == "## Related Notes" {
+				break
+			}
+			continue
+		}
+		text := plainText(line)
+		if text == "" {
+			if len(parts) > 0 {
+				break // end of the first paragraph
+			}
+			continue
+		}
+		parts = append(parts, text)
+		// List items and quotes are separate thoughts; stop at the first.
+		if titleListRe.MatchString(line) {
+			break
+		}
+	}
+
+	para := strings.Join(parts, " ")
+	if loc := titleSentenceRe.FindStringIndex(para); loc != nil {
+		para = para[:loc[0]+1]
+	}
+	return strings.TrimSpace(para)
+}
+
+// plainText strips markdown from one line: list markers, checkboxes, quotes,
+// links (keeping their text), bare URLs, inline code marks, emphasis and tags.
+func plainText(line string) string {
+	line = titleListRe.ReplaceAllString(line, "")
+	line = titleWikiLinkRe.ReplaceAllStringFunc(line, func(m string) string {
+		sub := titleWikiLinkRe.FindStringSubmatch(m)
+		if sub[2] != "" {
+			return sub[2]
+		}
+		return path.Base(sub[1])
+	})
+	line = titleMDLinkRe.ReplaceAllString(line, "$1")
+	line = titleURLRe.ReplaceAllString(line, "")
+	line = strings.NewReplacer("`", "", "**", "", "__", "", "==", "").Replace(line)
+
+	var words []string
+	for _, w := range strings.Fields(line) {
+		if strings.HasPrefix(w, "#") && len(w) > 1 {
+			continue // inline tag
+		}
+		words = append(words, strings.Trim(w, "*_"))
+	}
+	return strings.TrimSpace(strings.Join(words, " "))
+}
+
+// titleFromURL titles a URL-only note from the URL's last path segment, or
+// its host when the path is empty.
+func titleFromURL(body string) string {
+	raw := titleURLRe.FindString(body)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	seg := path.Base(strings.TrimRight(u.Path, "/"))
+	seg = strings.TrimSuffix(seg, path.Ext(seg))
+	if seg == "" || seg == "." || seg == "/" {
+		return strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	words := strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '_' || r == '+' })
+	if len(words) > maxTitleWords {
+		words = words[:maxTitleWords]
+	}
+	return upperFirst(strings.Join(words, " "))
+}
+
+// cleanGeneratedText makes model or extracted text safe as a single-line
+// frontmatter value: one line, no surrounding quotes, at most maxRunes runes.
+// Quoting for YAML is left to frontmatterValue when the value is written.
+func cleanGeneratedText(s string, maxRunes int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.Trim(s, `"'`+"`")
+	s = strings.TrimSpace(strings.TrimSuffix(s, ":"))
+	if s == "" {
+		return ""
+	}
+	return truncateRunes(s, maxRunes)
+}
+
+// upperFirst capitalises the first letter of s.
+func upperFirst(s string) string {
+	for i, r := range s {
+		return s[:i] + string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
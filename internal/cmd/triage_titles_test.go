@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+func TestExtractiveTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"first sentence", "Use sqlite for the cache layer. It is simpler than redis.", "Use sqlite for the cache layer"},
+		{"leading clause", "Idea for the onboarding flow, maybe send a welcome email after signup", "Idea for the onboarding flow"},
+		{"cut to eight words", "we should really think about moving every single service to the new cluster soon", "We should really think about moving every single"},
+		{"markdown stripped", "- [ ] Call **Alice** about [[Projects/Billing|billing]] `export`", "Call Alice about billing export"},
+		{"skips code and tags", "```\ncode\n```\n#inbox Read the [paper](https://x.org/p) tonight", "Read the paper tonight"},
+		{"url only", "https://example.com/blog/why-go-is-fast.html\n", "Why go is fast"},
+		{"host only", "https://www.example.com/", "example.com"},
+		{"empty", "\n\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractiveTitle(tt.body); got != tt.want {
+				t.Errorf("extractiveTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractiveSummary(t *testing.T) {
+	body := "Meeting with the design team\nabout the new dashboard: colours agreed. Next steps below.\n\n- ship it"
+	want := "Meeting with the design team about the new dashboard: colours agreed."
+	if got := extractiveSummary(body); got != want {
+		t.Errorf("extractiveSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestPlanNaming(t *testing.T) {
+	dir := writeVaultFiles(t, map[string]string{
+		"Inbox/20260221-143022.md": "---\ntype: fleeting\n---\nUse sqlite for the cache layer. It is simpler than redis and needs no server.\n",
+		"Inbox/titled.md":          "---\ntitle: Cache notes\nsummary: Kept as is\n---\nUse sqlite.\n",
+		"Inbox/headed.md":          "# Cache plan\n\nMove sessions to sqlite next sprint.\n",
+	})
+	now := time.Date(2026, 2, 22, 0, 0, 0, 0, time.UTC)
+
+	// Extractive fallback without an LLM.
+	plan, err := planTriage(dir, PendingNote{Path: "Inbox/20260221-143022.md"}, &triageContext{titles: true}, now)
+	if err != nil {
+		t.Fatalf("planTriage() error: %v", err)
+	}
+	if plan.title != "Use sqlite for the cache layer" || plan.titleBy != titleSourceLocal {
+		t.Errorf("title = %q (%s)", plan.title, plan.titleBy)
+	}
+	if plan.summary != "" {
+		t.Errorf("summary = %q, want none (first sentence equals title)", plan.summary)
+	}
+	if plan.toPath != "Ideas/use-sqlite-for-the-cache-layer.md" {
+		t.Errorf("toPath = %q", plan.toPath)
+	}
+
+	// LLM suggestions take priority.
+	tc := &triageContext{titles: true, llm: &mockLLMClassifier{result: LLMClassifyResult{
+		Type: NoteTypeIdea, Confidence: 0.9,
+		Title: `"SQLite: cache backend"`, Summary: "Prefer SQLite over Redis\nfor caching.",
+	}}}
+	plan, err = planTriage(dir, PendingNote{Path: "Inbox/20260221-143022.md"}, tc, now)
+	if err != nil {
+		t.Fatalf("planTriage() error: %v", err)
+	}
+	if plan.title != "SQLite: cache backend" || plan.titleBy != titleSourceLLM || plan.summary != "Prefer SQLite over Redis for caching." {
+		t.Errorf("plan = title %q (%s), summary %q", plan.title, plan.titleBy, plan.summary)
+	}
+	if plan.toPath != "Ideas/sqlite-cache-backend.md" {
+		t.Errorf("toPath = %q", plan.toPath)
+	}
+	content := buildTriagedContent(plan.parsed, plan.rawFM, plan.noteType, nil, nil, now)
+	if !strings.Contains(content, "title: \"SQLite: cache backend\"\n") || !strings.Contains(content, "summary: Prefer SQLite over Redis for caching.\n") {
+		t.Errorf("generated fields not written:\n%s", content)
+	}
+	if got := frontmatterString(vault.ParseNote(content).Frontmatter, "title"); got != "SQLite: cache backend" {
+		t.Errorf("written title reads back as %q", got)
+	}
+
+	// Existing title and summary are kept.
+	plan, err = planTriage(dir, PendingNote{Path: "Inbox/titled.md"}, tc, now)
+	if err != nil {
+		t.Fatalf("planTriage() error: %v", err)
+	}
+	if plan.title != "" || plan.summary != "" || frontmatterString(plan.parsed.Frontmatter, "summary") != "Kept as is" {
+		t.Errorf("titled note renamed: title %q, summary %q", plan.title, plan.summary)
+	}
+
+	// A titled note gets a summary only when summaries are asked for.
+	plan, err = planTriage(dir, PendingNote{Path: "Inbox/headed.md"}, &triageContext{titles: true}, now)
+	if err != nil {
+		t.Fatalf("planTriage() error: %v", err)
+	}
+	if plan.title != "" || plan.summary != "" || frontmatterString(plan.parsed.Frontmatter, "summary") != "" {
+		t.Errorf("--titles touched a titled note: title %q, summary %q", plan.title, plan.summary)
+	}
+	plan, err = planTriage(dir, PendingNote{Path: "Inbox/headed.md"}, &triageContext{summaries: true}, now)
+	if err != nil {
+		t.Fatalf("planTriage() error: %v", err)
+	}
+	if plan.title != "" || plan.summary != "Move sessions to sqlite next sprint." {
+		t.Errorf("--summaries: title %q, summary %q", plan.title, plan.summary)
+	}
+}
+
+func TestTriageCmd_TitlesDryRun(t *testing.T) {
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	dir := writeVaultFiles(t, map[string]string{
+		"Inbox/20260221-143022.md": "Try the pomodoro timer for deep work sessions, 50/10 split.\n",
+	})
+
+	out := captureStdout(t, func() {
+		if err := TriageCmd(dir, TriageOptions{Auto: true, Titles: true, DryRun: true, JSONOutput: true}); err != nil {
+			t.Fatalf("TriageCmd() error: %v", err)
+		}
+	})
+	var result TriageOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(result.Processed) != 1 {
+		t.Fatalf("processed = %+v", result.Processed)
+	}
+	p := result.Processed[0]
+	if p.Title != "Try the pomodoro timer for deep work sessions" || p.TitleBy != "extractive" ||
+		p.ToPath != "Ideas/try-the-pomodoro-timer-for-deep-work-sessions.md" {
+		t.Errorf("dry run = %+v", p)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Inbox/20260221-143022.md")); err != nil {
+		t.Errorf("dry run moved the note: %v", err)
+	}
+}
+
+func TestNoteTitled(t *testing.T) {
+	if noteTitled(vault.ParseNote("just text")) {
+		t.Error("untitled note reported as titled")
+	}
+	if !noteTitled(vault.ParseNote("# Heading\n\ntext")) {
+		t.Error("note with H1 reported as untitled")
+	}
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/classify"
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// triageModelFile is the learned classifier saved by triage --train, under .obsidian/.
+const triageModelFile = "triage-model.json"
+
+// triageModelPath returns the location of the learned classifier for a vault.
+func triageModelPath(vaultPath string) string {
+	return filepath.Join(vaultPath, ".obsidian", triageModelFile)
+}
+
+// TriageTrainOutput is the JSON output for triage --train and --eval.
+type TriageTrainOutput struct {
+	Examples  int             `json:"examples"`
+	FromNotes int             `json:"from_notes"`  // triaged notes in the vault
+	FromLabel int             `json:"from_labels"` // interactive decisions whose note is gone or merged
+	Report    classify.Report `json:"report"`
+	ModelPath string          `json:"model_path,omitempty"` // set when the model was saved
+}
+
+// triageTrainCmd cross-validates a classifier on past triage decisions and,
+// unless eval is set, saves it for use by triage --auto and --interactive.
+func triageTrainCmd(vaultPath string, eval, jsonOutput bool) error {
+	examples, fromNotes, err := triageTrainingExamples(vaultPath)
+	if err != nil {
+		return err
+	}
+
+	model, report, err := classify.Fit(examples, classify.DefaultOptions())
+	if err != nil {
+		return fmt.Errorf("cannot train triage classifier: %w", err)
+	}
+
+	result := TriageTrainOutput{
+		Examples:  len(examples),
+		FromNotes: fromNotes,
+		FromLabel: len(examples) - fromNotes,
+		Report:    report,
+	}
+	if !eval {
+		if err := classify.Save(triageModelPath(vaultPath), model); err != nil {
+			return fmt.Errorf("saving triage model: %w", err)
+		}
+		result.ModelPath = filepath.ToSlash(filepath.Join(".obsidian", triageModelFile))
+	}
+
+	if jsonOutput {
+		return output.JSON(result)
+	}
+	printTriageTrainReport(result)
+	return nil
+}
+
+// triageTrainingExamples collects labelled notes: every note with a triaged:
+// date and a known type, plus recorded interactive decisions whose result is
+// not already one of those notes (merged or since-moved notes).
+func triageTrainingExamples(vaultPath string) (examples []classify.Example, fromNotes int, err error) {
+	notes, err := vault.ListNotes(vaultPath, "")
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing notes: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, info := range notes {
+		data, err := os.ReadFile(filepath.Join(vaultPath, info.Path))
+		if err != nil {
+			continue
+		}
+		parsed := vault.ParseNote(string(data))
+		if frontmatterString(parsed.Frontmatter, "triaged") == "" {
+			continue
+		}
+		noteType := frontmatterString(parsed.Frontmatter, "type")
+		if !isValidNoteType(NoteType(noteType)) || noteType == string(NoteTypeFleeting) {
+			continue
+		}
+		examples = append(examples, classify.Example{
+			ID:    info.Path,
+			Text:  trainingText(parsed),
+			Label: noteType,
+		})
+		seen[info.Path] = true
+	}
+	fromNotes = len(examples)
+
+	labels, err := loadTriageLabels(vaultPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, l := range labels {
+		if l.Type == "" || (seen[l.ToPath] && l.Action == triageActionAccept) {
+			continue
+		}
+		if !isValidNoteType(NoteType(l.Type)) || l.Type == string(NoteTypeFleeting) {
+			continue
+		}
+		examples = append(examples, classify.Example{
+			ID:    "label:" + l.Time + ":" + l.Path,
+			Text:  l.Content,
+			Label: l.Type,
+		})
+	}
+	return examples, fromNotes, nil
+}
+
+// trainingText returns the classifier input for a triaged note: the same
+// fields sent to the LLM, without the Related Notes section triage appended.
+func trainingText(parsed *vault.Note) string {
+	text := buildLLMContent(parsed)
+	if i := strings.Index(text, "\n## Related Notes\n"); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
+
+// loadTriageLabels reads the decisions recorded by triage --interactive.
+// Malformed lines are skipped.
+func loadTriageLabels(vaultPath string) ([]TriageLabel, error) {
+	f, err := os.Open(triageLabelsPath(vaultPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading triage labels: %w", err)
+	}
+	defer f.Close()
+
+	var labels []TriageLabel
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var l TriageLabel
+		if err := json.Unmarshal(scanner.Bytes(), &l); err == nil {
+			labels = append(labels, l)
+		}
+	}
+	return labels, scanner.Err()
+}
+
+func printTriageTrainReport(result TriageTrainOutput) {
+	r := result.Report
+	header := fmt.Sprintf("Triage classifier (%d-fold cross-validation)", r.Folds)
+	fmt.Println(header)
+	fmt.Println(strings.Repeat("=", len(header)))
+	fmt.Println()
+	fmt.Printf("Examples:    %d (%d triaged notes, %d recorded decisions)\n", result.Examples, result.FromNotes, result.FromLabel)
+	fmt.Printf("Accuracy:    %.1f%% (majority baseline %.1f%%)\n", r.Accuracy*100, r.Baseline*100)
+	fmt.Printf("Calibration: temperature %.2f, expected calibration error %.3f\n", r.Temperature, r.ECE)
+
+	fmt.Println()
+	fmt.Printf("  %-12s %7s %9s %7s %5s\n", "type", "support", "precision", "recall", "f1")
+	for _, m := range r.PerClass {
+		fmt.Printf("  %-12s %7d %9.2f %7.2f %5.2f\n", m.Class, m.Support, m.Precision, m.Recall, m.F1)
+	}
+
+	classes := make([]string, 0, len(r.PerClass))
+	for _, m := range r.PerClass {
+		classes = append(classes, m.Class)
+	}
+	sort.Strings(classes)
+	fmt.Println()
+	fmt.Println("Confusion (rows: actual, columns: predicted)")
+	fmt.Printf("  %-12s", "")
+	for _, c := range classes {
+		fmt.Printf(" %9s", c)
+	}
+	fmt.Println()
+	for _, actual := range classes {
+		fmt.Printf("  %-12s", actual)
+		for _, pred := range classes {
+			fmt.Printf(" %9d", r.Confusion[actual][pred])
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	if result.ModelPath != "" {
+		fmt.Printf("Saved model to %s\n", result.ModelPath)
+	} else {
+		fmt.Println("Evaluation only; run triage --train to save the model.")
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/classify"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+func triagedVault(t *testing.T) string {
+	files := map[string]string{}
+	bodies := map[string][]string{
+		"task":      {"- [ ] call the plumber", "- [ ] renew passport", "- [ ] send invoice to client", "- [ ] fix the login bug", "- [ ] book dentist"},
+		"reference": {"https://go.dev/blog/errors", "https://sqlite.org/fts5.html docs", "https://arxiv.org/abs/1706.03762", "https://example.com/caching guide", "https://github.com/a/b readme"},
+		"idea":      {"What if notes expired", "Maybe a garden journal", "Could search rank by recency", "What if meetings were written", "Maybe voice memos become notes"},
+	}
+	for noteType, list := range bodies {
+		for i, body := range list {
+			path := fmt.Sprintf("%s/%s-%d.md", typeFolder(noteType), noteType, i)
+			files[path] = fmt.Sprintf("---\ntype: %s\nstatus: processed\ntriaged: 2026-01-0%d\n---\n%s\n\n## Related Notes\n- [[x]]\n", noteType, i+1, body)
+		}
+	}
+	files["Notes/untriaged.md"] = "---\ntype: note\n---\nnot a label\n"
+	return writeVaultFiles(t, files)
+}
+
+func TestTriageTrainingExamples(t *testing.T) {
+	dir := triagedVault(t)
+	if err := recordTriageLabel(dir, TriageLabel{Path: "Inbox/m.md", Action: triageActionMerge, Type: "task", ToPath: "Tasks/task-0.md", Content: "- [ ] merged"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordTriageLabel(dir, TriageLabel{Path: "Inbox/s.md", Action: triageActionSkip, Content: "skipped"}); err != nil {
+		t.Fatal(err)
+	}
+
+	examples, fromNotes, err := triageTrainingExamples(dir)
+	if err != nil {
+		t.Fatalf("triageTrainingExamples() error: %v", err)
+	}
+	if fromNotes != 15 || len(examples) != 16 {
+		t.Errorf("got %d examples (%d from notes), want 16 (15)", len(examples), fromNotes)
+	}
+	for _, ex := range examples {
+		if ex.ID == "Tasks/task-0.md" && ex.Text != "- [ ] call the plumber\n" {
+			t.Errorf("training text = %q, want Related Notes stripped", ex.Text)
+		}
+	}
+}
+
+func TestTriageTrainCmd(t *testing.T) {
+	dir := triagedVault(t)
+
+	out := captureStdout(t, func() {
+		if err := TriageCmd(dir, TriageOptions{Train: true, JSONOutput: true}); err != nil {
+			t.Fatalf("TriageCmd(--train) error: %v", err)
+		}
+	})
+	var result TriageTrainOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Examples != 15 || result.Report.Folds != 5 || result.ModelPath == "" {
+		t.Errorf("result = %+v", result)
+	}
+
+	model, err := classify.Load(triageModelPath(dir))
+	if err != nil || model == nil {
+		t.Fatalf("model not saved: %v", err)
+	}
+	parsed := vault.ParseNote("---\ntype: fleeting\n---\n- [ ] water the plants\n")
+	noteType, by, conf := classifyLocal(parsed, model)
+	if noteType != "task" {
+		t.Errorf("classifyLocal() = %q via %s (%.2f), want task", noteType, by, conf)
+	}
+
+	// An explicit frontmatter type always wins over the model.
+	parsed = vault.ParseNote("---\ntype: idea\n---\n- [ ] water the plants\n")
+	if noteType, by, _ := classifyLocal(parsed, model); noteType != "idea" || by != "frontmatter" {
+		t.Errorf("classifyLocal() = %q via %s, want idea via frontmatter", noteType, by)
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/output"
+	"github.com/joeyhipolito/obsidian-cli/internal/schema"
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// ValidateOptions configures the validate command.
+type ValidateOptions struct {
+	Path       string // note or folder; empty with All validates every note
+	All        bool
+	Fix        bool // fill missing required properties that have defaults
+	JSONOutput bool
+}
+
+// ValidatedNote is a note with schema errors or filled-in defaults.
+type ValidatedNote struct {
+	Path   string         `json:"path"`
+	Type   string         `json:"type"`
+	Issues []schema.Issue `json:"issues"`
+	Filled []schema.Fill  `json:"filled,omitempty"`
+}
+
+// ValidateOutput is the JSON output of the validate command.
+type ValidateOutput struct {
+	Checked int             `json:"checked"` // notes whose type has a schema
+	Skipped int             `json:"skipped"` // notes without a type or with no schema for it
+	Invalid int             `json:"invalid"`
+	Fixed   int             `json:"fixed"`
+	Notes   []ValidatedNote `json:"notes"`
+}
+
+// ValidateCmd checks notes against the schema for their type, defined in
+// .obsidian/schemas.toml. It returns an error after reporting when a note is
+// still invalid, so scripts can fail on it.
+func ValidateCmd(vaultPath string, opts ValidateOptions) error {
+	set, err := schema.Load(vaultPath)
+	if err != nil {
+		return err
+	}
+	if len(set.Schemas) == 0 {
+		return fmt.Errorf("no schemas defined — create %s", filepath.Join(".obsidian", schema.FileName))
+	}
+
+	notes, err := vault.ListNotes(vaultPath, "")
+	if err != nil {
+		return fmt.Errorf("failed to list notes: %w", err)
+	}
+	filter := strings.Trim(filepath.ToSlash(opts.Path), "/")
+	if opts.All {
+		filter = ""
+	}
+
+	result := ValidateOutput{Notes: []ValidatedNote{}}
+	now := time.Now()
+	matched := false
+	snapshots := newNoteHistory(vaultPath)
+	for _, info := range notes {
+		if !mentionInScope(info.Path, filter) {
+			continue
+		}
+		matched = true
+		fullPath := filepath.Join(vaultPath, info.Path)
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		content := string(data)
+		noteType := frontmatterString(vault.ParseNote(content).Frontmatter, "type")
+		sc := set.Lookup(noteType)
+		if sc == nil {
+			result.Skipped++
+			continue
+		}
+		result.Checked++
+
+		v := ValidatedNote{Path: info.Path, Type: noteType}
+		if opts.Fix {
+			if fills := sc.Defaults(content, now); len(fills) > 0 {
+				updated, ok := addFrontmatterProperties(content, fills)
+				if ok {
+					snapshots.snapshot(info.Path, "validate --fix")
+					if err := os.WriteFile(fullPath, []byte(updated), 0644); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to write %s: %v\n", info.Path, err)
+					} else {
+						content, v.Filled = updated, fills
+						result.Fixed++
+					}
+				}
+			}
+		}
+		v.Issues = sc.Validate(content)
+		if len(v.Issues) > 0 {
+			result.Invalid++
+		}
+		if len(v.Issues) > 0 || len(v.Filled) > 0 {
+			result.Notes = append(result.Notes, v)
+		}
+	}
+	snapshots.save()
+	if filter != "" && !matched {
+		return fmt.Errorf("no notes found at %s", opts.Path)
+	}
+
+	if opts.JSONOutput {
+		if err := output.JSON(result); err != nil {
+			return err
+		}
+	} else {
+		printValidateReport(result, len(set.Schemas))
+	}
+	if result.Invalid > 0 {
+		return fmt.Errorf("%d note(s) do not match their schema", result.Invalid)
+	}
+	return nil
+}
+
+// addFrontmatterProperties appends properties at the end of the note's
+// frontmatter. ok is false when the note has no frontmatter block.
+func addFrontmatterProperties(content string, fills []schema.Fill) (string, bool) {
+	fm, body, ok := vault.SplitFrontmatter(content)
+	if !ok {
+		return content, false
+	}
+	var b strings.Builder
+	b.WriteString("---\n")
+	if fm = strings.TrimRight(fm, "\r\n"); fm != "" {
+		b.WriteString(fm + "\n")
+	}
+	for _, f := range fills {
+		fmt.Fprintf(&b, "%s: %s\n", f.Property, frontmatterValue(f.Value))
+	}
+	b.WriteString("---\n")
+	b.WriteString(body)
+	return b.String(), true
+}
+
+func printValidateReport(r ValidateOutput, schemas int) {
+	for _, n := range r.Notes {
+		if len(n.Filled) > 0 {
+			var filled []string
+			for _, f := range n.Filled {
+				filled = append(filled, f.Property+" = "+f.Value)
+			}
+			fmt.Printf("%s: filled %s\n", n.Path, strings.Join(filled, ", "))
+		}
+		for _, i := range n.Issues {
+			fmt.Printf("%s:%d: %s: %s\n", n.Path, i.Line, i.Property, i.Message)
+		}
+	}
+	if len(r.Notes) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("Checked %d notes against %d schemas: %d invalid", r.Checked, schemas, r.Invalid)
+	if r.Fixed > 0 {
+		fmt.Printf(", %d fixed", r.Fixed)
+	}
+	if r.Skipped > 0 {
+		fmt.Printf(" (%d notes without a schema skipped)", r.Skipped)
+	}
+	fmt.Println()
+}
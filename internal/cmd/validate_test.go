@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+	"github.com/joeyhipolito/obsidian-cli/internal/schema"
+)
+
+const validateSchemas = `[reference]
+required = ["source"]
+
+[reference.properties.source]
+type = "url"
+
+[task]
+required = ["status"]
+
+[task.properties.status]
+enum = ["todo", "done"]
+default = "todo"
+`
+
+func TestValidateCmd(t *testing.T) {
+	t.Setenv(config.ConfigDirEnv, t.TempDir())
+	dir := writeVaultFiles(t, map[string]string{
+		".obsidian/schemas.toml": validateSchemas,
+		"Refs/good.md":           "---\ntype: reference\nsource: https://go.dev\n---\nbody\n",
+		"Refs/bad.md":            "---\ntitle: Bad\ntype: reference\nsource: somewhere\n---\nbody\n",
+		"Tasks/new.md":           "---\ntype: task\n---\nbody\n",
+		"Notes/plain.md":         "no frontmatter\n",
+	})
+
+	var result ValidateOutput
+	out := captureStdout(t, func() {
+		if err := ValidateCmd(dir, ValidateOptions{All: true, JSONOutput: true}); err == nil {
+			t.Error("ValidateCmd() succeeded with invalid notes")
+		}
+	})
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Checked != 3 || result.Skipped != 1 || result.Invalid != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	if n := result.Notes[0]; n.Path != "Refs/bad.md" || n.Issues[0].Line != 4 || n.Issues[0].Property != "source" {
+		t.Errorf("first note = %+v", n)
+	}
+
+	// --fix fills the default and the folder then validates.
+	out = captureStdout(t, func() {
+		if err := ValidateCmd(dir, ValidateOptions{Path: "Tasks/", Fix: true}); err != nil {
+			t.Errorf("ValidateCmd(Tasks --fix) error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Tasks/new.md: filled status = todo") {
+		t.Errorf("report:\n%s", out)
+	}
+	if got := mustRead(t, filepath.Join(dir, "Tasks/new.md")); got != "---\ntype: task\nstatus: todo\n---\nbody\n" {
+		t.Errorf("fixed note:\n%s", got)
+	}
+
+	if err := ValidateCmd(dir, ValidateOptions{Path: "Missing"}); err == nil {
+		t.Error("ValidateCmd(missing path) succeeded")
+	}
+	if err := os.Remove(schema.Path(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateCmd(dir, ValidateOptions{All: true}); err == nil {
+		t.Error("ValidateCmd() without schemas succeeded")
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -25,8 +28,56 @@ type Config struct {
 	GeminiAPIKey string
 	VaultPath    string
 	WebsitePath  string
+	// HistoryRetention is the number of snapshots kept per note (0 = default).
+	HistoryRetention int
+
+	// LLM provider settings (see ResolveLLM).
+	LLMProvider string // anthropic, openai (any OpenAI-compatible endpoint) or none
+	LLMModel    string
+	LLMBaseURL  string // e.g. http://localhost:11434/v1 for a local server
+	LLMAPIKey   string
+	LLMTimeout  int // seconds per request attempt (0 = default)
+	LLMRetries  int // retries after a failed attempt (0 = default, <0 = none)
+
+	// Limits for capture --fetch (0 = default).
+	FetchMaxBytes int // maximum page size in bytes
+	FetchMaxChars int // maximum extracted markdown length
+	FetchTimeout  int // seconds
+
+	// Clustering for promote (see ResolvePromote; zero = default).
+	PromoteAlgorithm         string // agglomerative, hdbscan, louvain or components
+	PromoteTagThreshold      float64
+	PromoteSemanticThreshold float64
+	PromoteLinkage           float64
+	PromoteMinSize           int
+	PromoteMaxSize           int
+
+	// Where enrich writes accepted links (see ResolveEnrich; empty = default).
+	EnrichHeading    string // e.g. "## See also"
+	EnrichLinkFormat string // e.g. "- {link} — {reason}"
+
+	// Health check overrides by check name (see ResolveChecks), read from
+	// check_<name>_<setting> keys with the name's hyphens as underscores.
+	Checks map[string]CheckConfig
+	// Frontmatter properties each note type must have, from required_<type>
+	// keys, e.g. required_project=status, due.
+	RequiredProperties map[string][]string
+}
+
+// CheckConfig overrides one health check's settings. Unset values keep the
+// check's defaults.
+type CheckConfig struct {
+	Enabled   *bool    // check_<name>_enabled
+	Weight    *float64 // check_<name>_weight: score points per issue
+	Cap       int      // check_<name>_cap: most points the check can cost
+	Threshold float64  // check_<name>_threshold: e.g. stale days, large note bytes
+	Exclude   []string // check_<name>_exclude: folders the check skips
+	Pattern   string   // check_<name>_pattern: e.g. the filename rule
 }
 
+// checkSettingKeys are the settings a check_<name>_<setting> key may set.
+var checkSettingKeys = []string{"enabled", "weight", "cap", "threshold", "exclude", "pattern"}
+
 // Store manages the obsidian config directory and file.
 // It checks the configured env var to allow overriding the default location.
 type Store struct {
@@ -127,6 +178,79 @@ func (s *Store) Load() (*Config, error) {
 			cfg.VaultPath = value
 		case "website_path":
 			cfg.WebsitePath = value
+		case "history_retention":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				cfg.HistoryRetention = n
+			}
+		case "llm_provider":
+			cfg.LLMProvider = value
+		case "llm_model":
+			cfg.LLMModel = value
+		case "llm_base_url":
+			cfg.LLMBaseURL = value
+		case "llm_api_key":
+			cfg.LLMAPIKey = value
+		case "llm_timeout":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				cfg.LLMTimeout = n
+			}
+		case "llm_retries":
+			// llm_retries=0 turns retries off, which LLMRetries stores as -1
+			// so that 0 can keep meaning the default.
+			if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+				cfg.LLMRetries = n
+				if n == 0 {
+					cfg.LLMRetries = -1
+				}
+			}
+		case "fetch_max_bytes":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				cfg.FetchMaxBytes = n
+			}
+		case "fetch_max_chars":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				cfg.FetchMaxChars = n
+			}
+		case "fetch_timeout":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				cfg.FetchTimeout = n
+			}
+		case "promote_algorithm":
+			cfg.PromoteAlgorithm = value
+		case "promote_tag_threshold":
+			if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 && f <= 1 {
+				cfg.PromoteTagThreshold = f
+			}
+		case "promote_semantic_threshold":
+			if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 && f <= 1 {
+				cfg.PromoteSemanticThreshold = f
+			}
+		case "promote_linkage":
+			if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 && f <= 1 {
+				cfg.PromoteLinkage = f
+			}
+		case "promote_min_size":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				cfg.PromoteMinSize = n
+			}
+		case "promote_max_size":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				cfg.PromoteMaxSize = n
+			}
+		case "enrich_heading":
+			cfg.EnrichHeading = value
+		case "enrich_link_format":
+			cfg.EnrichLinkFormat = value
+		default:
+			switch {
+			case strings.HasPrefix(key, "check_"):
+				parseCheckKey(cfg, strings.TrimPrefix(key, "check_"), value)
+			case strings.HasPrefix(key, "required_"):
+				if cfg.RequiredProperties == nil {
+					cfg.RequiredProperties = make(map[string][]string)
+				}
+				cfg.RequiredProperties[strings.TrimPrefix(key, "required_")] = splitList(value)
+			}
 		}
 	}
 	if err := scanner.Err(); err != nil {
@@ -162,6 +286,68 @@ func (s *Store) Save(cfg *Config) error {
 		b.WriteString("# Path to your website project (for obsidian sync)\n")
 		fmt.Fprintf(&b, "website_path=%s\n", cfg.WebsitePath)
 	}
+	if cfg.HistoryRetention > 0 {
+		b.WriteString("\n")
+		b.WriteString("# Number of note snapshots kept per note (obsidian history)\n")
+		fmt.Fprintf(&b, "history_retention=%d\n", cfg.HistoryRetention)
+	}
+	if cfg.LLMProvider != "" || cfg.LLMModel != "" || cfg.LLMBaseURL != "" || cfg.LLMAPIKey != "" ||
+		cfg.LLMTimeout > 0 || cfg.LLMRetries != 0 {
+		b.WriteString("\n")
+		b.WriteString("# LLM provider for triage: anthropic, openai (OpenAI-compatible) or none\n")
+		writeIfSet(&b, "llm_provider", cfg.LLMProvider)
+		writeIfSet(&b, "llm_model", cfg.LLMModel)
+		writeIfSet(&b, "llm_base_url", cfg.LLMBaseURL)
+		writeIfSet(&b, "llm_api_key", cfg.LLMAPIKey)
+		if cfg.LLMTimeout > 0 {
+			fmt.Fprintf(&b, "llm_timeout=%d\n", cfg.LLMTimeout)
+		}
+		if cfg.LLMRetries != 0 {
+			fmt.Fprintf(&b, "llm_retries=%d\n", max(cfg.LLMRetries, 0))
+		}
+	}
+	if cfg.FetchMaxBytes > 0 || cfg.FetchMaxChars > 0 || cfg.FetchTimeout > 0 {
+		b.WriteString("\n")
+		b.WriteString("# Limits for capture --fetch: page bytes, extracted characters, seconds\n")
+		if cfg.FetchMaxBytes > 0 {
+			fmt.Fprintf(&b, "fetch_max_bytes=%d\n", cfg.FetchMaxBytes)
+		}
+		if cfg.FetchMaxChars > 0 {
+			fmt.Fprintf(&b, "fetch_max_chars=%d\n", cfg.FetchMaxChars)
+		}
+		if cfg.FetchTimeout > 0 {
+			fmt.Fprintf(&b, "fetch_timeout=%d\n", cfg.FetchTimeout)
+		}
+	}
+	if cfg.PromoteAlgorithm != "" || cfg.PromoteTagThreshold > 0 || cfg.PromoteSemanticThreshold > 0 ||
+		cfg.PromoteLinkage > 0 || cfg.PromoteMinSize > 0 || cfg.PromoteMaxSize > 0 {
+		b.WriteString("\n")
+		b.WriteString("# Clustering for promote: algorithm (agglomerative, hdbscan, louvain, components),\n")
+		b.WriteString("# similarity thresholds and linkage between 0 and 1, cluster size limits\n")
+		writeIfSet(&b, "promote_algorithm", cfg.PromoteAlgorithm)
+		writeFloatIfSet(&b, "promote_tag_threshold", cfg.PromoteTagThreshold)
+		writeFloatIfSet(&b, "promote_semantic_threshold", cfg.PromoteSemanticThreshold)
+		writeFloatIfSet(&b, "promote_linkage", cfg.PromoteLinkage)
+		if cfg.PromoteMinSize > 0 {
+			fmt.Fprintf(&b, "promote_min_size=%d\n", cfg.PromoteMinSize)
+		}
+		if cfg.PromoteMaxSize > 0 {
+			fmt.Fprintf(&b, "promote_max_size=%d\n", cfg.PromoteMaxSize)
+		}
+	}
+	if cfg.EnrichHeading != "" || cfg.EnrichLinkFormat != "" {
+		b.WriteString("\n")
+		b.WriteString("# Section heading and line format for links added by enrich\n")
+		b.WriteString("# ({link}, {note}, {path}, {reason}, {similarity})\n")
+		writeIfSet(&b, "enrich_heading", cfg.EnrichHeading)
+		writeIfSet(&b, "enrich_link_format", cfg.EnrichLinkFormat)
+	}
+	if len(cfg.Checks) > 0 || len(cfg.RequiredProperties) > 0 {
+		b.WriteString("\n")
+		b.WriteString("# Health checks: check_<name>_enabled|weight|cap|threshold|exclude|pattern\n")
+		b.WriteString("# and required_<type> frontmatter properties (see obsidian maintain --list-checks)\n")
+		writeChecks(&b, cfg)
+	}
 
 	if err := os.WriteFile(p, []byte(b.String()), 0600); err != nil {
 		return fmt.Errorf("writing config: %w", err)
@@ -169,6 +355,104 @@ func (s *Store) Save(cfg *Config) error {
 	return nil
 }
 
+// parseCheckKey applies "<name>_<setting> = value" to the named check.
+// Invalid values are ignored, like other settings.
+func parseCheckKey(cfg *Config, rest, value string) {
+	for _, setting := range checkSettingKeys {
+		name, ok := strings.CutSuffix(rest, "_"+setting)
+		if !ok || name == "" {
+			continue
+		}
+		name = strings.ReplaceAll(name, "_", "-")
+		if cfg.Checks == nil {
+			cfg.Checks = make(map[string]CheckConfig)
+		}
+		c := cfg.Checks[name]
+		switch setting {
+		case "enabled":
+			if b, err := strconv.ParseBool(value); err == nil {
+				c.Enabled = &b
+			}
+		case "weight":
+			if f, err := strconv.ParseFloat(value, 64); err == nil && f >= 0 {
+				c.Weight = &f
+			}
+		case "cap":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				c.Cap = n
+			}
+		case "threshold":
+			if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 {
+				c.Threshold = f
+			}
+		case "exclude":
+			c.Exclude = splitList(value)
+		case "pattern":
+			c.Pattern = value
+		}
+		cfg.Checks[name] = c
+		return
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty items.
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// writeChecks writes the health check overrides, sorted by check name.
+func writeChecks(b *strings.Builder, cfg *Config) {
+	names := make([]string, 0, len(cfg.Checks))
+	for name := range cfg.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := cfg.Checks[name]
+		prefix := "check_" + strings.ReplaceAll(name, "-", "_") + "_"
+		if c.Enabled != nil {
+			fmt.Fprintf(b, "%senabled=%t\n", prefix, *c.Enabled)
+		}
+		if c.Weight != nil {
+			fmt.Fprintf(b, "%sweight=%s\n", prefix, strconv.FormatFloat(*c.Weight, 'g', -1, 64))
+		}
+		if c.Cap > 0 {
+			fmt.Fprintf(b, "%scap=%d\n", prefix, c.Cap)
+		}
+		writeFloatIfSet(b, prefix+"threshold", c.Threshold)
+		writeIfSet(b, prefix+"exclude", strings.Join(c.Exclude, ", "))
+		writeIfSet(b, prefix+"pattern", c.Pattern)
+	}
+	types := make([]string, 0, len(cfg.RequiredProperties))
+	for t := range cfg.RequiredProperties {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		writeIfSet(b, "required_"+t, strings.Join(cfg.RequiredProperties[t], ", "))
+	}
+}
+
+// writeIfSet writes key=value when value is non-empty.
+func writeIfSet(b *strings.Builder, key, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s=%s\n", key, value)
+	}
+}
+
+// writeFloatIfSet writes key=value when value is positive.
+func writeFloatIfSet(b *strings.Builder, key string, value float64) {
+	if value > 0 {
+		fmt.Fprintf(b, "%s=%s\n", key, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+}
+
 // Package-level functions use defaultStore for backward compatibility.
 
 // Path returns the full path to the config file (~/.obsidian/config).
@@ -233,3 +517,199 @@ func ResolveWebsitePath() string {
 	}
 	return os.Getenv("OBSIDIAN_WEBSITE_PATH")
 }
+
+// ResolveHistoryRetention returns the number of snapshots to keep per note from
+// config or environment. Returns 0 when unset, meaning the history default.
+func ResolveHistoryRetention() int {
+	cfg, err := Load()
+	if err == nil && cfg.HistoryRetention > 0 {
+		return cfg.HistoryRetention
+	}
+	if n, err := strconv.Atoi(os.Getenv("OBSIDIAN_HISTORY_RETENTION")); err == nil && n > 0 {
+		return n
+	}
+	return 0
+}
+
+// LLM providers accepted in llm_provider.
+const (
+	LLMProviderAnthropic = "anthropic"
+	LLMProviderOpenAI    = "openai"
+	LLMProviderNone      = "none"
+)
+
+// LLMSettings are the resolved LLM provider settings.
+type LLMSettings struct {
+	Provider string // anthropic, openai, or "" when no provider is configured
+	Model    string // empty means the provider default
+	BaseURL  string // empty means the provider default
+	APIKey   string
+	Timeout  time.Duration // 0 means the client default
+	Retries  int           // 0 means the client default, <0 means none
+}
+
+// ResolveLLM returns the LLM settings from config or environment.
+// Each value comes from the config file, then OBSIDIAN_LLM_PROVIDER,
+// OBSIDIAN_LLM_MODEL and OBSIDIAN_LLM_BASE_URL. The API key falls back to
+// ANTHROPIC_API_KEY or OPENAI_API_KEY for the chosen provider. When no
+// provider is set but ANTHROPIC_API_KEY is, the provider is anthropic.
+// Provider "none" disables the LLM.
+func ResolveLLM() LLMSettings {
+	cfg, err := Load()
+	if err != nil {
+		cfg = &Config{}
+	}
+	pick := func(value, env string) string {
+		if value != "" {
+			return value
+		}
+		return os.Getenv(env)
+	}
+
+	s := LLMSettings{
+		Provider: strings.ToLower(pick(cfg.LLMProvider, "OBSIDIAN_LLM_PROVIDER")),
+		Model:    pick(cfg.LLMModel, "OBSIDIAN_LLM_MODEL"),
+		BaseURL:  pick(cfg.LLMBaseURL, "OBSIDIAN_LLM_BASE_URL"),
+		APIKey:   cfg.LLMAPIKey,
+		Timeout:  time.Duration(cfg.LLMTimeout) * time.Second,
+		Retries:  cfg.LLMRetries,
+	}
+	if s.Provider == "" && os.Getenv("ANTHROPIC_API_KEY") != "" {
+		s.Provider = LLMProviderAnthropic
+	}
+	switch s.Provider {
+	case LLMProviderAnthropic:
+		s.APIKey = pick(s.APIKey, "ANTHROPIC_API_KEY")
+	case LLMProviderOpenAI:
+		s.APIKey = pick(s.APIKey, "OPENAI_API_KEY")
+	case LLMProviderNone:
+		s.Provider = ""
+	}
+	return s
+}
+
+// FetchSettings are the resolved limits for fetching web pages. Zero values
+// mean the fetcher defaults.
+type FetchSettings struct {
+	MaxBytes int64
+	MaxChars int
+	Timeout  time.Duration
+}
+
+// ResolveFetch returns the web page fetch limits from config or environment
+// (OBSIDIAN_FETCH_MAX_BYTES, OBSIDIAN_FETCH_MAX_CHARS, OBSIDIAN_FETCH_TIMEOUT
+// in seconds).
+func ResolveFetch() FetchSettings {
+	cfg, err := Load()
+	if err != nil {
+		cfg = &Config{}
+	}
+	pick := func(value int, env string) int {
+		if value > 0 {
+			return value
+		}
+		if n, err := strconv.Atoi(os.Getenv(env)); err == nil && n > 0 {
+			return n
+		}
+		return 0
+	}
+	return FetchSettings{
+		MaxBytes: int64(pick(cfg.FetchMaxBytes, "OBSIDIAN_FETCH_MAX_BYTES")),
+		MaxChars: pick(cfg.FetchMaxChars, "OBSIDIAN_FETCH_MAX_CHARS"),
+		Timeout:  time.Duration(pick(cfg.FetchTimeout, "OBSIDIAN_FETCH_TIMEOUT")) * time.Second,
+	}
+}
+
+// PromoteSettings are the resolved clustering settings for promote. Zero
+// values mean the promote defaults.
+type PromoteSettings struct {
+	Algorithm         string
+	TagThreshold      float64
+	SemanticThreshold float64
+	Linkage           float64
+	MinSize           int
+	MaxSize           int
+}
+
+// ResolvePromote returns the promote clustering settings from config or
+// environment (OBSIDIAN_PROMOTE_ALGORITHM, OBSIDIAN_PROMOTE_TAG_THRESHOLD,
+// OBSIDIAN_PROMOTE_SEMANTIC_THRESHOLD, OBSIDIAN_PROMOTE_LINKAGE,
+// OBSIDIAN_PROMOTE_MIN_SIZE, OBSIDIAN_PROMOTE_MAX_SIZE).
+func ResolvePromote() PromoteSettings {
+	cfg, err := Load()
+	if err != nil {
+		cfg = &Config{}
+	}
+	pickFloat := func(value float64, env string) float64 {
+		if value > 0 {
+			return value
+		}
+		if f, err := strconv.ParseFloat(os.Getenv(env), 64); err == nil && f > 0 && f <= 1 {
+			return f
+		}
+		return 0
+	}
+	pickInt := func(value int, env string) int {
+		if value > 0 {
+			return value
+		}
+		if n, err := strconv.Atoi(os.Getenv(env)); err == nil && n > 0 {
+			return n
+		}
+		return 0
+	}
+	algorithm := cfg.PromoteAlgorithm
+	if algorithm == "" {
+		algorithm = os.Getenv("OBSIDIAN_PROMOTE_ALGORITHM")
+	}
+	return PromoteSettings{
+		Algorithm:         algorithm,
+		TagThreshold:      pickFloat(cfg.PromoteTagThreshold, "OBSIDIAN_PROMOTE_TAG_THRESHOLD"),
+		SemanticThreshold: pickFloat(cfg.PromoteSemanticThreshold, "OBSIDIAN_PROMOTE_SEMANTIC_THRESHOLD"),
+		Linkage:           pickFloat(cfg.PromoteLinkage, "OBSIDIAN_PROMOTE_LINKAGE"),
+		MinSize:           pickInt(cfg.PromoteMinSize, "OBSIDIAN_PROMOTE_MIN_SIZE"),
+		MaxSize:           pickInt(cfg.PromoteMaxSize, "OBSIDIAN_PROMOTE_MAX_SIZE"),
+	}
+}
+
+// EnrichSettings are the resolved settings for links written by enrich.
+// Empty values mean the enrich defaults.
+type EnrichSettings struct {
+	Heading    string
+	LinkFormat string
+}
+
+// ResolveEnrich returns the enrich settings from config or environment
+// (OBSIDIAN_ENRICH_HEADING, OBSIDIAN_ENRICH_LINK_FORMAT).
+func ResolveEnrich() EnrichSettings {
+	cfg, err := Load()
+	if err != nil {
+		cfg = &Config{}
+	}
+	pick := func(value, env string) string {
+		if value != "" {
+			return value
+		}
+		return os.Getenv(env)
+	}
+	return EnrichSettings{
+		Heading:    pick(cfg.EnrichHeading, "OBSIDIAN_ENRICH_HEADING"),
+		LinkFormat: pick(cfg.EnrichLinkFormat, "OBSIDIAN_ENRICH_LINK_FORMAT"),
+	}
+}
+
+// CheckSettings are the configured health check overrides. Checks have no
+// environment fallback.
+type CheckSettings struct {
+	Checks             map[string]CheckConfig
+	RequiredProperties map[string][]string
+}
+
+// ResolveChecks returns the health check overrides from the config file.
+func ResolveChecks() CheckSettings {
+	cfg, err := Load()
+	if err != nil {
+		return CheckSettings{}
+	}
+	return CheckSettings{Checks: cfg.Checks, RequiredProperties: cfg.RequiredProperties}
+}
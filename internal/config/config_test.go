@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewStoreWithEnv_defaultDir(t *testing.T) {
@@ -72,9 +74,10 @@ func TestStore_SaveAndLoad(t *testing.T) {
 	s := NewStoreWithEnv(ConfigDirEnv)
 
 	want := &Config{
-		GeminiAPIKey: "testkey",
-		VaultPath:    "/tmp/vault",
-		WebsitePath:  "/tmp/site",
+		GeminiAPIKey:     "testkey",
+		VaultPath:        "/tmp/vault",
+		WebsitePath:      "/tmp/site",
+		HistoryRetention: 5,
 	}
 	if err := s.Save(want); err != nil {
 		t.Fatalf("Save() error: %v", err)
@@ -97,6 +100,9 @@ func TestStore_SaveAndLoad(t *testing.T) {
 	if got.WebsitePath != want.WebsitePath {
 		t.Errorf("WebsitePath = %q, want %q", got.WebsitePath, want.WebsitePath)
 	}
+	if got.HistoryRetention != want.HistoryRetention {
+		t.Errorf("HistoryRetention = %d, want %d", got.HistoryRetention, want.HistoryRetention)
+	}
 }
 
 func TestStore_Permissions(t *testing.T) {
@@ -143,3 +149,174 @@ func TestPackageLevelFunctions_respectEnvVar(t *testing.T) {
 		t.Error("Exists() = false after Save()")
 	}
 }
+
+func TestResolveLLM(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv(ConfigDirEnv, tmp)
+	for _, env := range []string{"OBSIDIAN_LLM_PROVIDER", "OBSIDIAN_LLM_MODEL", "OBSIDIAN_LLM_BASE_URL", "ANTHROPIC_API_KEY", "OPENAI_API_KEY"} {
+		t.Setenv(env, "")
+	}
+
+	if s := ResolveLLM(); s.Provider != "" {
+		t.Errorf("ResolveLLM() with nothing set: Provider = %q, want empty", s.Provider)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "ak")
+	if s := ResolveLLM(); s.Provider != LLMProviderAnthropic || s.APIKey != "ak" {
+		t.Errorf("ResolveLLM() with ANTHROPIC_API_KEY = %+v", s)
+	}
+
+	cfg := &Config{VaultPath: "/v", LLMProvider: "openai", LLMModel: "llama3", LLMBaseURL: "http://localhost:11434/v1", LLMTimeout: 30, LLMRetries: 4}
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.LLMProvider != "openai" || loaded.LLMModel != "llama3" || loaded.LLMTimeout != 30 || loaded.LLMRetries != 4 {
+		t.Errorf("Load() LLM fields = %+v", loaded)
+	}
+	t.Setenv("OPENAI_API_KEY", "ok")
+	s := ResolveLLM()
+	if s.Provider != LLMProviderOpenAI || s.APIKey != "ok" || s.BaseURL != "http://localhost:11434/v1" || s.Timeout != 30*time.Second || s.Retries != 4 {
+		t.Errorf("ResolveLLM() from config = %+v", s)
+	}
+
+	if err := Save(&Config{VaultPath: "/v", LLMProvider: "none"}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	if s := ResolveLLM(); s.Provider != "" {
+		t.Errorf("ResolveLLM() with provider none: Provider = %q, want empty", s.Provider)
+	}
+}
+
+func TestResolveLLM_ZeroRetries(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(ConfigDirEnv, dir)
+	content := "vault_path=/v\nllm_provider=openai\nllm_retries=0\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.LLMRetries != -1 {
+		t.Errorf("Load() LLMRetries = %d, want -1", cfg.LLMRetries)
+	}
+	if s := ResolveLLM(); s.Retries != -1 {
+		t.Errorf("ResolveLLM() Retries = %d, want -1 (no retries)", s.Retries)
+	}
+
+	// Saving writes the setting back as 0.
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\nllm_retries=0\n") {
+		t.Errorf("saved config missing llm_retries=0:\n%s", data)
+	}
+}
+
+func TestResolveFetch(t *testing.T) {
+	t.Setenv(ConfigDirEnv, t.TempDir())
+	t.Setenv("OBSIDIAN_FETCH_MAX_BYTES", "")
+	t.Setenv("OBSIDIAN_FETCH_MAX_CHARS", "5000")
+	t.Setenv("OBSIDIAN_FETCH_TIMEOUT", "")
+
+	if err := Save(&Config{VaultPath: "/v", FetchMaxBytes: 1 << 20, FetchTimeout: 5}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	s := ResolveFetch()
+	if s.MaxBytes != 1<<20 || s.MaxChars != 5000 || s.Timeout != 5*time.Second {
+		t.Errorf("ResolveFetch() = %+v", s)
+	}
+}
+
+func TestResolvePromote(t *testing.T) {
+	t.Setenv(ConfigDirEnv, t.TempDir())
+	t.Setenv("OBSIDIAN_PROMOTE_ALGORITHM", "louvain")
+	t.Setenv("OBSIDIAN_PROMOTE_TAG_THRESHOLD", "")
+	t.Setenv("OBSIDIAN_PROMOTE_SEMANTIC_THRESHOLD", "")
+	t.Setenv("OBSIDIAN_PROMOTE_LINKAGE", "1.5")
+	t.Setenv("OBSIDIAN_PROMOTE_MIN_SIZE", "")
+	t.Setenv("OBSIDIAN_PROMOTE_MAX_SIZE", "8")
+
+	if err := Save(&Config{VaultPath: "/v", PromoteTagThreshold: 0.4, PromoteSemanticThreshold: 0.75, PromoteMinSize: 4}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	s := ResolvePromote()
+	want := PromoteSettings{Algorithm: "louvain", TagThreshold: 0.4, SemanticThreshold: 0.75, MinSize: 4, MaxSize: 8}
+	if s != want {
+		t.Errorf("ResolvePromote() = %+v, want %+v", s, want)
+	}
+}
+
+func TestResolveEnrich(t *testing.T) {
+	t.Setenv(ConfigDirEnv, t.TempDir())
+	t.Setenv("OBSIDIAN_ENRICH_HEADING", "## See also")
+	t.Setenv("OBSIDIAN_ENRICH_LINK_FORMAT", "")
+
+	if err := Save(&Config{VaultPath: "/v", EnrichLinkFormat: "- {link} — {reason}"}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	s := ResolveEnrich()
+	if s.Heading != "## See also" || s.LinkFormat != "- {link} — {reason}" {
+		t.Errorf("ResolveEnrich() = %+v", s)
+	}
+}
+
+func TestResolveChecks(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(ConfigDirEnv, dir)
+	content := "vault_path=/v\n" +
+		"check_stale_exclude = Archive/, Templates\n" +
+		"check_stale_threshold = 90\n" +
+		"check_broken_links_weight = 0\n" +
+		"check_tag_casing_enabled = false\n" +
+		"check_filename_rules_pattern = ^[a-z0-9-]+$\n" +
+		"check_empty_cap = nope\n" +
+		"required_project = status, due\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := ResolveChecks()
+	stale := s.Checks["stale"]
+	if stale.Threshold != 90 || len(stale.Exclude) != 2 || stale.Exclude[1] != "Templates" {
+		t.Errorf("stale = %+v", stale)
+	}
+	if w := s.Checks["broken-links"].Weight; w == nil || *w != 0 {
+		t.Errorf("broken-links weight = %v", w)
+	}
+	if e := s.Checks["tag-casing"].Enabled; e == nil || *e {
+		t.Errorf("tag-casing enabled = %v", e)
+	}
+	if p := s.Checks["filename-rules"].Pattern; p != "^[a-z0-9-]+$" {
+		t.Errorf("filename-rules pattern = %q", p)
+	}
+	if c := s.Checks["empty"].Cap; c != 0 {
+		t.Errorf("invalid cap parsed as %d", c)
+	}
+	if got := s.RequiredProperties["project"]; len(got) != 2 || got[1] != "due" {
+		t.Errorf("required project = %v", got)
+	}
+
+	// Save writes the overrides back in a form Load reads.
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	again := ResolveChecks()
+	if again.Checks["stale"].Threshold != 90 || *again.Checks["broken-links"].Weight != 0 || again.RequiredProperties["project"][0] != "status" {
+		t.Errorf("round trip = %+v", again)
+	}
+}
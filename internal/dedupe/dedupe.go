@@ -0,0 +1,227 @@
+// Package dedupe finds duplicate and near-duplicate notes. Three signals are
+// combined: normalised source URLs (exact), MinHash signatures over word
+// shingles with locality-sensitive hashing (near-identical text), and cosine
+// similarity of index embeddings (same content, different words).
+package dedupe
+
+import (
+	"math"
+	"sort"
+)
+
+// Match reasons, strongest first.
+const (
+	ReasonURL       = "url"
+	ReasonText      = "text"
+	ReasonEmbedding = "embedding"
+)
+
+// Options holds the similarity thresholds.
+type Options struct {
+	TextThreshold      float64 // minimum estimated Jaccard similarity of shingles
+	EmbeddingThreshold float64 // minimum embedding cosine similarity
+}
+
+// DefaultOptions returns thresholds tuned to flag repeated captures of the
+// same idea without flagging notes that are merely on the same topic.
+func DefaultOptions() Options {
+	return Options{TextThreshold: 0.6, EmbeddingThreshold: 0.95}
+}
+
+// Doc is one note to compare.
+type Doc struct {
+	ID        string
+	Title     string
+	URLs      []string  // source URLs; normalised by Add and Find
+	Text      string    // body text used for shingling
+	Embedding []float32 // optional
+}
+
+// Match is a likely duplicate of a document.
+type Match struct {
+	ID     string  `json:"path"`
+	Title  string  `json:"title,omitempty"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"` // url, text or embedding
+}
+
+// entry is an indexed document.
+type entry struct {
+	doc  Doc
+	urls []string
+	sig  Signature
+}
+
+// Corpus indexes documents for duplicate lookup.
+type Corpus struct {
+	opts    Options
+	entries []*entry
+	byURL   map[string][]int
+	bands   map[uint64][]int
+}
+
+// NewCorpus returns an empty corpus.
+func NewCorpus(opts Options) *Corpus {
+	return &Corpus{
+		opts:  opts,
+		byURL: make(map[string][]int),
+		bands: make(map[uint64][]int),
+	}
+}
+
+// Len returns the number of documents in the corpus.
+func (c *Corpus) Len() int { return len(c.entries) }
+
+// Add indexes a document.
+func (c *Corpus) Add(doc Doc) {
+	e := prepare(doc)
+	idx := len(c.entries)
+	c.entries = append(c.entries, e)
+	for _, u := range e.urls {
+		c.byURL[u] = append(c.byURL[u], idx)
+	}
+	for _, k := range e.sig.bandKeys() {
+		c.bands[k] = append(c.bands[k], idx)
+	}
+}
+
+// Find returns the corpus documents that are likely duplicates of doc, best
+// first. A corpus document with the same ID as doc is skipped.
+func (c *Corpus) Find(doc Doc) []Match {
+	return c.find(prepare(doc), -1)
+}
+
+// FindID returns the likely duplicates of the corpus document with the given
+// ID, best first, or nil when no document has that ID.
+func (c *Corpus) FindID(id string) []Match {
+	for _, e := range c.entries {
+		if e.doc.ID == id {
+			return c.find(e, -1)
+		}
+	}
+	return nil
+}
+
+// Pair is a pair of likely duplicate documents in the corpus.
+type Pair struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// Pairs returns every likely duplicate pair in the corpus, best first.
+func (c *Corpus) Pairs() []Pair {
+	var pairs []Pair
+	for i, e := range c.entries {
+		for _, m := range c.find(e, i) {
+			pairs = append(pairs, Pair{
+				A:      e.doc.ID,
+				B:      m.ID,
+				Score:  m.Score,
+				Reason: m.Reason,
+			})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
+
+// find matches e against the corpus. When self >= 0, only entries after self
+// are considered so each pair is reported once.
+func (c *Corpus) find(e *entry, self int) []Match {
+	best := make(map[int]Match)
+	consider := func(idx int, score float64, reason string) {
+		if idx <= self {
+			return
+		}
+		other := c.entries[idx]
+		if other.doc.ID == e.doc.ID {
+			return
+		}
+		if cur, ok := best[idx]; ok && cur.Score >= score {
+			return
+		}
+		best[idx] = Match{ID: other.doc.ID, Title: other.doc.Title, Score: score, Reason: reason}
+	}
+
+	for _, u := range e.urls {
+		for _, idx := range c.byURL[u] {
+			consider(idx, 1, ReasonURL)
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, k := range e.sig.bandKeys() {
+		for _, idx := range c.bands[k] {
+			if seen[idx] {
+				continue
+			}
+			seen[idx] = true
+			if sim := e.sig.Similarity(c.entries[idx].sig); sim >= c.opts.TextThreshold {
+				consider(idx, sim, ReasonText)
+			}
+		}
+	}
+
+	if len(e.doc.Embedding) > 0 {
+		for idx, other := range c.entries {
+			if idx <= self || len(other.doc.Embedding) == 0 {
+				continue
+			}
+			if sim := cosine(e.doc.Embedding, other.doc.Embedding); sim >= c.opts.EmbeddingThreshold {
+				consider(idx, sim, ReasonEmbedding)
+			}
+		}
+	}
+
+	matches := make([]Match, 0, len(best))
+	for _, m := range best {
+		m.Score = math.Round(m.Score*1000) / 1000
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// prepare normalises a document's URLs and computes its signature.
+func prepare(doc Doc) *entry {
+	e := &entry{doc: doc, sig: MinHash(Shingles(doc.Text))}
+	for _, raw := range doc.URLs {
+		if u := NormalizeURL(raw); u != "" {
+			e.urls = append(e.urls, u)
+		}
+	}
+	return e
+}
+
+// cosine returns the cosine similarity of two vectors, or 0 when their
+// lengths differ or either is zero.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,74 @@
+package dedupe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://example.com/post", "https://example.com/post"},
+		{"http://WWW.Example.com/post/", "https://example.com/post"},
+		{"https://example.com:443/post#comments", "https://example.com/post"},
+		{"https://example.com/post?utm_source=x&b=2&a=1&fbclid=abc", "https://example.com/post?a=1&b=2"},
+		{"https://example.com:8080/", "https://example.com:8080"},
+		{"ftp://example.com/file", ""},
+		{"not a url", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeURL(tt.in); got != tt.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSignatureSimilarity(t *testing.T) {
+	base := "the quick brown fox jumps over the lazy dog while the cat sleeps on the warm mat by the fire"
+	edited := strings.Replace(base, "warm", "soft", 1)
+	other := "quarterly revenue grew in every region except the north where supply problems delayed shipments"
+
+	a, b, c := MinHash(Shingles(base)), MinHash(Shingles(edited)), MinHash(Shingles(other))
+	if sim := a.Similarity(a); sim != 1 {
+		t.Errorf("self similarity = %v, want 1", sim)
+	}
+	// 3 of 18 shingles change: true Jaccard is 15/21 ≈ 0.71.
+	if sim := a.Similarity(b); sim < 0.55 || sim > 0.87 {
+		t.Errorf("near-duplicate similarity = %v, want ≈ 0.71", sim)
+	}
+	if sim := a.Similarity(c); sim > 0.1 {
+		t.Errorf("unrelated similarity = %v, want ≈ 0", sim)
+	}
+	if MinHash(Shingles("  ")) != nil {
+		t.Error("empty text should have no signature")
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	c := NewCorpus(DefaultOptions())
+	c.Add(Doc{ID: "a.md", URLs: []string{"https://blog.example.com/go-generics?utm_source=rss"}, Text: "Notes on generics."})
+	c.Add(Doc{ID: "b.md", Text: "Meeting notes: we agreed to ship the billing export on Friday and to review the retry logic next week."})
+	c.Add(Doc{ID: "c.md", Text: "Something else entirely about gardening tomatoes in raised beds.", Embedding: []float32{1, 0, 0}})
+	c.Add(Doc{ID: "d.md", Text: "Unrelated words about growing vegetables outdoors.", Embedding: []float32{0.99, 0.05, 0}})
+
+	matches := c.Find(Doc{ID: "new.md", URLs: []string{"http://blog.example.com/go-generics/"}})
+	if len(matches) != 1 || matches[0].ID != "a.md" || matches[0].Reason != ReasonURL {
+		t.Errorf("URL match = %+v", matches)
+	}
+
+	matches = c.Find(Doc{ID: "new.md", Text: "Meeting notes: we agreed to ship the billing export on Friday and to review the retry logic next month."})
+	if len(matches) != 1 || matches[0].ID != "b.md" || matches[0].Reason != ReasonText {
+		t.Errorf("text match = %+v", matches)
+	}
+
+	if matches := c.Find(Doc{ID: "b.md", Text: "Meeting notes: we agreed to ship the billing export on Friday and to review the retry logic next week."}); len(matches) != 0 {
+		t.Errorf("a document should not match itself: %+v", matches)
+	}
+
+	pairs := c.Pairs()
+	if len(pairs) != 1 || pairs[0].A != "c.md" || pairs[0].B != "d.md" || pairs[0].Reason != ReasonEmbedding {
+		t.Errorf("Pairs() = %+v", pairs)
+	}
+}
@@ -0,0 +1,143 @@
+package dedupe
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// Signature parameters. With 32 bands of 4 rows, pairs with Jaccard
+// similarity 0.6 share a band with probability ~0.99, and pairs at 0.2 with
+// probability ~0.05.
+const (
+	numHashes   = 128
+	bandRows    = 4
+	numBands    = numHashes / bandRows
+	shingleSize = 3 // words per shingle
+)
+
+// mersennePrime is the modulus of the universal hash family (2^61 - 1).
+const mersennePrime = (1 << 61) - 1
+
+// hashParams are the fixed (a, b) pairs of the permutation hashes, generated
+// once from a constant seed so signatures are stable across runs.
+var hashParams = func() [numHashes][2]uint64 {
+	var p [numHashes][2]uint64
+	state := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 { // splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+	for i := range p {
+		p[i][0] = next()%(mersennePrime-1) + 1
+		p[i][1] = next() % mersennePrime
+	}
+	return p
+}()
+
+// Signature is a MinHash sketch of a document's shingle set. The fraction of
+// equal positions in two signatures estimates their Jaccard similarity.
+type Signature []uint64
+
+// Words splits text into lowercase words of letters and digits.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Shingles returns the hashed set of overlapping word 3-grams in text. Texts
+// shorter than one shingle yield a single shingle of all their words, so
+// identical short notes still match.
+func Shingles(text string) map[uint64]bool {
+	words := Words(text)
+	set := make(map[uint64]bool)
+	if len(words) == 0 {
+		return set
+	}
+	n := shingleSize
+	if len(words) < n {
+		n = len(words)
+	}
+	for i := 0; i+n <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+n], " ")))
+		set[h.Sum64()] = true
+	}
+	return set
+}
+
+// MinHash computes the signature of a shingle set, or nil for an empty set.
+func MinHash(shingles map[uint64]bool) Signature {
+	if len(shingles) == 0 {
+		return nil
+	}
+	sig := make(Signature, numHashes)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for s := range shingles {
+		x := s % mersennePrime
+		for i, ab := range hashParams {
+			if h := mulAddMod(ab[0], x, ab[1]); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity estimates the Jaccard similarity of the documents behind two
+// signatures. It is 0 when either is empty.
+func (s Signature) Similarity(other Signature) float64 {
+	if len(s) == 0 || len(s) != len(other) {
+		return 0
+	}
+	equal := 0
+	for i := range s {
+		if s[i] == other[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(s))
+}
+
+// bandKeys returns one hash per LSH band. Documents sharing any band key are
+// candidate near-duplicates.
+func (s Signature) bandKeys() []uint64 {
+	if len(s) != numHashes {
+		return nil
+	}
+	keys := make([]uint64, numBands)
+	for b := range keys {
+		h := fnv.New64a()
+		buf := [8]byte{byte(b)} // keep bands apart
+		h.Write(buf[:])
+		for _, v := range s[b*bandRows : (b+1)*bandRows] {
+			for i := range buf {
+				buf[i] = byte(v >> (8 * i))
+			}
+			h.Write(buf[:])
+		}
+		keys[b] = h.Sum64()
+	}
+	return keys
+}
+
+// mulAddMod returns (a*x + b) mod 2^61-1 without overflow.
+func mulAddMod(a, x, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, x)
+	// Reduce the 128-bit product modulo 2^61-1.
+	r := (lo & mersennePrime) + (lo >> 61) + (hi << 3)
+	r = (r & mersennePrime) + (r >> 61)
+	r += b
+	r = (r & mersennePrime) + (r >> 61)
+	if r >= mersennePrime {
+		r -= mersennePrime
+	}
+	return r
+}
@@ -0,0 +1,66 @@
+package dedupe
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams are query parameters that identify a visit rather than a
+// page. They are dropped during normalisation.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"mc_cid": true, "mc_eid": true, "igshid": true, "si": true, "ref": true,
+	"ref_src": true, "ref_url": true, "_hsenc": true, "_hsmi": true, "spm": true,
+}
+
+// NormalizeURL returns a canonical form of an http(s) URL so that links to the
+// same page compare equal: the scheme is https, the host is lowercased without
+// "www." or a default port, tracking parameters (utm_* and common click IDs)
+// and the fragment are dropped, the remaining query is sorted, and a trailing
+// slash is removed. It returns "" for anything that is not an http(s) URL.
+func NormalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var q strings.Builder
+	for _, k := range keys {
+		vals := query[k]
+		sort.Strings(vals)
+		for _, v := range vals {
+			if q.Len() > 0 {
+				q.WriteByte('&')
+			}
+			q.WriteString(url.QueryEscape(k))
+			q.WriteByte('=')
+			q.WriteString(url.QueryEscape(v))
+		}
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	out := "https://" + host + path
+	if q.Len() > 0 {
+		out += "?" + q.String()
+	}
+	return out
+}
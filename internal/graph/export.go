@@ -0,0 +1,170 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Export formats.
+const (
+	FormatGraphML = "graphml" // Gephi, yEd, Cytoscape
+	FormatDOT     = "dot"     // Graphviz
+	FormatJSON    = "json"    // nodes and edges with metrics
+)
+
+// Formats lists the export formats.
+var Formats = []string{FormatGraphML, FormatDOT, FormatJSON}
+
+// Write writes the graph and its metrics in the named format.
+func Write(w io.Writer, format string, g *Graph, m Metrics) error {
+	switch strings.ToLower(format) {
+	case FormatGraphML:
+		return WriteGraphML(w, g, m)
+	case FormatDOT:
+		return WriteDOT(w, g, m)
+	case FormatJSON:
+		return WriteJSON(w, g, m)
+	}
+	return fmt.Errorf("unknown graph format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// nodeAttrs are the per-node values every format carries, in order.
+func nodeAttrs(g *Graph, m Metrics, i int) []struct{ key, value string } {
+	return []struct{ key, value string }{
+		{"title", g.Nodes[i].Title},
+		{"tags", strings.Join(g.Nodes[i].Tags, ", ")},
+		{"in_degree", strconv.Itoa(m.InDegree[i])},
+		{"out_degree", strconv.Itoa(m.OutDegree[i])},
+		{"pagerank", strconv.FormatFloat(m.PageRank[i], 'g', 6, 64)},
+		{"betweenness", strconv.FormatFloat(m.Betweenness[i], 'g', 6, 64)},
+		{"component", strconv.Itoa(m.Component[i])},
+		{"community", strconv.Itoa(m.Community[i])},
+	}
+}
+
+// graphMLTypes are the GraphML types of the node attributes.
+var graphMLTypes = map[string]string{
+	"title": "string", "tags": "string",
+	"in_degree": "int", "out_degree": "int",
+	"pagerank": "double", "betweenness": "double",
+	"component": "int", "community": "int",
+}
+
+// WriteGraphML writes the graph as GraphML. Node ids are note paths; node
+// metrics and edge kinds and weights are attributes.
+func WriteGraphML(w io.Writer, g *Graph, m Metrics) error {
+	var b strings.Builder
+	esc := func(s string) string {
+		var e strings.Builder
+		xml.EscapeText(&e, []byte(s))
+		return e.String()
+	}
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	if len(g.Nodes) > 0 {
+		for _, a := range nodeAttrs(g, m, 0) {
+			fmt.Fprintf(&b, "  <key id=%q for=\"node\" attr.name=%q attr.type=%q/>\n", a.key, a.key, graphMLTypes[a.key])
+		}
+	}
+	b.WriteString("  <key id=\"kind\" for=\"edge\" attr.name=\"kind\" attr.type=\"string\"/>\n")
+	b.WriteString("  <key id=\"weight\" for=\"edge\" attr.name=\"weight\" attr.type=\"double\"/>\n")
+	b.WriteString("  <graph id=\"vault\" edgedefault=\"directed\">\n")
+	for i, n := range g.Nodes {
+		fmt.Fprintf(&b, "    <node id=\"%s\">\n", esc(n.Path))
+		for _, a := range nodeAttrs(g, m, i) {
+			fmt.Fprintf(&b, "      <data key=%q>%s</data>\n", a.key, esc(a.value))
+		}
+		b.WriteString("    </node>\n")
+	}
+	for _, e := range g.Edges {
+		directed := ""
+		if e.Kind == EdgeSemantic {
+			directed = ` directed="false"`
+		}
+		fmt.Fprintf(&b, "    <edge source=\"%s\" target=\"%s\"%s>\n", esc(g.Nodes[e.From].Path), esc(g.Nodes[e.To].Path), directed)
+		fmt.Fprintf(&b, "      <data key=\"kind\">%s</data>\n", e.Kind)
+		fmt.Fprintf(&b, "      <data key=\"weight\">%s</data>\n", strconv.FormatFloat(edgeWeight(e), 'g', 6, 64))
+		b.WriteString("    </edge>\n")
+	}
+	b.WriteString("  </graph>\n</graphml>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteDOT writes the graph in Graphviz DOT. Semantic edges are dashed and
+// have no arrowhead.
+func WriteDOT(w io.Writer, g *Graph, m Metrics) error {
+	var b strings.Builder
+	b.WriteString("digraph vault {\n")
+	b.WriteString("  node [shape=ellipse];\n")
+	for i, n := range g.Nodes {
+		var attrs []string
+		for _, a := range nodeAttrs(g, m, i) {
+			key := a.key
+			if key == "title" {
+				key = "label"
+			}
+			attrs = append(attrs, key+"="+strconv.Quote(a.value))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", strconv.Quote(n.Path), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if e.Kind == EdgeSemantic {
+			style = fmt.Sprintf(", style=dashed, dir=none, weight=%s", strconv.FormatFloat(edgeWeight(e), 'g', 6, 64))
+		}
+		fmt.Fprintf(&b, "  %s -> %s [kind=%q%s];\n", strconv.Quote(g.Nodes[e.From].Path), strconv.Quote(g.Nodes[e.To].Path), e.Kind, style)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// jsonNode is a node in the JSON export.
+type jsonNode struct {
+	Node
+	InDegree    int     `json:"in_degree"`
+	OutDegree   int     `json:"out_degree"`
+	PageRank    float64 `json:"pagerank"`
+	Betweenness float64 `json:"betweenness"`
+	Component   int     `json:"component"`
+	Community   int     `json:"community"`
+}
+
+// jsonEdge is an edge in the JSON export, by note path.
+type jsonEdge struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Kind   string  `json:"kind"`
+	Weight float64 `json:"weight"`
+}
+
+// WriteJSON writes {"nodes": [...], "edges": [...]}, the shape d3 and most
+// graph viewers load directly.
+func WriteJSON(w io.Writer, g *Graph, m Metrics) error {
+	out := struct {
+		Nodes []jsonNode `json:"nodes"`
+		Edges []jsonEdge `json:"edges"`
+	}{Nodes: []jsonNode{}, Edges: []jsonEdge{}}
+	for i, n := range g.Nodes {
+		out.Nodes = append(out.Nodes, jsonNode{
+			Node:        n,
+			InDegree:    m.InDegree[i],
+			OutDegree:   m.OutDegree[i],
+			PageRank:    m.PageRank[i],
+			Betweenness: m.Betweenness[i],
+			Component:   m.Component[i],
+			Community:   m.Community[i],
+		})
+	}
+	for _, e := range g.Edges {
+		out.Edges = append(out.Edges, jsonEdge{g.Nodes[e.From].Path, g.Nodes[e.To].Path, e.Kind, edgeWeight(e)})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,298 @@
+// Package graph holds the vault's note graph and the structural measures
+// computed on it: degrees, PageRank, betweenness centrality, connected
+// components and communities.
+//
+// Link edges are directed, from the linking note to the linked one. Semantic
+// edges join notes with similar embeddings and have no direction. PageRank
+// follows edges in their direction (semantic ones both ways); betweenness,
+// components and communities treat the graph as undirected.
+package graph
+
+import (
+	"math"
+	"sort"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/cluster"
+)
+
+// Edge kinds.
+const (
+	EdgeLink     = "link"     // wikilink from From to To
+	EdgeSemantic = "semantic" // embedding similarity between From and To
+)
+
+// Defaults for PageRank.
+const (
+	DefaultDamping    = 0.85
+	pageRankMaxRounds = 100
+	pageRankTolerance = 1e-9
+)
+
+// Node is a note in the graph.
+type Node struct {
+	Path  string   `json:"path"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// Edge joins two nodes by index.
+type Edge struct {
+	From   int     `json:"from"`
+	To     int     `json:"to"`
+	Kind   string  `json:"kind"`
+	Weight float64 `json:"weight"`
+}
+
+// Graph is a set of notes and the edges between them.
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// Metrics holds the measures for each node, indexed like Graph.Nodes.
+type Metrics struct {
+	InDegree    []int
+	OutDegree   []int
+	PageRank    []float64
+	Betweenness []float64 // normalized to 0..1
+	Component   []int     // index into Components
+	Community   []int     // index into Communities
+	Components  [][]int   // largest first
+	Communities [][]int   // largest first
+}
+
+// Analyze computes every measure.
+func Analyze(g *Graph) Metrics {
+	m := Metrics{
+		PageRank:    PageRank(g, DefaultDamping),
+		Betweenness: Betweenness(g),
+		Components:  Components(g),
+		Communities: Communities(g),
+	}
+	m.InDegree, m.OutDegree = Degrees(g)
+	m.Component = labels(len(g.Nodes), m.Components)
+	m.Community = labels(len(g.Nodes), m.Communities)
+	return m
+}
+
+// labels maps each node to the index of the group holding it.
+func labels(n int, groups [][]int) []int {
+	out := make([]int, n)
+	for i, g := range groups {
+		for _, node := range g {
+			out[node] = i
+		}
+	}
+	return out
+}
+
+// Degrees returns each node's in and out degree. Link edges count in their
+// direction; semantic edges count as both in and out for both ends.
+func Degrees(g *Graph) (in, out []int) {
+	in, out = make([]int, len(g.Nodes)), make([]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		out[e.From]++
+		in[e.To]++
+		if e.Kind == EdgeSemantic {
+			out[e.To]++
+			in[e.From]++
+		}
+	}
+	return in, out
+}
+
+// PageRank returns each node's PageRank with the given damping factor,
+// iterating until the scores change by less than 1e-9 in total. Edges pass
+// rank in proportion to their weight; rank from notes without outgoing edges
+// is spread over every note. Scores sum to 1.
+func PageRank(g *Graph, damping float64) []float64 {
+	n := len(g.Nodes)
+	if n == 0 {
+		return nil
+	}
+	type arc struct {
+		to int
+		w  float64
+	}
+	outArcs := make([][]arc, n)
+	outWeight := make([]float64, n)
+	add := func(a, b int, w float64) {
+		outArcs[a] = append(outArcs[a], arc{b, w})
+		outWeight[a] += w
+	}
+	for _, e := range g.Edges {
+		w := edgeWeight(e)
+		add(e.From, e.To, w)
+		if e.Kind == EdgeSemantic {
+			add(e.To, e.From, w)
+		}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	for round := 0; round < pageRankMaxRounds; round++ {
+		dangling := 0.0
+		for a := range rank {
+			if outWeight[a] == 0 {
+				dangling += rank[a]
+			}
+		}
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for a, arcs := range outArcs {
+			for _, e := range arcs {
+				next[e.to] += damping * rank[a] * e.w / outWeight[a]
+			}
+		}
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < pageRankTolerance {
+			break
+		}
+	}
+	return rank
+}
+
+// edgeWeight is the weight an edge carries; unset weights count as 1.
+func edgeWeight(e Edge) float64 {
+	if e.Weight <= 0 {
+		return 1
+	}
+	return e.Weight
+}
+
+// neighbours returns the undirected adjacency lists, without duplicates or
+// self-loops, each sorted.
+func neighbours(g *Graph) [][]int {
+	seen := make(map[[2]int]bool)
+	adj := make([][]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		a, b := e.From, e.To
+		if a == b {
+			continue
+		}
+		if a > b {
+			a, b = b, a
+		}
+		if seen[[2]int{a, b}] {
+			continue
+		}
+		seen[[2]int{a, b}] = true
+		adj[a] = append(adj[a], b)
+		adj[b] = append(adj[b], a)
+	}
+	for _, l := range adj {
+		sort.Ints(l)
+	}
+	return adj
+}
+
+// Betweenness returns each node's betweenness centrality on the undirected,
+// unweighted graph (Brandes' algorithm): the share of shortest paths between
+// other pairs of notes that pass through it. High scores mark bridges
+// between otherwise separate parts of the vault.
+func Betweenness(g *Graph) []float64 {
+	n := len(g.Nodes)
+	adj := neighbours(g)
+	cb := make([]float64, n)
+
+	sigma := make([]float64, n)
+	dist := make([]int, n)
+	delta := make([]float64, n)
+	preds := make([][]int, n)
+	for s := 0; s < n; s++ {
+		for i := range sigma {
+			sigma[i], dist[i], delta[i], preds[i] = 0, -1, 0, preds[i][:0]
+		}
+		sigma[s], dist[s] = 1, 0
+		order := []int{s} // nodes in order of distance from s
+		for q := 0; q < len(order); q++ {
+			v := order[q]
+			for _, w := range adj[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					order = append(order, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+		for i := len(order) - 1; i > 0; i-- {
+			w := order[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			cb[w] += delta[w]
+		}
+	}
+
+	// Each undirected path was counted from both ends; normalize by the
+	// number of pairs not involving the node.
+	if n > 2 {
+		scale := 1 / float64((n-1)*(n-2))
+		for i := range cb {
+			cb[i] *= scale
+		}
+	}
+	return cb
+}
+
+// Components returns the connected components of the undirected graph,
+// largest first, then by first node. Nodes within each ascend.
+func Components(g *Graph) [][]int {
+	adj := neighbours(g)
+	seen := make([]bool, len(g.Nodes))
+	var groups [][]int
+	for start := range g.Nodes {
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+		group := []int{start}
+		for q := 0; q < len(group); q++ {
+			for _, next := range adj[group[q]] {
+				if !seen[next] {
+					seen[next] = true
+					group = append(group, next)
+				}
+			}
+		}
+		sort.Ints(group)
+		groups = append(groups, group)
+	}
+	sortGroups(groups)
+	return groups
+}
+
+// Communities returns the Louvain communities of the undirected weighted
+// graph, largest first, then by first node. Notes without edges are
+// communities of one.
+func Communities(g *Graph) [][]int {
+	edges := make([]cluster.Edge, len(g.Edges))
+	for i, e := range g.Edges {
+		edges[i] = cluster.Edge{A: e.From, B: e.To, Weight: edgeWeight(e)}
+	}
+	groups := cluster.Communities(len(g.Nodes), edges)
+	sortGroups(groups)
+	return groups
+}
+
+// sortGroups orders groups largest first, then by first node.
+func sortGroups(groups [][]int) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i]) != len(groups[j]) {
+			return len(groups[i]) > len(groups[j])
+		}
+		return groups[i][0] < groups[j][0]
+	})
+}
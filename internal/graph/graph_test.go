@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// twoTriangles builds two linked triangles (0-2 and 4-6) joined through
+// node 3, plus an isolated node 7.
+func twoTriangles() *Graph {
+	g := &Graph{}
+	for _, p := range []string{"a", "b", "c", "bridge", "d", "e", "f", "alone"} {
+		g.Nodes = append(g.Nodes, Node{Path: p + ".md", Title: p})
+	}
+	link := func(a, b int) { g.Edges = append(g.Edges, Edge{From: a, To: b, Kind: EdgeLink}) }
+	link(0, 1)
+	link(1, 2)
+	link(2, 0)
+	link(4, 5)
+	link(5, 6)
+	link(6, 4)
+	link(2, 3)
+	link(3, 4)
+	return g
+}
+
+func TestDegrees(t *testing.T) {
+	g := &Graph{Nodes: make([]Node, 3), Edges: []Edge{
+		{From: 0, To: 1, Kind: EdgeLink},
+		{From: 0, To: 2, Kind: EdgeLink},
+		{From: 1, To: 2, Kind: EdgeSemantic, Weight: 0.9},
+	}}
+	in, out := Degrees(g)
+	if !reflect.DeepEqual(in, []int{0, 2, 2}) || !reflect.DeepEqual(out, []int{2, 1, 1}) {
+		t.Errorf("Degrees() = %v, %v", in, out)
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	// Everyone links to the hub.
+	g := &Graph{Nodes: make([]Node, 4)}
+	for i := 1; i < 4; i++ {
+		g.Edges = append(g.Edges, Edge{From: i, To: 0, Kind: EdgeLink})
+	}
+	rank := PageRank(g, DefaultDamping)
+	sum := 0.0
+	for i, r := range rank {
+		sum += r
+		if i > 0 && r >= rank[0] {
+			t.Errorf("rank[%d] = %v not below hub %v", i, r, rank[0])
+		}
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("ranks sum to %v, want 1", sum)
+	}
+}
+
+func TestBetweenness(t *testing.T) {
+	cb := Betweenness(twoTriangles())
+	// Every path between the triangles crosses 2, 3 and 4.
+	if cb[3] <= cb[0] || cb[2] <= cb[0] || cb[4] <= cb[5] {
+		t.Errorf("Betweenness() = %v", cb)
+	}
+	if cb[0] != 0 || cb[7] != 0 {
+		t.Errorf("peripheral nodes have betweenness: %v", cb)
+	}
+	// Node 3 lies on paths between the 3 nodes on each side: 9 of the 21
+	// pairs among the other 7 nodes.
+	if want := 9.0 / 21; math.Abs(cb[3]-want) > 1e-9 {
+		t.Errorf("Betweenness()[3] = %v, want %v", cb[3], want)
+	}
+}
+
+func TestComponentsAndCommunities(t *testing.T) {
+	g := twoTriangles()
+	if got, want := Components(g), [][]int{{0, 1, 2, 3, 4, 5, 6}, {7}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Components() = %v, want %v", got, want)
+	}
+	if got, want := Communities(g), [][]int{{0, 1, 2, 3}, {4, 5, 6}, {7}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Communities() = %v, want %v", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := &Graph{
+		Nodes: []Node{{Path: "a & b.md", Title: "A & B"}, {Path: "c.md", Title: "C"}},
+		Edges: []Edge{{From: 0, To: 1, Kind: EdgeLink}, {From: 1, To: 0, Kind: EdgeSemantic, Weight: 0.8}},
+	}
+	m := Analyze(g)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "graphml", g, m); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<node id="a &amp; b.md">`, `<data key="title">A &amp; B</data>`, `<edge source="c.md" target="a &amp; b.md" directed="false">`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("GraphML missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := Write(&buf, "dot", g, m); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"a & b.md" [label="A & B"`, `"a & b.md" -> "c.md" [kind="link"];`, `style=dashed, dir=none`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("DOT missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := Write(&buf, "json", g, m); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Nodes []map[string]any `json:"nodes"`
+		Edges []map[string]any `json:"edges"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil || len(out.Nodes) != 2 || out.Edges[1]["kind"] != "semantic" {
+		t.Errorf("JSON = %s (%v)", buf.String(), err)
+	}
+
+	if err := Write(&buf, "svg", g, m); err == nil {
+		t.Error("Write(svg) succeeded")
+	}
+}
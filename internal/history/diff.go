@@ -0,0 +1,212 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type diffOp struct {
+	kind opKind
+	line string
+	aIdx int // 0-based line index in a (valid for equal/delete)
+	bIdx int // 0-based line index in b (valid for equal/insert)
+}
+
+// UnifiedDiff returns a unified diff (as produced by `diff -u`) turning a into b.
+// Returns an empty string when the contents are identical.
+func UnifiedDiff(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		first := start
+		for first < len(ops) && ops[first].kind == opEqual {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other.
+		hunkStart := max(first-diffContext, start)
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != opEqual {
+				last = i
+				continue
+			}
+			if i-last > 2*diffContext {
+				break
+			}
+		}
+		hunkEnd := min(last+diffContext+1, len(ops))
+
+		writeHunk(&sb, ops[hunkStart:hunkEnd])
+		start = hunkEnd
+	}
+	return sb.String()
+}
+
+// writeHunk writes a single @@ hunk for the given ops.
+func writeHunk(sb *strings.Builder, ops []diffOp) {
+	aStart, bStart := -1, -1
+	aCount, bCount := 0, 0
+	for _, op := range ops {
+		if op.kind != opInsert {
+			if aStart < 0 {
+				aStart = op.aIdx
+			}
+			aCount++
+		}
+		if op.kind != opDelete {
+			if bStart < 0 {
+				bStart = op.bIdx
+			}
+			bCount++
+		}
+	}
+	// An empty side is reported at the line before the hunk, per diff -u.
+	if aStart < 0 {
+		aStart = ops[0].aIdx - 1
+	}
+	if bStart < 0 {
+		bStart = ops[0].bIdx - 1
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+	for _, op := range ops {
+		sb.WriteByte(byte(op.kind))
+		sb.WriteString(op.line)
+		sb.WriteByte('\n')
+	}
+}
+
+// hunkRange formats a 0-based start and a line count as a 1-based diff range.
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffLines computes an edit script between a and b using the longest common
+// subsequence. Shared prefix and suffix lines are trimmed first, which keeps
+// the quadratic table small for typical note edits.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	// lcs[i][j] = length of LCS of midA[i:] and midB[j:].
+	n, m := len(midA), len(midB)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: opEqual, line: a[i], aIdx: i, bIdx: i})
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && midA[i] == midB[j]:
+			ops = append(ops, diffOp{kind: opEqual, line: midA[i], aIdx: prefix + i, bIdx: prefix + j})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] >= lcs[i+1][j]):
+			ops = append(ops, diffOp{kind: opInsert, line: midB[j], aIdx: prefix + i, bIdx: prefix + j})
+			j++
+		default:
+			ops = append(ops, diffOp{kind: opDelete, line: midA[i], aIdx: prefix + i, bIdx: prefix + j})
+			i++
+		}
+	}
+	for k := 0; k < suffix; k++ {
+		ai, bi := len(a)-suffix+k, len(b)-suffix+k
+		ops = append(ops, diffOp{kind: opEqual, line: a[ai], aIdx: ai, bIdx: bi})
+	}
+
+	// Deletions should come before insertions within a change block so the
+	// output reads like diff -u. The LCS walk above prefers insertions, so
+	// reorder each contiguous run of changes.
+	return groupChanges(ops)
+}
+
+// groupChanges reorders each contiguous run of non-equal ops so that all
+// deletions precede all insertions.
+func groupChanges(ops []diffOp) []diffOp {
+	out := make([]diffOp, 0, len(ops))
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			out = append(out, ops[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && ops[j].kind != opEqual {
+			j++
+		}
+		for _, op := range ops[i:j] {
+			if op.kind == opDelete {
+				out = append(out, op)
+			}
+		}
+		for _, op := range ops[i:j] {
+			if op.kind == opInsert {
+				out = append(out, op)
+			}
+		}
+		i = j
+	}
+	return out
+}
+
+// splitLines splits content into lines without their trailing newline.
+// A trailing newline does not produce an extra empty line.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.TrimSuffix(s, "\n")
+	return strings.Split(s, "\n")
+}
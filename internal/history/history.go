@@ -0,0 +1,261 @@
+// Package history keeps content-addressed snapshots of notes that the CLI
+// rewrites, so vaults without git can still inspect and diff prior versions.
+//
+// Snapshots live under <vault>/.obsidian/history: file contents are stored once
+// per SHA-256 hash in objects/, and log.json maps each note path to its ordered
+// list of versions.
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultRetention is the number of versions kept per note when no retention
+// is configured.
+const DefaultRetention = 20
+
+// Version describes one stored snapshot of a note.
+type Version struct {
+	Rev    int    `json:"rev"`              // per-note revision number, increasing, never reused
+	Hash   string `json:"hash"`             // SHA-256 of the content (hex)
+	Time   int64  `json:"time"`             // Unix timestamp the snapshot was taken
+	Size   int64  `json:"size"`             // content size in bytes
+	Reason string `json:"reason,omitempty"` // command that was about to modify the note
+}
+
+// Store manages the snapshot log and object directory for a vault.
+type Store struct {
+	dir       string
+	retention int
+	log       map[string][]Version
+}
+
+// Dir returns the history directory for a given vault.
+func Dir(vaultPath string) string {
+	return vaultPath + "/.obsidian/history"
+}
+
+// Open loads the snapshot log for the vault. The directory is created lazily on
+// the first Save, so opening a vault with no history is free.
+// retention <= 0 uses DefaultRetention.
+func Open(vaultPath string, retention int) (*Store, error) {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	s := &Store{
+		dir:       Dir(vaultPath),
+		retention: retention,
+		log:       make(map[string][]Version),
+	}
+
+	data, err := os.ReadFile(s.logPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("cannot read history log: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.log); err != nil {
+		return nil, fmt.Errorf("cannot parse history log: %w", err)
+	}
+	if s.log == nil {
+		s.log = make(map[string][]Version)
+	}
+	return s, nil
+}
+
+// NormalizePath converts a user-supplied note path into the key used by the
+// log: slash-separated, relative, with a .md extension.
+func NormalizePath(notePath string) string {
+	p := filepath.ToSlash(filepath.Clean(notePath))
+	p = strings.TrimPrefix(p, "./")
+	if !strings.HasSuffix(p, ".md") {
+		p += ".md"
+	}
+	return p
+}
+
+// Snapshot records content as the newest version of notePath.
+// Returns the stored version and true, or the latest existing version and false
+// when content is identical to it (no new snapshot is taken).
+func (s *Store) Snapshot(notePath string, content []byte, reason string, now time.Time) (Version, bool, error) {
+	key := NormalizePath(notePath)
+	hash := hashContent(content)
+
+	versions := s.log[key]
+	if n := len(versions); n > 0 && versions[n-1].Hash == hash {
+		return versions[n-1], false, nil
+	}
+
+	if err := s.writeObject(hash, content); err != nil {
+		return Version{}, false, err
+	}
+
+	rev := 1
+	if n := len(versions); n > 0 {
+		rev = versions[n-1].Rev + 1
+	}
+	v := Version{
+		Rev:    rev,
+		Hash:   hash,
+		Time:   now.Unix(),
+		Size:   int64(len(content)),
+		Reason: reason,
+	}
+	versions = append(versions, v)
+	if len(versions) > s.retention {
+		versions = versions[len(versions)-s.retention:]
+	}
+	s.log[key] = versions
+	return v, true, nil
+}
+
+// Versions returns the stored versions of notePath, oldest first.
+func (s *Store) Versions(notePath string) []Version {
+	return s.log[NormalizePath(notePath)]
+}
+
+// Version returns the version of notePath with the given revision number.
+func (s *Store) Version(notePath string, rev int) (Version, bool) {
+	for _, v := range s.Versions(notePath) {
+		if v.Rev == rev {
+			return v, true
+		}
+	}
+	return Version{}, false
+}
+
+// Read returns the stored content of a version.
+func (s *Store) Read(v Version) ([]byte, error) {
+	data, err := os.ReadFile(s.objectPath(v.Hash))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read snapshot %s: %w", shortHash(v.Hash), err)
+	}
+	return data, nil
+}
+
+// Save writes the log to disk and removes objects no longer referenced by any
+// version (pruned by retention).
+func (s *Store) Save() error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("cannot create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal history log: %w", err)
+	}
+
+	// Write via rename so an interrupted run never leaves a truncated log.
+	tmp := s.logPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("cannot write history log: %w", err)
+	}
+	if err := os.Rename(tmp, s.logPath()); err != nil {
+		return fmt.Errorf("cannot write history log: %w", err)
+	}
+
+	return s.collectGarbage()
+}
+
+// Paths returns every note path with at least one stored version, sorted.
+func (s *Store) Paths() []string {
+	paths := make([]string, 0, len(s.log))
+	for p := range s.log {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// collectGarbage removes object files whose hash is not referenced by the log.
+func (s *Store) collectGarbage() error {
+	referenced := make(map[string]bool)
+	for _, versions := range s.log {
+		for _, v := range versions {
+			referenced[v.Hash] = true
+		}
+	}
+
+	objectsDir := filepath.Join(s.dir, "objects")
+	shards, err := os.ReadDir(objectsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot read history objects: %w", err)
+	}
+	for _, shard := range shards {
+		if !shard.IsDir() {
+			continue
+		}
+		shardDir := filepath.Join(objectsDir, shard.Name())
+		entries, err := os.ReadDir(shardDir)
+		if err != nil {
+			continue
+		}
+		remaining := len(entries)
+		for _, e := range entries {
+			if referenced[e.Name()] {
+				continue
+			}
+			if err := os.Remove(filepath.Join(shardDir, e.Name())); err == nil {
+				remaining--
+			}
+		}
+		if remaining == 0 {
+			os.Remove(shardDir)
+		}
+	}
+	return nil
+}
+
+// writeObject stores content under its hash. Existing objects are left as-is.
+func (s *Store) writeObject(hash string, content []byte) error {
+	p := s.objectPath(hash)
+	if _, err := os.Stat(p); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("cannot create history directory: %w", err)
+	}
+	if err := os.WriteFile(p, content, 0644); err != nil {
+		return fmt.Errorf("cannot write snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) logPath() string {
+	return filepath.Join(s.dir, "log.json")
+}
+
+// objectPath shards objects by the first two hex characters, git-style.
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash[:2], hash)
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// shortHash returns the first 8 characters of a hash for display.
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
+// ShortHash returns the abbreviated form of a version hash used in reports.
+func (v Version) ShortHash() string {
+	return shortHash(v.Hash)
+}
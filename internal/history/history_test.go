@@ -0,0 +1,153 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	vaultPath := t.TempDir()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Open(vaultPath, 0)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+
+	v1, added, err := s.Snapshot("Notes/a", []byte("one\n"), "append", now)
+	if err != nil || !added {
+		t.Fatalf("Snapshot() = %v, %v, want added", added, err)
+	}
+	if v1.Rev != 1 || v1.Reason != "append" || v1.Size != 4 {
+		t.Errorf("unexpected version: %+v", v1)
+	}
+
+	// Identical content is not stored twice.
+	if _, added, _ := s.Snapshot("Notes/a.md", []byte("one\n"), "append", now); added {
+		t.Error("Snapshot() of identical content added a new version")
+	}
+
+	v2, _, _ := s.Snapshot("Notes/a.md", []byte("two\n"), "triage", now.Add(time.Hour))
+	if v2.Rev != 2 {
+		t.Errorf("second Rev = %d, want 2", v2.Rev)
+	}
+
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	reopened, err := Open(vaultPath, 0)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	versions := reopened.Versions("Notes/a.md")
+	if len(versions) != 2 {
+		t.Fatalf("Versions() len = %d, want 2", len(versions))
+	}
+	data, err := reopened.Read(versions[0])
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if string(data) != "one\n" {
+		t.Errorf("Read() = %q, want %q", data, "one\n")
+	}
+}
+
+func TestSnapshot_RetentionPrunesObjects(t *testing.T) {
+	vaultPath := t.TempDir()
+	now := time.Now()
+
+	s, _ := Open(vaultPath, 2)
+	var first Version
+	for i, body := range []string{"a", "b", "c"} {
+		v, _, err := s.Snapshot("n.md", []byte(body), "", now.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("Snapshot() error: %v", err)
+		}
+		if i == 0 {
+			first = v
+		}
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	versions := s.Versions("n.md")
+	if len(versions) != 2 || versions[0].Rev != 2 || versions[1].Rev != 3 {
+		t.Fatalf("Versions() = %+v, want revs 2 and 3", versions)
+	}
+	if _, err := os.Stat(filepath.Join(Dir(vaultPath), "objects", first.Hash[:2], first.Hash)); !os.IsNotExist(err) {
+		t.Error("pruned object was not garbage collected")
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Notes/a", "Notes/a.md"},
+		{"Notes/a.md", "Notes/a.md"},
+		{"./Notes/a.md", "Notes/a.md"},
+	}
+	for _, tt := range tests {
+		if got := NormalizePath(tt.in); got != tt.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "title\n\none\ntwo\nthree\n"
+	b := "title\n\none\n2\nthree\nfour\n"
+
+	got := UnifiedDiff("a", "b", a, b)
+	want := strings.Join([]string{
+		"--- a",
+		"+++ b",
+		"@@ -1,5 +1,6 @@",
+		" title",
+		" ",
+		" one",
+		"-two",
+		"+2",
+		" three",
+		"+four",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a' + i))
+		a = append(a, line)
+		b = append(b, line)
+	}
+	b[1] = "B"
+	b[18] = "S"
+
+	got := UnifiedDiff("a", "b", strings.Join(a, "\n")+"\n", strings.Join(b, "\n")+"\n")
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Errorf("expected 2 hunks, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@") || !strings.Contains(got, "@@ -16,5 +16,5 @@") {
+		t.Errorf("unexpected hunk headers:\n%s", got)
+	}
+}
+
+func TestUnifiedDiff_Identical(t *testing.T) {
+	if got := UnifiedDiff("a", "b", "same\n", "same\n"); got != "" {
+		t.Errorf("UnifiedDiff() of identical content = %q, want empty", got)
+	}
+}
+
+func TestUnifiedDiff_FromEmpty(t *testing.T) {
+	got := UnifiedDiff("a", "b", "", "x\ny\n")
+	if !strings.Contains(got, "@@ -0,0 +1,2 @@\n+x\n+y\n") {
+		t.Errorf("UnifiedDiff() from empty =\n%s", got)
+	}
+}
@@ -0,0 +1,66 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MetricPoint is one recorded value of a metric.
+type MetricPoint struct {
+	Time   time.Time
+	Source string // command that recorded it: maintain or health
+	Name   string
+	Value  float64
+}
+
+// RecordMetrics stores a snapshot of metrics taken by source at the given
+// time. Recording the same source at the same second again replaces it.
+func (s *Store) RecordMetrics(source string, at time.Time, values map[string]float64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record metrics: %w", err)
+	}
+	defer tx.Rollback()
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, err := tx.Exec(`
+			INSERT INTO metrics (recorded_at, source, name, value) VALUES (?, ?, ?, ?)
+			ON CONFLICT(recorded_at, source, name) DO UPDATE SET value = excluded.value
+		`, at.Unix(), source, name, values[name])
+		if err != nil {
+			return fmt.Errorf("failed to record metrics: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// MetricsSince returns the metrics recorded at or after since, oldest first.
+func (s *Store) MetricsSince(since time.Time) ([]MetricPoint, error) {
+	rows, err := s.db.Query(`
+		SELECT recorded_at, source, name, value FROM metrics
+		WHERE recorded_at >= ?
+		ORDER BY recorded_at, source, name
+	`, since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var points []MetricPoint
+	for rows.Next() {
+		var p MetricPoint
+		var at int64
+		if err := rows.Scan(&at, &p.Source, &p.Name, &p.Value); err != nil {
+			return nil, err
+		}
+		p.Time = time.Unix(at, 0)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
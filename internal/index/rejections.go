@@ -0,0 +1,50 @@
+package index
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of rejected suggestions.
+const (
+	RejectLink = "link" // source and target are note paths, in sorted order
+	RejectTag  = "tag"  // source is a note path, target a lower-case tag
+)
+
+// Rejection is a suggestion the user turned down.
+type Rejection struct {
+	Source string
+	Target string
+}
+
+// RejectSuggestion records that a suggestion was rejected. Rejecting the
+// same suggestion again only updates its time.
+func (s *Store) RejectSuggestion(kind, source, target string, at time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO rejections (kind, source, target, rejected_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(kind, source, target) DO UPDATE SET rejected_at = excluded.rejected_at
+	`, kind, source, target, at.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to record rejection: %w", err)
+	}
+	return nil
+}
+
+// Rejections returns the rejected suggestions of a kind.
+func (s *Store) Rejections(kind string) (map[Rejection]bool, error) {
+	rows, err := s.db.Query("SELECT source, target FROM rejections WHERE kind = ?", kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rejections: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[Rejection]bool)
+	for rows.Next() {
+		var r Rejection
+		if err := rows.Scan(&r.Source, &r.Target); err != nil {
+			return nil, err
+		}
+		result[r] = true
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,137 @@
+package index
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/review"
+)
+
+// ReviewRow is a note with its spaced-repetition state.
+type ReviewRow struct {
+	Path  string
+	Title string
+	Body  string
+	State review.State
+}
+
+// GetReview returns the review state of a note. ok is false when the note
+// has never been reviewed.
+func (s *Store) GetReview(path string) (st review.State, ok bool, err error) {
+	var due, last int64
+	err = s.db.QueryRow(
+		"SELECT ease, interval, reps, lapses, due, last_review FROM reviews WHERE path = ?", path,
+	).Scan(&st.Ease, &st.Interval, &st.Reps, &st.Lapses, &due, &last)
+	if err == sql.ErrNoRows {
+		return review.New(), false, nil
+	}
+	if err != nil {
+		return review.State{}, false, fmt.Errorf("failed to read review state: %w", err)
+	}
+	st.Due, st.LastReview = time.Unix(due, 0), time.Unix(last, 0)
+	return st, true, nil
+}
+
+// SaveReview stores the review state of a note.
+func (s *Store) SaveReview(path string, st review.State) error {
+	_, err := s.db.Exec(`
+		INSERT INTO reviews (path, ease, interval, reps, lapses, due, last_review)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			ease = excluded.ease, interval = excluded.interval, reps = excluded.reps,
+			lapses = excluded.lapses, due = excluded.due, last_review = excluded.last_review
+	`, path, st.Ease, st.Interval, st.Reps, st.Lapses, st.Due.Unix(), st.LastReview.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to save review state: %w", err)
+	}
+	return nil
+}
+
+// MoveReview carries a note's review state over to its new path. A note
+// already scheduled at the new path keeps its own state.
+func (s *Store) MoveReview(from, to string) error {
+	if _, err := s.db.Exec("UPDATE OR IGNORE reviews SET path = ? WHERE path = ?", to, from); err != nil {
+		return fmt.Errorf("failed to move review state: %w", err)
+	}
+	return s.DeleteReview(from)
+}
+
+// DeleteReview forgets the review state of a note that no longer exists.
+func (s *Store) DeleteReview(path string) error {
+	if _, err := s.db.Exec("DELETE FROM reviews WHERE path = ?", path); err != nil {
+		return fmt.Errorf("failed to delete review state: %w", err)
+	}
+	return nil
+}
+
+// DueReviews returns indexed notes whose review is due at or before dueBy,
+// most overdue first.
+func (s *Store) DueReviews(dueBy time.Time) ([]ReviewRow, error) {
+	rows, err := s.db.Query(`
+		SELECT r.path, n.title, n.body, r.ease, r.interval, r.reps, r.lapses, r.due, r.last_review
+		FROM reviews r JOIN notes n ON n.path = r.path
+		WHERE r.due <= ?
+		ORDER BY r.due, r.path
+	`, dueBy.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ReviewRow
+	for rows.Next() {
+		var r ReviewRow
+		var due, last int64
+		if err := rows.Scan(&r.Path, &r.Title, &r.Body, &r.State.Ease, &r.State.Interval,
+			&r.State.Reps, &r.State.Lapses, &due, &last); err != nil {
+			return nil, err
+		}
+		r.State.Due, r.State.LastReview = time.Unix(due, 0), time.Unix(last, 0)
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// UnreviewedNotes returns up to limit randomly chosen notes that have never
+// been reviewed and were last modified before olderThan.
+func (s *Store) UnreviewedNotes(olderThan int64, limit int) ([]NoteRow, error) {
+	rows, err := s.db.Query(`
+		SELECT path, title, body, mod_time FROM notes
+		WHERE mod_time < ? AND mod_time > 0 AND path NOT IN (SELECT path FROM reviews)
+		ORDER BY RANDOM() LIMIT ?
+	`, olderThan, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unreviewed notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []NoteRow
+	for rows.Next() {
+		var n NoteRow
+		if err := rows.Scan(&n.Path, &n.Title, &n.Body, &n.ModTime); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// ReviewedSince returns the paths of notes reviewed at or after since.
+func (s *Store) ReviewedSince(since int64) (map[string]bool, error) {
+	rows, err := s.db.Query("SELECT path FROM reviews WHERE last_review >= ?", since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent reviews: %w", err)
+	}
+	defer rows.Close()
+
+	paths := make(map[string]bool)
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		paths[p] = true
+	}
+	return paths, rows.Err()
+}
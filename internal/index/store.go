@@ -28,6 +28,7 @@ type NoteRow struct {
 	Tags      string // comma-separated
 	Headings  string // newline-separated
 	Wikilinks string // comma-separated
+	Aliases   string // comma-separated
 	Body      string
 	ModTime   int64
 	Embedding []float32
@@ -73,13 +74,27 @@ func (s *Store) createSchema() error {
 			wikilinks TEXT NOT NULL DEFAULT '',
 			body      TEXT NOT NULL DEFAULT '',
 			mod_time  INTEGER NOT NULL DEFAULT 0,
-			embedding BLOB
+			embedding BLOB,
+			aliases   TEXT NOT NULL DEFAULT ''
 		)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to create notes table: %w", err)
 	}
 
+	// Columns added after the initial schema. Databases created by older
+	// versions are migrated in place, and their notes are marked stale so
+	// the next 'obsidian index' run fills in the new fields.
+	added, err := s.addColumnIfMissing("notes", "aliases", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return err
+	}
+	if added {
+		if _, err := s.db.Exec("UPDATE notes SET mod_time = 0"); err != nil {
+			return fmt.Errorf("failed to reset mod times after migration: %w", err)
+		}
+	}
+
 	// FTS5 virtual table for keyword search over title, tags, headings, body
 	_, err = s.db.Exec(`
 		CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(
@@ -96,6 +111,53 @@ func (s *Store) createSchema() error {
 		return fmt.Errorf("failed to create FTS5 table: %w", err)
 	}
 
+	// Spaced-repetition state per note (see reviews.go). Kept separate from
+	// notes so re-indexing never resets a schedule.
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS reviews (
+			path        TEXT PRIMARY KEY,
+			ease        REAL NOT NULL,
+			interval    INTEGER NOT NULL,
+			reps        INTEGER NOT NULL DEFAULT 0,
+			lapses      INTEGER NOT NULL DEFAULT 0,
+			due         INTEGER NOT NULL,
+			last_review INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create reviews table: %w", err)
+	}
+
+	// Enrich suggestions the user rejected (see rejections.go), so they are
+	// not suggested again. Kept separate from notes like reviews.
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS rejections (
+			kind        TEXT NOT NULL,
+			source      TEXT NOT NULL,
+			target      TEXT NOT NULL,
+			rejected_at INTEGER NOT NULL,
+			PRIMARY KEY (kind, source, target)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create rejections table: %w", err)
+	}
+
+	// Health metrics recorded by each maintain and health run (see
+	// metrics.go), one row per metric, for trends over time.
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS metrics (
+			recorded_at INTEGER NOT NULL,
+			source      TEXT NOT NULL,
+			name        TEXT NOT NULL,
+			value       REAL NOT NULL,
+			PRIMARY KEY (recorded_at, source, name)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics table: %w", err)
+	}
+
 	// Triggers to keep FTS5 in sync with the notes table
 	triggers := []string{
 		`CREATE TRIGGER IF NOT EXISTS notes_ai AFTER INSERT ON notes BEGIN
@@ -122,6 +184,39 @@ func (s *Store) createSchema() error {
 	return nil
 }
 
+// addColumnIfMissing adds a column to table unless it already exists.
+// Returns true when the column was added.
+func (s *Store) addColumnIfMissing(table, column, decl string) (bool, error) {
+	rows, err := s.db.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return false, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	rows.Close()
+
+	if _, err := s.db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + decl); err != nil {
+		return false, fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
+	return true, nil
+}
+
 // GetModTime returns the stored mod_time for a note path, or 0 if not indexed.
 func (s *Store) GetModTime(path string) (int64, error) {
 	var modTime int64
@@ -132,6 +227,16 @@ func (s *Store) GetModTime(path string) (int64, error) {
 	return modTime, err
 }
 
+// GetBody returns the stored body of a note, or "" if not indexed.
+func (s *Store) GetBody(path string) (string, error) {
+	var body string
+	err := s.db.QueryRow("SELECT body FROM notes WHERE path = ?", path).Scan(&body)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return body, err
+}
+
 // GetAllPaths returns all indexed note paths.
 func (s *Store) GetAllPaths() (map[string]bool, error) {
 	rows, err := s.db.Query("SELECT path FROM notes")
@@ -159,8 +264,8 @@ func (s *Store) UpsertNote(note *NoteRow) error {
 	}
 
 	_, err := s.db.Exec(`
-		INSERT INTO notes (path, title, tags, headings, wikilinks, body, mod_time, embedding)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO notes (path, title, tags, headings, wikilinks, body, mod_time, embedding, aliases)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(path) DO UPDATE SET
 			title     = excluded.title,
 			tags      = excluded.tags,
@@ -168,12 +273,15 @@ func (s *Store) UpsertNote(note *NoteRow) error {
 			wikilinks = excluded.wikilinks,
 			body      = excluded.body,
 			mod_time  = excluded.mod_time,
-			embedding = excluded.embedding
-	`, note.Path, note.Title, note.Tags, note.Headings, note.Wikilinks, note.Body, note.ModTime, embBlob)
+			embedding = excluded.embedding,
+			aliases   = excluded.aliases
+	`, note.Path, note.Title, note.Tags, note.Headings, note.Wikilinks, note.Body, note.ModTime, embBlob, note.Aliases)
 	return err
 }
 
-// DeleteNote removes a note from the index.
+// DeleteNote removes a note from the index. Its review state is kept, so a
+// note that is re-indexed under a new path can keep its schedule; see
+// MoveReview and DeleteReview.
 func (s *Store) DeleteNote(path string) error {
 	_, err := s.db.Exec("DELETE FROM notes WHERE path = ?", path)
 	return err
@@ -222,6 +330,28 @@ func (s *Store) SearchKeyword(query string, limit int) ([]SearchResult, error) {
 	return results, rows.Err()
 }
 
+// NotesMentioning returns the paths of notes whose body contains phrase as
+// an FTS5 phrase: the same tokens in order, ignoring case and punctuation.
+// Callers check the text itself; this only narrows the notes to read.
+func (s *Store) NotesMentioning(phrase string) ([]string, error) {
+	query := `body : "` + strings.ReplaceAll(phrase, `"`, `""`) + `"`
+	rows, err := s.db.Query("SELECT path FROM notes_fts WHERE notes_fts MATCH ?", query)
+	if err != nil {
+		return nil, fmt.Errorf("FTS5 search failed: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	return paths, rows.Err()
+}
+
 // SearchSemantic performs vector similarity search using cosine similarity.
 func (s *Store) SearchSemantic(queryEmbedding []float32, limit int) ([]SearchResult, error) {
 	rows, err := s.db.Query("SELECT path, title, embedding FROM notes WHERE embedding IS NOT NULL")
@@ -381,7 +511,7 @@ func sqrt32(x float32) float32 {
 
 // GetAllNoteRows returns all indexed notes with their metadata and embeddings.
 func (s *Store) GetAllNoteRows() ([]NoteRow, error) {
-	rows, err := s.db.Query("SELECT path, title, tags, headings, wikilinks, body, mod_time, embedding FROM notes")
+	rows, err := s.db.Query("SELECT path, title, tags, headings, wikilinks, body, mod_time, embedding, aliases FROM notes")
 	if err != nil {
 		return nil, err
 	}
@@ -391,7 +521,7 @@ func (s *Store) GetAllNoteRows() ([]NoteRow, error) {
 	for rows.Next() {
 		var n NoteRow
 		var embBlob []byte
-		if err := rows.Scan(&n.Path, &n.Title, &n.Tags, &n.Headings, &n.Wikilinks, &n.Body, &n.ModTime, &embBlob); err != nil {
+		if err := rows.Scan(&n.Path, &n.Title, &n.Tags, &n.Headings, &n.Wikilinks, &n.Body, &n.ModTime, &embBlob, &n.Aliases); err != nil {
 			return nil, err
 		}
 		n.Embedding = decodeEmbedding(embBlob)
@@ -448,11 +578,15 @@ func (s *Store) GetModTimes(paths []string) (map[string]int64, error) {
 	return result, rows.Err()
 }
 
-// RandomOldNotes returns up to limit randomly selected notes with mod_time < olderThan.
+// RandomOldNotes returns up to limit randomly selected notes with mod_time <
+// olderThan that have not been reviewed since olderThan.
 func (s *Store) RandomOldNotes(olderThan int64, limit int) ([]NoteRow, error) {
 	rows, err := s.db.Query(
-		"SELECT path, title, body, mod_time FROM notes WHERE mod_time < ? AND mod_time > 0 ORDER BY RANDOM() LIMIT ?",
-		olderThan, limit,
+		`SELECT path, title, body, mod_time FROM notes
+		WHERE mod_time < ? AND mod_time > 0
+			AND path NOT IN (SELECT path FROM reviews WHERE last_review >= ?)
+		ORDER BY RANDOM() LIMIT ?`,
+		olderThan, olderThan, limit,
 	)
 	if err != nil {
 		return nil, err
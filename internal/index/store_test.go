@@ -1,9 +1,16 @@
 package index
 
 import (
+	"database/sql"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/review"
 )
 
 func TestOpenAndClose(t *testing.T) {
@@ -93,6 +100,36 @@ func TestDeleteNote(t *testing.T) {
 	}
 }
 
+func TestMoveReview(t *testing.T) {
+	store := openTestStore(t)
+	defer store.Close()
+
+	now := time.Unix(1700000000, 0)
+	st := review.New()
+	st.Reps, st.Due, st.LastReview = 2, now, now
+	store.SaveReview("Inbox/a.md", st)
+	store.SaveReview("Ideas/b.md", review.New())
+
+	if err := store.MoveReview("Inbox/a.md", "Ideas/a.md"); err != nil {
+		t.Fatalf("MoveReview failed: %v", err)
+	}
+	if got, ok, _ := store.GetReview("Ideas/a.md"); !ok || got.Reps != 2 {
+		t.Errorf("moved review = %+v, %v", got, ok)
+	}
+	if _, ok, _ := store.GetReview("Inbox/a.md"); ok {
+		t.Error("review left at the old path")
+	}
+
+	// A note already scheduled at the destination keeps its own state.
+	store.SaveReview("Inbox/b.md", st)
+	if err := store.MoveReview("Inbox/b.md", "Ideas/b.md"); err != nil {
+		t.Fatalf("MoveReview failed: %v", err)
+	}
+	if got, _, _ := store.GetReview("Ideas/b.md"); got.Reps != 0 {
+		t.Errorf("destination review overwritten: %+v", got)
+	}
+}
+
 func TestNoteCount(t *testing.T) {
 	store := openTestStore(t)
 	defer store.Close()
@@ -175,6 +212,30 @@ func TestSearchKeyword(t *testing.T) {
 	}
 }
 
+func TestNotesMentioning(t *testing.T) {
+	store := openTestStore(t)
+	defer store.Close()
+
+	for _, row := range []NoteRow{
+		{Path: "a.md", Title: "Vector Search", Body: "Notes on vector search engines."},
+		{Path: "b.md", Title: "B", Body: "A search for vectors, then VECTOR-SEARCH tuning."},
+		{Path: "c.md", Title: "C", Body: "Search the vector space."},
+	} {
+		if err := store.UpsertNote(&row); err != nil {
+			t.Fatalf("UpsertNote: %v", err)
+		}
+	}
+
+	got, err := store.NotesMentioning(`Vector "Search"`)
+	if err != nil {
+		t.Fatalf("NotesMentioning failed: %v", err)
+	}
+	sort.Strings(got)
+	if want := []string{"a.md", "b.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NotesMentioning() = %v, want %v", got, want)
+	}
+}
+
 func TestSearchSemantic(t *testing.T) {
 	store := openTestStore(t)
 	defer store.Close()
@@ -283,3 +344,85 @@ func openTestStore(t *testing.T) *Store {
 	}
 	return store
 }
+
+func TestOpen_MigratesAliasesColumn(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+
+	// Simulate a database created before the aliases column existed.
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE notes (
+		path TEXT PRIMARY KEY, title TEXT NOT NULL DEFAULT '', tags TEXT NOT NULL DEFAULT '',
+		headings TEXT NOT NULL DEFAULT '', wikilinks TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL DEFAULT '', mod_time INTEGER NOT NULL DEFAULT 0, embedding BLOB)`); err != nil {
+		t.Fatalf("create old schema: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO notes (path, mod_time) VALUES ('a.md', 42)`); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	db.Close()
+
+	store, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed on old schema: %v", err)
+	}
+	defer store.Close()
+
+	// Existing rows are marked stale so the next index run refills them.
+	if mt, _ := store.GetModTime("a.md"); mt != 0 {
+		t.Errorf("mod_time after migration = %d, want 0", mt)
+	}
+
+	if err := store.UpsertNote(&NoteRow{Path: "b.md", Aliases: "Bee, B", ModTime: 1}); err != nil {
+		t.Fatalf("UpsertNote failed: %v", err)
+	}
+	rows, err := store.GetAllNoteRows()
+	if err != nil {
+		t.Fatalf("GetAllNoteRows failed: %v", err)
+	}
+	for _, r := range rows {
+		if r.Path == "b.md" && r.Aliases != "Bee, B" {
+			t.Errorf("Aliases = %q, want %q", r.Aliases, "Bee, B")
+		}
+	}
+}
+
+func TestRecordMetrics(t *testing.T) {
+	store := openTestStore(t)
+	defer store.Close()
+
+	day := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := store.RecordMetrics("maintain", day, map[string]float64{"health_score": 90, "broken_links": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordMetrics("health", day.Add(24*time.Hour), map[string]float64{"orphan_notes": 4}); err != nil {
+		t.Fatal(err)
+	}
+	// Recording the same snapshot again replaces its values.
+	if err := store.RecordMetrics("maintain", day, map[string]float64{"health_score": 85}); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := store.MetricsSince(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range points {
+		got = append(got, p.Time.UTC().Format("01-02")+" "+p.Source+" "+p.Name+"="+formatValue(p.Value))
+	}
+	want := []string{"03-01 maintain broken_links=2", "03-01 maintain health_score=85", "03-02 health orphan_notes=4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MetricsSince() = %v, want %v", got, want)
+	}
+
+	if points, _ := store.MetricsSince(day.Add(time.Hour)); len(points) != 1 {
+		t.Errorf("MetricsSince(later) = %v, want only the health snapshot", points)
+	}
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
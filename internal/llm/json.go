@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// jsonRepairAttempts is how many times CompleteJSON asks the model to fix an
+// invalid reply before giving up.
+const jsonRepairAttempts = 2
+
+// CompleteJSON sends a request whose reply must be JSON, decodes it into out
+// (a pointer) and checks it with validate (optional). When the reply does not
+// parse or validate, the model is shown its reply and the error and asked to
+// correct it, up to jsonRepairAttempts times.
+func (c *Client) CompleteJSON(ctx context.Context, req Request, out any, validate func() error) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("CompleteJSON: out must be a non-nil pointer")
+	}
+
+	req.Messages = append([]Message(nil), req.Messages...)
+	var lastErr error
+	for attempt := 0; attempt <= jsonRepairAttempts; attempt++ {
+		resp, err := c.Complete(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		target.Elem().Set(reflect.Zero(target.Elem().Type()))
+		lastErr = json.Unmarshal([]byte(ExtractJSON(resp.Text)), out)
+		if lastErr == nil && validate != nil {
+			lastErr = validate()
+		}
+		if lastErr == nil {
+			return nil
+		}
+
+		req.Messages = append(req.Messages,
+			Message{Role: "assistant", Content: resp.Text},
+			Message{Role: "user", Content: fmt.Sprintf(
+				"That reply was not valid: %v. Reply again with only the corrected JSON, no prose or code fences.", lastErr)},
+		)
+	}
+	return fmt.Errorf("invalid JSON from model after %d attempts: %w", jsonRepairAttempts+1, lastErr)
+}
+
+// ExtractJSON returns the JSON value in a model reply, dropping code fences
+// and any prose around the outermost object or array.
+func ExtractJSON(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+		if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+			text = text[nl+1:] // drop the language tag line
+		}
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+		text = strings.TrimSpace(text)
+	}
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		return text
+	}
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return text
+	}
+	closer := byte('}')
+	if text[start] == '[' {
+		closer = ']'
+	}
+	if end := strings.LastIndexByte(text, closer); end > start {
+		return text[start : end+1]
+	}
+	return text[start:]
+}
@@ -0,0 +1,201 @@
+// Package llm is a small provider-neutral client for chat-style language
+// models. A Provider sends one request; Client adds per-attempt timeouts,
+// retries with backoff, JSON output validation and repair, and token usage
+// accounting on top.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+)
+
+// Client defaults, used when settings leave a value unset.
+const (
+	DefaultTimeout = 15 * time.Second
+	DefaultRetries = 2
+	DefaultBackoff = 500 * time.Millisecond
+)
+
+// Message is one chat turn.
+type Message struct {
+	Role    string `json:"role"` // "user" or "assistant"
+	Content string `json:"content"`
+}
+
+// Request is a provider-neutral completion request.
+type Request struct {
+	Model     string // filled from the client when empty
+	System    string
+	Messages  []Message
+	MaxTokens int
+}
+
+// Usage counts model calls and tokens.
+type Usage struct {
+	Calls        int `json:"calls"`
+	Retries      int `json:"retries,omitempty"`
+	Failures     int `json:"failures,omitempty"`
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Response is a completion result.
+type Response struct {
+	Text  string
+	Model string
+	Usage Usage // InputTokens and OutputTokens for this call
+}
+
+// Provider sends a single completion request to a model API.
+type Provider interface {
+	Name() string
+	DefaultModel() string
+	Complete(ctx context.Context, req Request) (Response, error)
+}
+
+// StatusError is an HTTP error from a provider API.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error (HTTP %d): %s", e.StatusCode, e.Message)
+}
+
+// retryable reports whether a failed attempt is worth repeating: rate limits,
+// server errors, timeouts and network failures.
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode == 429 || se.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// Options configures a Client.
+type Options struct {
+	Model   string        // default model; empty means the provider default
+	Timeout time.Duration // per attempt; 0 means DefaultTimeout
+	Retries int           // retries after a failed attempt; 0 means DefaultRetries, <0 means none
+	Backoff time.Duration // first retry delay, doubled per retry; 0 means DefaultBackoff
+}
+
+// Client sends requests through a Provider with timeouts, retries and usage
+// accounting. It is safe for concurrent use.
+type Client struct {
+	provider Provider
+	opts     Options
+
+	mu    sync.Mutex
+	usage Usage
+}
+
+// New returns a client for provider.
+func New(provider Provider, opts Options) *Client {
+	if opts.Model == "" {
+		opts.Model = provider.DefaultModel()
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Retries == 0 {
+		opts.Retries = DefaultRetries
+	} else if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	return &Client{provider: provider, opts: opts}
+}
+
+// FromSettings builds a client from resolved config settings. It returns
+// (nil, nil) when no provider is configured.
+func FromSettings(s config.LLMSettings) (*Client, error) {
+	var p Provider
+	switch s.Provider {
+	case "":
+		return nil, nil
+	case config.LLMProviderAnthropic:
+		if s.APIKey == "" {
+			return nil, fmt.Errorf("llm provider anthropic needs llm_api_key or ANTHROPIC_API_KEY")
+		}
+		p = NewAnthropic(s.APIKey, s.BaseURL)
+	case config.LLMProviderOpenAI:
+		if s.Model == "" {
+			return nil, fmt.Errorf("llm provider openai needs llm_model")
+		}
+		p = NewOpenAI(s.APIKey, s.BaseURL)
+	default:
+		return nil, fmt.Errorf("unknown llm provider %q (use anthropic, openai or none)", s.Provider)
+	}
+	return New(p, Options{Model: s.Model, Timeout: s.Timeout, Retries: s.Retries}), nil
+}
+
+// Provider returns the client's provider name.
+func (c *Client) Provider() string { return c.provider.Name() }
+
+// Model returns the default model used for requests.
+func (c *Client) Model() string { return c.opts.Model }
+
+// Usage returns the accumulated usage.
+func (c *Client) Usage() Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usage
+}
+
+// Complete sends a request, retrying retryable failures with exponential
+// backoff. Each attempt has its own timeout.
+func (c *Client) Complete(ctx context.Context, req Request) (Response, error) {
+	if req.Model == "" {
+		req.Model = c.opts.Model
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = 1024
+	}
+
+	delay := c.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+		resp, err := c.provider.Complete(attemptCtx, req)
+		cancel()
+
+		c.mu.Lock()
+		c.usage.Calls++
+		c.usage.InputTokens += resp.Usage.InputTokens
+		c.usage.OutputTokens += resp.Usage.OutputTokens
+		if err != nil {
+			c.usage.Failures++
+		}
+		c.mu.Unlock()
+
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= c.opts.Retries || !retryable(err) || ctx.Err() != nil {
+			return Response{}, fmt.Errorf("%s: %w", c.provider.Name(), err)
+		}
+
+		c.mu.Lock()
+		c.usage.Retries++
+		c.mu.Unlock()
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return Response{}, ctx.Err()
+		}
+		delay *= 2
+	}
+}
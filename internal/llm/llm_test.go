@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/config"
+)
+
+func TestAnthropic_Complete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "key" {
+			t.Errorf("unexpected request %s key=%q", r.URL.Path, r.Header.Get("x-api-key"))
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["system"] != "sys" || body["model"] != "claude-haiku-4-5-20251001" {
+			t.Errorf("request body = %v", body)
+		}
+		fmt.Fprint(w, `{"model":"m","content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":12,"output_tokens":3}}`)
+	}))
+	defer srv.Close()
+
+	c := New(NewAnthropic("key", srv.URL), Options{})
+	resp, err := c.Complete(context.Background(), Request{System: "sys", Messages: []Message{{Role: "user", Content: "x"}}})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if resp.Text != "hi" || resp.Usage.InputTokens != 12 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if u := c.Usage(); u.Calls != 1 || u.InputTokens != 12 || u.OutputTokens != 3 {
+		t.Errorf("usage = %+v", u)
+	}
+}
+
+func TestOpenAI_Complete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Authorization sent without a key")
+		}
+		var body struct {
+			Model    string    `json:"model"`
+			Messages []Message `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "llama3" || len(body.Messages) != 2 || body.Messages[0].Role != "system" {
+			t.Errorf("request = %+v", body)
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":5,"completion_tokens":1}}`)
+	}))
+	defer srv.Close()
+
+	c, err := FromSettings(config.LLMSettings{Provider: "openai", Model: "llama3", BaseURL: srv.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("FromSettings() error: %v", err)
+	}
+	resp, err := c.Complete(context.Background(), Request{System: "s", Messages: []Message{{Role: "user", Content: "x"}}})
+	if err != nil || resp.Text != "ok" || resp.Usage.OutputTokens != 1 {
+		t.Errorf("Complete() = %+v, %v", resp, err)
+	}
+}
+
+func TestClient_RetriesRetryableErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"type":"rate_limit_error","message":"slow down"}}`)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, `<html>bad gateway</html>`)
+		default:
+			fmt.Fprint(w, `{"content":[{"type":"text","text":"done"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(NewAnthropic("key", srv.URL), Options{Backoff: time.Millisecond})
+	resp, err := c.Complete(context.Background(), Request{Messages: []Message{{Role: "user", Content: "x"}}})
+	if err != nil || resp.Text != "done" {
+		t.Fatalf("Complete() = %+v, %v", resp, err)
+	}
+	if u := c.Usage(); u.Calls != 3 || u.Retries != 2 || u.Failures != 2 {
+		t.Errorf("usage = %+v", u)
+	}
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	p := &Scripted{Replies: []ScriptedReply{{Err: &StatusError{StatusCode: 401, Message: "bad key"}}}}
+	c := New(p, Options{Backoff: time.Millisecond})
+	_, err := c.Complete(context.Background(), Request{})
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != 401 || len(p.Requests) != 1 {
+		t.Errorf("Complete() error = %v after %d requests, want one 401", err, len(p.Requests))
+	}
+}
+
+func TestCompleteJSON_Repair(t *testing.T) {
+	p := &Scripted{Replies: []ScriptedReply{
+		{Text: "Sure! Here it is"},
+		{Text: "```json\n{\"type\":\"bogus\"}\n```"},
+		{Text: `Result: {"type":"task"} hope that helps`},
+	}}
+	c := New(p, Options{})
+
+	var out struct {
+		Type string `json:"type"`
+	}
+	err := c.CompleteJSON(context.Background(), Request{Messages: []Message{{Role: "user", Content: "classify"}}}, &out, func() error {
+		if out.Type != "task" {
+			return fmt.Errorf("type %q is not allowed", out.Type)
+		}
+		return nil
+	})
+	if err != nil || out.Type != "task" {
+		t.Fatalf("CompleteJSON() = %+v, %v", out, err)
+	}
+	last := p.Requests[2].Messages
+	if len(last) != 5 || last[3].Content != "```json\n{\"type\":\"bogus\"}\n```" || !strings.Contains(last[4].Content, `type "bogus" is not allowed`) {
+		t.Errorf("repair conversation = %+v", last)
+	}
+}
+
+func TestCompleteJSON_GivesUp(t *testing.T) {
+	c := New(&Scripted{Replies: []ScriptedReply{{Text: "no json here"}}}, Options{})
+	var out map[string]any
+	if err := c.CompleteJSON(context.Background(), Request{}, &out, nil); err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("CompleteJSON() error = %v", err)
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := map[string]string{
+		`{"a":1}`:                   `{"a":1}`,
+		"```json\n{\"a\":1}\n```":   `{"a":1}`,
+		"```\n[1,2]\n```":           `[1,2]`,
+		`Here: {"a":{"b":2}} done.`: `{"a":{"b":2}}`,
+		`nothing`:                   `nothing`,
+	}
+	for in, want := range tests {
+		if got := ExtractJSON(in); got != want {
+			t.Errorf("ExtractJSON(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFromSettings(t *testing.T) {
+	if c, err := FromSettings(config.LLMSettings{}); c != nil || err != nil {
+		t.Errorf("FromSettings(empty) = %v, %v; want nil, nil", c, err)
+	}
+	if _, err := FromSettings(config.LLMSettings{Provider: "anthropic"}); err == nil {
+		t.Error("anthropic without key should fail")
+	}
+	if _, err := FromSettings(config.LLMSettings{Provider: "openai"}); err == nil {
+		t.Error("openai without model should fail")
+	}
+	if _, err := FromSettings(config.LLMSettings{Provider: "gpt"}); err == nil {
+		t.Error("unknown provider should fail")
+	}
+	c, err := FromSettings(config.LLMSettings{Provider: "anthropic", APIKey: "k"})
+	if err != nil || c.Model() != "claude-haiku-4-5-20251001" || c.Provider() != "anthropic" {
+		t.Errorf("FromSettings(anthropic) = %v, %v", c, err)
+	}
+}
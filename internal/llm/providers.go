@@ -0,0 +1,248 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxResponseBytes caps how much of a provider response is read.
+const maxResponseBytes = 4 << 20
+
+// Anthropic calls the Anthropic Messages API.
+type Anthropic struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewAnthropic returns an Anthropic provider. baseURL may be empty for the
+// public API.
+func NewAnthropic(apiKey, baseURL string) *Anthropic {
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+	return &Anthropic{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{}}
+}
+
+// Name implements Provider.
+func (a *Anthropic) Name() string { return "anthropic" }
+
+// DefaultModel implements Provider.
+func (a *Anthropic) DefaultModel() string { return "claude-haiku-4-5-20251001" }
+
+// anthropicResponse is the JSON shape returned by the Messages API.
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Complete implements Provider.
+func (a *Anthropic) Complete(ctx context.Context, req Request) (Response, error) {
+	body := map[string]any{
+		"model":      req.Model,
+		"max_tokens": req.MaxTokens,
+		"messages":   req.Messages,
+	}
+	if req.System != "" {
+		body["system"] = req.System
+	}
+	headers := map[string]string{
+		"x-api-key":         a.apiKey,
+		"anthropic-version": "2023-06-01",
+	}
+
+	var apiResp anthropicResponse
+	status, err := postJSON(ctx, a.httpClient, a.baseURL+"/v1/messages", headers, body, &apiResp)
+	if err != nil {
+		return Response{}, err
+	}
+	if apiResp.Error != nil || status/100 != 2 {
+		msg := http.StatusText(status)
+		if apiResp.Error != nil {
+			msg = apiResp.Error.Message
+		}
+		return Response{}, &StatusError{StatusCode: status, Message: msg}
+	}
+
+	resp := Response{Model: apiResp.Model}
+	resp.Usage.InputTokens = apiResp.Usage.InputTokens
+	resp.Usage.OutputTokens = apiResp.Usage.OutputTokens
+	for _, c := range apiResp.Content {
+		if c.Type == "text" {
+			resp.Text += c.Text
+		}
+	}
+	if resp.Text == "" {
+		return resp, fmt.Errorf("unexpected response format: no text content")
+	}
+	return resp, nil
+}
+
+// OpenAI calls an OpenAI-compatible chat completions API, including local
+// servers such as Ollama, llama.cpp or LM Studio.
+type OpenAI struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOpenAI returns an OpenAI-compatible provider. baseURL is the API root
+// including the version (e.g. http://localhost:11434/v1); empty means the
+// OpenAI API. apiKey may be empty for local servers.
+func NewOpenAI(apiKey, baseURL string) *OpenAI {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	return &OpenAI{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{}}
+}
+
+// Name implements Provider.
+func (o *OpenAI) Name() string { return "openai" }
+
+// DefaultModel implements Provider. OpenAI-compatible servers have no common
+// default, so the model must be configured.
+func (o *OpenAI) DefaultModel() string { return "" }
+
+// openAIResponse is the JSON shape returned by chat completions.
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Complete implements Provider.
+func (o *OpenAI) Complete(ctx context.Context, req Request) (Response, error) {
+	messages := make([]Message, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, Message{Role: "system", Content: req.System})
+	}
+	messages = append(messages, req.Messages...)
+	body := map[string]any{
+		"model":      req.Model,
+		"max_tokens": req.MaxTokens,
+		"messages":   messages,
+	}
+	headers := map[string]string{}
+	if o.apiKey != "" {
+		headers["Authorization"] = "Bearer " + o.apiKey
+	}
+
+	var apiResp openAIResponse
+	status, err := postJSON(ctx, o.httpClient, o.baseURL+"/chat/completions", headers, body, &apiResp)
+	if err != nil {
+		return Response{}, err
+	}
+	if apiResp.Error != nil || status/100 != 2 {
+		msg := http.StatusText(status)
+		if apiResp.Error != nil {
+			msg = apiResp.Error.Message
+		}
+		return Response{}, &StatusError{StatusCode: status, Message: msg}
+	}
+	if len(apiResp.Choices) == 0 {
+		return Response{}, fmt.Errorf("unexpected response format: no choices")
+	}
+
+	resp := Response{Model: apiResp.Model, Text: apiResp.Choices[0].Message.Content}
+	resp.Usage.InputTokens = apiResp.Usage.PromptTokens
+	resp.Usage.OutputTokens = apiResp.Usage.CompletionTokens
+	return resp, nil
+}
+
+// postJSON posts body as JSON and decodes the response into out. It returns
+// the HTTP status; a non-JSON error body yields a StatusError.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out any) (int, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("read response: %w", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		if resp.StatusCode/100 != 2 {
+			return resp.StatusCode, &StatusError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		}
+		return resp.StatusCode, fmt.Errorf("decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// Scripted is a Provider that replays canned replies, for tests and dry runs.
+// Each call consumes the next reply; the last one repeats when the script is
+// exhausted. Requests are recorded.
+type Scripted struct {
+	Replies  []ScriptedReply
+	Requests []Request
+
+	next int
+}
+
+// ScriptedReply is one canned provider result.
+type ScriptedReply struct {
+	Text  string
+	Err   error
+	Usage Usage
+}
+
+// Name implements Provider.
+func (s *Scripted) Name() string { return "mock" }
+
+// DefaultModel implements Provider.
+func (s *Scripted) DefaultModel() string { return "mock" }
+
+// Complete implements Provider.
+func (s *Scripted) Complete(ctx context.Context, req Request) (Response, error) {
+	s.Requests = append(s.Requests, req)
+	if len(s.Replies) == 0 {
+		return Response{}, fmt.Errorf("scripted provider has no replies")
+	}
+	r := s.Replies[min(s.next, len(s.Replies)-1)]
+	s.next++
+	if r.Err != nil {
+		return Response{Usage: r.Usage}, r.Err
+	}
+	return Response{Text: r.Text, Model: req.Model, Usage: r.Usage}, nil
+}
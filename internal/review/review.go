@@ -0,0 +1,149 @@
+// Package review schedules notes for spaced-repetition review. It implements
+// the SM-2 algorithm as adapted by Anki: each note has an ease factor and an
+// interval in days; grading a review moves the due date out by the interval
+// and adjusts the ease.
+package review
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Grade is how well a note was recalled at review.
+type Grade int
+
+// Grades, worst first.
+const (
+	Again Grade = iota + 1 // forgotten: start over
+	Hard                   // recalled with effort
+	Good                   // recalled
+	Easy                   // recalled effortlessly
+)
+
+var gradeNames = map[Grade]string{Again: "again", Hard: "hard", Good: "good", Easy: "easy"}
+
+// String returns the grade name.
+func (g Grade) String() string {
+	if name, ok := gradeNames[g]; ok {
+		return name
+	}
+	return fmt.Sprintf("Grade(%d)", int(g))
+}
+
+// ParseGrade parses a grade name, its first letter, or 1-4.
+func ParseGrade(s string) (Grade, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for g, name := range gradeNames {
+		if s == name || s == name[:1] || s == fmt.Sprint(int(g)) {
+			return g, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid grade %q (want again, hard, good or easy)", s)
+}
+
+// Scheduling constants.
+const (
+	DefaultEase = 2.5  // ease of a note that has never been reviewed
+	MinEase     = 1.3  // ease never drops below this
+	MaxInterval = 3650 // days
+	hardFactor  = 1.2  // interval multiplier for Hard
+	easyBonus   = 1.3  // extra multiplier for Easy
+)
+
+// State is the review state of one note.
+type State struct {
+	Ease       float64   `json:"ease"`
+	Interval   int       `json:"interval_days"`
+	Reps       int       `json:"reps"`   // successful reviews in a row
+	Lapses     int       `json:"lapses"` // times graded Again after a success
+	Due        time.Time `json:"due"`
+	LastReview time.Time `json:"last_review"`
+}
+
+// New returns the state of a note that has not been reviewed yet.
+func New() State {
+	return State{Ease: DefaultEase}
+}
+
+// IsNew reports whether the note has never been reviewed.
+func (s State) IsNew() bool {
+	return s.LastReview.IsZero()
+}
+
+// Schedule returns the state after grading a review at now.
+//
+// Again resets the streak and brings the note back tomorrow. The first two
+// successful reviews use fixed steps (1 and 3 days for Good; Easy skips
+// ahead). After that, Good multiplies the interval by the ease, Hard by 1.2
+// and Easy by ease × 1.3. Hard and Again lower the ease; Easy raises it.
+func Schedule(s State, g Grade, now time.Time) State {
+	if s.Ease == 0 {
+		s.Ease = DefaultEase
+	}
+	prev := float64(max(s.Interval, 1))
+	graduated := s.Reps >= 2
+
+	switch g {
+	case Again:
+		if s.Reps > 0 {
+			s.Lapses++
+		}
+		s.Reps = 0
+		s.Interval = 1
+		s.Ease -= 0.2
+	case Hard:
+		if s.Reps == 0 {
+			s.Interval = 1
+		} else {
+			s.Interval = int(math.Round(prev * hardFactor))
+		}
+		s.Reps++
+		s.Ease -= 0.15
+	case Good:
+		switch s.Reps {
+		case 0:
+			s.Interval = 1
+		case 1:
+			s.Interval = 3
+		default:
+			s.Interval = int(math.Round(prev * s.Ease))
+		}
+		s.Reps++
+	case Easy:
+		switch s.Reps {
+		case 0:
+			s.Interval = 4
+		default:
+			s.Interval = int(math.Round(prev * s.Ease * easyBonus))
+		}
+		s.Reps++
+		s.Ease += 0.15
+	}
+
+	s.Ease = math.Max(MinEase, math.Round(s.Ease*100)/100)
+	if g != Again && graduated && s.Interval <= int(prev) {
+		s.Interval = int(prev) + 1 // a success always moves the note further out
+	}
+	s.Interval = min(max(s.Interval, 1), MaxInterval)
+	s.LastReview = now
+	s.Due = startOfDay(now).AddDate(0, 0, s.Interval)
+	return s
+}
+
+// IsDue reports whether the note should be reviewed on the day of now.
+func (s State) IsDue(now time.Time) bool {
+	return !s.Due.After(EndOfDay(now))
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay returns the last instant of t's day; notes due by then are due
+// on that day.
+func EndOfDay(t time.Time) time.Time {
+	return startOfDay(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
@@ -0,0 +1,80 @@
+package review
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGrade(t *testing.T) {
+	for in, want := range map[string]Grade{"again": Again, "H": Hard, "3": Good, " easy ": Easy} {
+		if g, err := ParseGrade(in); err != nil || g != want {
+			t.Errorf("ParseGrade(%q) = %v, %v; want %v", in, g, err, want)
+		}
+	}
+	if _, err := ParseGrade("maybe"); err == nil {
+		t.Error("ParseGrade(maybe) succeeded")
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return time.Date(2026, 3, 1+n, 0, 0, 0, 0, time.UTC) }
+
+	s := New()
+	var intervals []int
+	for _, g := range []Grade{Good, Good, Good, Good} {
+		s = Schedule(s, g, now)
+		intervals = append(intervals, s.Interval)
+	}
+	if want := []int{1, 3, 8, 20}; !equalInts(intervals, want) {
+		t.Errorf("Good intervals = %v, want %v", intervals, want)
+	}
+	if s.Ease != DefaultEase || s.Reps != 4 || !s.Due.Equal(day(20)) {
+		t.Errorf("state = %+v", s)
+	}
+
+	lapsed := Schedule(s, Again, now)
+	if lapsed.Interval != 1 || lapsed.Reps != 0 || lapsed.Lapses != 1 || lapsed.Ease != 2.3 {
+		t.Errorf("Again = %+v", lapsed)
+	}
+
+	if hard := Schedule(s, Hard, now); hard.Interval != 24 || hard.Ease != 2.35 {
+		t.Errorf("Hard = %+v", hard)
+	}
+	if easy := Schedule(s, Easy, now); easy.Interval != 65 || easy.Ease != 2.65 {
+		t.Errorf("Easy = %+v", easy)
+	}
+
+	// Repeated failures bottom out at the minimum ease.
+	for range 10 {
+		s = Schedule(s, Again, now)
+	}
+	if s.Ease != MinEase || s.Lapses != 1 {
+		t.Errorf("after many Again: %+v", s)
+	}
+	// A graduated Hard review always moves the note further out.
+	s = State{Ease: MinEase, Interval: 1, Reps: 2}
+	if s = Schedule(s, Hard, now); s.Interval != 2 {
+		t.Errorf("Hard at interval 1 = %d, want 2", s.Interval)
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := State{Due: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)}
+	if s.IsDue(now) || !s.IsDue(now.AddDate(0, 0, 1)) {
+		t.Errorf("IsDue wrong around %v", s.Due)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
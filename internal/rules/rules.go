@@ -0,0 +1,442 @@
+// Package rules loads user-defined triage routing rules from the vault and
+// matches notes against them.
+//
+// Rules live in <vault>/.obsidian/triage-rules.toml and are evaluated in file
+// order; the first rule whose conditions all hold wins:
+//
+//	[[rule]]
+//	name = "oss captures"
+//	destination = "Projects/OSS/"
+//	[rule.match]
+//	source_domain = ["github.com"]
+//
+//	[[rule]]
+//	name = "meetings"
+//	type = "note"
+//	destination = "Meetings/YYYY/"
+//	add_tags = ["meeting-notes"]
+//	template = "meeting"          # Templates/meeting.md, for new notes
+//	[rule.match]
+//	tags = ["meeting"]
+//	[rule.set]
+//	project = "team-sync"
+package rules
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/toml"
+)
+
+// FileName is the rules file name inside the vault's .obsidian directory.
+const FileName = "triage-rules.toml"
+
+// Path returns the location of the rules file for a vault.
+func Path(vaultPath string) string {
+	return filepath.Join(vaultPath, ".obsidian", FileName)
+}
+
+// Rule is one routing rule: conditions that must all hold, and the actions
+// applied to a matching note.
+type Rule struct {
+	Name string `json:"name"`
+
+	// Conditions. Empty conditions are ignored; list conditions match when
+	// any element matches.
+	Frontmatter   map[string]string `json:"frontmatter,omitempty"`    // key -> value ("*" = present)
+	Body          []*regexp.Regexp  `json:"-"`                        // any regex matches the body
+	SourceDomains []string          `json:"source_domains,omitempty"` // host of the source: URL, subdomains included
+	Tags          []string          `json:"tags,omitempty"`           // note has any tag (nested tags included)
+	LLMTypes      []string          `json:"llm_types,omitempty"`      // type returned by the LLM classifier
+
+	// Actions.
+	Destination string            `json:"destination,omitempty"` // folder ("Dir/") or path template
+	Type        string            `json:"type,omitempty"`        // note type to record instead of classifying
+	AddTags     []string          `json:"add_tags,omitempty"`
+	Set         map[string]string `json:"set,omitempty"`      // frontmatter properties to set
+	Template    string            `json:"template,omitempty"` // note template for notes written to a new path
+}
+
+// Input is what a rule is matched against.
+type Input struct {
+	Frontmatter map[string]any
+	Body        string
+	Tags        []string // frontmatter and inline tags, without '#'
+	Source      string   // source: URL or origin
+	LLMType     string   // empty when no LLM classified the note
+}
+
+// Set is an ordered list of rules.
+type Set struct {
+	Rules []Rule
+}
+
+// Load reads the vault's rules file. It returns (nil, nil) when the file does
+// not exist.
+func Load(vaultPath string) (*Set, error) {
+	data, err := os.ReadFile(Path(vaultPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", FileName, err)
+	}
+	set, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", FileName, err)
+	}
+	return set, nil
+}
+
+// Parse parses rules from TOML source.
+func Parse(src string) (*Set, error) {
+	root, err := toml.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	for key := range root {
+		if key != "rule" {
+			return nil, fmt.Errorf("unknown top-level key %q (rules are declared with [[rule]])", key)
+		}
+	}
+	tables, _ := root["rule"].([]toml.Table)
+
+	set := &Set{}
+	for i, t := range tables {
+		r, err := parseRule(t)
+		if err != nil {
+			name := fmt.Sprintf("rule %d", i+1)
+			if n, ok := t["name"].(string); ok && n != "" {
+				name = fmt.Sprintf("rule %q", n)
+			}
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		set.Rules = append(set.Rules, r)
+	}
+	return set, nil
+}
+
+func parseRule(t toml.Table) (Rule, error) {
+	var r Rule
+	for key, v := range t {
+		var err error
+		switch key {
+		case "name":
+			r.Name, err = stringValue(key, v)
+		case "destination":
+			r.Destination, err = stringValue(key, v)
+		case "type":
+			r.Type, err = stringValue(key, v)
+		case "template":
+			r.Template, err = stringValue(key, v)
+		case "add_tags":
+			r.AddTags, err = listValue(key, v)
+		case "set":
+			r.Set, err = stringTable(key, v)
+		case "match":
+			m, ok := v.(toml.Table)
+			if !ok {
+				return r, fmt.Errorf("match must be a table ([rule.match])")
+			}
+			err = parseMatch(&r, m)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return r, err
+		}
+	}
+	if r.Destination == "" && r.Type == "" && r.Template == "" && len(r.AddTags) == 0 && len(r.Set) == 0 {
+		return r, fmt.Errorf("no actions (set destination, type, template, add_tags or [rule.set])")
+	}
+	for i, tag := range r.AddTags {
+		r.AddTags[i] = strings.TrimPrefix(tag, "#")
+	}
+	for key := range r.Set {
+		switch key {
+		case "type":
+			return r, fmt.Errorf("set.type is not allowed (use type)")
+		case "tags":
+			return r, fmt.Errorf("set.tags is not allowed (use add_tags)")
+		case "status", "triaged":
+			return r, fmt.Errorf("set.%s is managed by triage", key)
+		}
+	}
+	return r, nil
+}
+
+func parseMatch(r *Rule, m toml.Table) error {
+	for key, v := range m {
+		var err error
+		switch key {
+		case "frontmatter":
+			r.Frontmatter, err = stringTable("match.frontmatter", v)
+		case "body":
+			var patterns []string
+			if patterns, err = listValue("match.body", v); err != nil {
+				break
+			}
+			for _, p := range patterns {
+				re, reErr := regexp.Compile(p)
+				if reErr != nil {
+					return fmt.Errorf("match.body: %w", reErr)
+				}
+				r.Body = append(r.Body, re)
+			}
+		case "source_domain":
+			r.SourceDomains, err = listValue("match.source_domain", v)
+		case "tags":
+			r.Tags, err = listValue("match.tags", v)
+			for i, tag := range r.Tags {
+				r.Tags[i] = strings.TrimPrefix(tag, "#")
+			}
+		case "llm_type":
+			r.LLMTypes, err = listValue("match.llm_type", v)
+		default:
+			err = fmt.Errorf("unknown match key %q", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stringValue accepts a single string.
+func stringValue(key string, v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return s, nil
+}
+
+// listValue accepts a string or an array of strings.
+func listValue(key string, v any) ([]string, error) {
+	switch val := v.(type) {
+	case string:
+		return []string{val}, nil
+	case []string:
+		return val, nil
+	}
+	return nil, fmt.Errorf("%s must be a string or an array of strings", key)
+}
+
+// stringTable accepts a table of string values.
+func stringTable(key string, v any) (map[string]string, error) {
+	t, ok := v.(toml.Table)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a table", key)
+	}
+	out := make(map[string]string, len(t))
+	for k, val := range t {
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s must be a string", key, k)
+		}
+		out[k] = s
+	}
+	return out, nil
+}
+
+// Match returns the first rule matching the input, or nil.
+func (s *Set) Match(in Input) *Rule {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Rules {
+		if s.Rules[i].Matches(in) {
+			return &s.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Matches reports whether every condition of the rule holds for the input.
+func (r *Rule) Matches(in Input) bool {
+	for key, want := range r.Frontmatter {
+		if !frontmatterMatches(in.Frontmatter[key], want) {
+			return false
+		}
+	}
+	if len(r.Body) > 0 && !anyRegex(r.Body, in.Body) {
+		return false
+	}
+	if len(r.SourceDomains) > 0 && !domainMatches(in.Source, r.SourceDomains) {
+		return false
+	}
+	if len(r.Tags) > 0 && !tagsMatch(in.Tags, r.Tags) {
+		return false
+	}
+	if len(r.LLMTypes) > 0 && !containsFold(r.LLMTypes, in.LLMType) {
+		return false
+	}
+	return true
+}
+
+func frontmatterMatches(value any, want string) bool {
+	var values []string
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		values = []string{v}
+	case []string:
+		values = v
+	default:
+		values = []string{fmt.Sprint(v)}
+	}
+	if want == "*" {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), want) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyRegex(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// SourceDomain returns the lowercased host of a source URL without "www.",
+// or "" when the source is not a URL.
+func SourceDomain(source string) string {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return ""
+	}
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+func domainMatches(source string, domains []string) bool {
+	host := SourceDomain(source)
+	if host == "" {
+		return false
+	}
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "www.")
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagsMatch reports whether any note tag equals a wanted tag or is nested
+// under it (meeting matches meeting/weekly).
+func tagsMatch(have, want []string) bool {
+	for _, h := range have {
+		h = strings.ToLower(strings.TrimPrefix(h, "#"))
+		for _, w := range want {
+			w = strings.ToLower(w)
+			if h == w || strings.HasPrefix(h, w+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// DestinationVars are the values available to destination templates.
+type DestinationVars struct {
+	Date   time.Time // note creation date (falls back to now)
+	Type   string
+	Slug   string // filename slug without .md
+	Domain string // source domain, "" when none
+}
+
+var (
+	placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z]+)\s*\}\}`)
+	dateSegmentRe = regexp.MustCompile(`^(YYYY|MM|DD|[-_. ])+$`)
+)
+
+// RenderDestination expands the rule's destination template into a
+// vault-relative note path. Placeholders are {{YYYY}}, {{MM}}, {{DD}},
+// {{type}}, {{slug}} and {{domain}}; a path segment made only of YYYY, MM and
+// DD (such as "YYYY" or "YYYY-MM") is expanded without braces. A destination
+// ending in "/" is a folder and receives "{{slug}}.md"; ".md" is appended when
+// missing. Returns "" when the rule has no destination.
+func (r *Rule) RenderDestination(v DestinationVars) string {
+	if r.Destination == "" {
+		return ""
+	}
+	tmpl := filepath.ToSlash(r.Destination)
+	if strings.HasSuffix(tmpl, "/") {
+		tmpl += "{{slug}}"
+	}
+
+	segments := strings.Split(tmpl, "/")
+	for i, seg := range segments {
+		if dateSegmentRe.MatchString(seg) && strings.ContainsAny(seg, "YMD") {
+			segments[i] = expandDate(seg, v.Date)
+		}
+	}
+	tmpl = strings.Join(segments, "/")
+
+	out := placeholderRe.ReplaceAllStringFunc(tmpl, func(m string) string {
+		name := placeholderRe.FindStringSubmatch(m)[1]
+		switch name {
+		case "YYYY", "MM", "DD":
+			return expandDate(name, v.Date)
+		case "type":
+			return v.Type
+		case "slug":
+			return v.Slug
+		case "domain":
+			if v.Domain == "" {
+				return "unknown"
+			}
+			return v.Domain
+		}
+		return m
+	})
+
+	// Clean as a rooted path so the result can never escape the vault.
+	out = strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+out)), "/")
+	if !strings.HasSuffix(out, ".md") {
+		out += ".md"
+	}
+	return out
+}
+
+// expandDate replaces YYYY, MM and DD in s with parts of t.
+func expandDate(s string, t time.Time) string {
+	return strings.NewReplacer(
+		"YYYY", t.Format("2006"),
+		"MM", t.Format("01"),
+		"DD", t.Format("02"),
+	).Replace(s)
+}
@@ -0,0 +1,152 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleRules = `
+# Routing rules
+[[rule]]
+name = "oss"
+destination = "Projects/OSS/"
+[rule.match]
+source_domain = ["github.com"]
+
+[[rule]]
+name = "meetings"
+type = "note"
+destination = "Meetings/YYYY/{{slug}}"
+add_tags = ["#meeting-notes"]
+template = "meeting"
+[rule.match]
+tags = "meeting"
+body = ['(?i)attendees:']
+[rule.match.frontmatter]
+kind = "sync"
+[rule.set]
+project = "team-sync"
+
+[[rule]]
+name = "llm tasks"
+destination = "Tasks/{{YYYY}}-{{MM}}/{{slug}}.md"
+[rule.match]
+llm_type = ["task"]
+`
+
+func TestParse(t *testing.T) {
+	set, err := Parse(sampleRules)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(set.Rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(set.Rules))
+	}
+	m := set.Rules[1]
+	if m.Name != "meetings" || m.Type != "note" || m.Frontmatter["kind"] != "sync" || m.Set["project"] != "team-sync" || m.Template != "meeting" {
+		t.Errorf("meetings rule = %+v", m)
+	}
+	if len(m.AddTags) != 1 || m.AddTags[0] != "meeting-notes" {
+		t.Errorf("AddTags = %v, want [meeting-notes]", m.AddTags)
+	}
+	if len(m.Body) != 1 || len(m.Tags) != 1 {
+		t.Errorf("Body = %v, Tags = %v", m.Body, m.Tags)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unquoted string", "[[rule]]\nname = oops\n", "line 2"},
+		{"unknown key", "[[rule]]\nname = \"x\"\ndest = \"A/\"\n", `rule "x": unknown key "dest"`},
+		{"no actions", "[[rule]]\n[rule.match]\ntags = [\"a\"]\n", "no actions"},
+		{"bad regex", "[[rule]]\ntype = \"idea\"\n[rule.match]\nbody = \"(\"\n", "match.body"},
+		{"duplicate key", "[[rule]]\ntype = \"a\"\ntype = \"b\"\n", "duplicate key"},
+		{"top-level key", "foo = \"bar\"\n", "unknown top-level key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	set, err := Parse(sampleRules)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		in   Input
+		want string
+	}{
+		{"subdomain", Input{Source: "https://gist.github.com/x"}, "oss"},
+		{"other domain", Input{Source: "https://notgithub.com/x"}, ""},
+		{"all conditions", Input{
+			Frontmatter: map[string]any{"kind": "Sync"},
+			Tags:        []string{"meeting/weekly"},
+			Body:        "Attendees: me",
+		}, "meetings"},
+		{"missing frontmatter", Input{Tags: []string{"meeting"}, Body: "attendees:"}, ""},
+		{"llm type", Input{LLMType: "task"}, "llm tasks"},
+		{"no llm", Input{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if r := set.Match(tt.in); r != nil {
+				got = r.Name
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderDestination(t *testing.T) {
+	date := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	vars := DestinationVars{Date: date, Type: "note", Slug: "standup", Domain: "github.com"}
+
+	tests := []struct {
+		dest string
+		want string
+	}{
+		{"Meetings/YYYY/", "Meetings/2026/standup.md"},
+		{"Meetings/YYYY-MM/", "Meetings/2026-03/standup.md"},
+		{"Log/{{YYYY}}/{{MM}}-{{DD}} {{slug}}", "Log/2026/03-09 standup.md"},
+		{"Web/{{domain}}/{{type}}/", "Web/github.com/note/standup.md"},
+		{"/Inbox/../Archive/{{slug}}.md", "Archive/standup.md"},
+	}
+	for _, tt := range tests {
+		r := Rule{Destination: tt.dest}
+		if got := r.RenderDestination(vars); got != tt.want {
+			t.Errorf("RenderDestination(%q) = %q, want %q", tt.dest, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	set, err := Load(dir)
+	if err != nil || set != nil {
+		t.Fatalf("Load() without file = %v, %v; want nil, nil", set, err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".obsidian"), 0755)
+	os.WriteFile(Path(dir), []byte("[[rule]]\nname = \"x\"\nbogus\n"), 0644)
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Load() error = %v, want line 3", err)
+	}
+}
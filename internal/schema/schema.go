@@ -0,0 +1,264 @@
+// Package schema loads per-type frontmatter schemas from the vault and
+// validates notes against them.
+//
+// Schemas live in <vault>/.obsidian/schemas.toml, one table per note type
+// (the note's "type" property):
+//
+//	[task]
+//	required = ["status"]
+//	optional = ["due", "project"]
+//	strict = true                 # report properties not listed
+//
+//	[task.properties.status]
+//	type = "string"
+//	enum = ["todo", "doing", "done"]
+//	default = "todo"              # filled in by validate --fix
+//
+//	[task.properties.due]
+//	type = "date"
+//	format = "YYYY-MM-DD"         # Moment.js format, as in Obsidian
+//
+// Property types are string, number, boolean, date, datetime, list, url and
+// link. A date or datetime default of "today" or "now" is the current time.
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/toml"
+)
+
+// FileName is the schemas file name inside the vault's .obsidian directory.
+const FileName = "schemas.toml"
+
+// Path returns the location of the schemas file for a vault.
+func Path(vaultPath string) string {
+	return filepath.Join(vaultPath, ".obsidian", FileName)
+}
+
+// Property types.
+const (
+	TypeString   = "string"
+	TypeNumber   = "number"
+	TypeBoolean  = "boolean"
+	TypeDate     = "date"
+	TypeDateTime = "datetime"
+	TypeList     = "list"
+	TypeURL      = "url"
+	TypeLink     = "link" // a [[wikilink]]
+)
+
+var propertyTypes = []string{TypeString, TypeNumber, TypeBoolean, TypeDate, TypeDateTime, TypeList, TypeURL, TypeLink}
+
+// Default date formats.
+const (
+	DefaultDateFormat     = "YYYY-MM-DD"
+	DefaultDateTimeFormat = "YYYY-MM-DDTHH:mm"
+)
+
+// builtinProperties are Obsidian's own properties, allowed by strict schemas.
+var builtinProperties = []string{"type", "tags", "aliases", "cssclasses"}
+
+// Property describes one frontmatter property.
+type Property struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type,omitempty"`   // empty = any value
+	Enum    []string `json:"enum,omitempty"`   // allowed values (each item, for lists)
+	Format  string   `json:"format,omitempty"` // Moment.js format for date and datetime
+	Default string   `json:"default,omitempty"`
+}
+
+// Schema is the frontmatter schema of one note type.
+type Schema struct {
+	Type       string               `json:"type"`
+	Required   []string             `json:"required,omitempty"`
+	Optional   []string             `json:"optional,omitempty"`
+	Strict     bool                 `json:"strict,omitempty"`
+	Properties map[string]*Property `json:"properties,omitempty"`
+}
+
+// Set is the schemas of a vault, by lowercased note type.
+type Set struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Load reads the vault's schemas file. A missing file yields an empty set.
+func Load(vaultPath string) (*Set, error) {
+	data, err := os.ReadFile(Path(vaultPath))
+	if os.IsNotExist(err) {
+		return &Set{Schemas: map[string]*Schema{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", FileName, err)
+	}
+	set, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", FileName, err)
+	}
+	return set, nil
+}
+
+// Parse parses a schemas file.
+func Parse(src string) (*Set, error) {
+	root, err := toml.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	set := &Set{Schemas: map[string]*Schema{}}
+	for name, v := range root {
+		t, ok := v.(toml.Table)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a table ([%s])", name, name)
+		}
+		s, err := parseSchema(name, t)
+		if err != nil {
+			return nil, fmt.Errorf("[%s]: %w", name, err)
+		}
+		key := strings.ToLower(name)
+		if _, dup := set.Schemas[key]; dup {
+			return nil, fmt.Errorf("type %q is defined more than once", key)
+		}
+		set.Schemas[key] = s
+	}
+	return set, nil
+}
+
+// parseSchema builds one note type's schema from its table.
+func parseSchema(name string, t toml.Table) (*Schema, error) {
+	s := &Schema{Type: name, Properties: map[string]*Property{}}
+	for key, v := range t {
+		var err error
+		switch key {
+		case "required":
+			s.Required, err = listValue(key, v)
+		case "optional":
+			s.Optional, err = listValue(key, v)
+		case "strict":
+			var b string
+			if b, err = stringValue(key, v); err == nil {
+				s.Strict = b == "true"
+			}
+		case "properties":
+			props, ok := v.(toml.Table)
+			if !ok {
+				return nil, fmt.Errorf("properties must be a table")
+			}
+			for prop, pv := range props {
+				pt, ok := pv.(toml.Table)
+				if !ok {
+					return nil, fmt.Errorf("properties.%s must be a table", prop)
+				}
+				p, err := parseProperty(prop, pt)
+				if err != nil {
+					return nil, fmt.Errorf("properties.%s: %w", prop, err)
+				}
+				s.Properties[prop] = p
+			}
+		default:
+			return nil, fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseProperty builds a property description from its table.
+func parseProperty(name string, t toml.Table) (*Property, error) {
+	p := &Property{Name: name}
+	for key, v := range t {
+		var err error
+		switch key {
+		case "type":
+			p.Type, err = stringValue(key, v)
+			if err == nil && !contains(propertyTypes, p.Type) {
+				err = fmt.Errorf("unknown type %q (want %s)", p.Type, strings.Join(propertyTypes, ", "))
+			}
+		case "enum":
+			p.Enum, err = listValue(key, v)
+		case "format":
+			p.Format, err = stringValue(key, v)
+		case "default":
+			p.Default, err = stringValue(key, v)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.Format == "" {
+		switch p.Type {
+		case TypeDate:
+			p.Format = DefaultDateFormat
+		case TypeDateTime:
+			p.Format = DefaultDateTimeFormat
+		}
+	}
+	return p, nil
+}
+
+func stringValue(key string, v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return s, nil
+}
+
+func listValue(key string, v any) ([]string, error) {
+	switch l := v.(type) {
+	case []string:
+		return l, nil
+	case string:
+		return []string{l}, nil
+	}
+	return nil, fmt.Errorf("%s must be a list of strings", key)
+}
+
+// Lookup returns the schema for a note type, or nil when there is none.
+// Types match case-insensitively.
+func (s *Set) Lookup(noteType string) *Schema {
+	if s == nil || noteType == "" {
+		return nil
+	}
+	return s.Schemas[strings.ToLower(strings.TrimSpace(noteType))]
+}
+
+// Types returns the note types with schemas, sorted.
+func (s *Set) Types() []string {
+	types := make([]string, 0, len(s.Schemas))
+	for t := range s.Schemas {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// property returns the description of a property, or an untyped one.
+func (s *Schema) property(name string) *Property {
+	if p, ok := s.Properties[name]; ok {
+		return p
+	}
+	return &Property{Name: name}
+}
+
+// allowed reports whether a strict schema permits a property.
+func (s *Schema) allowed(name string) bool {
+	_, described := s.Properties[name]
+	return described || contains(s.Required, name) || contains(s.Optional, name) || contains(builtinProperties, name)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSchemas = `
+[reference]
+required = ["source"]
+optional = ["author"]
+
+[reference.properties.source]
+type = "url"
+
+[task]
+required = ["status", "created"]
+optional = ["due", "tags"]
+strict = true
+
+[task.properties.status]
+type = "string"
+enum = ["todo", "doing", "done"]
+default = "todo"
+
+[task.properties.created]
+type = "date"
+default = "today"
+
+[task.properties.due]
+type = "datetime"
+format = "YYYY-MM-DD HH:mm"
+`
+
+func TestParse(t *testing.T) {
+	set, err := Parse(testSchemas)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if got := set.Types(); !reflect.DeepEqual(got, []string{"reference", "task"}) {
+		t.Errorf("Types() = %v", got)
+	}
+	task := set.Lookup("TASK")
+	if task == nil || !task.Strict || task.Properties["created"].Format != DefaultDateFormat {
+		t.Fatalf("Lookup(TASK) = %+v", task)
+	}
+	if set.Lookup("fleeting") != nil || set.Lookup("") != nil {
+		t.Error("Lookup found a schema for an unknown type")
+	}
+
+	for _, src := range []string{
+		"[task]\nrequired = \"status\"\nextra = 1\n",
+		"[task.properties.due]\ntype = \"timestamp\"\n",
+		"[task.properties.due]\nmin = 1\n",
+		"task = \"x\"\n",
+		"[task]\nrequired = [\"a\"]\n[Task]\nrequired = [\"b\"]\n",
+	} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) succeeded", src)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	set, err := Parse(testSchemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note := strings.Join([]string{
+		"---",
+		"type: task",
+		"status: blocked",
+		"due: 2026-03-01",
+		"priority: high",
+		"tags: [work]",
+		"---",
+		"body",
+	}, "\n")
+	var got []string
+	for _, i := range set.Lookup("task").Validate(note) {
+		got = append(got, i.String())
+	}
+	want := []string{
+		"2: created: missing required property",
+		`3: status: "blocked" should be one of todo, doing, done`,
+		"4: due: should be a datetime in the format YYYY-MM-DD HH:mm",
+		"5: priority: not allowed by the task schema",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	ref := set.Lookup("reference")
+	if issues := ref.Validate("---\ntype: reference\nsource: https://go.dev/doc\n---\n"); len(issues) != 0 {
+		t.Errorf("valid reference: %v", issues)
+	}
+	if issues := ref.Validate("---\ntype: reference\nsource: [a, b]\n---\n"); len(issues) != 1 || issues[0].Message != "should be a single url, not a list" {
+		t.Errorf("list source: %v", issues)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	set, err := Parse(testSchemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)
+	got := set.Lookup("task").Defaults("---\ntype: task\nstatus: \"\"\n---\n", now)
+	want := []Fill{{"status", "todo"}, {"created", "2026-03-07"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Defaults() = %+v, want %+v", got, want)
+	}
+	if got := set.Lookup("reference").Defaults("---\ntype: reference\n---\n", now); len(got) != 0 {
+		t.Errorf("Defaults() without defaults = %+v", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	set, err := Load(dir)
+	if err != nil || len(set.Schemas) != 0 {
+		t.Fatalf("Load() without a file = %+v, %v", set, err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Path(dir), []byte("[task\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Load() of a bad file: %v", err)
+	}
+}
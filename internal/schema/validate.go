@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// Issue is a schema violation. Line is 1-based in the note: the property's
+// line, or the type property's line for a missing property.
+type Issue struct {
+	Line     int    `json:"line"`
+	Property string `json:"property"`
+	Message  string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d: %s: %s", i.Line, i.Property, i.Message)
+}
+
+// Fill is a default value for a missing property.
+type Fill struct {
+	Property string `json:"property"`
+	Value    string `json:"value"`
+}
+
+// Validate checks a note's frontmatter against the schema.
+func (s *Schema) Validate(content string) []Issue {
+	fm := vault.ParseNote(content).Frontmatter
+	lines, order := propertyLines(content)
+	typeLine := lines["type"]
+	if typeLine == 0 {
+		typeLine = 1
+	}
+
+	var issues []Issue
+	for _, name := range s.Required {
+		if !present(fm[name]) {
+			issues = append(issues, Issue{Line: typeLine, Property: name, Message: "missing required property"})
+		}
+	}
+	for _, name := range order {
+		if s.Strict && !s.allowed(name) {
+			issues = append(issues, Issue{Line: lines[name], Property: name, Message: fmt.Sprintf("not allowed by the %s schema", s.Type)})
+			continue
+		}
+		if msg := s.property(name).check(fm[name]); msg != "" {
+			issues = append(issues, Issue{Line: lines[name], Property: name, Message: msg})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
+
+// Defaults returns values for the required properties the note is missing
+// that have a default, in schema order.
+func (s *Schema) Defaults(content string, now time.Time) []Fill {
+	fm := vault.ParseNote(content).Frontmatter
+	var fills []Fill
+	for _, name := range s.Required {
+		p := s.property(name)
+		if present(fm[name]) || p.Default == "" {
+			continue
+		}
+		fills = append(fills, Fill{Property: name, Value: p.defaultValue(now)})
+	}
+	return fills
+}
+
+// defaultValue expands "today" and "now" for date properties.
+func (p *Property) defaultValue(now time.Time) string {
+	if (p.Type == TypeDate || p.Type == TypeDateTime) && (p.Default == "today" || p.Default == "now") {
+		return now.Format(vault.MomentLayout(p.Format))
+	}
+	return p.Default
+}
+
+// check returns what is wrong with a property's value, or "". Blank values
+// pass; required properties are checked separately.
+func (p *Property) check(v any) string {
+	if !present(v) {
+		return ""
+	}
+	list, isList := v.([]string)
+	if p.Type == TypeList {
+		if !isList {
+			return "should be a list"
+		}
+		return p.checkEnum(list)
+	}
+	if isList {
+		if p.Type == "" {
+			return p.checkEnum(list)
+		}
+		return fmt.Sprintf("should be a single %s, not a list", p.Type)
+	}
+	s := strings.TrimSpace(fmt.Sprint(v))
+
+	switch p.Type {
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return "should be a number"
+		}
+	case TypeBoolean:
+		if s != "true" && s != "false" {
+			return "should be true or false"
+		}
+	case TypeDate, TypeDateTime:
+		if _, err := time.Parse(vault.MomentLayout(p.Format), s); err != nil {
+			return fmt.Sprintf("should be a %s in the format %s", p.Type, p.Format)
+		}
+	case TypeURL:
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "should be an http(s) URL"
+		}
+	case TypeLink:
+		if !strings.HasPrefix(s, "[[") || !strings.HasSuffix(s, "]]") {
+			return "should be a [[link]]"
+		}
+	}
+	return p.checkEnum([]string{s})
+}
+
+// checkEnum reports values outside the property's enum.
+func (p *Property) checkEnum(values []string) string {
+	if len(p.Enum) == 0 {
+		return ""
+	}
+	for _, v := range values {
+		if !contains(p.Enum, v) {
+			return fmt.Sprintf("%q should be one of %s", v, strings.Join(p.Enum, ", "))
+		}
+	}
+	return ""
+}
+
+// present reports whether a frontmatter value is set and not blank.
+func present(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(t) != ""
+	case []string:
+		return len(t) > 0
+	}
+	return true
+}
+
+// propertyLines returns the 1-based line of each top-level frontmatter key,
+// and the keys in the order they appear.
+func propertyLines(content string) (map[string]int, []string) {
+	lines := strings.Split(content, "\n")
+	out := make(map[string]int)
+	var order []string
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r") != "---" {
+		return out, nil
+	}
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if line == "---" {
+			break
+		}
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		colon := strings.Index(line, ":")
+		if colon <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:colon])
+		if _, seen := out[key]; !seen {
+			out[key] = i + 1
+			order = append(order, key)
+		}
+	}
+	return out, order
+}
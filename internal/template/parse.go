@@ -0,0 +1,207 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+type nodeKind int
+
+const (
+	nodeText      nodeKind = iota
+	nodeTag                // {{expr}}
+	nodeTemplater          // <% expr %>
+	nodeIf                 // {{#if expr}} then {{else}} els {{/if}}
+)
+
+type node struct {
+	kind      nodeKind
+	text      string
+	expr      string
+	line      int
+	then, els []node
+}
+
+// token is a piece of template source. Tokens alternate between text and
+// tags, starting and ending with text, which may be empty.
+type token struct {
+	kind nodeKind // nodeText, nodeTag or nodeTemplater
+	text string
+	expr string
+	line int
+	// Templater whitespace control: '-' trims one newline, '_' all
+	// whitespace, before or after the tag.
+	trimBefore, trimAfter byte
+}
+
+func parse(src string) ([]node, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	trimTemplater(toks)
+	trimStandalone(toks)
+	return build(toks)
+}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	for {
+		i := strings.Index(src, "{{")
+		if j := strings.Index(src, "<%"); j >= 0 && (i < 0 || j < i) {
+			i = j
+		}
+		if i < 0 {
+			return append(toks, token{kind: nodeText, text: src}), nil
+		}
+		toks = append(toks, token{kind: nodeText, text: src[:i]})
+		line += strings.Count(src[:i], "\n")
+
+		closer := "}}"
+		if src[i] == '<' {
+			closer = "%>"
+		}
+		end := strings.Index(src[i+2:], closer)
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: %s is not closed", line, src[i:i+2])
+		}
+		inner := src[i+2 : i+2+end]
+		t := token{kind: nodeTag, expr: strings.TrimSpace(inner), line: line}
+		if closer == "%>" {
+			t.kind = nodeTemplater
+			if strings.HasPrefix(inner, "*") {
+				return nil, fmt.Errorf("line %d: Templater JavaScript blocks (<%%* %%>) are not supported", line)
+			}
+			inner = strings.TrimPrefix(inner, "+")
+			if inner != "" && (inner[0] == '-' || inner[0] == '_') {
+				t.trimBefore, inner = inner[0], inner[1:]
+			}
+			if n := len(inner); n > 0 && (inner[n-1] == '-' || inner[n-1] == '_') {
+				t.trimAfter, inner = inner[n-1], inner[:n-1]
+			}
+			t.expr = strings.TrimSpace(inner)
+		}
+		toks = append(toks, t)
+		line += strings.Count(inner, "\n")
+		src = src[i+2+end+2:]
+	}
+}
+
+// trimTemplater applies Templater's whitespace control to the text around
+// its tags.
+func trimTemplater(toks []token) {
+	for k := 1; k < len(toks); k += 2 {
+		switch toks[k].trimBefore {
+		case '-':
+			toks[k-1].text = strings.TrimSuffix(strings.TrimSuffix(toks[k-1].text, "\n"), "\r")
+		case '_':
+			toks[k-1].text = strings.TrimRight(toks[k-1].text, " \t\r\n")
+		}
+		switch toks[k].trimAfter {
+		case '-':
+			toks[k+1].text = strings.TrimPrefix(strings.TrimPrefix(toks[k+1].text, "\r"), "\n")
+		case '_':
+			toks[k+1].text = strings.TrimLeft(toks[k+1].text, " \t\r\n")
+		}
+	}
+}
+
+// trimStandalone removes the lines of block tags ({{#if}}, {{else}},
+// {{/if}}) that stand alone on their line, so they leave no blank lines.
+func trimStandalone(toks []token) {
+	last := len(toks) - 1
+	start := make([]int, len(toks))
+	end := make([]int, len(toks))
+	for k, t := range toks {
+		end[k] = len(t.text)
+	}
+	for k := 1; k < last; k += 2 {
+		if toks[k].kind != nodeTag || !isBlock(toks[k].expr) {
+			continue
+		}
+		prev, next := toks[k-1].text, toks[k+1].text
+		nl := strings.LastIndex(prev, "\n")
+		if strings.TrimSpace(prev[nl+1:]) != "" || (nl < 0 && k-1 != 0) {
+			continue
+		}
+		first := strings.Index(next, "\n")
+		rest := next
+		if first >= 0 {
+			rest = next[:first]
+		}
+		if strings.TrimSpace(rest) != "" || (first < 0 && k+1 != last) {
+			continue
+		}
+		end[k-1] = nl + 1
+		if first < 0 {
+			start[k+1] = len(next)
+		} else {
+			start[k+1] = first + 1
+		}
+	}
+	for k := 0; k <= last; k += 2 {
+		if start[k] >= end[k] {
+			toks[k].text = ""
+		} else {
+			toks[k].text = toks[k].text[start[k]:end[k]]
+		}
+	}
+}
+
+func isBlock(expr string) bool {
+	return expr == "else" || strings.HasPrefix(expr, "#") || strings.HasPrefix(expr, "/")
+}
+
+// build turns tokens into a tree of conditionals.
+func build(toks []token) ([]node, error) {
+	type frame struct {
+		n      node
+		inElse bool
+	}
+	var root []node
+	var stack []*frame
+	add := func(n node) {
+		switch {
+		case len(stack) == 0:
+			root = append(root, n)
+		case stack[len(stack)-1].inElse:
+			stack[len(stack)-1].n.els = append(stack[len(stack)-1].n.els, n)
+		default:
+			stack[len(stack)-1].n.then = append(stack[len(stack)-1].n.then, n)
+		}
+	}
+	for _, t := range toks {
+		switch {
+		case t.kind == nodeText:
+			if t.text != "" {
+				add(node{kind: nodeText, text: t.text})
+			}
+		case t.kind == nodeTemplater || !isBlock(t.expr):
+			add(node{kind: t.kind, expr: t.expr, line: t.line})
+		case t.expr == "else":
+			if len(stack) == 0 || stack[len(stack)-1].inElse {
+				return nil, fmt.Errorf("line %d: {{else}} without {{#if}}", t.line)
+			}
+			stack[len(stack)-1].inElse = true
+		case t.expr == "/if":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: {{/if}} without {{#if}}", t.line)
+			}
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			add(f.n)
+		default:
+			rest, ok := strings.CutPrefix(t.expr, "#if")
+			cond := strings.TrimSpace(rest)
+			if !ok || cond == "" || (rest[0] != ' ' && rest[0] != '\t') {
+				return nil, fmt.Errorf("line %d: unknown block {{%s}} (want #if, else or /if)", t.line, t.expr)
+			}
+			stack = append(stack, &frame{n: node{kind: nodeIf, expr: cond, line: t.line}})
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("line %d: {{#if}} is not closed", stack[len(stack)-1].n.line)
+	}
+	return root, nil
+}
@@ -0,0 +1,83 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Settings are the template options Obsidian stores in the vault: the core
+// Templates plugin's .obsidian/templates.json, with Templater's templates
+// folder as a fallback.
+type Settings struct {
+	Folder     string `json:"folder"`
+	DateFormat string `json:"dateFormat"`
+	TimeFormat string `json:"timeFormat"`
+}
+
+// templaterData is the part of Templater's settings used here.
+type templaterData struct {
+	TemplatesFolder string `json:"templates_folder"`
+}
+
+// LoadSettings reads the vault's template settings. Missing files yield
+// empty settings.
+func LoadSettings(vaultPath string) (Settings, error) {
+	var s Settings
+	if err := readJSON(filepath.Join(vaultPath, ".obsidian", "templates.json"), &s); err != nil {
+		return s, err
+	}
+	if s.Folder == "" {
+		var t templaterData
+		if err := readJSON(filepath.Join(vaultPath, ".obsidian", "plugins", "templater-obsidian", "data.json"), &t); err != nil {
+			return s, err
+		}
+		s.Folder = t.TemplatesFolder
+	}
+	s.Folder = strings.Trim(filepath.ToSlash(s.Folder), "/")
+	return s, nil
+}
+
+// readJSON decodes a JSON file into v, leaving v alone when the file does
+// not exist.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// Resolve finds a template by vault-relative path or by name in the
+// templates folder, with or without .md, and returns its vault-relative
+// path.
+func (s Settings) Resolve(vaultPath, name string) (string, error) {
+	name = strings.Trim(filepath.ToSlash(filepath.Clean(name)), "/")
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("template %q is outside the vault", name)
+	}
+	if !strings.HasSuffix(name, ".md") {
+		name += ".md"
+	}
+	candidates := []string{name}
+	if s.Folder != "" && !strings.HasPrefix(name, s.Folder+"/") {
+		candidates = append(candidates, s.Folder+"/"+name)
+	}
+	for _, c := range candidates {
+		if info, err := os.Stat(filepath.Join(vaultPath, c)); err == nil && !info.IsDir() {
+			return c, nil
+		}
+	}
+	if s.Folder != "" {
+		return "", fmt.Errorf("template %q not found in the vault or in %s/", strings.TrimSuffix(name, ".md"), s.Folder)
+	}
+	return "", fmt.Errorf("template %q not found", strings.TrimSuffix(name, ".md"))
+}
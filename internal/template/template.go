@@ -0,0 +1,279 @@
+// Package template renders note templates. It understands the syntax of
+// Obsidian's core Templates plugin and the common subset of Templater:
+//
+//	{{title}} {{folder}}               the new note's name and folder
+//	{{date}} {{time}}                  its date, in the configured formats
+//	{{date:YYYY-MM-DD}} {{time:HH:mm}} the same in a Moment.js format
+//	{{yesterday}} {{tomorrow}}         the day before and after, same formats
+//	{{cursor}}                         where editing should start
+//	{{project}}                        a variable (--var project=...)
+//	{{project|inbox}}                  a variable with a default
+//	{{#if project}}...{{else}}...{{/if}}
+//	{{#if status == "done"}}...{{/if}} also != and !name
+//
+//	<% tp.file.title %> <% tp.file.folder() %> <% tp.file.cursor() %>
+//	<% tp.date.now("YYYY-MM-DD", 7) %> <% tp.date.tomorrow() %>
+//	<% tp.date.yesterday() %> <% tp.date.weekday("YYYY-MM-DD", 0) %>
+//	<% tp.file.creation_date("YYYY-MM-DD") %>
+//	<% tp.system.prompt("Project", "inbox") %>
+//
+// A variable that is not set is prompted for when the caller can ask,
+// otherwise its default is used; without one, rendering fails. Templater
+// prompts are variables named by their prompt text. Variables may also
+// override title and folder. The whole template is rendered, so templates
+// can set frontmatter too.
+package template
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// Default formats of {{date}} and {{time}}, as in the Templates plugin.
+const (
+	DefaultDateFormat = "YYYY-MM-DD"
+	DefaultTimeFormat = "HH:mm"
+)
+
+// CursorMarker stands in for {{cursor}} in rendered output until
+// StripCursor removes it.
+const CursorMarker = "\x00cursor\x00"
+
+// Context is what a template is rendered with.
+type Context struct {
+	Title      string    // the new note's file name, without .md
+	Folder     string    // the new note's vault-relative folder
+	Date       time.Time // {{date}}: now, or the day of a daily note
+	DateFormat string    // default format of {{date}} ("" = YYYY-MM-DD)
+	TimeFormat string    // default format of {{time}} ("" = HH:mm)
+	Vars       map[string]string
+
+	// Prompt asks for a variable that is not set; def is its default, if
+	// any. Nil when nobody can be asked.
+	Prompt func(name, def string) (string, error)
+}
+
+// Render expands a template.
+func Render(src string, ctx Context) (string, error) {
+	nodes, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+	if ctx.DateFormat == "" {
+		ctx.DateFormat = DefaultDateFormat
+	}
+	if ctx.TimeFormat == "" {
+		ctx.TimeFormat = DefaultTimeFormat
+	}
+	if ctx.Date.IsZero() {
+		ctx.Date = time.Now()
+	}
+	r := &renderer{ctx: ctx, vars: make(map[string]string, len(ctx.Vars))}
+	for k, v := range ctx.Vars {
+		r.vars[k] = v
+	}
+	var b strings.Builder
+	if err := r.render(&b, nodes); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// References reports whether a template uses a variable, so callers can
+// place content themselves when it does not.
+func References(src, name string) bool {
+	nodes, err := parse(src)
+	if err != nil {
+		return false
+	}
+	return references(nodes, name)
+}
+
+func references(nodes []node, name string) bool {
+	for _, n := range nodes {
+		switch {
+		case n.kind == nodeTag:
+			key, _, _ := strings.Cut(n.expr, "|")
+			if strings.TrimSpace(key) == name {
+				return true
+			}
+		case n.kind == nodeIf:
+			if references(n.then, name) || references(n.els, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StripCursor removes cursor markers from rendered content and returns the
+// 1-based line of the first one, or 0 when there is none.
+func StripCursor(content string) (string, int) {
+	i := strings.Index(content, CursorMarker)
+	if i < 0 {
+		return content, 0
+	}
+	line := strings.Count(content[:i], "\n") + 1
+	return strings.ReplaceAll(content, CursorMarker, ""), line
+}
+
+// ParseVars parses key=value pairs as given to --var.
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		key, value, ok := strings.Cut(p, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q (want key=value)", p)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+type renderer struct {
+	ctx  Context
+	vars map[string]string // ctx.Vars plus prompted answers
+}
+
+func (r *renderer) render(b *strings.Builder, nodes []node) error {
+	for _, n := range nodes {
+		switch n.kind {
+		case nodeText:
+			b.WriteString(n.text)
+		case nodeTag:
+			s, err := r.tag(n.expr)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n.line, err)
+			}
+			b.WriteString(s)
+		case nodeTemplater:
+			s, err := r.templater(n.expr)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n.line, err)
+			}
+			b.WriteString(s)
+		case nodeIf:
+			branch := n.els
+			if r.holds(n.expr) {
+				branch = n.then
+			}
+			if err := r.render(b, branch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tag expands a {{...}} expression.
+func (r *renderer) tag(expr string) (string, error) {
+	name, def, hasDef := strings.Cut(expr, "|")
+	name, def = strings.TrimSpace(name), strings.TrimSpace(def)
+	key, format, hasFormat := strings.Cut(name, ":")
+	key, format = strings.TrimSpace(key), strings.TrimSpace(format)
+
+	switch strings.ToLower(key) {
+	case "date", "yesterday", "tomorrow":
+		if !hasFormat {
+			format = r.ctx.DateFormat
+		}
+		return r.date(strings.ToLower(key), format), nil
+	case "time":
+		if !hasFormat {
+			format = r.ctx.TimeFormat
+		}
+		return r.date("date", format), nil
+	}
+	if hasFormat {
+		return "", fmt.Errorf("{{%s}}: only date and time take a format", expr)
+	}
+	if key == "cursor" {
+		return CursorMarker, nil
+	}
+	if v, ok := r.vars[key]; ok {
+		return v, nil
+	}
+	switch key {
+	case "title":
+		return r.ctx.Title, nil
+	case "folder":
+		return r.ctx.Folder, nil
+	}
+	return r.variable(key, def, hasDef)
+}
+
+// variable prompts for a variable that is not set, falling back to its
+// default when there is nobody to ask or the prompt fails.
+func (r *renderer) variable(name, def string, hasDef bool) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty variable name")
+	}
+	if r.ctx.Prompt != nil {
+		if v, err := r.ctx.Prompt(name, def); err == nil {
+			r.vars[name] = v
+			return v, nil
+		}
+	}
+	if hasDef {
+		return def, nil
+	}
+	return "", fmt.Errorf("variable %q is not set (pass --var %s=...)", name, name)
+}
+
+// date formats the context date, or the day before or after it.
+func (r *renderer) date(which, format string) string {
+	t := r.ctx.Date
+	switch which {
+	case "yesterday":
+		t = t.AddDate(0, 0, -1)
+	case "tomorrow":
+		t = t.AddDate(0, 0, 1)
+	}
+	return t.Format(vault.MomentLayout(format))
+}
+
+// value returns a variable or built-in for a condition, without prompting.
+func (r *renderer) value(name string) string {
+	if v, ok := r.vars[name]; ok {
+		return v
+	}
+	switch name {
+	case "title":
+		return r.ctx.Title
+	case "folder":
+		return r.ctx.Folder
+	}
+	return ""
+}
+
+// holds evaluates an #if condition: name, !name, name == value or
+// name != value. A variable holds when it is set to something other than
+// "", "false", "no" or "0".
+func (r *renderer) holds(cond string) bool {
+	for _, op := range []string{"==", "!="} {
+		if left, right, ok := strings.Cut(cond, op); ok {
+			equal := r.value(strings.TrimSpace(left)) == unquote(strings.TrimSpace(right))
+			return equal == (op == "==")
+		}
+	}
+	if name, ok := strings.CutPrefix(cond, "!"); ok {
+		return !r.holds(strings.TrimSpace(name))
+	}
+	switch strings.ToLower(strings.TrimSpace(r.value(cond))) {
+	case "", "false", "no", "0":
+		return false
+	}
+	return true
+}
+
+// unquote strips matching single or double quotes.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
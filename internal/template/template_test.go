@@ -0,0 +1,208 @@
+package template
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 2026-03-05 is a Thursday.
+var testDate = time.Date(2026, 3, 5, 14, 30, 0, 0, time.UTC)
+
+func TestRenderCoreSyntax(t *testing.T) {
+	src := strings.Join([]string{
+		"---",
+		"created: {{date}} {{time}}",
+		"project: {{project}}",
+		"---",
+		"# {{title}} ({{folder}})",
+		"{{date:dddd, MMMM D}} · [[{{yesterday}}]] · [[{{tomorrow:YYYY-MM-DD}}]]",
+		"{{ status | todo }}",
+		"{{cursor}}",
+	}, "\n")
+	got, err := Render(src, Context{
+		Title:  "Kickoff",
+		Folder: "Meetings",
+		Date:   testDate,
+		Vars:   map[string]string{"project": "apollo"},
+	})
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	want := strings.Join([]string{
+		"---",
+		"created: 2026-03-05 14:30",
+		"project: apollo",
+		"---",
+		"# Kickoff (Meetings)",
+		"Thursday, March 5 · [[2026-03-04]] · [[2026-03-06]]",
+		"todo",
+		CursorMarker,
+	}, "\n")
+	if got != want {
+		t.Errorf("Render() =\n%q\nwant\n%q", got, want)
+	}
+
+	content, line := StripCursor(got)
+	if line != 8 || strings.Contains(content, CursorMarker) {
+		t.Errorf("StripCursor() line = %d, content %q", line, content)
+	}
+}
+
+func TestRenderConditionals(t *testing.T) {
+	src := "A\n{{#if project}}\nproject {{project}}\n{{else}}\nno project\n{{/if}}\n" +
+		"{{#if status == \"done\"}}done{{/if}}{{#if status != done}}open{{/if}}\n" +
+		"{{#if !urgent}}\ncalm\n{{/if}}\nB\n"
+	tests := []struct {
+		vars map[string]string
+		want string
+	}{
+		{map[string]string{"project": "apollo", "status": "done"}, "A\nproject apollo\ndone\ncalm\nB\n"},
+		{map[string]string{"project": "false", "urgent": "yes"}, "A\nno project\nopen\nB\n"},
+	}
+	for _, tt := range tests {
+		got, err := Render(src, Context{Date: testDate, Vars: tt.vars})
+		if err != nil {
+			t.Fatalf("Render(%v) error: %v", tt.vars, err)
+		}
+		if got != tt.want {
+			t.Errorf("Render(%v) = %q, want %q", tt.vars, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"{{#if x}}open", "{{/if}}", "{{else}}", "{{#each x}}{{/each}}", "{{#if}}{{/if}}", "{{unclosed"} {
+		if _, err := Render(bad, Context{}); err == nil {
+			t.Errorf("Render(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestRenderTemplater(t *testing.T) {
+	src := strings.Join([]string{
+		`# <% tp.file.title %> in <% tp.file.folder() %> (<% tp.file.folder(true) %>)`,
+		`<% tp.date.now("YYYY-MM-DD") %> <% tp.date.now("YYYY-MM-DD", -1) %> <% tp.date.now("YYYY-MM-DD", "P1W") %>`,
+		`<% tp.date.tomorrow("DD") %> <% tp.date.yesterday() %> <% tp.date.weekday("ddd D", 0) %>`,
+		`<%- tp.file.creation_date() -%>`,
+		`Project: <% tp.system.prompt("Project name") %>, <% tp.system.prompt('Owner', "me") %>`,
+		`<% tp.file.cursor(1) %>`,
+	}, "\n")
+	got, err := Render(src, Context{
+		Title:  "Plan",
+		Folder: "Projects/Apollo",
+		Date:   testDate,
+		Vars:   map[string]string{"Project name": "apollo"},
+	})
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	want := strings.Join([]string{
+		"# Plan in Apollo (Projects/Apollo)",
+		"2026-03-05 2026-03-04 2026-03-12",
+		"06 2026-03-04 Mon 2" + "2026-03-05 14:30" + "Project: apollo, me",
+		CursorMarker,
+	}, "\n")
+	if got != want {
+		t.Errorf("Render() =\n%q\nwant\n%q", got, want)
+	}
+
+	for _, bad := range []string{`<%* tR += "x" %>`, `<% tp.system.suggester(["a"], ["a"]) %>`, `<% tp.date.now("YYYY", 0, "2020-01-01", "YYYY-MM-DD") %>`, `<% tp.date.now("YYYY", "soon") %>`} {
+		if _, err := Render(bad, Context{}); err == nil {
+			t.Errorf("Render(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestRenderVariables(t *testing.T) {
+	if _, err := Render("{{project}}", Context{}); err == nil || !strings.Contains(err.Error(), "--var project=") {
+		t.Errorf("missing variable error = %v", err)
+	}
+
+	var asked []string
+	prompt := func(name, def string) (string, error) {
+		asked = append(asked, name+"|"+def)
+		return "answer", nil
+	}
+	got, err := Render("{{project}} {{project}} {{owner|me}} {{title}}", Context{Title: "T", Prompt: prompt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "answer answer answer T" || strings.Join(asked, ",") != "project|,owner|me" {
+		t.Errorf("Render() = %q, asked %v", got, asked)
+	}
+
+	failing := func(string, string) (string, error) { return "", errors.New("no tty") }
+	if _, err := Render("{{project}}", Context{Prompt: failing}); err == nil {
+		t.Error("prompt error was ignored")
+	}
+
+	if !References("a {{ content }} b", "content") || References("{{contents}}", "content") {
+		t.Error("References() is wrong")
+	}
+	if !References("{{#if x}}{{content}}{{/if}}", "content") {
+		t.Error("References() missed a variable inside a conditional")
+	}
+
+	vars, err := ParseVars([]string{"project=apollo", "note=a=b", "empty="})
+	if err != nil || vars["project"] != "apollo" || vars["note"] != "a=b" || vars["empty"] != "" {
+		t.Errorf("ParseVars() = %v, %v", vars, err)
+	}
+	if _, err := ParseVars([]string{"novalue"}); err == nil {
+		t.Error("ParseVars accepted a pair without =")
+	}
+}
+
+func TestSettingsResolve(t *testing.T) {
+	vault := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		full := filepath.Join(vault, rel)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := LoadSettings(vault)
+	if err != nil || s != (Settings{}) {
+		t.Fatalf("LoadSettings() without files = %+v, %v", s, err)
+	}
+
+	write(".obsidian/plugins/templater-obsidian/data.json", `{"templates_folder": "Templater"}`)
+	if s, _ := LoadSettings(vault); s.Folder != "Templater" {
+		t.Errorf("Templater folder fallback = %q", s.Folder)
+	}
+
+	write(".obsidian/templates.json", `{"folder": "/99 Templates/", "dateFormat": "DD.MM.YYYY"}`)
+	s, err = LoadSettings(vault)
+	if err != nil || s.Folder != "99 Templates" || s.DateFormat != "DD.MM.YYYY" {
+		t.Fatalf("LoadSettings() = %+v, %v", s, err)
+	}
+
+	write("99 Templates/meeting.md", "x")
+	write("Other/idea.md", "x")
+	for name, want := range map[string]string{
+		"meeting":                 "99 Templates/meeting.md",
+		"meeting.md":              "99 Templates/meeting.md",
+		"99 Templates/meeting.md": "99 Templates/meeting.md",
+		"Other/idea":              "Other/idea.md",
+	} {
+		if got, err := s.Resolve(vault, name); err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"missing", "../outside"} {
+		if _, err := s.Resolve(vault, name); err == nil {
+			t.Errorf("Resolve(%q) succeeded", name)
+		}
+	}
+
+	write(".obsidian/templates.json", `{"folder": `)
+	if _, err := LoadSettings(vault); err == nil {
+		t.Error("LoadSettings() accepted malformed JSON")
+	}
+}
@@ -0,0 +1,158 @@
+package template
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeyhipolito/obsidian-cli/internal/vault"
+)
+
+// templaterArgs is the number of arguments each supported Templater
+// function takes.
+var templaterArgs = map[string]int{
+	"tp.file.title": 0, "tp.file.cursor": 1, "tp.file.folder": 1,
+	"tp.file.creation_date": 1, "tp.file.last_modified_date": 1,
+	"tp.date.now": 2, "tp.date.tomorrow": 1, "tp.date.yesterday": 1, "tp.date.weekday": 2,
+	"tp.system.prompt": 2,
+}
+
+// call is a parsed Templater expression such as tp.date.now("YYYY", 1).
+type call struct {
+	name string
+	args []string
+}
+
+// templater expands a <% ... %> expression. Only the tp.file, tp.date and
+// tp.system functions that need no editor are supported.
+func (r *renderer) templater(expr string) (string, error) {
+	c, err := parseCall(expr)
+	if err != nil {
+		return "", err
+	}
+	arg := func(i int, def string) string {
+		if i < len(c.args) {
+			return c.args[i]
+		}
+		return def
+	}
+	limit, ok := templaterArgs[c.name]
+	if !ok {
+		return "", fmt.Errorf("unsupported Templater expression %q", expr)
+	}
+	if len(c.args) > limit {
+		return "", fmt.Errorf("%s: too many arguments (Templater reference dates are not supported)", c.name)
+	}
+
+	switch c.name {
+	case "tp.file.title":
+		return r.ctx.Title, nil
+	case "tp.file.cursor":
+		return CursorMarker, nil
+	case "tp.file.folder":
+		if arg(0, "false") == "true" {
+			return r.ctx.Folder, nil
+		}
+		if r.ctx.Folder == "" {
+			return "", nil
+		}
+		return path.Base(r.ctx.Folder), nil
+	case "tp.file.creation_date", "tp.file.last_modified_date":
+		return r.ctx.Date.Format(vault.MomentLayout(arg(0, "YYYY-MM-DD HH:mm"))), nil
+	case "tp.date.now":
+		t, err := offsetDate(r.ctx.Date, arg(1, "0"))
+		if err != nil {
+			return "", err
+		}
+		return t.Format(vault.MomentLayout(arg(0, DefaultDateFormat))), nil
+	case "tp.date.tomorrow":
+		return r.ctx.Date.AddDate(0, 0, 1).Format(vault.MomentLayout(arg(0, DefaultDateFormat))), nil
+	case "tp.date.yesterday":
+		return r.ctx.Date.AddDate(0, 0, -1).Format(vault.MomentLayout(arg(0, DefaultDateFormat))), nil
+	case "tp.date.weekday":
+		day, err := strconv.Atoi(arg(1, "0"))
+		if err != nil {
+			return "", fmt.Errorf("tp.date.weekday: weekday must be a number")
+		}
+		// Weeks start on Monday (weekday 0), as in Moment's ISO locale.
+		monday := r.ctx.Date.AddDate(0, 0, -((int(r.ctx.Date.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, day).Format(vault.MomentLayout(arg(0, DefaultDateFormat))), nil
+	case "tp.system.prompt":
+		if len(c.args) == 0 || c.args[0] == "" {
+			return "", fmt.Errorf("tp.system.prompt: missing prompt text")
+		}
+		if v, ok := r.vars[c.args[0]]; ok {
+			return v, nil
+		}
+		return r.variable(c.args[0], arg(1, ""), len(c.args) > 1)
+	}
+	return "", fmt.Errorf("unsupported Templater expression %q", expr)
+}
+
+var callRe = regexp.MustCompile(`^(tp(?:\.[A-Za-z_]+)+)\s*(?:\((.*)\))?$`)
+
+// parseCall parses a function call or property access with string, number
+// and boolean arguments.
+func parseCall(expr string) (call, error) {
+	m := callRe.FindStringSubmatch(strings.TrimSuffix(strings.TrimSpace(expr), ";"))
+	if m == nil {
+		return call{}, fmt.Errorf("unsupported Templater expression %q", expr)
+	}
+	c := call{name: m[1]}
+	src := strings.TrimSpace(m[2])
+	for src != "" {
+		var a string
+		if q := src[0]; q == '"' || q == '\'' || q == '`' {
+			end := strings.IndexByte(src[1:], q)
+			if end < 0 {
+				return call{}, fmt.Errorf("%s: unterminated string", c.name)
+			}
+			a, src = src[1:end+1], src[end+2:]
+		} else {
+			end := strings.IndexByte(src, ',')
+			if end < 0 {
+				end = len(src)
+			}
+			a, src = strings.TrimSpace(src[:end]), src[end:]
+		}
+		c.args = append(c.args, a)
+		src = strings.TrimSpace(src)
+		if src != "" {
+			if src[0] != ',' {
+				return call{}, fmt.Errorf("%s: malformed arguments", c.name)
+			}
+			src = strings.TrimSpace(src[1:])
+		}
+	}
+	return c, nil
+}
+
+var durationRe = regexp.MustCompile(`^(-?)P(-?\d+)([DWMY])$`)
+
+// offsetDate moves t by a Templater offset: a number of days or a one-unit
+// ISO 8601 duration such as "P1W" or "-P1M".
+func offsetDate(t time.Time, offset string) (time.Time, error) {
+	if n, err := strconv.Atoi(offset); err == nil {
+		return t.AddDate(0, 0, n), nil
+	}
+	m := durationRe.FindStringSubmatch(strings.ToUpper(offset))
+	if m == nil {
+		return t, fmt.Errorf("invalid date offset %q (want days or a duration like P1W)", offset)
+	}
+	n, _ := strconv.Atoi(m[2])
+	if m[1] == "-" {
+		n = -n
+	}
+	switch m[3] {
+	case "W":
+		return t.AddDate(0, 0, 7*n), nil
+	case "M":
+		return t.AddDate(0, n, 0), nil
+	case "Y":
+		return t.AddDate(n, 0, 0), nil
+	}
+	return t.AddDate(0, 0, n), nil
+}
@@ -0,0 +1,244 @@
+// Package toml parses the subset of TOML used by the vault's configuration
+// files in .obsidian: triage rules and note schemas.
+package toml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Table is a parsed TOML table. Values are string, []string, Table or
+// []Table.
+type Table map[string]any
+
+// Parse parses the subset of TOML used by vault configuration files:
+// comments, bare and quoted keys, basic and literal strings, booleans,
+// numbers (kept as strings), single-line arrays of scalars, [table] and
+// [[array.of.tables]] headers with dotted names. It returns the root table;
+// arrays of tables are stored as []Table.
+func Parse(src string) (Table, error) {
+	root := Table{}
+	current := root
+
+	for i, raw := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripComment(strings.TrimSuffix(raw, "\r")))
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "[["):
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			keys, err := splitKey(strings.TrimSpace(line[2 : len(line)-2]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			parent, err := descend(root, keys[:len(keys)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			last := keys[len(keys)-1]
+			var list []Table
+			switch v := parent[last].(type) {
+			case nil:
+			case []Table:
+				list = v
+			default:
+				return nil, fmt.Errorf("line %d: %s is not an array of tables", lineNo, last)
+			}
+			current = Table{}
+			parent[last] = append(list, current)
+
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			keys, err := splitKey(strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			t, err := descend(root, keys)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			current = t
+
+		default:
+			eq := indexOutsideQuotes(line, '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+			}
+			keys, err := splitKey(strings.TrimSpace(line[:eq]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			value, err := parseValue(strings.TrimSpace(line[eq+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			t, err := descend(current, keys[:len(keys)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			last := keys[len(keys)-1]
+			if _, exists := t[last]; exists {
+				return nil, fmt.Errorf("line %d: duplicate key %q", lineNo, last)
+			}
+			t[last] = value
+		}
+	}
+	return root, nil
+}
+
+// descend walks (creating as needed) nested tables. When a key holds an array
+// of tables, the most recent element is used, as TOML specifies.
+func descend(t Table, keys []string) (Table, error) {
+	for _, k := range keys {
+		switch v := t[k].(type) {
+		case nil:
+			next := Table{}
+			t[k] = next
+			t = next
+		case Table:
+			t = v
+		case []Table:
+			t = v[len(v)-1]
+		default:
+			return nil, fmt.Errorf("%s is not a table", k)
+		}
+	}
+	return t, nil
+}
+
+// splitKey splits a dotted key, honouring quoted segments.
+func splitKey(s string) ([]string, error) {
+	var keys []string
+	for s != "" {
+		s = strings.TrimSpace(s)
+		var key string
+		if s[0] == '"' || s[0] == '\'' {
+			end := strings.IndexByte(s[1:], s[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted key")
+			}
+			key = s[1 : end+1]
+			s = strings.TrimSpace(s[end+2:])
+		} else {
+			end := strings.IndexByte(s, '.')
+			if end < 0 {
+				end = len(s)
+			}
+			key = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		if key == "" {
+			return nil, fmt.Errorf("empty key")
+		}
+		keys = append(keys, key)
+		if s != "" {
+			if s[0] != '.' {
+				return nil, fmt.Errorf("unexpected %q in key", s)
+			}
+			s = s[1:]
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return keys, nil
+}
+
+// parseValue parses a scalar or a single-line array of scalars.
+func parseValue(s string) (any, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	if s[0] == '[' {
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("arrays must be on a single line")
+		}
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		var items []string
+		for inner != "" {
+			comma := indexOutsideQuotes(inner, ',')
+			item := inner
+			if comma >= 0 {
+				item, inner = inner[:comma], strings.TrimSpace(inner[comma+1:])
+			} else {
+				inner = ""
+			}
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue // trailing comma
+			}
+			v, err := parseScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	}
+	return parseScalar(s)
+}
+
+// parseScalar parses a string, boolean or number. Non-strings are returned in
+// their literal form.
+func parseScalar(s string) (string, error) {
+	switch s[0] {
+	case '"':
+		if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		return v, nil
+	case '\'':
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : len(s)-1], nil
+	}
+	if s == "true" || s == "false" {
+		return s, nil
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64); err == nil {
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid value %q (strings must be quoted)", s)
+}
+
+// stripComment removes a trailing # comment outside of strings.
+func stripComment(line string) string {
+	if idx := indexOutsideQuotes(line, '#'); idx >= 0 {
+		return line[:idx]
+	}
+	return line
+}
+
+// indexOutsideQuotes returns the index of the first c not inside a quoted
+// string, or -1.
+func indexOutsideQuotes(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' && quote == '"' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
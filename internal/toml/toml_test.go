@@ -0,0 +1,74 @@
+package toml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	src := `
+# comment
+title = "Schemas" # trailing comment
+enabled = true
+
+[reference]
+required = ["source", 'author', ]
+"quoted key" = 'C:\literal'
+
+[reference.properties.source]
+type = "url"
+
+[[rule]]
+name = "first"
+[rule.match]
+tags = ["a#b"]
+
+[[rule]]
+name = "second"
+weight = 1.5
+`
+	got, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	want := Table{
+		"title":   "Schemas",
+		"enabled": "true",
+		"reference": Table{
+			"required":   []string{"source", "author"},
+			"quoted key": `C:\literal`,
+			"properties": Table{"source": Table{"type": "url"}},
+		},
+		"rule": []Table{
+			{"name": "first", "match": Table{"tags": []string{"a#b"}}},
+			{"name": "second", "weight": "1.5"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"name = oops\n", "line 1"},
+		{"[[rule]\n", "line 1: unterminated table header"},
+		{"[rule\n", "line 1: unterminated table header"},
+		{"name\n", "expected key = value"},
+		{"a = \"x\"\na = \"y\"\n", `line 2: duplicate key "a"`},
+		{"a = \"x\"\n[a.b]\n", "a is not a table"},
+		{"a = \"x\"\n[[a]]\n", "a is not an array of tables"},
+		{"a = [\"x\",\n", "single line"},
+		{"a = \"open\n", "unterminated string"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want containing %q", tt.src, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Attachment kinds reported in AttachmentInfo.Kind.
+const (
+	AttachmentImage  = "image"
+	AttachmentPDF    = "pdf"
+	AttachmentCanvas = "canvas"
+	AttachmentAudio  = "audio"
+	AttachmentVideo  = "video"
+	AttachmentOther  = "other"
+)
+
+// AttachmentInfo contains metadata about a non-markdown file in the vault.
+type AttachmentInfo struct {
+	Path    string `json:"path"`     // Relative path within vault
+	Name    string `json:"name"`     // Filename with extension
+	Kind    string `json:"kind"`     // image, pdf, canvas, audio, video or other
+	ModTime int64  `json:"mod_time"` // Unix timestamp of last modification
+	Size    int64  `json:"size"`     // File size in bytes
+}
+
+// AttachmentKind classifies a file by its extension.
+func AttachmentKind(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg", ".bmp", ".avif":
+		return AttachmentImage
+	case ".pdf":
+		return AttachmentPDF
+	case ".canvas":
+		return AttachmentCanvas
+	case ".mp3", ".wav", ".m4a", ".ogg", ".flac":
+		return AttachmentAudio
+	case ".mp4", ".mov", ".mkv", ".webm", ".ogv":
+		return AttachmentVideo
+	}
+	return AttachmentOther
+}
+
+// ListAttachments lists all non-markdown files in a vault directory.
+// dir is relative to vaultPath; empty string lists the entire vault.
+// Hidden files and directories (like .obsidian, .git, .DS_Store) are skipped.
+func ListAttachments(vaultPath, dir string) ([]AttachmentInfo, error) {
+	searchPath := vaultPath
+	if dir != "" {
+		searchPath = filepath.Join(vaultPath, dir)
+	}
+
+	info, err := os.Stat(searchPath)
+	if err != nil {
+		return nil, fmt.Errorf("directory not found: %s", dir)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", dir)
+	}
+
+	var attachments []AttachmentInfo
+	err = filepath.WalkDir(searchPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip inaccessible entries
+		}
+		if path == searchPath {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(vaultPath, path)
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		attachments = append(attachments, AttachmentInfo{
+			Path:    filepath.ToSlash(relPath),
+			Name:    d.Name(),
+			Kind:    AttachmentKind(d.Name()),
+			ModTime: info.ModTime().Unix(),
+			Size:    info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// HashFile returns the hex SHA-256 of a vault file's content.
+func HashFile(vaultPath, filePath string) (string, error) {
+	f, err := os.Open(filepath.Join(vaultPath, filePath))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,48 @@
+package vault
+
+import "strings"
+
+// momentTokens maps Moment.js format tokens, the date syntax of Obsidian's
+// daily notes, templates and properties, to Go layout elements. Longer
+// tokens come first so "YYYY" is not read as two "YY".
+var momentTokens = []struct{ moment, layout string }{
+	{"YYYY", "2006"}, {"YY", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dddd", "Monday"}, {"ddd", "Mon"},
+	{"DD", "02"}, {"D", "2"},
+	{"HH", "15"}, {"H", "15"}, {"hh", "03"}, {"h", "3"},
+	{"mm", "04"}, {"m", "4"},
+	{"ss", "05"}, {"s", "5"},
+	{"A", "PM"}, {"a", "pm"},
+	{"ZZ", "-0700"}, {"Z", "-07:00"},
+}
+
+// MomentLayout converts a Moment.js date format such as "YYYY-MM-DD" or
+// "dddd, MMMM D" to a Go time layout. Text in [brackets] is literal; other
+// characters are kept as they are.
+func MomentLayout(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end > 0 {
+				b.WriteString(format[i+1 : i+end])
+				i += end + 1
+				continue
+			}
+		}
+		matched := false
+		for _, t := range momentTokens {
+			if strings.HasPrefix(format[i:], t.moment) {
+				b.WriteString(t.layout)
+				i += len(t.moment)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	return b.String()
+}
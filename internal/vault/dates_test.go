@@ -0,0 +1,23 @@
+package vault
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMomentLayout(t *testing.T) {
+	at := time.Date(2026, 3, 7, 14, 5, 9, 0, time.UTC)
+	for format, want := range map[string]string{
+		"YYYY-MM-DD":           "2026-03-07",
+		"YYYY-MM-DDTHH:mm":     "2026-03-07T14:05",
+		"dddd, MMMM D YYYY":    "Saturday, March 7 2026",
+		"ddd D MMM YY":         "Sat 7 Mar 26",
+		"h:mm A":               "2:05 PM",
+		"[Week of] YYYY-MM-DD": "Week of 2026-03-07",
+		"YYYY/MM/DD HH:mm:ss":  "2026/03/07 14:05:09",
+	} {
+		if got := at.Format(MomentLayout(format)); got != want {
+			t.Errorf("MomentLayout(%q) formats as %q, want %q", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package vault
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Link kinds reported in Link.Kind.
+const (
+	LinkWiki     = "wikilink" // [[target#fragment|alias]]
+	LinkMarkdown = "markdown" // [text](target.md#fragment)
+)
+
+// Link is a reference from a note body to another note or file.
+type Link struct {
+	Kind     string `json:"kind"`               // LinkWiki or LinkMarkdown
+	Target   string `json:"target"`             // note name or path, without fragment; empty for same-note links
+	Fragment string `json:"fragment,omitempty"` // heading text or ^block-id after '#'
+	Alias    string `json:"alias,omitempty"`    // display text
+	Embed    bool   `json:"embed,omitempty"`    // true for ![[...]] and ![...](...)
+	Line     int    `json:"line"`               // 1-based line in the original content
+}
+
+// Tag is an inline #tag occurrence in a note body.
+type Tag struct {
+	Name string `json:"name"` // tag without the leading '#'
+	Line int    `json:"line"` // 1-based line in the original content
+}
+
+// BlockID is a ^block-id anchor at the end of a line.
+type BlockID struct {
+	ID   string `json:"id"`   // identifier without the leading '^'
+	Line int    `json:"line"` // 1-based line in the original content
+}
+
+var (
+	// wikiLinkRe matches [[...]] and ![[...]]; the inner text is split later.
+	wikiLinkRe = regexp.MustCompile(`(!?)\[\[([^\[\]]+)\]\]`)
+	// mdLinkRe matches [text](target) and ![alt](target), with an optional
+	// "title" and <angle-bracketed> targets that may contain spaces.
+	mdLinkRe = regexp.MustCompile(`(!?)\[([^\[\]]*)\]\((?:<([^>]+)>|([^)\s]+))(?:\s+"[^"]*")?\)`)
+	// inlineTagRe matches #tags preceded by line start or whitespace.
+	// Obsidian allows letters, digits, _, - and / (for nesting).
+	inlineTagRe = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]+)`)
+	blockIDRe   = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)\s*$`)
+	urlSchemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+	allDigitsRe = regexp.MustCompile(`^[0-9/]+$`)
+)
+
+// bodyScan holds everything extracted from a single pass over the body.
+type bodyScan struct {
+	headings []Heading
+	links    []Link
+	embeds   []Link
+	tags     []Tag
+	blockIDs []BlockID
+}
+
+// scanBody walks the body line by line, skipping fenced code blocks and
+// masking inline code spans, and extracts headings, links, embeds, inline
+// tags and block IDs. firstLine is the 1-based line number of the body's
+// first line within the original content.
+func scanBody(body string, firstLine int) bodyScan {
+	var out bodyScan
+	var fence string // opening fence marker while inside a fenced block
+
+	for i, line := range strings.Split(body, "\n") {
+		lineNo := firstLine + i
+		line = strings.TrimSuffix(line, "\r")
+
+		if marker := fenceMarker(line); marker != "" {
+			if fence == "" {
+				fence = marker
+				continue
+			}
+			if marker[0] == fence[0] && len(marker) >= len(fence) && strings.TrimSpace(line) == marker {
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			out.headings = append(out.headings, Heading{
+				Level: len(m[1]),
+				Text:  strings.TrimSpace(m[2]),
+			})
+		}
+
+		masked := maskInlineCode(line)
+
+		for _, m := range wikiLinkRe.FindAllStringSubmatch(masked, -1) {
+			l := parseWikiLinkInner(m[2])
+			l.Line = lineNo
+			if m[1] == "!" {
+				l.Embed = true
+				out.embeds = append(out.embeds, l)
+			} else {
+				out.links = append(out.links, l)
+			}
+		}
+
+		// Blank out wikilinks so their inner text is not re-read as markdown links or tags.
+		plain := wikiLinkRe.ReplaceAllStringFunc(masked, func(s string) string {
+			return strings.Repeat(" ", len(s))
+		})
+
+		for _, m := range mdLinkRe.FindAllStringSubmatch(plain, -1) {
+			l, ok := parseMarkdownLink(m[2], m[3]+m[4])
+			if !ok {
+				continue
+			}
+			l.Line = lineNo
+			if m[1] == "!" {
+				l.Embed = true
+				out.embeds = append(out.embeds, l)
+			} else {
+				out.links = append(out.links, l)
+			}
+		}
+
+		// Tags are read from text outside links so URL fragments don't count.
+		tagText := mdLinkRe.ReplaceAllStringFunc(plain, func(s string) string {
+			return strings.Repeat(" ", len(s))
+		})
+		for _, m := range inlineTagRe.FindAllStringSubmatch(tagText, -1) {
+			name := strings.TrimRight(m[1], "/")
+			// A tag needs at least one non-numeric character (#123 is not a tag).
+			if name == "" || allDigitsRe.MatchString(name) {
+				continue
+			}
+			out.tags = append(out.tags, Tag{Name: name, Line: lineNo})
+		}
+
+		if m := blockIDRe.FindStringSubmatch(masked); m != nil {
+			out.blockIDs = append(out.blockIDs, BlockID{ID: m[1], Line: lineNo})
+		}
+	}
+	return out
+}
+
+// fenceMarker returns the ``` or ~~~ run opening a fenced code block on this
+// line (indented at most three spaces), or "" if the line is not a fence.
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return ""
+	}
+	c := trimmed[0]
+	if c != '`' && c != '~' {
+		return ""
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == c {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return trimmed[:n]
+}
+
+// maskInlineCode replaces `code spans` (including their backticks) with spaces
+// so that links and tags inside them are ignored while column positions stay intact.
+func maskInlineCode(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+	b := []byte(line)
+	for i := 0; i < len(b); {
+		if b[i] != '`' {
+			i++
+			continue
+		}
+		// Measure the opening backtick run.
+		j := i
+		for j < len(b) && b[j] == '`' {
+			j++
+		}
+		run := j - i
+		// Find a closing run of exactly the same length.
+		closeAt := -1
+		for k := j; k < len(b); {
+			if b[k] != '`' {
+				k++
+				continue
+			}
+			e := k
+			for e < len(b) && b[e] == '`' {
+				e++
+			}
+			if e-k == run {
+				closeAt = e
+				break
+			}
+			k = e
+		}
+		if closeAt == -1 {
+			i = j // unmatched run is literal text
+			continue
+		}
+		for k := i; k < closeAt; k++ {
+			b[k] = ' '
+		}
+		i = closeAt
+	}
+	return string(b)
+}
+
+// parseWikiLinkInner splits the inside of [[...]] into target, fragment and alias.
+func parseWikiLinkInner(inner string) Link {
+	l := Link{Kind: LinkWiki}
+	target := inner
+	if idx := strings.Index(inner, "|"); idx >= 0 {
+		target = inner[:idx]
+		l.Alias = strings.TrimSpace(inner[idx+1:])
+	}
+	// Pipes escaped inside tables arrive as "\|".
+	target = strings.TrimSuffix(strings.TrimSpace(target), `\`)
+	if idx := strings.Index(target, "#"); idx >= 0 {
+		l.Fragment = strings.TrimSpace(target[idx+1:])
+		target = target[:idx]
+	}
+	l.Target = strings.TrimSpace(target)
+	return l
+}
+
+// parseMarkdownLink builds a Link from a markdown link's text and destination.
+// Returns false for external URLs (anything with a scheme).
+func parseMarkdownLink(text, dest string) (Link, bool) {
+	dest = strings.TrimSpace(dest)
+	if dest == "" || urlSchemeRe.MatchString(dest) {
+		return Link{}, false
+	}
+	l := Link{Kind: LinkMarkdown, Alias: strings.TrimSpace(text)}
+	if idx := strings.Index(dest, "#"); idx >= 0 {
+		l.Fragment = dest[idx+1:]
+		dest = dest[:idx]
+	}
+	if decoded, err := url.PathUnescape(dest); err == nil {
+		dest = decoded
+	}
+	if decoded, err := url.PathUnescape(l.Fragment); err == nil {
+		l.Fragment = decoded
+	}
+	l.Target = dest
+	return l, true
+}
+
+// NoteKeys returns lowercase lookup keys (without .md) under which the link's
+// target may be found, given the vault-relative path of the note containing it.
+// Markdown links are tried relative to the source note first, then from the
+// vault root. Returns nil for same-note links.
+func (l Link) NoteKeys(sourcePath string) []string {
+	if l.Target == "" {
+		return nil
+	}
+	target := strings.ToLower(strings.TrimSuffix(l.Target, ".md"))
+	if l.Kind != LinkMarkdown {
+		return []string{target}
+	}
+	var keys []string
+	dir := path.Dir(strings.ReplaceAll(sourcePath, `\`, "/"))
+	if rel := path.Clean(path.Join(dir, target)); !strings.HasPrefix(rel, "../") {
+		keys = append(keys, strings.ToLower(rel))
+	}
+	if root := strings.TrimPrefix(path.Clean("/"+target), "/"); len(keys) == 0 || keys[0] != root {
+		keys = append(keys, root)
+	}
+	return keys
+}
+
+// IsAttachment reports whether the link points at a non-markdown file
+// (image, PDF, canvas, ...), judged by its extension.
+func (l Link) IsAttachment() bool {
+	ext := strings.ToLower(path.Ext(l.Target))
+	return ext != "" && ext != ".md" && len(ext) <= 6 && !strings.ContainsAny(ext, " ")
+}
+
+// extractAliases reads the aliases (or legacy alias) frontmatter key.
+func extractAliases(fm map[string]any) []string {
+	var raw []string
+	for _, key := range []string{"aliases", "alias"} {
+		switch v := fm[key].(type) {
+		case []string:
+			raw = append(raw, v...)
+		case string:
+			// A bare string may hold a comma-separated list.
+			for _, a := range strings.Split(v, ",") {
+				raw = append(raw, a)
+			}
+		}
+	}
+	var aliases []string
+	seen := make(map[string]bool)
+	for _, a := range raw {
+		a = strings.Trim(strings.TrimSpace(a), `"'`)
+		if a != "" && !seen[strings.ToLower(a)] {
+			seen[strings.ToLower(a)] = true
+			aliases = append(aliases, a)
+		}
+	}
+	return aliases
+}
+
+// AllTags returns the note's frontmatter and inline tags, without '#',
+// de-duplicated case-insensitively in first-seen order.
+func (n *Note) AllTags() []string {
+	var raw []string
+	switch v := n.Frontmatter["tags"].(type) {
+	case []string:
+		raw = append(raw, v...)
+	case string:
+		for _, t := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' }) {
+			raw = append(raw, t)
+		}
+	}
+	for _, t := range n.Tags {
+		raw = append(raw, t.Name)
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, t := range raw {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "#")
+		if t != "" && !seen[strings.ToLower(t)] {
+			seen[strings.ToLower(t)] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -1,5 +1,6 @@
 // Package vault provides utilities for reading and manipulating Obsidian vault notes.
-// It handles frontmatter YAML parsing, wikilink extraction, and heading extraction.
+// It handles frontmatter YAML parsing and extraction of headings, links, embeds,
+// inline tags, aliases and block IDs.
 package vault
 
 import (
@@ -14,7 +15,12 @@ type Note struct {
 	Frontmatter map[string]any `json:"frontmatter,omitempty"`
 	Body        string         `json:"body"`
 	Headings    []Heading      `json:"headings,omitempty"`
-	Wikilinks   []string       `json:"wikilinks,omitempty"`
+	Wikilinks   []string       `json:"wikilinks,omitempty"` // unique [[target#fragment]] values, embeds excluded
+	Links       []Link         `json:"links,omitempty"`     // wikilinks and local markdown links, in order
+	Embeds      []Link         `json:"embeds,omitempty"`    // ![[...]] and ![...](...) embeds
+	Tags        []Tag          `json:"tags,omitempty"`      // inline #tags in the body
+	Aliases     []string       `json:"aliases,omitempty"`   // frontmatter aliases
+	BlockIDs    []BlockID      `json:"block_ids,omitempty"` // ^block-id anchors
 }
 
 // Heading represents a markdown heading with its level and text.
@@ -23,12 +29,11 @@ type Heading struct {
 	Text  string `json:"text"`
 }
 
-var (
-	wikilinkRe = regexp.MustCompile(`\[\[([^\]|]+)(?:\|[^\]]+)?\]\]`)
-	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
-)
+var headingRe = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
 
-// ParseNote parses a markdown string into frontmatter, body, headings, and wikilinks.
+// ParseNote parses a markdown string into frontmatter, body, headings, links,
+// embeds, inline tags, aliases and block IDs. Content inside fenced code blocks
+// and inline code spans is ignored when extracting links, tags and headings.
 func ParseNote(content string) *Note {
 	note := &Note{
 		Frontmatter: make(map[string]any),
@@ -43,13 +48,34 @@ func ParseNote(content string) *Note {
 		}
 	}
 
+	// Line numbers are reported relative to the original content, so count
+	// the lines consumed by the frontmatter block.
+	firstLine := strings.Count(content[:len(content)-len(body)], "\n") + 1
+
+	scan := scanBody(body, firstLine)
+
 	note.Body = body
-	note.Headings = extractHeadings(body)
-	note.Wikilinks = extractWikilinks(body)
+	note.Headings = scan.headings
+	note.Links = scan.links
+	note.Embeds = scan.embeds
+	note.Tags = scan.tags
+	note.BlockIDs = scan.blockIDs
+	note.Aliases = extractAliases(note.Frontmatter)
+	note.Wikilinks = uniqueWikilinks(scan.links)
 
 	return note
 }
 
+// SplitFrontmatter returns the raw frontmatter block of content (without
+// the --- delimiters) and the body after it. ok is false when content does
+// not start with a complete frontmatter block.
+func SplitFrontmatter(content string) (fm, body string, ok bool) {
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return "", content, false
+	}
+	return splitFrontmatter(content)
+}
+
 // splitFrontmatter splits content at the YAML frontmatter delimiters (---).
 // Returns the frontmatter content (without delimiters), the remaining body, and whether
 // a valid frontmatter block was found.
@@ -94,6 +120,9 @@ func splitFrontmatter(content string) (string, string, bool) {
 	return fm, body, true
 }
 
+// yamlUnescaper undoes the \" and \\ escapes of a double-quoted YAML scalar.
+var yamlUnescaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+
 // parseFrontmatterYAML parses simple YAML key-value pairs from frontmatter.
 // Supports string values, lists (- item), and inline lists [a, b].
 // This is a lightweight parser for common Obsidian frontmatter patterns
@@ -156,7 +185,11 @@ func parseFrontmatterYAML(fm string) map[string]any {
 		if len(value) >= 2 {
 			if (value[0] == '"' && value[len(value)-1] == '"') ||
 				(value[0] == '\'' && value[len(value)-1] == '\'') {
-				value = value[1 : len(value)-1]
+				if value[0] == '"' {
+					value = yamlUnescaper.Replace(value[1 : len(value)-1])
+				} else {
+					value = value[1 : len(value)-1]
+				}
 				wasQuoted = true
 			}
 		}
@@ -197,41 +230,26 @@ func parseFrontmatterYAML(fm string) map[string]any {
 	return result
 }
 
-// extractHeadings finds all markdown headings in the body.
-func extractHeadings(body string) []Heading {
-	var headings []Heading
-	scanner := bufio.NewScanner(strings.NewReader(body))
-	for scanner.Scan() {
-		matches := headingRe.FindStringSubmatch(scanner.Text())
-		if matches != nil {
-			headings = append(headings, Heading{
-				Level: len(matches[1]),
-				Text:  strings.TrimSpace(matches[2]),
-			})
-		}
-	}
-	return headings
-}
-
-// extractWikilinks finds all [[wikilink]] targets in the body.
-// For aliased links like [[target|alias]], only the target is returned.
-// Duplicates are removed.
-func extractWikilinks(body string) []string {
-	matches := wikilinkRe.FindAllStringSubmatch(body, -1)
-	if matches == nil {
-		return nil
-	}
-
+// uniqueWikilinks returns the distinct [[wikilink]] targets (with any #fragment)
+// in order of first appearance. For aliased links like [[target|alias]], only
+// the target is returned.
+func uniqueWikilinks(links []Link) []string {
 	seen := make(map[string]bool)
-	var links []string
-	for _, m := range matches {
-		target := strings.TrimSpace(m[1])
-		if !seen[target] {
+	var out []string
+	for _, l := range links {
+		if l.Kind != LinkWiki {
+			continue
+		}
+		target := l.Target
+		if l.Fragment != "" {
+			target += "#" + l.Fragment
+		}
+		if target != "" && !seen[target] {
 			seen[target] = true
-			links = append(links, target)
+			out = append(out, target)
 		}
 	}
-	return links
+	return out
 }
 
 // FormatFrontmatter converts a map of key-value pairs into YAML frontmatter block.
@@ -74,6 +74,11 @@ func TestParseNote_QuotedValues(t *testing.T) {
 	if note.Frontmatter["author"] != "Jane Doe" {
 		t.Errorf("expected author 'Jane Doe', got %v", note.Frontmatter["author"])
 	}
+
+	note = ParseNote("---\ntitle: \"Say \\\"hi\\\" to C:\\\\tmp\"\npath: 'C:\\tmp'\n---\n")
+	if note.Frontmatter["title"] != `Say "hi" to C:\tmp` || note.Frontmatter["path"] != `C:\tmp` {
+		t.Errorf("escapes: title %q, path %q", note.Frontmatter["title"], note.Frontmatter["path"])
+	}
 }
 
 func TestParseNote_MultipleHeadings(t *testing.T) {
@@ -143,3 +148,124 @@ func TestFormatFrontmatter_Empty(t *testing.T) {
 		t.Errorf("expected empty string for empty map, got %q", result)
 	}
 }
+
+func TestParseNote_InlineTags(t *testing.T) {
+	content := "---\ntags: [work]\n---\n# Heading\n\nA #project/alpha note with #Work and #123.\nSee https://example.com/#frag and `#code`.\n"
+
+	note := ParseNote(content)
+
+	if len(note.Tags) != 2 {
+		t.Fatalf("expected 2 inline tags, got %v", note.Tags)
+	}
+	if note.Tags[0].Name != "project/alpha" || note.Tags[0].Line != 6 {
+		t.Errorf("tag 0: expected project/alpha on line 6, got %+v", note.Tags[0])
+	}
+	if note.Tags[1].Name != "Work" {
+		t.Errorf("tag 1: expected Work, got %+v", note.Tags[1])
+	}
+
+	all := note.AllTags()
+	if len(all) != 2 || all[0] != "work" || all[1] != "project/alpha" {
+		t.Errorf("AllTags() = %v, want [work project/alpha]", all)
+	}
+}
+
+func TestParseNote_Aliases(t *testing.T) {
+	content := "---\naliases:\n  - Go Lang\n  - golang\n---\nBody\n"
+
+	note := ParseNote(content)
+
+	if len(note.Aliases) != 2 || note.Aliases[0] != "Go Lang" || note.Aliases[1] != "golang" {
+		t.Errorf("expected aliases [Go Lang golang], got %v", note.Aliases)
+	}
+
+	note = ParseNote("---\naliases: Single\n---\n")
+	if len(note.Aliases) != 1 || note.Aliases[0] != "Single" {
+		t.Errorf("expected aliases [Single], got %v", note.Aliases)
+	}
+}
+
+func TestParseNote_LinksAndEmbeds(t *testing.T) {
+	content := "---\ntitle: T\n---\n" +
+		"Link [[Target#Section|shown]] and [[Other]].\n" +
+		"Embed ![[diagram.png]] and ![[Note#^abc]].\n" +
+		"Markdown [text](Folder/My%20Note.md#Intro) and [web](https://example.com).\n" +
+		"Image ![alt](<assets/pic one.png>)\n"
+
+	note := ParseNote(content)
+
+	if len(note.Links) != 3 {
+		t.Fatalf("expected 3 links, got %+v", note.Links)
+	}
+	l := note.Links[0]
+	if l.Kind != LinkWiki || l.Target != "Target" || l.Fragment != "Section" || l.Alias != "shown" || l.Line != 4 {
+		t.Errorf("link 0 unexpected: %+v", l)
+	}
+	md := note.Links[2]
+	if md.Kind != LinkMarkdown || md.Target != "Folder/My Note.md" || md.Fragment != "Intro" || md.Alias != "text" || md.Line != 6 {
+		t.Errorf("markdown link unexpected: %+v", md)
+	}
+
+	if len(note.Embeds) != 3 {
+		t.Fatalf("expected 3 embeds, got %+v", note.Embeds)
+	}
+	if !note.Embeds[0].Embed || note.Embeds[0].Target != "diagram.png" || !note.Embeds[0].IsAttachment() {
+		t.Errorf("embed 0 unexpected: %+v", note.Embeds[0])
+	}
+	if note.Embeds[1].Target != "Note" || note.Embeds[1].Fragment != "^abc" || note.Embeds[1].IsAttachment() {
+		t.Errorf("embed 1 unexpected: %+v", note.Embeds[1])
+	}
+	if note.Embeds[2].Target != "assets/pic one.png" || note.Embeds[2].Line != 7 {
+		t.Errorf("embed 2 unexpected: %+v", note.Embeds[2])
+	}
+
+	// Embeds are not reported as wikilinks.
+	if len(note.Wikilinks) != 2 || note.Wikilinks[0] != "Target#Section" || note.Wikilinks[1] != "Other" {
+		t.Errorf("expected wikilinks [Target#Section Other], got %v", note.Wikilinks)
+	}
+}
+
+func TestParseNote_SkipsCode(t *testing.T) {
+	content := "Real [[keep]]\n\n```go\n// [[fenced]] #fenced\n# not a heading\n```\n\n~~~~\n[[tilde]]\n```\nstill inside\n~~~~\n\nInline `[[inline]]` and ``[[double `tick`]]`` #real\n"
+
+	note := ParseNote(content)
+
+	if len(note.Wikilinks) != 1 || note.Wikilinks[0] != "keep" {
+		t.Errorf("expected wikilinks [keep], got %v", note.Wikilinks)
+	}
+	if len(note.Headings) != 0 {
+		t.Errorf("expected no headings from code block, got %v", note.Headings)
+	}
+	if len(note.Tags) != 1 || note.Tags[0].Name != "real" {
+		t.Errorf("expected tags [real], got %v", note.Tags)
+	}
+}
+
+func TestParseNote_BlockIDs(t *testing.T) {
+	content := "A paragraph. ^para-1\n\n- item ^item2\nnot^anid\n"
+
+	note := ParseNote(content)
+
+	if len(note.BlockIDs) != 2 {
+		t.Fatalf("expected 2 block IDs, got %v", note.BlockIDs)
+	}
+	if note.BlockIDs[0].ID != "para-1" || note.BlockIDs[0].Line != 1 {
+		t.Errorf("block 0 unexpected: %+v", note.BlockIDs[0])
+	}
+	if note.BlockIDs[1].ID != "item2" || note.BlockIDs[1].Line != 3 {
+		t.Errorf("block 1 unexpected: %+v", note.BlockIDs[1])
+	}
+}
+
+func TestLink_NoteKeys(t *testing.T) {
+	wiki := Link{Kind: LinkWiki, Target: "My Note"}
+	if got := wiki.NoteKeys("a/b.md"); len(got) != 1 || got[0] != "my note" {
+		t.Errorf("wikilink NoteKeys = %v", got)
+	}
+
+	md := Link{Kind: LinkMarkdown, Target: "../Other.md"}
+	got := md.NoteKeys("Projects/x/b.md")
+	if len(got) != 2 || got[0] != "projects/other" || got[1] != "other" {
+		t.Errorf("markdown NoteKeys = %v", got)
+	}
+}
@@ -0,0 +1,417 @@
+package vault
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ResolveStatus describes the outcome of resolving a link target.
+type ResolveStatus string
+
+const (
+	// Resolved means the target maps to exactly one file.
+	Resolved ResolveStatus = "resolved"
+	// Ambiguous means several notes share the linked basename or alias.
+	// Resolution.Path holds Obsidian's best guess; Candidates lists all of them.
+	Ambiguous ResolveStatus = "ambiguous"
+	// Missing means no note, alias or attachment matches the target.
+	Missing ResolveStatus = "missing"
+)
+
+// Resolution is the result of resolving a link.
+type Resolution struct {
+	Status     ResolveStatus `json:"status"`
+	Path       string        `json:"path,omitempty"`       // vault-relative path of the resolved file
+	Candidates []string      `json:"candidates,omitempty"` // all matches when ambiguous
+	ViaAlias   bool          `json:"via_alias,omitempty"`  // matched a frontmatter alias rather than a filename
+	Attachment bool          `json:"attachment,omitempty"` // resolved to a non-markdown file
+	// FragmentMissing is set when the file exists but the #heading or #^block
+	// named by the link does not.
+	FragmentMissing bool `json:"fragment_missing,omitempty"`
+}
+
+// resolverEntry holds what the resolver knows about one note.
+type resolverEntry struct {
+	path     string
+	headings map[string]bool // normalized heading text
+	blocks   map[string]bool // lowercased block IDs
+	indexed  bool            // headings/blocks were registered (fragments can be checked)
+}
+
+// Resolver resolves link targets to vault files following Obsidian's rules:
+// a bare name matches a note's basename, a partial path matches the end of a
+// note's path, names with an extension match attachments, aliases are used
+// when no filename matches, and markdown links are tried relative to the
+// linking note before the vault root.
+type Resolver struct {
+	notes       map[string]*resolverEntry // lowercased path without .md -> entry
+	byName      map[string][]string       // lowercased basename (no .md) -> note paths
+	byAlias     map[string][]string       // lowercased alias -> note paths
+	attachments map[string]string         // lowercased path -> path
+	attByName   map[string][]string       // lowercased attachment filename -> paths
+}
+
+// NewResolver returns an empty resolver. Register files with AddNote and AddAttachment.
+func NewResolver() *Resolver {
+	return &Resolver{
+		notes:       make(map[string]*resolverEntry),
+		byName:      make(map[string][]string),
+		byAlias:     make(map[string][]string),
+		attachments: make(map[string]string),
+		attByName:   make(map[string][]string),
+	}
+}
+
+// LoadResolver registers every note in the vault (with its aliases, headings
+// and block IDs) and every attachment. Hidden directories are skipped.
+func LoadResolver(vaultPath string) (*Resolver, error) {
+	notes, err := ListNotes(vaultPath, "")
+	if err != nil {
+		return nil, err
+	}
+	r := NewResolver()
+	for _, info := range notes {
+		// Unreadable notes are still registered so links to them resolve.
+		note, _ := ReadNote(vaultPath, info.Path)
+		r.AddNote(info.Path, note)
+	}
+	if err := r.AddAttachments(vaultPath); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddAttachments registers every non-markdown file in the vault.
+func (r *Resolver) AddAttachments(vaultPath string) error {
+	attachments, err := ListAttachments(vaultPath, "")
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		r.AddAttachment(a.Path)
+	}
+	return nil
+}
+
+// AddNote registers a note. note may be nil when only the path is known, in
+// which case fragments in links to it are not checked.
+func (r *Resolver) AddNote(notePath string, note *Note) {
+	var aliases []string
+	if note != nil {
+		aliases = note.Aliases
+	}
+	e := r.addNotePath(notePath, aliases)
+	if note == nil {
+		return
+	}
+	e.indexed = true
+	for _, h := range note.Headings {
+		e.headings[normalizeHeading(h.Text)] = true
+	}
+	for _, b := range note.BlockIDs {
+		e.blocks[strings.ToLower(b.ID)] = true
+	}
+}
+
+// AddNoteAliases registers a note by path and aliases only (e.g. from the index).
+func (r *Resolver) AddNoteAliases(notePath string, aliases []string) {
+	r.addNotePath(notePath, aliases)
+}
+
+func (r *Resolver) addNotePath(notePath string, aliases []string) *resolverEntry {
+	notePath = filepath.ToSlash(notePath)
+	key := strings.ToLower(strings.TrimSuffix(notePath, ".md"))
+	e, ok := r.notes[key]
+	if !ok {
+		e = &resolverEntry{
+			path:     notePath,
+			headings: make(map[string]bool),
+			blocks:   make(map[string]bool),
+		}
+		r.notes[key] = e
+		name := strings.ToLower(strings.TrimSuffix(path.Base(notePath), ".md"))
+		r.byName[name] = append(r.byName[name], notePath)
+	}
+	for _, a := range aliases {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a != "" && !containsPath(r.byAlias[a], notePath) {
+			r.byAlias[a] = append(r.byAlias[a], notePath)
+		}
+	}
+	return e
+}
+
+// AddAttachment registers a non-markdown file (image, PDF, canvas, ...).
+func (r *Resolver) AddAttachment(filePath string) {
+	filePath = filepath.ToSlash(filePath)
+	key := strings.ToLower(filePath)
+	if _, ok := r.attachments[key]; ok {
+		return
+	}
+	r.attachments[key] = filePath
+	name := strings.ToLower(path.Base(filePath))
+	r.attByName[name] = append(r.attByName[name], filePath)
+}
+
+// Notes returns the paths of all registered notes, sorted.
+func (r *Resolver) Notes() []string {
+	paths := make([]string, 0, len(r.notes))
+	for _, e := range r.notes {
+		paths = append(paths, e.path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Aliases returns the aliases registered for every note, keyed by lowercased alias.
+func (r *Resolver) Aliases() map[string][]string {
+	return r.byAlias
+}
+
+// Resolve resolves a parsed link from the note at sourcePath.
+func (r *Resolver) Resolve(sourcePath string, l Link) Resolution {
+	if l.Target == "" {
+		// [[#Heading]] points into the linking note itself.
+		res := Resolution{Status: Resolved, Path: filepath.ToSlash(sourcePath)}
+		res.FragmentMissing = r.fragmentMissing(res.Path, l.Fragment)
+		return res
+	}
+
+	var res Resolution
+	if l.Kind == LinkMarkdown {
+		res = r.resolveMarkdown(sourcePath, l.Target)
+	} else {
+		res = r.resolveTarget(sourcePath, l.Target)
+	}
+	if res.Status != Missing && !res.Attachment {
+		res.FragmentMissing = r.fragmentMissing(res.Path, l.Fragment)
+	}
+	return res
+}
+
+// ResolveTarget resolves a raw wikilink target such as "Note", "Folder/Note",
+// "Note#Heading" or "image.png" from the note at sourcePath.
+func (r *Resolver) ResolveTarget(sourcePath, target string) Resolution {
+	return r.Resolve(sourcePath, parseWikiLinkInner(target))
+}
+
+// resolveTarget applies Obsidian's wikilink rules to a target without fragment.
+func (r *Resolver) resolveTarget(sourcePath, target string) Resolution {
+	target = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(target)), "/")
+	lower := strings.ToLower(target)
+
+	// Attachments: the target carries a non-markdown extension.
+	if (Link{Target: target}).IsAttachment() {
+		if p, ok := r.attachments[lower]; ok {
+			return Resolution{Status: Resolved, Path: p, Attachment: true}
+		}
+		if cands := r.attachmentsBySuffix(lower); len(cands) > 0 {
+			res := pick(sourcePath, cands)
+			res.Attachment = true
+			return res
+		}
+		// Fall through: "v1.2" style note names look like extensions.
+	}
+
+	key := strings.TrimSuffix(lower, ".md")
+
+	// Exact vault path.
+	if e, ok := r.notes[key]; ok {
+		return Resolution{Status: Resolved, Path: e.path}
+	}
+
+	// Bare name or partial path: match basename, then require the path suffix.
+	name := path.Base(key)
+	var cands []string
+	for _, p := range r.byName[name] {
+		pk := strings.ToLower(strings.TrimSuffix(p, ".md"))
+		if pk == key || strings.HasSuffix(pk, "/"+key) {
+			cands = append(cands, p)
+		}
+	}
+	if len(cands) > 0 {
+		return pick(sourcePath, cands)
+	}
+
+	// Aliases only apply to whole targets, not paths.
+	if aliased := r.byAlias[lower]; len(aliased) > 0 {
+		res := pick(sourcePath, aliased)
+		res.ViaAlias = true
+		return res
+	}
+
+	return Resolution{Status: Missing}
+}
+
+// resolveMarkdown resolves a markdown link path, relative to the linking note
+// first and then from the vault root.
+func (r *Resolver) resolveMarkdown(sourcePath, target string) Resolution {
+	target = filepath.ToSlash(target)
+	dir := path.Dir(filepath.ToSlash(sourcePath))
+	for _, candidate := range []string{path.Join(dir, target), path.Clean("/" + target)[1:]} {
+		if strings.HasPrefix(candidate, "../") {
+			continue
+		}
+		lower := strings.ToLower(candidate)
+		if p, ok := r.attachments[lower]; ok {
+			return Resolution{Status: Resolved, Path: p, Attachment: true}
+		}
+		if e, ok := r.notes[strings.TrimSuffix(lower, ".md")]; ok {
+			return Resolution{Status: Resolved, Path: e.path}
+		}
+	}
+	// Markdown links written without a folder behave like wikilinks.
+	if !strings.Contains(target, "/") {
+		return r.resolveTarget(sourcePath, target)
+	}
+	return Resolution{Status: Missing}
+}
+
+// attachmentsBySuffix returns attachments whose path ends with the given
+// lowercased name or partial path.
+func (r *Resolver) attachmentsBySuffix(lower string) []string {
+	var cands []string
+	for _, p := range r.attByName[path.Base(lower)] {
+		pl := strings.ToLower(p)
+		if pl == lower || strings.HasSuffix(pl, "/"+lower) {
+			cands = append(cands, p)
+		}
+	}
+	return cands
+}
+
+// pick turns a candidate list into a resolution. With several candidates the
+// link is ambiguous; the best guess prefers a file in the linking note's
+// folder, then the shortest path, then lexical order.
+func pick(sourcePath string, cands []string) Resolution {
+	if len(cands) == 1 {
+		return Resolution{Status: Resolved, Path: cands[0]}
+	}
+	sorted := append([]string(nil), cands...)
+	sourceDir := path.Dir(filepath.ToSlash(sourcePath))
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := path.Dir(sorted[i]) == sourceDir, path.Dir(sorted[j]) == sourceDir
+		if si != sj {
+			return si
+		}
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) < len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	return Resolution{Status: Ambiguous, Path: sorted[0], Candidates: sorted}
+}
+
+// fragmentMissing reports whether notePath lacks the heading or block named by
+// fragment. Unknown notes and empty fragments are never reported missing.
+func (r *Resolver) fragmentMissing(notePath, fragment string) bool {
+	if fragment == "" {
+		return false
+	}
+	e, ok := r.notes[strings.ToLower(strings.TrimSuffix(notePath, ".md"))]
+	if !ok || !e.indexed {
+		return false
+	}
+	if strings.HasPrefix(fragment, "^") {
+		return !e.blocks[strings.ToLower(fragment[1:])]
+	}
+	// Nested heading links ([[Note#H1#H2]]) point at the last heading.
+	parts := strings.Split(fragment, "#")
+	return !e.headings[normalizeHeading(parts[len(parts)-1])]
+}
+
+var headingPunctRe = regexp.MustCompile(`[\s\-_:]+`)
+
+// normalizeHeading folds case, whitespace and separator punctuation so that
+// "Setup: Step 1", "setup step-1" and "Setup  Step 1" compare equal.
+func normalizeHeading(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.TrimSpace(headingPunctRe.ReplaceAllString(h, " "))
+}
+
+// LinkText returns the shortest link text that uniquely identifies the note at
+// notePath, as Obsidian writes it with "shortest path when possible": the bare
+// basename when unique, otherwise the shortest unique trailing path. The .md
+// extension is omitted.
+func (r *Resolver) LinkText(notePath string) string {
+	notePath = filepath.ToSlash(notePath)
+	noExt := strings.TrimSuffix(notePath, ".md")
+	name := strings.ToLower(path.Base(noExt))
+	others := r.byName[name]
+	if len(others) <= 1 {
+		return path.Base(noExt)
+	}
+
+	parts := strings.Split(noExt, "/")
+	for n := 2; n <= len(parts); n++ {
+		suffix := strings.ToLower(strings.Join(parts[len(parts)-n:], "/"))
+		unique := true
+		for _, o := range others {
+			if o == notePath {
+				continue
+			}
+			ol := strings.ToLower(strings.TrimSuffix(o, ".md"))
+			if ol == suffix || strings.HasSuffix(ol, "/"+suffix) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return strings.Join(parts[len(parts)-n:], "/")
+		}
+	}
+	return noExt
+}
+
+// ResolveLoose resolves a free-form name such as an LLM-extracted entity:
+// first exactly like a wikilink, then by comparing slugified names against
+// note basenames and aliases ("Golang Error Handling" finds
+// golang-error-handling.md). Returns Missing when nothing matches.
+func (r *Resolver) ResolveLoose(name string) Resolution {
+	if res := r.resolveTarget("", name); res.Status != Missing && !res.Attachment {
+		return res
+	}
+	slug := slugKey(name)
+	if slug == "" {
+		return Resolution{Status: Missing}
+	}
+	var cands []string
+	for base, paths := range r.byName {
+		if slugKey(base) == slug {
+			cands = append(cands, paths...)
+		}
+	}
+	if len(cands) == 0 {
+		for alias, paths := range r.byAlias {
+			if slugKey(alias) == slug {
+				cands = append(cands, paths...)
+			}
+		}
+	}
+	if len(cands) == 0 {
+		return Resolution{Status: Missing}
+	}
+	sort.Strings(cands)
+	return pick("", cands)
+}
+
+var slugNonAlnumRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugKey lowercases s and replaces runs of non-alphanumeric characters with
+// single hyphens. Returns "" when nothing alphanumeric remains.
+func slugKey(s string) string {
+	s = slugNonAlnumRe.ReplaceAllString(strings.ToLower(s), "-")
+	return strings.Trim(s, "-")
+}
+
+func containsPath(paths []string, p string) bool {
+	for _, x := range paths {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testResolver() *Resolver {
+	r := NewResolver()
+	r.AddNote("Notes/Go.md", ParseNote("---\naliases: [golang]\n---\n# Go\n\n## Error Handling\n\nText ^intro\n"))
+	r.AddNote("Projects/plan.md", ParseNote("# Plan\n"))
+	r.AddNote("Archive/plan.md", ParseNote("# Old plan\n"))
+	r.AddNote("Projects/sub/readme.md", nil)
+	r.AddAttachment("Attachments/diagram.png")
+	return r
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	r := testResolver()
+	tests := []struct {
+		name       string
+		source     string
+		link       Link
+		wantStatus ResolveStatus
+		wantPath   string
+	}{
+		{"basename", "x.md", Link{Kind: LinkWiki, Target: "go"}, Resolved, "Notes/Go.md"},
+		{"full path", "x.md", Link{Kind: LinkWiki, Target: "Notes/Go"}, Resolved, "Notes/Go.md"},
+		{"partial path", "x.md", Link{Kind: LinkWiki, Target: "sub/readme"}, Resolved, "Projects/sub/readme.md"},
+		{"alias", "x.md", Link{Kind: LinkWiki, Target: "Golang"}, Resolved, "Notes/Go.md"},
+		{"attachment", "x.md", Link{Kind: LinkWiki, Target: "diagram.png", Embed: true}, Resolved, "Attachments/diagram.png"},
+		{"qualified disambiguates", "x.md", Link{Kind: LinkWiki, Target: "Archive/plan"}, Resolved, "Archive/plan.md"},
+		{"ambiguous prefers same folder", "Archive/x.md", Link{Kind: LinkWiki, Target: "plan"}, Ambiguous, "Archive/plan.md"},
+		{"markdown relative", "Projects/x.md", Link{Kind: LinkMarkdown, Target: "../Notes/Go.md"}, Resolved, "Notes/Go.md"},
+		{"markdown from root", "Projects/x.md", Link{Kind: LinkMarkdown, Target: "Notes/Go.md"}, Resolved, "Notes/Go.md"},
+		{"missing", "x.md", Link{Kind: LinkWiki, Target: "nope"}, Missing, ""},
+		{"wrong folder", "x.md", Link{Kind: LinkWiki, Target: "Other/plan"}, Missing, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Resolve(tt.source, tt.link)
+			if got.Status != tt.wantStatus || got.Path != tt.wantPath {
+				t.Errorf("Resolve() = %s %q, want %s %q", got.Status, got.Path, tt.wantStatus, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestResolver_Ambiguous(t *testing.T) {
+	r := testResolver()
+	got := r.ResolveTarget("x.md", "plan")
+	if got.Status != Ambiguous {
+		t.Fatalf("Status = %s, want ambiguous", got.Status)
+	}
+	if len(got.Candidates) != 2 || got.Candidates[0] != got.Path {
+		t.Errorf("Candidates = %v, want 2 with best guess %q first", got.Candidates, got.Path)
+	}
+}
+
+func TestResolver_Fragments(t *testing.T) {
+	r := testResolver()
+	tests := []struct {
+		target      string
+		wantMissing bool
+	}{
+		{"Go#Error Handling", false},
+		{"Go#error-handling", false},
+		{"Go#Go#Error Handling", false},
+		{"Go#Nope", true},
+		{"Go#^intro", false},
+		{"Go#^outro", true},
+		{"sub/readme#Anything", false}, // headings unknown: never reported
+	}
+	for _, tt := range tests {
+		got := r.ResolveTarget("x.md", tt.target)
+		if got.Status == Missing {
+			t.Errorf("ResolveTarget(%q) missing", tt.target)
+			continue
+		}
+		if got.FragmentMissing != tt.wantMissing {
+			t.Errorf("ResolveTarget(%q).FragmentMissing = %v, want %v", tt.target, got.FragmentMissing, tt.wantMissing)
+		}
+	}
+}
+
+func TestResolver_LinkText(t *testing.T) {
+	r := testResolver()
+	tests := []struct{ path, want string }{
+		{"Notes/Go.md", "Go"},
+		{"Projects/plan.md", "Projects/plan"},
+		{"Archive/plan.md", "Archive/plan"},
+	}
+	for _, tt := range tests {
+		if got := r.LinkText(tt.path); got != tt.want {
+			t.Errorf("LinkText(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestResolver_ResolveLoose(t *testing.T) {
+	r := NewResolver()
+	r.AddNote("Notes/golang-error-handling.md", nil)
+	r.AddNoteAliases("Notes/k8s.md", []string{"Kubernetes"})
+
+	if got := r.ResolveLoose("Golang Error Handling"); got.Path != "Notes/golang-error-handling.md" {
+		t.Errorf("ResolveLoose(slug) = %+v", got)
+	}
+	if got := r.ResolveLoose("kubernetes"); got.Path != "Notes/k8s.md" || !got.ViaAlias {
+		t.Errorf("ResolveLoose(alias) = %+v", got)
+	}
+	if got := r.ResolveLoose("Missing Thing"); got.Status != Missing {
+		t.Errorf("ResolveLoose(missing) = %+v", got)
+	}
+}
+
+func TestLoadResolver(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"Notes/a.md":         "---\naliases: [Alpha]\n---\n# A\n",
+		"img/photo.jpg":      "binary",
+		".obsidian/skip.png": "hidden",
+	} {
+		full := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := LoadResolver(dir)
+	if err != nil {
+		t.Fatalf("LoadResolver() error: %v", err)
+	}
+	if got := r.ResolveTarget("x.md", "alpha"); got.Path != "Notes/a.md" {
+		t.Errorf("alias lookup = %+v", got)
+	}
+	if got := r.ResolveTarget("x.md", "photo.jpg"); !got.Attachment {
+		t.Errorf("attachment lookup = %+v", got)
+	}
+	if got := r.ResolveTarget("x.md", "skip.png"); got.Status != Missing {
+		t.Errorf("hidden file resolved: %+v", got)
+	}
+}
@@ -0,0 +1,205 @@
+package vault
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// markdownDestEscaper encodes the characters that would end or break a bare
+// markdown link destination.
+var markdownDestEscaper = strings.NewReplacer("%", "%25", " ", "%20", "(", "%28", ")", "%29")
+
+// linkEdit replaces line[start:end] with text.
+type linkEdit struct {
+	start, end int
+	text       string
+}
+
+// RewriteLinks rewrites link targets in a note's body. fn is called for every
+// wikilink, markdown link and embed outside code, in the same form ParseNote
+// reports them (including Line); when it returns ok, the link's target is
+// replaced with newTarget while the fragment, display text and embed marker
+// are kept. Markdown targets have spaces, parentheses and percent signs
+// percent-encoded unless the link uses <angle brackets>. Returns the new
+// content and the number of links rewritten.
+func RewriteLinks(content string, fn func(l Link) (newTarget string, ok bool)) (string, int) {
+	body := content
+	if strings.HasPrefix(content, "---\n") || strings.HasPrefix(content, "---\r\n") {
+		if _, rest, ok := splitFrontmatter(content); ok {
+			body = rest
+		}
+	}
+	head := content[:len(content)-len(body)]
+	firstLine := strings.Count(head, "\n") + 1
+
+	lines := strings.Split(body, "\n")
+	var fence string
+	count := 0
+
+	for i, raw := range lines {
+		line := strings.TrimSuffix(raw, "\r")
+		if marker := fenceMarker(line); marker != "" {
+			if fence == "" {
+				fence = marker
+				continue
+			}
+			if marker[0] == fence[0] && len(marker) >= len(fence) && strings.TrimSpace(line) == marker {
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+
+		masked := maskInlineCode(line)
+		var edits []linkEdit
+
+		for _, m := range wikiLinkRe.FindAllStringSubmatchIndex(masked, -1) {
+			inner := line[m[4]:m[5]]
+			l := parseWikiLinkInner(inner)
+			l.Line = firstLine + i
+			l.Embed = m[3] > m[2]
+			newTarget, ok := fn(l)
+			if !ok {
+				continue
+			}
+			// The target is everything before the first '#' or '|'; a table
+			// escape ("\|") leaves a trailing backslash that must survive.
+			end := len(inner)
+			if idx := strings.IndexAny(inner, "#|"); idx >= 0 {
+				end = idx
+			}
+			if strings.HasSuffix(inner[:end], `\`) {
+				end--
+			}
+			edits = append(edits, linkEdit{start: m[4], end: m[4] + end, text: newTarget})
+		}
+
+		plain := wikiLinkRe.ReplaceAllStringFunc(masked, func(s string) string {
+			return strings.Repeat(" ", len(s))
+		})
+		for _, m := range mdLinkRe.FindAllStringSubmatchIndex(plain, -1) {
+			destStart, destEnd, angled := m[8], m[9], false
+			if m[6] >= 0 {
+				destStart, destEnd, angled = m[6], m[7], true
+			}
+			dest := line[destStart:destEnd]
+			l, ok := parseMarkdownLink(line[m[4]:m[5]], dest)
+			if !ok {
+				continue
+			}
+			l.Line = firstLine + i
+			l.Embed = m[3] > m[2]
+			newTarget, ok := fn(l)
+			if !ok {
+				continue
+			}
+			end := len(dest)
+			if idx := strings.Index(dest, "#"); idx >= 0 {
+				end = idx
+			}
+			if !angled {
+				newTarget = markdownDestEscaper.Replace(newTarget)
+			}
+			edits = append(edits, linkEdit{start: destStart, end: destStart + end, text: newTarget})
+		}
+
+		if len(edits) == 0 {
+			continue
+		}
+		// Apply right to left so earlier offsets stay valid.
+		for a := 1; a < len(edits); a++ {
+			for b := a; b > 0 && edits[b].start > edits[b-1].start; b-- {
+				edits[b], edits[b-1] = edits[b-1], edits[b]
+			}
+		}
+		for _, e := range edits {
+			line = line[:e.start] + e.text + line[e.end:]
+		}
+		if strings.HasSuffix(raw, "\r") {
+			line += "\r"
+		}
+		lines[i] = line
+		count += len(edits)
+	}
+
+	if count == 0 {
+		return content, 0
+	}
+	return head + strings.Join(lines, "\n"), count
+}
+
+// ProseEdit replaces Start:End of a body line with Text.
+type ProseEdit struct {
+	Start, End int
+	Text       string
+}
+
+// proseURLRe matches bare URLs, which are not prose.
+var proseURLRe = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s)>\]]+`)
+
+// RewriteProse edits the plain text of a note's body. fn is called for every
+// body line outside fenced code blocks with its 1-based line number and the
+// line with inline code, wikilinks, embeds, markdown links, bare URLs and
+// #tags blanked to spaces, so offsets into it are offsets into the line. The
+// edits it returns must not overlap. Returns the new content and the number
+// of edits applied.
+func RewriteProse(content string, fn func(line int, prose string) []ProseEdit) (string, int) {
+	body := content
+	if strings.HasPrefix(content, "---\n") || strings.HasPrefix(content, "---\r\n") {
+		if _, rest, ok := splitFrontmatter(content); ok {
+			body = rest
+		}
+	}
+	head := content[:len(content)-len(body)]
+	firstLine := strings.Count(head, "\n") + 1
+
+	lines := strings.Split(body, "\n")
+	var fence string
+	count := 0
+	blank := func(s string) string { return strings.Repeat(" ", len(s)) }
+
+	for i, raw := range lines {
+		line := strings.TrimSuffix(raw, "\r")
+		if marker := fenceMarker(line); marker != "" {
+			if fence == "" {
+				fence = marker
+				continue
+			}
+			if marker[0] == fence[0] && len(marker) >= len(fence) && strings.TrimSpace(line) == marker {
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+
+		prose := maskInlineCode(line)
+		prose = wikiLinkRe.ReplaceAllStringFunc(prose, blank)
+		prose = mdLinkRe.ReplaceAllStringFunc(prose, blank)
+		prose = proseURLRe.ReplaceAllStringFunc(prose, blank)
+		prose = inlineTagRe.ReplaceAllStringFunc(prose, blank)
+
+		edits := fn(firstLine+i, prose)
+		if len(edits) == 0 {
+			continue
+		}
+		sort.Slice(edits, func(a, b int) bool { return edits[a].Start > edits[b].Start })
+		for _, e := range edits {
+			line = line[:e.Start] + e.Text + line[e.End:]
+		}
+		if strings.HasSuffix(raw, "\r") {
+			line += "\r"
+		}
+		lines[i] = line
+		count += len(edits)
+	}
+
+	if count == 0 {
+		return content, 0
+	}
+	return head + strings.Join(lines, "\n"), count
+}
@@ -0,0 +1,96 @@
+package vault
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRewriteLinks(t *testing.T) {
+	content := "---\ntitle: x\n---\n" +
+		"See [[old#Intro|the intro]] and ![[old]].\n" +
+		"| a | [[old\\|alias]] |\n" +
+		"A [markdown](old.md#Intro) link and `[[old]]` code.\n" +
+		"```\n[[old]]\n```\n" +
+		"Keep [[other]].\n"
+
+	got, n := RewriteLinks(content, func(l Link) (string, bool) {
+		if l.Target == "old" || l.Target == "old.md" {
+			if l.Kind == LinkMarkdown {
+				return "Notes/New Name.md", true
+			}
+			return "New Name", true
+		}
+		return "", false
+	})
+
+	want := "---\ntitle: x\n---\n" +
+		"See [[New Name#Intro|the intro]] and ![[New Name]].\n" +
+		"| a | [[New Name\\|alias]] |\n" +
+		"A [markdown](Notes/New%20Name.md#Intro) link and `[[old]]` code.\n" +
+		"```\n[[old]]\n```\n" +
+		"Keep [[other]].\n"
+	if n != 4 {
+		t.Errorf("RewriteLinks() count = %d, want 4", n)
+	}
+	if got != want {
+		t.Errorf("RewriteLinks() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRewriteLinks_LineNumbers(t *testing.T) {
+	content := "---\na: b\n---\n[[x]]\n[[x]]\n"
+	got, n := RewriteLinks(content, func(l Link) (string, bool) {
+		return "y", l.Line == 5
+	})
+	if n != 1 || got != "---\na: b\n---\n[[x]]\n[[y]]\n" {
+		t.Errorf("RewriteLinks() = %q (%d)", got, n)
+	}
+}
+
+func TestRewriteLinks_EscapesMarkdownTargets(t *testing.T) {
+	content := "[a](old.png) and [b](<old.png>)\n"
+	got, n := RewriteLinks(content, func(l Link) (string, bool) {
+		return "img/My (1) 100%.png", true
+	})
+	want := "[a](img/My%20%281%29%20100%25.png) and [b](<img/My (1) 100%.png>)\n"
+	if n != 2 || got != want {
+		t.Errorf("RewriteLinks() = %q (%d), want %q", got, n, want)
+	}
+	note := ParseNote(got)
+	if len(note.Links) == 0 || note.Links[0].Target != "img/My (1) 100%.png" {
+		t.Errorf("rewritten link parses as %+v", note.Links)
+	}
+}
+
+func TestRewriteProse(t *testing.T) {
+	content := "---\ntitle: Go\n---\n" +
+		"Go is fun; see [[Go]] and [Go](go.md).\n" +
+		"`Go` code, https://go.dev/Go and #Go stay.\n" +
+		"```\nGo\n```\n" +
+		"End with Go\n"
+	var lines []int
+	got, n := RewriteProse(content, func(line int, prose string) []ProseEdit {
+		lines = append(lines, line)
+		var edits []ProseEdit
+		for i := 0; ; {
+			j := strings.Index(prose[i:], "Go")
+			if j < 0 {
+				return edits
+			}
+			edits = append(edits, ProseEdit{Start: i + j, End: i + j + 2, Text: "[[Go]]"})
+			i += j + 2
+		}
+	})
+	want := "---\ntitle: Go\n---\n" +
+		"[[Go]] is fun; see [[Go]] and [Go](go.md).\n" +
+		"`Go` code, https://go.dev/Go and #Go stay.\n" +
+		"```\nGo\n```\n" +
+		"End with [[Go]]\n"
+	if n != 2 || got != want {
+		t.Errorf("RewriteProse() = %q (%d), want %q", got, n, want)
+	}
+	if wantLines := []int{4, 5, 9, 10}; !reflect.DeepEqual(lines, wantLines) {
+		t.Errorf("lines = %v, want %v", lines, wantLines)
+	}
+}
@@ -0,0 +1,155 @@
+// Package webpage fetches web pages and extracts their main article as
+// markdown together with page metadata, for capturing reference notes.
+// HTML is parsed with a small lenient parser and the article is found with
+// readability-style scoring; no browser or third-party parser is involved.
+package webpage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Defaults for Options.
+const (
+	DefaultMaxBytes  = 5 << 20 // 5 MB
+	DefaultMaxChars  = 100000
+	DefaultTimeout   = 20 * time.Second
+	DefaultUserAgent = "obsidian-cli (+https://github.com/joeyhipolito/obsidian-cli)"
+)
+
+// Options limits what Fetch downloads and keeps.
+type Options struct {
+	MaxBytes  int64         // maximum response body size (0 = DefaultMaxBytes)
+	MaxChars  int           // maximum markdown length kept (0 = DefaultMaxChars)
+	Timeout   time.Duration // whole-request timeout (0 = DefaultTimeout)
+	UserAgent string
+	Client    *http.Client // nil = a new client with Timeout
+}
+
+// Fetch downloads an http(s) URL and extracts its article. HTML pages go
+// through Extract; plain-text pages are kept as they are. Responses larger
+// than MaxBytes are rejected, and markdown longer than MaxChars is cut at a
+// paragraph boundary with Page.Truncated set.
+func Fetch(ctx context.Context, rawURL string, opts Options) (*Page, error) {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = DefaultMaxChars
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: opts.Timeout}
+	}
+
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: only http and https URLs can be fetched", rawURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("fetching %s: HTTP %d", u, resp.StatusCode)
+	}
+	if resp.ContentLength > opts.MaxBytes {
+		return nil, fmt.Errorf("fetching %s: response is %d bytes, over the %d byte limit", u, resp.ContentLength, opts.MaxBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, opts.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", u, err)
+	}
+	if int64(len(data)) > opts.MaxBytes {
+		return nil, fmt.Errorf("fetching %s: response is over the %d byte limit", u, opts.MaxBytes)
+	}
+
+	finalURL := u.String()
+	if resp.Request != nil && resp.Request.URL != nil {
+		finalURL = resp.Request.URL.String() // after redirects
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+	text := decodeText(data)
+
+	var page *Page
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		page = Extract(text, finalURL)
+	case "text/plain", "text/markdown":
+		page = &Page{URL: finalURL, Markdown: strings.TrimSpace(text)}
+		if parsed, err := url.Parse(finalURL); err == nil {
+			page.Site = strings.TrimPrefix(parsed.Hostname(), "www.")
+		}
+	default:
+		return nil, fmt.Errorf("fetching %s: unsupported content type %q", u, mediaType)
+	}
+
+	page.Markdown, page.Truncated = truncateMarkdown(page.Markdown, opts.MaxChars)
+	return page, nil
+}
+
+// decodeText converts a response body to UTF-8. Bodies that are not valid
+// UTF-8 are decoded as Latin-1, the usual fallback for legacy pages.
+func decodeText(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	runes := make([]rune, len(data))
+	for i, c := range data {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// truncateMarkdown cuts md to at most maxChars characters, ending at the last
+// paragraph break (or line break) that fits.
+func truncateMarkdown(md string, maxChars int) (string, bool) {
+	if utf8.RuneCountInString(md) <= maxChars {
+		return md, false
+	}
+	cut := len(md)
+	for i := range md {
+		if maxChars == 0 {
+			cut = i
+			break
+		}
+		maxChars--
+	}
+	head := md[:cut]
+	if i := strings.LastIndex(head, "\n\n"); i > len(head)/2 {
+		head = head[:i]
+	} else if i := strings.LastIndex(head, "\n"); i > len(head)/2 {
+		head = head[:i]
+	}
+	return strings.TrimSpace(head), true
+}
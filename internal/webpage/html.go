@@ -0,0 +1,330 @@
+package webpage
+
+import (
+	"html"
+	"strings"
+)
+
+// nodeType distinguishes element and text nodes.
+type nodeType int
+
+const (
+	elementNode nodeType = iota
+	textNode
+)
+
+// node is an element or text node of a parsed HTML document.
+type node struct {
+	typ      nodeType
+	tag      string            // lowercase element name
+	attrs    map[string]string // lowercase attribute names, unescaped values
+	text     string            // text nodes: unescaped text
+	parent   *node
+	children []*node
+}
+
+// attr returns the value of an attribute, or "".
+func (n *node) attr(name string) string {
+	return n.attrs[name]
+}
+
+// voidElements never have children or end tags.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
+
+// rawTextElements contain unparsed text up to their end tag.
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true, "noscript": true,
+}
+
+// closesParagraph lists elements whose start tag implicitly ends an open <p>.
+var closesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "div": true,
+	"dl": true, "fieldset": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "ul": true,
+}
+
+// parseHTML builds a tree from an HTML document. It is lenient in the way
+// browsers are for the cases that matter to article extraction: void and
+// raw-text elements, implicitly closed <p>, <li>, <dt>/<dd>, <tr> and <td>,
+// and stray end tags. It does not implement the full HTML5 algorithm.
+func parseHTML(src string) *node {
+	root := &node{typ: elementNode, tag: "#document"}
+	cur := root
+
+	appendChild := func(n *node) {
+		n.parent = cur
+		cur.children = append(cur.children, n)
+	}
+	// closeTo pops the open-element stack up to and including the nearest
+	// element named tag. It reports false when no such element is open.
+	closeTo := func(tag string, stopAt ...string) bool {
+		for n := cur; n != root; n = n.parent {
+			for _, s := range stopAt {
+				if n.tag == s {
+					return false
+				}
+			}
+			if n.tag == tag {
+				cur = n.parent
+				return true
+			}
+		}
+		return false
+	}
+
+	i := 0
+	for i < len(src) {
+		lt := strings.IndexByte(src[i:], '<')
+		if lt < 0 {
+			appendChild(&node{typ: textNode, text: html.UnescapeString(src[i:])})
+			break
+		}
+		if lt > 0 {
+			appendChild(&node{typ: textNode, text: html.UnescapeString(src[i : i+lt])})
+		}
+		i += lt
+
+		switch {
+		case strings.HasPrefix(src[i:], "<!--"):
+			end := strings.Index(src[i+4:], "-->")
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += 4 + end + 3
+			}
+			continue
+		case strings.HasPrefix(src[i:], "<!"), strings.HasPrefix(src[i:], "<?"):
+			end := strings.IndexByte(src[i:], '>')
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 1
+			}
+			continue
+		}
+
+		tag, attrs, closing, selfClosing, n := parseTag(src[i:])
+		if n == 0 {
+			// Not a tag: treat "<" as text.
+			appendChild(&node{typ: textNode, text: "<"})
+			i++
+			continue
+		}
+		i += n
+
+		if closing {
+			switch tag {
+			case "p":
+				// A stray </p> is ignored rather than closing an outer block.
+				closeTo("p", "div", "section", "article", "main", "body", "td", "li", "blockquote")
+			case "li":
+				closeTo("li", "ul", "ol")
+			case "td", "th":
+				closeTo(tag, "tr", "table")
+			case "tr":
+				closeTo("tr", "table")
+			default:
+				closeTo(tag)
+			}
+			continue
+		}
+
+		// Implied end tags.
+		if closesParagraph[tag] {
+			closeTo("p", "div", "section", "article", "main", "body", "td", "li", "blockquote", "button")
+		}
+		switch tag {
+		case "li":
+			closeTo("li", "ul", "ol")
+		case "dt", "dd":
+			if !closeTo("dt", "dl") {
+				closeTo("dd", "dl")
+			}
+		case "tr":
+			closeTo("tr", "table")
+		case "td", "th":
+			if !closeTo("td", "tr", "table") {
+				closeTo("th", "tr", "table")
+			}
+		case "option":
+			closeTo("option", "select")
+		}
+
+		el := &node{typ: elementNode, tag: tag, attrs: attrs}
+		appendChild(el)
+		if voidElements[tag] || selfClosing {
+			continue
+		}
+		if rawTextElements[tag] {
+			end := indexEndTag(src[i:], tag)
+			if end < 0 {
+				end = len(src) - i
+			}
+			text := src[i : i+end]
+			if tag == "title" || tag == "textarea" {
+				text = html.UnescapeString(text)
+			}
+			el.children = []*node{{typ: textNode, text: text, parent: el}}
+			i += end
+			if gt := strings.IndexByte(src[i:], '>'); gt >= 0 {
+				i += gt + 1
+			} else {
+				i = len(src)
+			}
+			continue
+		}
+		cur = el
+	}
+	return root
+}
+
+// parseTag parses a start or end tag at the beginning of s. n is the number
+// of bytes consumed, or 0 when s does not start with a tag.
+func parseTag(s string) (tag string, attrs map[string]string, closing, selfClosing bool, n int) {
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && isNameByte(s[i]) {
+		i++
+	}
+	if i == start || !isLetter(s[start]) {
+		return "", nil, false, false, 0
+	}
+	tag = strings.ToLower(s[start:i])
+
+	attrs = make(map[string]string)
+	for i < len(s) {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '>' {
+			return tag, attrs, closing, selfClosing, i + 1
+		}
+		if s[i] == '/' {
+			selfClosing = true
+			i++
+			continue
+		}
+		nameStart := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(s[nameStart:i])
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		value := ""
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				q := s[i]
+				end := strings.IndexByte(s[i+1:], q)
+				if end < 0 {
+					return "", nil, false, false, 0
+				}
+				value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				vStart := i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				value = s[vStart:i]
+			}
+		}
+		if name != "" {
+			if _, dup := attrs[name]; !dup {
+				attrs[name] = html.UnescapeString(value)
+			}
+		} else {
+			i++ // skip a stray character
+		}
+	}
+	return "", nil, false, false, 0
+}
+
+// indexEndTag returns the index of the end tag </tag> in s, matching the
+// name case-insensitively, or -1.
+func indexEndTag(s, tag string) int {
+	for off := 0; ; {
+		j := strings.Index(s[off:], "</")
+		if j < 0 {
+			return -1
+		}
+		j += off
+		if end := j + 2 + len(tag); end <= len(s) && strings.EqualFold(s[j+2:end], tag) {
+			return j
+		}
+		off = j + 2
+	}
+}
+
+func isNameByte(c byte) bool {
+	return isLetter(c) || c >= '0' && c <= '9' || c == '-' || c == ':' || c == '_'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// walk calls f for n and every descendant in document order. Returning false
+// from f skips the node's children.
+func walk(n *node, f func(*node) bool) {
+	if !f(n) {
+		return
+	}
+	for _, c := range n.children {
+		walk(c, f)
+	}
+}
+
+// find returns the first element named tag under n, or nil.
+func find(n *node, tag string) *node {
+	var found *node
+	walk(n, func(c *node) bool {
+		if found != nil {
+			return false
+		}
+		if c.typ == elementNode && c.tag == tag {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// textContent returns the concatenated text under n with whitespace collapsed.
+func textContent(n *node) string {
+	var b strings.Builder
+	walk(n, func(c *node) bool {
+		if c.typ == elementNode && (c.tag == "script" || c.tag == "style" || c.tag == "noscript") {
+			return false
+		}
+		if c.typ == textNode {
+			b.WriteString(c.text)
+			b.WriteByte(' ')
+		}
+		return true
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,320 @@
+package webpage
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// skippedElements never contribute to extracted content.
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "nav": true,
+	"aside": true, "footer": true, "form": true, "button": true, "iframe": true,
+	"svg": true, "select": true, "input": true, "textarea": true, "dialog": true,
+	"head": true, "title": true, "meta": true, "link": true, "object": true,
+}
+
+var (
+	mdBlankLinesRe = regexp.MustCompile(`\n{3,}`)
+	mdLangRe       = regexp.MustCompile(`(?:^|\s)(?:language|lang)-([\w+#-]+)`)
+)
+
+// mdRenderer converts an element subtree to markdown.
+type mdRenderer struct {
+	base *url.URL // for resolving relative links and images
+}
+
+// toMarkdown renders the nodes as one markdown document.
+func (r *mdRenderer) toMarkdown(nodes []*node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(r.render(n))
+	}
+	return cleanMarkdown(b.String())
+}
+
+// cleanMarkdown trims trailing spaces and collapses runs of blank lines.
+func cleanMarkdown(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	s = strings.Join(lines, "\n")
+	s = mdBlankLinesRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// block wraps rendered content as a paragraph-level block.
+func block(content string) string {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return ""
+	}
+	return "\n\n" + content + "\n\n"
+}
+
+func (r *mdRenderer) children(n *node) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(r.render(c))
+	}
+	return b.String()
+}
+
+// inline renders children as a single line.
+func (r *mdRenderer) inline(n *node) string {
+	return strings.Join(strings.Fields(r.children(n)), " ")
+}
+
+func (r *mdRenderer) render(n *node) string {
+	if n.typ == textNode {
+		return collapseSpace(n.text)
+	}
+	if skippedElements[n.tag] || isHidden(n) {
+		return ""
+	}
+
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := r.inline(n)
+		if text == "" {
+			return ""
+		}
+		return "\n\n" + strings.Repeat("#", int(n.tag[1]-'0')) + " " + text + "\n\n"
+
+	case "br":
+		return "\n"
+	case "hr":
+		return "\n\n---\n\n"
+
+	case "a":
+		text := r.inline(n)
+		href := r.resolve(n.attr("href"))
+		if text == "" || href == "" {
+			return text
+		}
+		return "[" + text + "](" + href + ")"
+
+	case "img":
+		src := n.attr("src")
+		if src == "" || strings.HasPrefix(src, "data:") {
+			src = n.attr("data-src")
+		}
+		src = r.resolve(src)
+		if src == "" {
+			return ""
+		}
+		return "![" + collapseSpace(n.attr("alt")) + "](" + src + ")"
+
+	case "strong", "b":
+		return wrapInline(r.children(n), "**")
+	case "em", "i":
+		return wrapInline(r.children(n), "*")
+	case "del", "s", "strike":
+		return wrapInline(r.children(n), "~~")
+	case "code", "kbd", "samp":
+		text := strings.TrimSpace(rawText(n))
+		if text == "" {
+			return ""
+		}
+		fence := "`"
+		if strings.Contains(text, "`") {
+			fence = "``"
+		}
+		return fence + text + fence
+
+	case "pre":
+		lang := ""
+		if m := mdLangRe.FindStringSubmatch(n.attr("class")); m != nil {
+			lang = m[1]
+		} else if code := find(n, "code"); code != nil {
+			if m := mdLangRe.FindStringSubmatch(code.attr("class")); m != nil {
+				lang = m[1]
+			}
+		}
+		code := strings.Trim(rawText(n), "\n")
+		if strings.TrimSpace(code) == "" {
+			return ""
+		}
+		return "\n\n```" + lang + "\n" + code + "\n```\n\n"
+
+	case "blockquote":
+		content := cleanMarkdown(r.children(n))
+		if content == "" {
+			return ""
+		}
+		lines := strings.Split(content, "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+
+	case "ul", "ol":
+		return r.list(n)
+
+	case "li": // outside a list
+		return block("- " + r.inline(n))
+
+	case "dt":
+		return block(wrapInline(r.inline(n), "**"))
+
+	case "table":
+		return r.table(n)
+
+	case "p", "div", "section", "article", "main", "header", "figure", "figcaption",
+		"dl", "dd", "address", "details", "summary", "center", "body", "html", "#document":
+		return block(r.children(n))
+	}
+	return r.children(n)
+}
+
+// list renders a ul or ol, indenting nested blocks under their item.
+func (r *mdRenderer) list(n *node) string {
+	var items []string
+	num := 1
+	for _, c := range n.children {
+		if c.typ != elementNode || c.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if n.tag == "ol" {
+			marker = fmt.Sprintf("%d. ", num)
+			num++
+		}
+		content := cleanMarkdown(r.children(c))
+		if content == "" {
+			continue
+		}
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(content, "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return "\n\n" + strings.Join(items, "\n") + "\n\n"
+}
+
+// table renders a table as a pipe table, using the first row as the header.
+func (r *mdRenderer) table(n *node) string {
+	var rows [][]string
+	walk(n, func(c *node) bool {
+		if c.typ != elementNode || c.tag != "tr" {
+			return true
+		}
+		var cells []string
+		for _, cell := range c.children {
+			if cell.typ == elementNode && (cell.tag == "td" || cell.tag == "th") {
+				cells = append(cells, strings.ReplaceAll(r.inline(cell), "|", `\|`))
+			}
+		}
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+		return false
+	})
+	if len(rows) == 0 {
+		return ""
+	}
+	if len(rows) == 1 && len(rows[0]) == 1 {
+		return block(rows[0][0]) // layout table
+	}
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	var b strings.Builder
+	for i, row := range rows {
+		for len(row) < cols {
+			row = append(row, "")
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+		}
+	}
+	return "\n\n" + b.String() + "\n"
+}
+
+// resolve makes a link absolute against the page URL. Fragment-only and
+// javascript: links resolve to "".
+func (r *mdRenderer) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if r.base != nil {
+		u = r.base.ResolveReference(u)
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(u.String(), "(", "%28"), ")", "%29")
+}
+
+// wrapInline wraps text in a markdown marker, keeping surrounding spaces
+// outside the marker.
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+// rawText returns the text under n without collapsing whitespace.
+func rawText(n *node) string {
+	var b strings.Builder
+	walk(n, func(c *node) bool {
+		if c.typ == textNode {
+			b.WriteString(c.text)
+		} else if c.tag == "br" {
+			b.WriteByte('\n')
+		}
+		return true
+	})
+	return b.String()
+}
+
+// collapseSpace replaces runs of whitespace with a single space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == ' ' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// isHidden reports whether an element is hidden from readers.
+func isHidden(n *node) bool {
+	if _, ok := n.attrs["hidden"]; ok {
+		return true
+	}
+	if n.attr("aria-hidden") == "true" {
+		return true
+	}
+	style := strings.ReplaceAll(strings.ToLower(n.attr("style")), " ", "")
+	return strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden")
+}
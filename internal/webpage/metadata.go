@@ -0,0 +1,165 @@
+package webpage
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Page is an article extracted from a web page.
+type Page struct {
+	URL         string `json:"url"` // canonical URL when the page declares one
+	Title       string `json:"title,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Published   string `json:"published,omitempty"` // YYYY-MM-DD
+	Site        string `json:"site,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+	Markdown    string `json:"markdown"`
+	Truncated   bool   `json:"truncated,omitempty"`
+}
+
+var titleSeparatorRe = regexp.MustCompile(`\s+[|–—·-]\s+[^|–—·-]+$`)
+
+// Extract parses an HTML document fetched from pageURL and returns its
+// metadata and main content as markdown. Metadata prefers OpenGraph and
+// article tags, then standard meta tags, then the document itself.
+func Extract(htmlSrc, pageURL string) *Page {
+	doc := parseHTML(htmlSrc)
+	base, _ := url.Parse(pageURL)
+
+	metas := make(map[string]string) // first value wins
+	var canonical, relAuthor, timeAttr string
+	walk(doc, func(n *node) bool {
+		if n.typ != elementNode {
+			return true
+		}
+		switch n.tag {
+		case "meta":
+			key := strings.ToLower(firstNonEmpty(n.attr("property"), n.attr("name"), n.attr("itemprop")))
+			if content := strings.TrimSpace(n.attr("content")); key != "" && content != "" {
+				if _, ok := metas[key]; !ok {
+					metas[key] = content
+				}
+			}
+		case "link":
+			rel := strings.ToLower(n.attr("rel"))
+			if rel == "canonical" && canonical == "" {
+				canonical = n.attr("href")
+			}
+		case "a":
+			if strings.ToLower(n.attr("rel")) == "author" && relAuthor == "" {
+				relAuthor = textContent(n)
+			}
+		case "time":
+			if timeAttr == "" {
+				timeAttr = n.attr("datetime")
+			}
+		}
+		if n.attr("itemprop") == "author" && n.tag != "meta" {
+			if _, ok := metas["itemprop:author"]; !ok {
+				metas["itemprop:author"] = textContent(n)
+			}
+		}
+		return true
+	})
+
+	p := &Page{URL: pageURL}
+	if u := resolveURL(base, firstNonEmpty(canonical, metas["og:url"])); u != "" {
+		p.URL = u
+	}
+
+	var docTitle, h1 string
+	if t := find(doc, "title"); t != nil {
+		docTitle = textContent(t)
+	}
+	if h := find(doc, "h1"); h != nil {
+		h1 = textContent(h)
+	}
+	p.Title = firstNonEmpty(metas["og:title"], metas["twitter:title"], h1, titleSeparatorRe.ReplaceAllString(docTitle, ""))
+	p.Author = firstNonEmpty(metas["author"], metas["article:author"], metas["itemprop:author"], metas["twitter:creator"], relAuthor)
+	if strings.HasPrefix(p.Author, "http://") || strings.HasPrefix(p.Author, "https://") {
+		p.Author = "" // article:author is often a profile URL
+	}
+	p.Published = normalizeDate(firstNonEmpty(
+		metas["article:published_time"], metas["datepublished"], metas["date"],
+		metas["dc.date"], metas["dcterms.created"], metas["pubdate"], timeAttr,
+	))
+	p.Site = metas["og:site_name"]
+	if p.Site == "" && base != nil {
+		p.Site = strings.TrimPrefix(base.Hostname(), "www.")
+	}
+	p.Description = firstNonEmpty(metas["og:description"], metas["description"], metas["twitter:description"])
+	p.Image = resolveURL(base, metas["og:image"])
+
+	r := &mdRenderer{base: base}
+	p.Markdown = r.toMarkdown(extractContent(doc))
+	p.Markdown = dropLeadingTitle(p.Markdown, p.Title)
+	return p
+}
+
+// dropLeadingTitle removes a first heading that repeats the page title,
+// since the note carries the title in frontmatter.
+func dropLeadingTitle(md, title string) string {
+	if title == "" || !strings.HasPrefix(md, "#") {
+		return md
+	}
+	line, rest, _ := strings.Cut(md, "\n")
+	if strings.EqualFold(strings.TrimSpace(strings.TrimLeft(line, "#")), title) {
+		return strings.TrimSpace(rest)
+	}
+	return md
+}
+
+// dateLayouts are the published-date formats seen in the wild, most
+// specific first.
+var dateLayouts = []string{
+	time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05",
+	"2006-01-02", "2006/01/02", "January 2, 2006", "Jan 2, 2006", "2 January 2006",
+	time.RFC1123, time.RFC1123Z,
+}
+
+// normalizeDate converts a date in a common format to YYYY-MM-DD, or "".
+func normalizeDate(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	if len(s) >= 10 {
+		if t, err := time.Parse("2006-01-02", s[:10]); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// resolveURL makes href absolute against base, or returns "".
+func resolveURL(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package webpage
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	unlikelyRe = regexp.MustCompile(`(?i)comment|sidebar|footer|nav|menu|share|social|related|promo|advert|\bads?\b|cookie|banner|newsletter|subscribe|popup|modal|breadcrumb`)
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|main|post|text|story|blog`)
+	negativeRe = regexp.MustCompile(`(?i)comment|sidebar|footer|nav|menu|share|social|related|promo|advert|meta|widget|sponsor|hidden|masthead`)
+)
+
+// scoredTags are the elements whose text scores their ancestors.
+var scoredTags = map[string]bool{
+	"p": true, "pre": true, "td": true, "blockquote": true, "li": true,
+	"h2": true, "h3": true, "section": true,
+}
+
+// unlikely reports whether an element's class or id marks it as page chrome
+// rather than content.
+func unlikely(n *node) bool {
+	if n.tag == "body" || n.tag == "html" || n.tag == "article" || n.tag == "main" {
+		return false
+	}
+	id := n.attr("class") + " " + n.attr("id")
+	return unlikelyRe.MatchString(id) && !positiveRe.MatchString(id)
+}
+
+// classWeight scores an element's class and id: +25 for content-like names,
+// -25 for chrome-like names.
+func classWeight(n *node) float64 {
+	w := 0.0
+	for _, s := range []string{n.attr("class"), n.attr("id")} {
+		if s == "" {
+			continue
+		}
+		if negativeRe.MatchString(s) {
+			w -= 25
+		}
+		if positiveRe.MatchString(s) {
+			w += 25
+		}
+	}
+	return w
+}
+
+// tagWeight is the initial score of a candidate container.
+func tagWeight(tag string) float64 {
+	switch tag {
+	case "article":
+		return 10
+	case "div", "main":
+		return 5
+	case "pre", "td", "blockquote", "section":
+		return 3
+	case "ol", "ul", "dl", "dd", "dt", "li", "form", "address":
+		return -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		return -5
+	}
+	return 0
+}
+
+// linkDensity is the share of an element's text that sits inside links.
+func linkDensity(n *node) float64 {
+	text := len(textContent(n))
+	if text == 0 {
+		return 0
+	}
+	links := 0
+	walk(n, func(c *node) bool {
+		if c.typ == elementNode && c.tag == "a" {
+			links += len(textContent(c))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(text)
+}
+
+// pruneUnlikely removes page chrome and hidden elements from the tree.
+func pruneUnlikely(n *node) {
+	kept := n.children[:0]
+	for _, c := range n.children {
+		if c.typ == elementNode && (skippedElements[c.tag] || unlikely(c) || isHidden(c)) {
+			continue
+		}
+		pruneUnlikely(c)
+		kept = append(kept, c)
+	}
+	n.children = kept
+}
+
+// extractContent picks the element most likely to hold the article, using
+// the Arc90 readability heuristics: paragraphs score their parent and
+// grandparent by length and comma count, containers are weighted by tag and
+// class name and penalised for link density, and siblings of the winner that
+// score well or read as prose are kept alongside it. It returns the nodes to
+// render, falling back to the whole body.
+func extractContent(doc *node) []*node {
+	body := find(doc, "body")
+	if body == nil {
+		body = doc
+	}
+	pruneUnlikely(body)
+
+	scores := make(map[*node]float64)
+	var candidates []*node
+	initialise := func(n *node) {
+		if _, ok := scores[n]; !ok {
+			scores[n] = tagWeight(n.tag) + classWeight(n)
+			candidates = append(candidates, n)
+		}
+	}
+
+	walk(body, func(n *node) bool {
+		if n.typ != elementNode || !scoredTags[n.tag] || n.parent == nil {
+			return true
+		}
+		text := textContent(n)
+		if len(text) < 25 {
+			return true
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		parent := n.parent
+		initialise(parent)
+		scores[parent] += score
+		if gp := parent.parent; gp != nil && gp.tag != "#document" {
+			initialise(gp)
+			scores[gp] += score / 2
+		}
+		return true
+	})
+
+	var top *node
+	topScore := 0.0
+	for _, c := range candidates {
+		s := scores[c] * (1 - linkDensity(c))
+		scores[c] = s
+		if top == nil || s > topScore {
+			top, topScore = c, s
+		}
+	}
+	if top == nil || top.parent == nil {
+		return []*node{body}
+	}
+
+	threshold := max(10, topScore*0.2)
+	var nodes []*node
+	for _, sib := range top.parent.children {
+		if sib == top {
+			nodes = append(nodes, sib)
+			continue
+		}
+		if sib.typ != elementNode {
+			continue
+		}
+		if s, ok := scores[sib]; ok && s >= threshold {
+			nodes = append(nodes, sib)
+			continue
+		}
+		if sib.tag == "p" {
+			text := textContent(sib)
+			if len(text) > 80 && linkDensity(sib) < 0.25 {
+				nodes = append(nodes, sib)
+			}
+		}
+	}
+	return nodes
+}
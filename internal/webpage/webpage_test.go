@@ -0,0 +1,189 @@
+package webpage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const articleHTML = `<!DOCTYPE html>
+<html><head>
+<title>Shipping Faster | Example Blog</title>
+<meta property="og:title" content="Shipping Faster with Sharded Tests">
+<meta property="og:site_name" content="Example Blog">
+<meta name="description" content="How we cut CI time in half.">
+<meta name="author" content="Ada Lovelace">
+<meta property="article:published_time" content="2025-03-14T09:30:00Z">
+<link rel="canonical" href="/posts/shipping-faster">
+<script>var tracking = "<p>not content</p>";</script>
+</head>
+<body>
+<nav class="site-nav"><a href="/">Home</a> <a href="/about">About</a></nav>
+<div class="sidebar"><p>Subscribe to our newsletter for weekly updates, tips, and more things.</p></div>
+<article class="post">
+<h1>Shipping Faster with Sharded Tests</h1>
+<p>Our integration suite had grown to forty minutes, which meant every change waited, reviewers lost context, and releases slipped.</p>
+<p>We split the suite into <strong>eight shards</strong>, cached module downloads, and ran them in parallel; see <a href="/posts/caching">the caching post</a> for details.</p>
+<h2>Results</h2>
+<ul><li>CI time dropped to 18 minutes</li><li>Flaky tests became visible</li></ul>
+<pre><code class="language-yaml">jobs:
+  test:
+    strategy: {matrix: {shard: [1, 2]}}
+</code></pre>
+<p>Sharding is not free, however: each shard pays the setup cost, so small suites get slower rather than faster.</p>
+</article>
+<div class="comments"><p>Great post, thanks so much for sharing this with everyone, really useful!</p></div>
+<footer><p>Copyright 2025, Example Blog, all rights reserved, no reproduction.</p></footer>
+</body></html>`
+
+func TestExtract(t *testing.T) {
+	p := Extract(articleHTML, "https://www.example.com/posts/shipping-faster?utm_source=x")
+
+	if p.Title != "Shipping Faster with Sharded Tests" || p.Author != "Ada Lovelace" || p.Published != "2025-03-14" {
+		t.Errorf("metadata = %+v", p)
+	}
+	if p.Site != "Example Blog" || p.Description != "How we cut CI time in half." {
+		t.Errorf("site/description = %q / %q", p.Site, p.Description)
+	}
+	if p.URL != "https://www.example.com/posts/shipping-faster" {
+		t.Errorf("URL = %q", p.URL)
+	}
+
+	for _, want := range []string{
+		"Our integration suite had grown to forty minutes",
+		"**eight shards**",
+		"[the caching post](https://www.example.com/posts/caching)",
+		"## Results",
+		"- CI time dropped to 18 minutes",
+		"```yaml\njobs:\n  test:",
+		"Sharding is not free",
+	} {
+		if !strings.Contains(p.Markdown, want) {
+			t.Errorf("markdown missing %q:\n%s", want, p.Markdown)
+		}
+	}
+	for _, unwanted := range []string{"Home", "newsletter", "Great post", "Copyright", "tracking", "# Shipping Faster"} {
+		if strings.Contains(p.Markdown, unwanted) {
+			t.Errorf("markdown contains %q:\n%s", unwanted, p.Markdown)
+		}
+	}
+}
+
+func TestExtract_Fallbacks(t *testing.T) {
+	src := `<html><head><title>Plain Page - Site</title></head><body>
+<div><p>First paragraph of a page without any metadata, long enough to score.</p>
+<p>Published <time datetime="2024-11-02">Nov 2</time> by <a rel="author" href="/me">Grace</a>.</p></div>
+</body></html>`
+	p := Extract(src, "https://www.notes.dev/a")
+	if p.Title != "Plain Page" || p.Site != "notes.dev" || p.Published != "2024-11-02" || p.Author != "Grace" {
+		t.Errorf("page = %+v", p)
+	}
+	if !strings.Contains(p.Markdown, "First paragraph") {
+		t.Errorf("markdown = %q", p.Markdown)
+	}
+}
+
+func TestParseHTML_ImpliedEndTags(t *testing.T) {
+	doc := parseHTML(`<ul><li>one<li>two</ul><p>a<p>b<table><tr><td>x<td>y</table>`)
+	var tags []string
+	walk(doc, func(n *node) bool {
+		if n.typ == elementNode && n.parent != nil {
+			tags = append(tags, n.parent.tag+">"+n.tag)
+		}
+		return true
+	})
+	got := strings.Join(tags, " ")
+	want := "#document>ul ul>li ul>li #document>p #document>p #document>table table>tr tr>td tr>td"
+	if got != want {
+		t.Errorf("tree = %s\nwant   %s", got, want)
+	}
+}
+
+func TestMarkdown_Table(t *testing.T) {
+	doc := parseHTML(`<table><tr><th>Name</th><th>Score</th></tr><tr><td>a|b</td><td>1</td></tr></table>`)
+	got := (&mdRenderer{}).toMarkdown([]*node{doc})
+	want := "| Name | Score |\n| --- | --- |\n| a\\|b | 1 |"
+	if got != want {
+		t.Errorf("table = %q, want %q", got, want)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("no User-Agent sent")
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, articleHTML)
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "just text\n")
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+		w.Write([]byte("caf\xe9"))
+	})
+	mux.HandleFunc("/pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		fmt.Fprint(w, "%PDF-1.4")
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ctx := context.Background()
+
+	p, err := Fetch(ctx, srv.URL+"/old", Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if p.Title != "Shipping Faster with Sharded Tests" || p.URL != srv.URL+"/posts/shipping-faster" || p.Truncated {
+		t.Errorf("page = %+v", p)
+	}
+
+	if p, err := Fetch(ctx, srv.URL+"/plain", Options{}); err != nil || p.Markdown != "just text" {
+		t.Errorf("plain = %+v, %v", p, err)
+	}
+	if p, err := Fetch(ctx, srv.URL+"/latin1", Options{}); err != nil || p.Markdown != "café" {
+		t.Errorf("latin1 = %+v, %v", p, err)
+	}
+
+	for path, want := range map[string]string{
+		"/pdf":     "unsupported content type",
+		"/missing": "HTTP 404",
+	} {
+		if _, err := Fetch(ctx, srv.URL+path, Options{}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Fetch(%s) error = %v, want %q", path, err, want)
+		}
+	}
+	if _, err := Fetch(ctx, "file:///etc/passwd", Options{}); err == nil {
+		t.Error("Fetch(file://) succeeded")
+	}
+}
+
+func TestFetch_Limits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, articleHTML)
+	}))
+	defer srv.Close()
+
+	if _, err := Fetch(context.Background(), srv.URL, Options{MaxBytes: 100}); err == nil || !strings.Contains(err.Error(), "byte limit") {
+		t.Errorf("MaxBytes error = %v", err)
+	}
+
+	p, err := Fetch(context.Background(), srv.URL, Options{MaxChars: 200})
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if !p.Truncated || len(p.Markdown) > 200 || strings.HasSuffix(p.Markdown, "\n") {
+		t.Errorf("truncated = %v, markdown (%d) = %q", p.Truncated, len(p.Markdown), p.Markdown)
+	}
+}